@@ -0,0 +1,219 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/token"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// AccessTokenController implements the access-token resource.
+type AccessTokenController struct {
+	*goa.Controller
+	db           application.DB
+	tokenManager token.Manager
+}
+
+// NewAccessTokenController creates an access-token controller.
+func NewAccessTokenController(service *goa.Service, db application.DB, tokenManager token.Manager) *AccessTokenController {
+	if db == nil {
+		panic("db must not be nil")
+	}
+	return &AccessTokenController{Controller: service.NewController("AccessTokenController"), db: db, tokenManager: tokenManager}
+}
+
+// List runs the list action.
+func (c *AccessTokenController) List(ctx *app.ListAccessTokenContext) error {
+	identityID, err := c.tokenManager.Locate(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		tokens, err := appl.AccessTokens().List(ctx.Context, identityID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(convertAccessTokensFromModel(ctx.RequestData, tokens))
+	})
+}
+
+// Show runs the show action.
+func (c *AccessTokenController) Show(ctx *app.ShowAccessTokenContext) error {
+	identityID, err := c.tokenManager.Locate(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		t, err := loadManagedAccessToken(ctx.Context, appl, identityID, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(&app.AccessToken{
+			Data: convertAccessTokenFromModel(ctx.RequestData, t, nil),
+		})
+	})
+}
+
+// Create runs the create action.
+func (c *AccessTokenController) Create(ctx *app.CreateAccessTokenContext) error {
+	identityID, err := c.tokenManager.Locate(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+	attributes := ctx.Payload.Data.Attributes
+	if attributes == nil || attributes.Name == nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("data.attributes.name", nil).Expected("not nil"))
+		return ctx.BadRequest(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		ident, err := loadIdentity(ctx.Context, appl, identityID)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+			return ctx.Unauthorized(jerrors)
+		}
+		data, err := createAccessToken(ctx.Context, appl, c.tokenManager, ctx.RequestData, ident, attributes)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		ctx.ResponseData.Header().Set("Location", app.AccessTokenHref(*data.ID))
+		return ctx.Created(&app.AccessToken{Data: data})
+	})
+}
+
+// Revoke runs the revoke action.
+func (c *AccessTokenController) Revoke(ctx *app.RevokeAccessTokenContext) error {
+	identityID, err := c.tokenManager.Locate(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		t, err := loadManagedAccessToken(ctx.Context, appl, identityID, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		err = appl.AccessTokens().Revoke(ctx.Context, t.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// createAccessToken creates an access token for the identity from the given
+// attributes and returns it along with the signed token.
+func createAccessToken(ctx context.Context, appl application.Application, tokenManager token.Manager, request *goa.RequestData, ident *account.Identity, attributes *app.AccessTokenAttributes) (*app.AccessTokenData, error) {
+	for _, scope := range attributes.Scopes {
+		if !isAccessTokenScope(scope) {
+			return nil, errors.NewBadParameterError("data.attributes.scopes", scope).Expected(AccessTokenScopes)
+		}
+	}
+	t := account.AccessToken{
+		IdentityID: ident.ID,
+		Name:       *attributes.Name,
+		Scopes:     strings.Join(attributes.Scopes, " "),
+		ExpiresAt:  attributes.ExpiresAt,
+	}
+	if err := appl.AccessTokens().Create(ctx, &t); err != nil {
+		return nil, err
+	}
+	tokenString, err := tokenManager.GenerateAccessToken(*ident, t)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return convertAccessTokenFromModel(request, &t, &tokenString), nil
+}
+
+// isAccessTokenScope returns true if an access token can be limited to the
+// given permission
+func isAccessTokenScope(scope string) bool {
+	for _, s := range AccessTokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// loadIdentity loads the identity with the given ID, turning an unknown ID
+// into an errors.NotFoundError.
+func loadIdentity(ctx context.Context, appl application.Application, id uuid.UUID) (*account.Identity, error) {
+	ident, err := appl.Identities().Load(ctx, id)
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.NewNotFoundError("identity", id.String())
+	}
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return ident, nil
+}
+
+// loadManagedAccessToken loads the access token with the given ID. Tokens of
+// identities other than the current one or the service accounts it owns are
+// treated like unknown ones, just like an ID that is not a valid UUID.
+func loadManagedAccessToken(ctx context.Context, appl application.Application, identityID uuid.UUID, id string) (*account.AccessToken, error) {
+	tokenID, err := uuid.FromString(id)
+	if err != nil {
+		return nil, errors.NewNotFoundError("access token", id)
+	}
+	t, err := appl.AccessTokens().Load(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	if !uuid.Equal(t.IdentityID, identityID) {
+		if _, err := loadServiceAccount(ctx, appl, identityID, t.IdentityID.String()); err != nil {
+			return nil, errors.NewNotFoundError("access token", id)
+		}
+	}
+	return t, nil
+}
+
+func convertAccessTokensFromModel(request *goa.RequestData, tokens []*account.AccessToken) *app.AccessTokenArray {
+	res := &app.AccessTokenArray{
+		Data: make([]*app.AccessTokenData, len(tokens)),
+	}
+	for index, t := range tokens {
+		res.Data[index] = convertAccessTokenFromModel(request, t, nil)
+	}
+	return res
+}
+
+// convertAccessTokenFromModel converts between internal and external REST
+// representation. The signed token is only known right after creation.
+func convertAccessTokenFromModel(request *goa.RequestData, t *account.AccessToken, tokenString *string) *app.AccessTokenData {
+	selfURL := absoluteURL(request, app.AccessTokenHref(t.ID))
+	return &app.AccessTokenData{
+		ID:   &t.ID,
+		Type: "access-tokens",
+		Attributes: &app.AccessTokenAttributes{
+			Name:       &t.Name,
+			Scopes:     t.ScopeList(),
+			ExpiresAt:  t.ExpiresAt,
+			Token:      tokenString,
+			Identity:   &t.IdentityID,
+			LastUsedAt: t.LastUsedAt,
+			RevokedAt:  t.RevokedAt,
+			CreatedAt:  &t.CreatedAt,
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
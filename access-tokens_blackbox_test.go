@@ -0,0 +1,145 @@
+package main_test
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestAccessTokenREST struct {
+	gormsupport.DBTestSuite
+
+	db           *gormapplication.GormDB
+	clean        func()
+	tokenManager almtoken.Manager
+	owner        account.Identity
+}
+
+func TestRunAccessTokenREST(t *testing.T) {
+	suite.Run(t, &TestAccessTokenREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestAccessTokenREST) SetupTest() {
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = gormsupport.DeleteCreatedEntities(rest.DB)
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+	rest.tokenManager = almtoken.NewManager(pub, priv)
+	rest.owner = account.Identity{FullName: "TestAccessTokenREST"}
+	require.Nil(rest.T(), account.NewIdentityRepository(rest.DB).Create(context.Background(), &rest.owner))
+}
+
+func (rest *TestAccessTokenREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestAccessTokenREST) serviceAs(identity account.Identity) *goa.Service {
+	return testsupport.ServiceAsUser("AccessToken-Service", rest.tokenManager, identity)
+}
+
+func (rest *TestAccessTokenREST) TestServiceAccountTokens() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	svc := rest.serviceAs(rest.owner)
+	saCtrl := NewServiceAccountController(svc, rest.db, rest.tokenManager)
+
+	name := "CI bot"
+	_, sa := test.CreateServiceAccountCreated(t, svc.Context, svc, saCtrl, &app.CreateServiceAccountPayload{
+		Data: &app.ServiceAccountData{
+			Type:       "service-accounts",
+			Attributes: &app.ServiceAccountAttributes{Name: &name},
+		},
+	})
+	assert.Equal(t, rest.owner.ID, *sa.Data.Attributes.Owner)
+	_, list := test.ListServiceAccountOK(t, svc.Context, svc, saCtrl)
+	require.Len(t, list.Data, 1)
+
+	tokensCtrl := NewServiceAccountTokensController(svc, rest.db, rest.tokenManager)
+	tokenName := "nightly import"
+	expiresAt := time.Now().Add(24 * time.Hour)
+	_, created := test.CreateServiceAccountTokensCreated(t, svc.Context, svc, tokensCtrl, sa.Data.ID.String(), &app.CreateAccessTokenPayload{
+		Data: &app.AccessTokenData{
+			Type: "access-tokens",
+			Attributes: &app.AccessTokenAttributes{
+				Name:      &tokenName,
+				Scopes:    []string{"read.workitem", "update.workitem"},
+				ExpiresAt: &expiresAt,
+			},
+		},
+	})
+	require.NotNil(t, created.Data.Attributes.Token)
+	extracted, err := rest.tokenManager.Extract(*created.Data.Attributes.Token)
+	require.Nil(t, err)
+	assert.Equal(t, *sa.Data.ID, extracted.ID)
+
+	_, tokens := test.ListServiceAccountTokensOK(t, svc.Context, svc, tokensCtrl, sa.Data.ID.String())
+	require.Len(t, tokens.Data, 1)
+	assert.Nil(t, tokens.Data[0].Attributes.Token)
+	assert.Equal(t, []string{"read.workitem", "update.workitem"}, tokens.Data[0].Attributes.Scopes)
+
+	// other identities neither see the service account nor its tokens
+	stranger := account.Identity{FullName: "TestAccessTokenREST stranger"}
+	require.Nil(t, account.NewIdentityRepository(rest.DB).Create(context.Background(), &stranger))
+	strangerSvc := rest.serviceAs(stranger)
+	test.ShowServiceAccountNotFound(t, strangerSvc.Context, strangerSvc, NewServiceAccountController(strangerSvc, rest.db, rest.tokenManager), sa.Data.ID.String())
+	test.RevokeAccessTokenNotFound(t, strangerSvc.Context, strangerSvc, NewAccessTokenController(strangerSvc, rest.db, rest.tokenManager), created.Data.ID.String())
+
+	// service accounts can not own service accounts
+	saIdentity := account.Identity{ID: *sa.Data.ID, FullName: name}
+	saSvc := rest.serviceAs(saIdentity)
+	test.CreateServiceAccountForbidden(t, saSvc.Context, saSvc, NewServiceAccountController(saSvc, rest.db, rest.tokenManager), &app.CreateServiceAccountPayload{
+		Data: &app.ServiceAccountData{
+			Type:       "service-accounts",
+			Attributes: &app.ServiceAccountAttributes{Name: &name},
+		},
+	})
+
+	tokenCtrl := NewAccessTokenController(svc, rest.db, rest.tokenManager)
+	test.RevokeAccessTokenOK(t, svc.Context, svc, tokenCtrl, created.Data.ID.String())
+	_, revoked := test.ShowAccessTokenOK(t, svc.Context, svc, tokenCtrl, created.Data.ID.String())
+	assert.NotNil(t, revoked.Data.Attributes.RevokedAt)
+
+	test.DeleteServiceAccountOK(t, svc.Context, svc, saCtrl, sa.Data.ID.String())
+	test.ShowServiceAccountNotFound(t, svc.Context, svc, saCtrl, sa.Data.ID.String())
+	test.ShowAccessTokenNotFound(t, svc.Context, svc, tokenCtrl, uuid.NewV4().String())
+}
+
+func (rest *TestAccessTokenREST) TestPersonalTokens() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	svc := rest.serviceAs(rest.owner)
+	ctrl := NewAccessTokenController(svc, rest.db, rest.tokenManager)
+
+	test.CreateAccessTokenBadRequest(t, svc.Context, svc, ctrl, &app.CreateAccessTokenPayload{
+		Data: &app.AccessTokenData{Type: "access-tokens", Attributes: &app.AccessTokenAttributes{}},
+	})
+	name := "laptop"
+	_, created := test.CreateAccessTokenCreated(t, svc.Context, svc, ctrl, &app.CreateAccessTokenPayload{
+		Data: &app.AccessTokenData{
+			Type:       "access-tokens",
+			Attributes: &app.AccessTokenAttributes{Name: &name, Scopes: []string{"manage.account"}},
+		},
+	})
+	assert.Equal(t, rest.owner.ID, *created.Data.Attributes.Identity)
+	assert.Nil(t, created.Data.Attributes.ExpiresAt)
+
+	_, list := test.ListAccessTokenOK(t, svc.Context, svc, ctrl)
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, name, *list.Data[0].Attributes.Name)
+}
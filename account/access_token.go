@@ -0,0 +1,162 @@
+package account
+
+import (
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// AccessToken describes a long-lived token that an Identity, typically a
+// service account, can use instead of a login token. The token itself is
+// not stored, only what is needed to validate and revoke it.
+type AccessToken struct {
+	gormsupport.Lifecycle
+	ID         uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	IdentityID uuid.UUID `sql:"type:uuid"`
+	Name       string
+	// Scopes is the space separated list of permissions the token may use
+	Scopes     string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (t AccessToken) TableName() string {
+	return "access_tokens"
+}
+
+// ScopeList returns the scopes of the token
+func (t AccessToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
+
+// HasScope returns true if the token may use the given permission
+func (t AccessToken) HasScope(scope string) bool {
+	for _, s := range t.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive returns true if the token is neither revoked nor expired at the given time
+func (t AccessToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
+
+// AccessTokenRepository represents the storage interface.
+type AccessTokenRepository interface {
+	Create(ctx context.Context, t *AccessToken) error
+	Load(ctx context.Context, id uuid.UUID) (*AccessToken, error)
+	List(ctx context.Context, identityID uuid.UUID) ([]*AccessToken, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	RevokeAll(ctx context.Context, identityID uuid.UUID) error
+	Use(ctx context.Context, id uuid.UUID, now time.Time) (*AccessToken, error)
+}
+
+// NewAccessTokenRepository creates a new storage type.
+func NewAccessTokenRepository(db *gorm.DB) AccessTokenRepository {
+	return &GormAccessTokenRepository{db: db}
+}
+
+// GormAccessTokenRepository is the implementation of the storage interface for AccessToken.
+type GormAccessTokenRepository struct {
+	db *gorm.DB
+}
+
+// Create creates a new access token for the identity set on the token.
+func (m *GormAccessTokenRepository) Create(ctx context.Context, t *AccessToken) error {
+	defer goa.MeasureSince([]string{"goa", "db", "accessToken", "create"}, time.Now())
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.NewBadParameterError("name", t.Name).Expected("not empty")
+	}
+	if t.ExpiresAt != nil && !t.ExpiresAt.After(time.Now()) {
+		return errors.NewBadParameterError("expiresAt", *t.ExpiresAt).Expected("a point in time in the future")
+	}
+	t.ID = uuid.NewV4()
+	t.Scopes = strings.Join(t.ScopeList(), " ")
+	if err := m.db.Create(t).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Load returns the access token with the given ID, revoked or not.
+func (m *GormAccessTokenRepository) Load(ctx context.Context, id uuid.UUID) (*AccessToken, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "accessToken", "load"}, time.Now())
+	var t AccessToken
+	err := m.db.Where("id = ?", id).First(&t).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.NewNotFoundError("access token", id.String())
+	}
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &t, nil
+}
+
+// List returns the access tokens of the given identity, newest first.
+func (m *GormAccessTokenRepository) List(ctx context.Context, identityID uuid.UUID) ([]*AccessToken, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "accessToken", "list"}, time.Now())
+	var rows []*AccessToken
+	err := m.db.Where("identity_id = ?", identityID).Order("created_at desc").Find(&rows).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return rows, nil
+}
+
+// Revoke makes the access token with the given ID unusable. Revoking a
+// revoked token has no effect.
+func (m *GormAccessTokenRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "accessToken", "revoke"}, time.Now())
+	if _, err := m.Load(ctx, id); err != nil {
+		return err
+	}
+	err := m.db.Model(&AccessToken{}).Where("id = ? AND revoked_at IS NULL", id).UpdateColumn("revoked_at", gorm.NowFunc()).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// RevokeAll revokes all access tokens of the given identity.
+func (m *GormAccessTokenRepository) RevokeAll(ctx context.Context, identityID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "accessToken", "revokeAll"}, time.Now())
+	err := m.db.Model(&AccessToken{}).Where("identity_id = ? AND revoked_at IS NULL", identityID).UpdateColumn("revoked_at", gorm.NowFunc()).Error
+	if err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Use records that the access token with the given ID got used at the given
+// time. It fails with an errors.UnauthorizedError if the token is unknown,
+// revoked or expired.
+func (m *GormAccessTokenRepository) Use(ctx context.Context, id uuid.UUID, now time.Time) (*AccessToken, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "accessToken", "use"}, time.Now())
+	t, err := m.Load(ctx, id)
+	if _, ok := err.(errors.NotFoundError); ok {
+		return nil, errors.NewUnauthorizedError("unknown access token")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !t.IsActive(now) {
+		return nil, errors.NewUnauthorizedError("access token is revoked or expired")
+	}
+	if err := m.db.Model(t).UpdateColumn("last_used_at", now).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	t.LastUsedAt = &now
+	return t, nil
+}
@@ -0,0 +1,98 @@
+package account_test
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessTokens(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer gormsupport.DeleteCreatedEntities(db)()
+
+	ctx := context.Background()
+	repo := account.NewAccessTokenRepository(db)
+	identity := account.Identity{FullName: "Test Access Tokens", ServiceAccount: true}
+	require.Nil(t, account.NewIdentityRepository(db).Create(ctx, &identity))
+
+	assert.IsType(t, errors.BadParameterError{}, repo.Create(ctx, &account.AccessToken{IdentityID: identity.ID, Name: " "}))
+	past := time.Now().Add(-time.Hour)
+	assert.IsType(t, errors.BadParameterError{}, repo.Create(ctx, &account.AccessToken{IdentityID: identity.ID, Name: "expired", ExpiresAt: &past}))
+
+	accessToken := account.AccessToken{IdentityID: identity.ID, Name: "ci", Scopes: " read.workitem   update.workitem "}
+	require.Nil(t, repo.Create(ctx, &accessToken))
+	assert.Equal(t, "read.workitem update.workitem", accessToken.Scopes)
+	assert.True(t, accessToken.HasScope("update.workitem"))
+	assert.False(t, accessToken.HasScope("manage.project"))
+
+	now := time.Now()
+	used, err := repo.Use(ctx, accessToken.ID, now)
+	require.Nil(t, err)
+	require.NotNil(t, used.LastUsedAt)
+	loaded, err := repo.Load(ctx, accessToken.ID)
+	require.Nil(t, err)
+	require.NotNil(t, loaded.LastUsedAt)
+	assert.WithinDuration(t, now, *loaded.LastUsedAt, time.Second)
+
+	// tokens without expiry stay valid until revoked
+	_, err = repo.Use(ctx, accessToken.ID, now.AddDate(10, 0, 0))
+	require.Nil(t, err)
+	require.Nil(t, repo.Revoke(ctx, accessToken.ID))
+	_, err = repo.Use(ctx, accessToken.ID, now)
+	assert.IsType(t, errors.UnauthorizedError{}, err)
+	_, err = repo.Use(ctx, uuid.NewV4(), now)
+	assert.IsType(t, errors.UnauthorizedError{}, err)
+
+	future := now.Add(time.Hour)
+	expiring := account.AccessToken{IdentityID: identity.ID, Name: "expiring", ExpiresAt: &future}
+	require.Nil(t, repo.Create(ctx, &expiring))
+	_, err = repo.Use(ctx, expiring.ID, future.Add(time.Second))
+	assert.IsType(t, errors.UnauthorizedError{}, err)
+
+	tokens, err := repo.List(ctx, identity.ID)
+	require.Nil(t, err)
+	assert.Len(t, tokens, 2)
+	require.Nil(t, repo.RevokeAll(ctx, identity.ID))
+	_, err = repo.Use(ctx, expiring.ID, now)
+	assert.IsType(t, errors.UnauthorizedError{}, err)
+}
+
+func TestMergeMovesServiceAccountsAndRevokesAccessTokens(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer gormsupport.DeleteCreatedEntities(db)()
+
+	ctx := context.Background()
+	identities := account.NewIdentityRepository(db)
+	tokens := account.NewAccessTokenRepository(db)
+	target := account.Identity{FullName: "Test Merge Target"}
+	require.Nil(t, identities.Create(ctx, &target))
+	source := account.Identity{FullName: "Test Merge Source"}
+	require.Nil(t, identities.Create(ctx, &source))
+	bot := account.Identity{FullName: "Test Merge Bot", ServiceAccount: true, OwnerID: &source.ID}
+	require.Nil(t, identities.Create(ctx, &bot))
+	sourceToken := account.AccessToken{IdentityID: source.ID, Name: "personal"}
+	require.Nil(t, tokens.Create(ctx, &sourceToken))
+	botToken := account.AccessToken{IdentityID: bot.ID, Name: "ci"}
+	require.Nil(t, tokens.Create(ctx, &botToken))
+
+	require.Nil(t, identities.Merge(ctx, target.ID, source.ID))
+
+	owned, err := identities.Query(account.IdentityServiceAccountsOf(target.ID))
+	require.Nil(t, err)
+	require.Len(t, owned, 1)
+	assert.Equal(t, bot.ID, owned[0].ID)
+	_, err = tokens.Use(ctx, sourceToken.ID, time.Now())
+	assert.IsType(t, errors.UnauthorizedError{}, err)
+	// the tokens of the service account keep working for the new owner
+	_, err = tokens.Use(ctx, botToken.ID, time.Now())
+	assert.Nil(t, err)
+}
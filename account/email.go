@@ -0,0 +1,129 @@
+package account
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// ListByIdentity returns the emails of the given identity, the primary one first.
+func (m *GormUserRepository) ListByIdentity(ctx context.Context, identityID uuid.UUID) ([]*User, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "user", "listByIdentity"}, time.Now())
+	var objs []*User
+	err := m.db.Where("identity_id = ?", identityID).Order("primary_email desc, email").Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// AddEmail registers an unverified secondary email for the given identity
+// and generates the code needed to verify it. Emails that are already
+// registered for the identity or verified by any identity are refused.
+func (m *GormUserRepository) AddEmail(ctx context.Context, identityID uuid.UUID, email string) (*User, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "user", "addEmail"}, time.Now())
+	email = strings.TrimSpace(email)
+	if !strings.Contains(email, "@") {
+		return nil, errors.NewBadParameterError("email", email).Expected("an email address")
+	}
+	var count int
+	err := m.db.Model(&User{}).Where("email = ? AND (verified OR identity_id = ?)", email, identityID).Count(&count).Error
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if count > 0 {
+		return nil, errors.NewBadParameterError("email", email).Expected("an email that is not registered yet")
+	}
+	code, err := newVerificationCode()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	u := User{
+		ID:               uuid.NewV4(),
+		Email:            email,
+		IdentityID:       identityID,
+		VerificationCode: code,
+	}
+	if err := m.db.Create(&u).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &u, nil
+}
+
+// VerifyEmail marks the email with the given ID as verified if the code
+// matches the one generated when the email was added.
+func (m *GormUserRepository) VerifyEmail(ctx context.Context, identityID uuid.UUID, id uuid.UUID, code string) (*User, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "user", "verifyEmail"}, time.Now())
+	u, err := m.loadOfIdentity(identityID, id)
+	if err != nil {
+		return nil, err
+	}
+	if u.Verified {
+		return u, nil
+	}
+	if code == "" || code != u.VerificationCode {
+		return nil, errors.NewBadParameterError("code", code).Expected("the verification code of the email")
+	}
+	var count int
+	err = m.db.Model(&User{}).Where("email = ? AND verified", u.Email).Count(&count).Error
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if count > 0 {
+		return nil, errors.NewBadParameterError("email", u.Email).Expected("an email that is not verified by another identity")
+	}
+	err = m.db.Model(u).Updates(map[string]interface{}{"verified": true, "verification_code": ""}).Error
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	u.Verified = true
+	u.VerificationCode = ""
+	return u, nil
+}
+
+// RemoveEmail removes a secondary email of the given identity. The primary
+// email can not be removed.
+func (m *GormUserRepository) RemoveEmail(ctx context.Context, identityID uuid.UUID, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "user", "removeEmail"}, time.Now())
+	u, err := m.loadOfIdentity(identityID, id)
+	if err != nil {
+		return err
+	}
+	if u.Primary {
+		return errors.NewBadParameterError("id", id.String()).Expected("a secondary email")
+	}
+	if err := m.db.Delete(u).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// loadOfIdentity loads the email with the given ID, treating emails of other
+// identities as unknown.
+func (m *GormUserRepository) loadOfIdentity(identityID uuid.UUID, id uuid.UUID) (*User, error) {
+	var u User
+	err := m.db.Where("id = ? AND identity_id = ?", id, identityID).First(&u).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.NewNotFoundError("email", id.String())
+	}
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &u, nil
+}
+
+// newVerificationCode returns a random code that is hard to guess.
+func newVerificationCode() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
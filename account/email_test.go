@@ -0,0 +1,90 @@
+package account_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecondaryEmails(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer gormsupport.DeleteCreatedEntities(db)()
+
+	ctx := context.Background()
+	userRepo := account.NewUserRepository(db)
+	identityRepo := account.NewIdentityRepository(db)
+	identity := account.Identity{FullName: "Test Secondary Emails"}
+	require.Nil(t, identityRepo.Create(ctx, &identity))
+	other := account.Identity{FullName: "Test Other Secondary Emails"}
+	require.Nil(t, identityRepo.Create(ctx, &other))
+	primary := account.User{Email: uuid.NewV4().String() + "@example.com", IdentityID: identity.ID, Primary: true, Verified: true}
+	require.Nil(t, userRepo.Create(ctx, &primary))
+
+	email := uuid.NewV4().String() + "@example.com"
+	secondary, err := userRepo.AddEmail(ctx, identity.ID, email)
+	require.Nil(t, err)
+	assert.False(t, secondary.Verified)
+	assert.NotEmpty(t, secondary.VerificationCode)
+
+	_, err = userRepo.AddEmail(ctx, identity.ID, email)
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, err = userRepo.AddEmail(ctx, identity.ID, "not-an-email")
+	assert.IsType(t, errors.BadParameterError{}, err)
+	// another identity may claim the email as long as nobody verified it
+	claimed, err := userRepo.AddEmail(ctx, other.ID, email)
+	require.Nil(t, err)
+
+	_, err = userRepo.VerifyEmail(ctx, identity.ID, secondary.ID, "wrong")
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, err = userRepo.VerifyEmail(ctx, other.ID, secondary.ID, secondary.VerificationCode)
+	assert.IsType(t, errors.NotFoundError{}, err)
+	verified, err := userRepo.VerifyEmail(ctx, identity.ID, secondary.ID, secondary.VerificationCode)
+	require.Nil(t, err)
+	assert.True(t, verified.Verified)
+	_, err = userRepo.VerifyEmail(ctx, other.ID, claimed.ID, claimed.VerificationCode)
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	emails, err := userRepo.ListByIdentity(ctx, identity.ID)
+	require.Nil(t, err)
+	require.Len(t, emails, 2)
+	assert.Equal(t, primary.Email, emails[0].Email)
+
+	assert.IsType(t, errors.BadParameterError{}, userRepo.RemoveEmail(ctx, identity.ID, primary.ID))
+	require.Nil(t, userRepo.RemoveEmail(ctx, identity.ID, secondary.ID))
+	assert.IsType(t, errors.NotFoundError{}, userRepo.RemoveEmail(ctx, identity.ID, secondary.ID))
+}
+
+func TestMergeIdentities(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer gormsupport.DeleteCreatedEntities(db)()
+
+	ctx := context.Background()
+	userRepo := account.NewUserRepository(db)
+	identityRepo := account.NewIdentityRepository(db)
+	target := account.Identity{FullName: "Test Merge Target"}
+	require.Nil(t, identityRepo.Create(ctx, &target))
+	source := account.Identity{FullName: "Test Merge Source"}
+	require.Nil(t, identityRepo.Create(ctx, &source))
+	email := account.User{Email: uuid.NewV4().String() + "@example.com", IdentityID: source.ID, Primary: true, Verified: true}
+	require.Nil(t, userRepo.Create(ctx, &email))
+
+	assert.IsType(t, errors.BadParameterError{}, identityRepo.Merge(ctx, target.ID, target.ID))
+	assert.IsType(t, errors.NotFoundError{}, identityRepo.Merge(ctx, target.ID, uuid.NewV4()))
+	require.Nil(t, identityRepo.Merge(ctx, target.ID, source.ID))
+
+	_, err := identityRepo.Load(ctx, source.ID)
+	assert.NotNil(t, err)
+	emails, err := userRepo.ListByIdentity(ctx, target.ID)
+	require.Nil(t, err)
+	require.Len(t, emails, 1)
+	assert.Equal(t, email.Email, emails[0].Email)
+	assert.False(t, emails[0].Primary)
+}
@@ -1,9 +1,11 @@
 package account
 
 import (
+	"strings"
 	"time"
 
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/goadesign/goa"
 	"github.com/jinzhu/gorm"
@@ -18,6 +20,12 @@ type Identity struct {
 	Emails   []User    // has many Users
 	FullName string    // The fullname of the Identity
 	ImageURL string    // The image URL for this Identity
+	Bio      string    // A short description the Identity gives of itself
+	Timezone string    // The IANA time zone name of the Identity, e.g. "Europe/Berlin"
+	// ServiceAccount marks identities of bots rather than persons
+	ServiceAccount bool
+	// OwnerID is the identity that manages the service account
+	OwnerID *uuid.UUID `sql:"type:uuid"`
 }
 
 // TableName overrides the table name settings in Gorm to force a specific table name
@@ -37,6 +45,8 @@ func (m Identity) ConvertIdentityFromModel() *app.Identity {
 			Attributes: &app.IdentityDataAttributes{
 				FullName: &m.FullName,
 				ImageURL: &m.ImageURL,
+				Bio:      &m.Bio,
+				Timezone: &m.Timezone,
 			},
 		},
 	}
@@ -61,6 +71,7 @@ type IdentityRepository interface {
 	Save(ctx context.Context, identity *Identity) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	Query(funcs ...func(*gorm.DB) *gorm.DB) ([]*Identity, error)
+	Merge(ctx context.Context, targetID, sourceID uuid.UUID) error
 }
 
 // TableName overrides the table name settings in Gorm to force a specific table name
@@ -110,7 +121,13 @@ func (m *GormIdentityRepository) Save(ctx context.Context, model *Identity) erro
 		goa.LogError(ctx, "error updating Identity", "error", err.Error())
 		return err
 	}
-	err = m.db.Model(obj).Updates(model).Error
+	// update with a map so that attributes can be cleared as well
+	err = m.db.Model(obj).Updates(map[string]interface{}{
+		"full_name": model.FullName,
+		"image_url": model.ImageURL,
+		"bio":       model.Bio,
+		"timezone":  model.Timezone,
+	}).Error
 
 	return err
 }
@@ -131,6 +148,67 @@ func (m *GormIdentityRepository) Delete(ctx context.Context, id uuid.UUID) error
 	return nil
 }
 
+// Merge moves everything that refers to the source identity over to the
+// target identity and deletes the source identity afterwards: its emails,
+// project and team memberships, the service accounts it owns, the work items
+// it created or is assigned to and its comments. Memberships in projects and
+// teams the target is already a member of are dropped. The access tokens of
+// the source identity are revoked.
+func (m *GormIdentityRepository) Merge(ctx context.Context, targetID, sourceID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "identity", "merge"}, time.Now())
+
+	if targetID == sourceID {
+		return errors.NewBadParameterError("sourceID", sourceID.String()).Expected("an identity other than the target")
+	}
+	for _, id := range []uuid.UUID{targetID, sourceID} {
+		if _, err := m.Load(ctx, id); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.NewNotFoundError("identity", id.String())
+			}
+			return errors.NewInternalError(err.Error())
+		}
+	}
+
+	now := gorm.NowFunc()
+	target := targetID.String()
+	source := sourceID.String()
+	// workitem.SystemCreator and workitem.SystemAssignee; the workitem package
+	// depends on this one
+	for _, field := range []string{"system.creator", "system.assignee"} {
+		err := m.db.Exec("UPDATE work_items SET fields = jsonb_set(fields, ?::text[], to_jsonb(?::text)), version = version + 1, updated_at = ? WHERE fields->>? = ?",
+			"{"+field+"}", target, now, field, source).Error
+		if err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+	}
+	statements := []struct {
+		sql  string
+		args []interface{}
+	}{
+		{"UPDATE comments SET created_by = ? WHERE created_by = ?", []interface{}{targetID, sourceID}},
+		{"UPDATE users SET identity_id = ?, primary_email = false, updated_at = ? WHERE identity_id = ? AND deleted_at IS NULL", []interface{}{targetID, now, sourceID}},
+		{`UPDATE project_memberships SET deleted_at = ? WHERE identity_id = ? AND deleted_at IS NULL
+			AND project_id IN (SELECT project_id FROM project_memberships WHERE identity_id = ? AND deleted_at IS NULL)`, []interface{}{now, sourceID, targetID}},
+		{"UPDATE project_memberships SET identity_id = ?, updated_at = ? WHERE identity_id = ? AND deleted_at IS NULL", []interface{}{targetID, now, sourceID}},
+		{`UPDATE team_members SET deleted_at = ? WHERE identity_id = ? AND deleted_at IS NULL
+			AND team_id IN (SELECT team_id FROM team_members WHERE identity_id = ? AND deleted_at IS NULL)`, []interface{}{now, sourceID, targetID}},
+		{"UPDATE team_members SET identity_id = ?, updated_at = ? WHERE identity_id = ? AND deleted_at IS NULL", []interface{}{targetID, now, sourceID}},
+		{"UPDATE identities SET owner_id = ?, updated_at = ? WHERE owner_id = ?", []interface{}{targetID, now, sourceID}},
+	}
+	for _, stmt := range statements {
+		if err := m.db.Exec(stmt.sql, stmt.args...).Error; err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+	}
+	if err := NewAccessTokenRepository(m.db).RevokeAll(ctx, sourceID); err != nil {
+		return err
+	}
+	if err := m.db.Delete(&Identity{}, "id = ?", sourceID).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
 // Query expose an open ended Query model
 func (m *GormIdentityRepository) Query(funcs ...func(*gorm.DB) *gorm.DB) ([]*Identity, error) {
 	defer goa.MeasureSince([]string{"goa", "db", "identity", "query"}, time.Now())
@@ -143,14 +221,59 @@ func (m *GormIdentityRepository) Query(funcs ...func(*gorm.DB) *gorm.DB) ([]*Ide
 	return objs, nil
 }
 
-// List return all user identities
-func (m *GormIdentityRepository) List(ctx context.Context) (*app.IdentityArray, error) {
+// IdentityServiceAccountsOf is a gorm filter for the service accounts owned by the given identity.
+func IdentityServiceAccountsOf(ownerID uuid.UUID) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("service_account AND owner_id = ? AND deleted_at IS NULL", ownerID).Order("full_name")
+	}
+}
+
+// IdentityFilter restricts the identities returned by List. Empty fields
+// do not restrict the result.
+type IdentityFilter struct {
+	NamePrefix  string     // only identities whose full name starts with this, ignoring case
+	EmailPrefix string     // only identities with an email starting with this, ignoring case
+	ProjectID   *uuid.UUID // only identities that are members of this project
+}
+
+// escapeLike escapes the characters that have a special meaning in a LIKE
+// pattern so that s only matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// List returns the identities matching the given filter ordered by full name,
+// starting with start (zero-based) and returning at most limit identities,
+// along with the total number of matching identities.
+func (m *GormIdentityRepository) List(ctx context.Context, filter IdentityFilter, start *int, limit *int) (*app.IdentityArray, uint64, error) {
 	defer goa.MeasureSince([]string{"goa", "db", "identity", "list"}, time.Now())
 	var rows []Identity
 
-	err := m.db.Model(&Identity{}).Order("full_name").Find(&rows).Error
+	db := m.db.Model(&Identity{})
+	if filter.NamePrefix != "" {
+		db = db.Where("full_name ILIKE ?", escapeLike(filter.NamePrefix)+"%")
+	}
+	if filter.EmailPrefix != "" {
+		db = db.Where("id IN (SELECT identity_id FROM users WHERE deleted_at IS NULL AND email ILIKE ?)", escapeLike(filter.EmailPrefix)+"%")
+	}
+	if filter.ProjectID != nil {
+		db = db.Where("id IN (SELECT identity_id FROM project_memberships WHERE deleted_at IS NULL AND project_id = ?)", *filter.ProjectID)
+	}
+
+	var count uint64
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	db = db.Order("full_name")
+	if start != nil {
+		db = db.Offset(*start)
+	}
+	if limit != nil {
+		db = db.Limit(*limit)
+	}
+	err := db.Find(&rows).Error
 	if err != nil && err != gorm.ErrRecordNotFound {
-		return nil, err
+		return nil, 0, err
 	}
 	res := app.IdentityArray{}
 	res.Data = make([]*app.IdentityData, len(rows))
@@ -158,5 +281,5 @@ func (m *GormIdentityRepository) List(ctx context.Context) (*app.IdentityArray,
 		ident := value.ConvertIdentityFromModel()
 		res.Data[index] = ident.Data
 	}
-	return &res, nil
+	return &res, count, nil
 }
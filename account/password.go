@@ -0,0 +1,163 @@
+package account
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/net/context"
+)
+
+const (
+	// MinPasswordLength is the minimum number of characters of a password
+	MinPasswordLength = 8
+	// maxPasswordBytes is the most bcrypt looks at
+	maxPasswordBytes = 72
+)
+
+// LockoutPolicy describes after how many failed logins in a row an email is
+// locked and for how long. A MaxFailures of 0 disables the lockout.
+type LockoutPolicy struct {
+	MaxFailures int
+	Duration    time.Duration
+}
+
+// invalidCredentials is the error of all failed logins, so that it does not
+// tell whether the email is known
+var invalidCredentials = errors.NewUnauthorizedError("invalid email or password")
+
+// dummyHash is compared against for unknown emails, so that they take as long
+// as known ones
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+
+// SetPassword sets the password for the local login with the email with the
+// given ID and unlocks it.
+func (m *GormUserRepository) SetPassword(ctx context.Context, id uuid.UUID, password string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "user", "setPassword"}, time.Now())
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	db := m.db.Model(&User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"password_hash":             hash,
+		"failed_logins":             0,
+		"locked_until":              nil,
+		"password_reset_hash":       "",
+		"password_reset_expires_at": nil,
+	})
+	if db.Error != nil {
+		return errors.NewInternalError(db.Error.Error())
+	}
+	if db.RowsAffected == 0 {
+		return errors.NewNotFoundError("email", id.String())
+	}
+	return nil
+}
+
+// Authenticate returns the verified email with its Identity if the password
+// matches the one set for it. Failed logins are counted and lock the email
+// according to the policy. All failures result in an errors.UnauthorizedError.
+func (m *GormUserRepository) Authenticate(ctx context.Context, email string, password string, lockout LockoutPolicy) (*User, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "user", "authenticate"}, time.Now())
+	var u User
+	err := m.db.Preload("Identity").Where("lower(email) = lower(?) AND verified", strings.TrimSpace(email)).First(&u).Error
+	if err == gorm.ErrRecordNotFound || (err == nil && u.PasswordHash == "") {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, invalidCredentials
+	}
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	now := time.Now()
+	if u.LockedUntil != nil && now.Before(*u.LockedUntil) {
+		return nil, errors.NewUnauthorizedError(fmt.Sprintf("too many failed logins, locked until %s", u.LockedUntil.Format(time.RFC3339)))
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil {
+		var lockedUntil interface{}
+		if lockout.MaxFailures > 0 {
+			lockedUntil = now.Add(lockout.Duration)
+		}
+		// counted in the database so that concurrent attempts do not get lost
+		err = m.db.Exec(`UPDATE users SET failed_logins = failed_logins + 1,
+			locked_until = CASE WHEN ? > 0 AND failed_logins + 1 >= ? THEN ? ELSE locked_until END
+			WHERE id = ?`, lockout.MaxFailures, lockout.MaxFailures, lockedUntil, u.ID).Error
+		if err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		return nil, invalidCredentials
+	}
+	if u.FailedLogins > 0 || u.LockedUntil != nil {
+		err = m.db.Model(&u).Updates(map[string]interface{}{"failed_logins": 0, "locked_until": nil}).Error
+		if err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+	}
+	return &u, nil
+}
+
+// CreatePasswordReset generates the code needed to set a new password for
+// the verified email without knowing the current one. The code is valid
+// until the given time and replaces any previous code.
+func (m *GormUserRepository) CreatePasswordReset(ctx context.Context, email string, until time.Time) (string, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "user", "createPasswordReset"}, time.Now())
+	code, err := newVerificationCode()
+	if err != nil {
+		return "", errors.NewInternalError(err.Error())
+	}
+	db := m.db.Model(&User{}).Where("lower(email) = lower(?) AND verified", strings.TrimSpace(email)).Updates(map[string]interface{}{
+		"password_reset_hash":       hashResetCode(code),
+		"password_reset_expires_at": until,
+	})
+	if db.Error != nil {
+		return "", errors.NewInternalError(db.Error.Error())
+	}
+	if db.RowsAffected == 0 {
+		return "", errors.NewNotFoundError("email", email)
+	}
+	return code, nil
+}
+
+// ResetPassword sets the password of the verified email if the code matches
+// the one created by CreatePasswordReset and has not expired. Each code can
+// be used once.
+func (m *GormUserRepository) ResetPassword(ctx context.Context, email string, code string, password string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "user", "resetPassword"}, time.Now())
+	var u User
+	err := m.db.Where("lower(email) = lower(?) AND verified", strings.TrimSpace(email)).First(&u).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return errors.NewInternalError(err.Error())
+	}
+	if err == gorm.ErrRecordNotFound || u.PasswordResetHash == "" || code == "" ||
+		u.PasswordResetExpiresAt == nil || time.Now().After(*u.PasswordResetExpiresAt) ||
+		subtle.ConstantTimeCompare([]byte(u.PasswordResetHash), []byte(hashResetCode(code))) != 1 {
+		return errors.NewBadParameterError("code", code).Expected("a valid password reset code for the email")
+	}
+	return m.SetPassword(ctx, u.ID, password)
+}
+
+// hashPassword checks the password against the minimum requirements and
+// returns its bcrypt hash
+func hashPassword(password string) (string, error) {
+	if len([]rune(password)) < MinPasswordLength || len(password) > maxPasswordBytes {
+		return "", errors.NewBadParameterError("password", "***").Expected(fmt.Sprintf("at least %d characters and at most %d bytes", MinPasswordLength, maxPasswordBytes))
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", errors.NewInternalError(err.Error())
+	}
+	return string(hash), nil
+}
+
+// hashResetCode returns the hash of the reset code as stored in the database
+func hashResetCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,124 @@
+package account_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createVerifiedUser creates an identity with a verified primary email
+func createVerifiedUser(t *testing.T, ctx context.Context) account.User {
+	identity := account.Identity{FullName: "Test Local Login"}
+	require.Nil(t, account.NewIdentityRepository(db).Create(ctx, &identity))
+	u := account.User{Email: uuid.NewV4().String() + "@example.com", IdentityID: identity.ID, Primary: true, Verified: true}
+	require.Nil(t, account.NewUserRepository(db).Create(ctx, &u))
+	return u
+}
+
+func TestAuthenticate(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer gormsupport.DeleteCreatedEntities(db)()
+
+	ctx := context.Background()
+	userRepo := account.NewUserRepository(db)
+	u := createVerifiedUser(t, ctx)
+	noLockout := account.LockoutPolicy{}
+
+	// no password set yet
+	_, err := userRepo.Authenticate(ctx, u.Email, "", noLockout)
+	assert.IsType(t, errors.UnauthorizedError{}, err)
+
+	assert.IsType(t, errors.BadParameterError{}, userRepo.SetPassword(ctx, u.ID, "short"))
+	assert.IsType(t, errors.NotFoundError{}, userRepo.SetPassword(ctx, uuid.NewV4(), "long enough"))
+	require.Nil(t, userRepo.SetPassword(ctx, u.ID, "correct horse"))
+
+	authenticated, err := userRepo.Authenticate(ctx, u.Email, "correct horse", noLockout)
+	require.Nil(t, err)
+	assert.Equal(t, u.IdentityID, authenticated.Identity.ID)
+	assert.Equal(t, "Test Local Login", authenticated.Identity.FullName)
+	// emails are matched regardless of their case
+	authenticated, err = userRepo.Authenticate(ctx, strings.ToUpper(u.Email), "correct horse", noLockout)
+	require.Nil(t, err)
+	assert.Equal(t, u.IdentityID, authenticated.Identity.ID)
+
+	_, err = userRepo.Authenticate(ctx, u.Email, "wrong horse", noLockout)
+	assert.IsType(t, errors.UnauthorizedError{}, err)
+	_, err = userRepo.Authenticate(ctx, "unknown@example.com", "correct horse", noLockout)
+	assert.IsType(t, errors.UnauthorizedError{}, err)
+}
+
+func TestAuthenticateLockout(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer gormsupport.DeleteCreatedEntities(db)()
+
+	ctx := context.Background()
+	userRepo := account.NewUserRepository(db)
+	u := createVerifiedUser(t, ctx)
+	require.Nil(t, userRepo.SetPassword(ctx, u.ID, "correct horse"))
+	lockout := account.LockoutPolicy{MaxFailures: 3, Duration: time.Hour}
+
+	// a successful login resets the count
+	for i := 0; i < 2; i++ {
+		_, err := userRepo.Authenticate(ctx, u.Email, "wrong horse", lockout)
+		assert.IsType(t, errors.UnauthorizedError{}, err)
+	}
+	_, err := userRepo.Authenticate(ctx, u.Email, "correct horse", lockout)
+	require.Nil(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err := userRepo.Authenticate(ctx, u.Email, "wrong horse", lockout)
+		assert.IsType(t, errors.UnauthorizedError{}, err)
+	}
+	_, err = userRepo.Authenticate(ctx, u.Email, "correct horse", lockout)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "locked until")
+
+	// setting a new password unlocks the email
+	require.Nil(t, userRepo.SetPassword(ctx, u.ID, "battery staple"))
+	_, err = userRepo.Authenticate(ctx, u.Email, "battery staple", lockout)
+	require.Nil(t, err)
+}
+
+func TestResetPassword(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer gormsupport.DeleteCreatedEntities(db)()
+
+	ctx := context.Background()
+	userRepo := account.NewUserRepository(db)
+	u := createVerifiedUser(t, ctx)
+
+	_, err := userRepo.CreatePasswordReset(ctx, "unknown@example.com", time.Now().Add(time.Hour))
+	assert.IsType(t, errors.NotFoundError{}, err)
+
+	expired, err := userRepo.CreatePasswordReset(ctx, u.Email, time.Now().Add(-time.Second))
+	require.Nil(t, err)
+	assert.IsType(t, errors.BadParameterError{}, userRepo.ResetPassword(ctx, u.Email, expired, "correct horse"))
+
+	code, err := userRepo.CreatePasswordReset(ctx, u.Email, time.Now().Add(time.Hour))
+	require.Nil(t, err)
+	assert.IsType(t, errors.BadParameterError{}, userRepo.ResetPassword(ctx, u.Email, "wrong", "correct horse"))
+	assert.IsType(t, errors.BadParameterError{}, userRepo.ResetPassword(ctx, u.Email, code, "short"))
+	require.Nil(t, userRepo.ResetPassword(ctx, u.Email, code, "correct horse"))
+	// each code can be used once
+	assert.IsType(t, errors.BadParameterError{}, userRepo.ResetPassword(ctx, u.Email, code, "battery staple"))
+
+	_, err = userRepo.Authenticate(ctx, u.Email, "correct horse", account.LockoutPolicy{})
+	require.Nil(t, err)
+
+	// emails are matched regardless of their case
+	code, err = userRepo.CreatePasswordReset(ctx, strings.ToUpper(u.Email), time.Now().Add(time.Hour))
+	require.Nil(t, err)
+	require.Nil(t, userRepo.ResetPassword(ctx, strings.ToUpper(u.Email), code, "battery staple"))
+	_, err = userRepo.Authenticate(ctx, u.Email, "battery staple", account.LockoutPolicy{})
+	require.Nil(t, err)
+}
@@ -17,6 +17,23 @@ type User struct {
 	Email      string    `sql:"unique_index"`                                            // This is the unique email field
 	IdentityID uuid.UUID `sql:"type:uuid"`                                               // Belongs To Identity
 	Identity   Identity
+	// Primary marks the email the Identity was created with
+	Primary bool `gorm:"column:primary_email"`
+	// Verified is set once the owner of the Identity proved to own the email
+	Verified bool
+	// VerificationCode has to be presented to verify the email
+	VerificationCode string
+	// PasswordHash is the bcrypt hash of the password for the local login
+	// with the email. It is empty if no password was set.
+	PasswordHash string
+	// FailedLogins counts the failed local logins since the last successful one
+	FailedLogins int
+	// LockedUntil refuses local logins until then after too many failures
+	LockedUntil *time.Time
+	// PasswordResetHash is the SHA-256 hash of the code needed to reset the
+	// password, which is valid until PasswordResetExpiresAt
+	PasswordResetHash      string
+	PasswordResetExpiresAt *time.Time
 }
 
 // TableName overrides the table name settings in Gorm to force a specific table name
@@ -43,6 +60,14 @@ type UserRepository interface {
 	Save(ctx context.Context, u *User) error
 	Delete(ctx context.Context, ID uuid.UUID) error
 	Query(funcs ...func(*gorm.DB) *gorm.DB) ([]*User, error)
+	ListByIdentity(ctx context.Context, identityID uuid.UUID) ([]*User, error)
+	AddEmail(ctx context.Context, identityID uuid.UUID, email string) (*User, error)
+	VerifyEmail(ctx context.Context, identityID uuid.UUID, id uuid.UUID, code string) (*User, error)
+	RemoveEmail(ctx context.Context, identityID uuid.UUID, id uuid.UUID) error
+	SetPassword(ctx context.Context, id uuid.UUID, password string) error
+	Authenticate(ctx context.Context, email string, password string, lockout LockoutPolicy) (*User, error)
+	CreatePasswordReset(ctx context.Context, email string, until time.Time) (string, error)
+	ResetPassword(ctx context.Context, email string, code string, password string) error
 }
 
 // TableName overrides the table name settings in Gorm to force a specific table name
@@ -144,6 +169,13 @@ func UserByEmails(emails []string) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB { return db }
 }
 
+// UserVerified is a gorm filter for verified emails.
+func UserVerified() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("verified")
+	}
+}
+
 // UserWithIdentity is a gorm filter for preloading the Identity relationship.
 func UserWithIdentity() func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
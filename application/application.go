@@ -1,8 +1,13 @@
 package application
 
 import (
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/area"
 	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/milestone"
 	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/team"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
 )
@@ -16,11 +21,18 @@ type Application interface {
 	TrackerQueries() TrackerQueryRepository
 	SearchItems() SearchRepository
 	Identities() IdentityRepository
+	Users() account.UserRepository
+	AccessTokens() account.AccessTokenRepository
 	WorkItemLinkCategories() link.WorkItemLinkCategoryRepository
 	WorkItemLinkTypes() link.WorkItemLinkTypeRepository
 	WorkItemLinks() link.WorkItemLinkRepository
 	WorkItemComments() comment.Repository
 	Projects() project.Repository
+	ProjectMemberships() project.MembershipRepository
+	Iterations() iteration.Repository
+	Areas() area.Repository
+	Milestones() milestone.Repository
+	Teams() team.Repository
 }
 
 // A Transaction abstracts a database transaction. The repositories created for the transaction object make changes inside the the transaction
@@ -1,8 +1,10 @@
 package application
 
 import (
+	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/criteria"
+	uuid "github.com/satori/go.uuid"
 	"golang.org/x/net/context"
 )
 
@@ -13,23 +15,28 @@ type TrackerRepository interface {
 	Delete(ctx context.Context, ID string) error
 	Create(ctx context.Context, url string, typeID string) (*app.Tracker, error)
 	List(ctx context.Context, criteria criteria.Expression, start *int, length *int) ([]*app.Tracker, error)
+	ListConflicts(ctx context.Context, ID string) ([]*app.TrackerConflict, error)
 }
 
 // TrackerQueryRepository encapsulate storage & retrieval of tracker queries
 type TrackerQueryRepository interface {
-	Create(ctx context.Context, query string, schedule string, tracker string) (*app.TrackerQuery, error)
+	Create(ctx context.Context, query string, schedule string, tracker string, projectID uuid.UUID) (*app.TrackerQuery, error)
 	Save(ctx context.Context, tq app.TrackerQuery) (*app.TrackerQuery, error)
 	Load(ctx context.Context, ID string) (*app.TrackerQuery, error)
 	Delete(ctx context.Context, ID string) error
-	List(ctx context.Context) ([]*app.TrackerQuery, error)
+	List(ctx context.Context, projectID *uuid.UUID) ([]*app.TrackerQuery, error)
+	Resync(ctx context.Context, ID string) (*app.TrackerQuery, error)
+	ListRuns(ctx context.Context, ID string, limit int) ([]*app.TrackerQueryRun, error)
 }
 
 // SearchRepository encapsulates searching of woritems,users,etc
 type SearchRepository interface {
 	SearchFullText(ctx context.Context, searchStr string, start *int, length *int) ([]*app.WorkItem, uint64, error)
+	SearchFullTextInProjects(ctx context.Context, searchStr string, projectIDs []uuid.UUID, start *int, length *int) ([]*app.WorkItem, uint64, error)
 }
 
 // IdentityRepository encapsulates identity
 type IdentityRepository interface {
-	List(ctx context.Context) (*app.IdentityArray, error)
+	account.IdentityRepository
+	List(ctx context.Context, filter account.IdentityFilter, start *int, limit *int) (*app.IdentityArray, uint64, error)
 }
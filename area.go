@@ -0,0 +1,168 @@
+package main
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/area"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// AreaController implements the area resource.
+type AreaController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewAreaController creates an area controller.
+func NewAreaController(service *goa.Service, db application.DB) *AreaController {
+	if db == nil {
+		panic("db must not be nil")
+	}
+	return &AreaController{Controller: service.NewController("AreaController"), db: db}
+}
+
+// Show runs the show action.
+func (c *AreaController) Show(ctx *app.ShowAreaContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		a, err := loadArea(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(&app.Area{
+			Data: convertAreaFromModel(ctx.RequestData, a),
+		})
+	})
+}
+
+// Subtree runs the subtree action.
+func (c *AreaController) Subtree(ctx *app.SubtreeAreaContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		a, err := loadArea(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		areas, err := appl.Areas().Subtree(ctx.Context, a.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(convertAreasFromModel(ctx.RequestData, areas))
+	})
+}
+
+// Update runs the update action.
+func (c *AreaController) Update(ctx *app.UpdateAreaContext) error {
+	attributes := ctx.Payload.Data.Attributes
+	if attributes == nil || attributes.Version == nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("data.attributes.version", nil).Expected("not nil"))
+		return ctx.BadRequest(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		a, err := loadArea(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		a.Version = *attributes.Version
+		if attributes.Name != nil {
+			a.Name = *attributes.Name
+		}
+		a, err = appl.Areas().Save(ctx.Context, *a)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(&app.Area{
+			Data: convertAreaFromModel(ctx.RequestData, a),
+		})
+	})
+}
+
+// Move runs the move action.
+func (c *AreaController) Move(ctx *app.MoveAreaContext) error {
+	var parentID *uuid.UUID
+	if attributes := ctx.Payload.Data.Attributes; attributes != nil {
+		parentID = attributes.Parent
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		a, err := loadArea(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		a, err = appl.Areas().Move(ctx.Context, a.ID, parentID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(&app.Area{
+			Data: convertAreaFromModel(ctx.RequestData, a),
+		})
+	})
+}
+
+// Delete runs the delete action.
+func (c *AreaController) Delete(ctx *app.DeleteAreaContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		a, err := loadArea(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		err = appl.Areas().Delete(ctx.Context, a.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// convertAreaFromModel converts between internal and external REST representation
+func convertAreaFromModel(request *goa.RequestData, a *area.Area) *app.AreaData {
+	selfURL := absoluteURL(request, app.AreaHref(a.ID))
+	return &app.AreaData{
+		ID:   &a.ID,
+		Type: "areas",
+		Attributes: &app.AreaAttributes{
+			Name:      &a.Name,
+			Parent:    a.ParentID(),
+			Path:      &a.Path,
+			Project:   &a.ProjectID,
+			Version:   &a.Version,
+			CreatedAt: &a.CreatedAt,
+			UpdatedAt: &a.UpdatedAt,
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
+
+// convertAreasFromModel converts a list of areas to their REST representation
+func convertAreasFromModel(request *goa.RequestData, areas []*area.Area) *app.AreaArray {
+	res := &app.AreaArray{
+		Data: make([]*app.AreaData, len(areas)),
+	}
+	for index, a := range areas {
+		res.Data[index] = convertAreaFromModel(request, a)
+	}
+	return res
+}
+
+// loadArea loads the area with the given ID, treating an ID that is not a
+// valid UUID like an unknown one.
+func loadArea(ctx context.Context, appl application.Application, id string) (*area.Area, error) {
+	areaID, err := uuid.FromString(id)
+	if err != nil {
+		return nil, errors.NewNotFoundError("area", id)
+	}
+	return appl.Areas().Load(ctx, areaID)
+}
@@ -0,0 +1,295 @@
+package area
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// pathSep separates the IDs of the ancestors of an area in its path
+const pathSep = "/"
+
+// Area classifies the work items of a project, e.g. by product component.
+// Areas form a tree per project. The Path of an area consists of the IDs of
+// all its ancestors followed by its own ID, each prefixed with "/", so the
+// subtree of an area consists of all areas whose path starts with its path.
+type Area struct {
+	gormsupport.Lifecycle
+	ID        uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	Version   int
+	ProjectID uuid.UUID `sql:"type:uuid"`
+	Name      string
+	Path      string
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (a Area) TableName() string {
+	return "areas"
+}
+
+// ParentID returns the ID of the parent area or nil for a root area
+func (a Area) ParentID() *uuid.UUID {
+	ids := strings.Split(strings.TrimPrefix(a.Path, pathSep), pathSep)
+	if len(ids) < 2 {
+		return nil
+	}
+	parentID, err := uuid.FromString(ids[len(ids)-2])
+	if err != nil {
+		return nil
+	}
+	return &parentID
+}
+
+// IsAncestorOf returns true if the other area is in the subtree of this area,
+// including the area itself
+func (a Area) IsAncestorOf(other Area) bool {
+	return other.Path == a.Path || strings.HasPrefix(other.Path, a.Path+pathSep)
+}
+
+// InAreas returns an expression that matches all work items classified with
+// one of the given areas. No work item matches an empty list.
+func InAreas(areas []*Area) criteria.Expression {
+	if len(areas) == 0 {
+		return criteria.Literal(false)
+	}
+	var result criteria.Expression
+	for _, a := range areas {
+		current := criteria.Equals(criteria.Field(workitem.SystemArea), criteria.Literal(a.ID.String()))
+		if result == nil {
+			result = current
+		} else {
+			result = criteria.Or(result, current)
+		}
+	}
+	return result
+}
+
+// Repository encapsulate storage & retrieval of areas
+type Repository interface {
+	Create(ctx context.Context, a *Area, parentID *uuid.UUID) error
+	Load(ctx context.Context, id uuid.UUID) (*Area, error)
+	Save(ctx context.Context, a Area) (*Area, error)
+	Move(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) (*Area, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, projectID uuid.UUID) ([]*Area, error)
+	Subtree(ctx context.Context, id uuid.UUID) ([]*Area, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for areas.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Create creates a new area below the given parent or a new root area if no
+// parent is given.
+// returns BadParameterError or InternalError
+func (r *GormRepository) Create(ctx context.Context, a *Area, parentID *uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "area", "create"}, time.Now())
+
+	a.ID = uuid.NewV4()
+	parentPath, err := r.parentPath(ctx, a.ProjectID, parentID)
+	if err != nil {
+		return err
+	}
+	a.Path = parentPath + pathSep + a.ID.String()
+	if err := r.checkSiblingName(*a); err != nil {
+		return err
+	}
+	tx := r.db.Create(a)
+	if err := tx.Error; err != nil {
+		return convertError(tx.Error, *a)
+	}
+	return nil
+}
+
+// Load returns the area for the given id
+// returns NotFoundError or InternalError
+func (r *GormRepository) Load(ctx context.Context, id uuid.UUID) (*Area, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "area", "load"}, time.Now())
+
+	res := Area{}
+	tx := r.db.Where("id = ?", id).First(&res)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("area", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &res, nil
+}
+
+// Save updates the name of the given area. Version must be the same as the one
+// in the stored version. Use Move to change the position of an area in the tree.
+// returns NotFoundError, BadParameterError, VersionConflictError or InternalError
+func (r *GormRepository) Save(ctx context.Context, a Area) (*Area, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "area", "save"}, time.Now())
+
+	existing, err := r.Load(ctx, a.ID)
+	if err != nil {
+		return nil, err
+	}
+	a.ProjectID = existing.ProjectID
+	a.Path = existing.Path
+	if err := r.checkSiblingName(a); err != nil {
+		return nil, err
+	}
+	oldVersion := a.Version
+	a.Version++
+	tx := r.db.Where("Version = ?", oldVersion).Save(&a)
+	if err := tx.Error; err != nil {
+		return nil, convertError(tx.Error, a)
+	}
+	if tx.RowsAffected == 0 {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+	return &a, nil
+}
+
+// Move moves the area with its whole subtree below the given parent, or to
+// the root of the tree if no parent is given. The paths of all descendants are
+// updated accordingly. An area can not be moved into its own subtree or into
+// another project.
+// returns NotFoundError, BadParameterError or InternalError
+func (r *GormRepository) Move(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) (*Area, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "area", "move"}, time.Now())
+
+	a, err := r.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	parentPath, err := r.parentPath(ctx, a.ProjectID, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if parentPath == a.Path || strings.HasPrefix(parentPath, a.Path+pathSep) {
+		return nil, errors.NewBadParameterError("parent", parentID.String()).Expected("not the area itself or one of its descendants")
+	}
+	oldPath := a.Path
+	a.Path = parentPath + pathSep + a.ID.String()
+	if err := r.checkSiblingName(*a); err != nil {
+		return nil, err
+	}
+	// re-path the area and all its descendants in one go by replacing the old
+	// path prefix with the new one
+	tx := r.db.Exec("UPDATE areas SET path = ? || substr(path, ?), version = version + 1, updated_at = ? WHERE deleted_at IS NULL AND (path = ? OR path LIKE ?)",
+		a.Path, len(oldPath)+1, gorm.NowFunc(), oldPath, oldPath+pathSep+"%")
+	if err := tx.Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return r.Load(ctx, id)
+}
+
+// Delete deletes the area with the given id. Areas that have child areas can
+// not be deleted.
+// returns NotFoundError, BadParameterError or InternalError
+func (r *GormRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "area", "delete"}, time.Now())
+
+	a, err := r.Load(ctx, id)
+	if err != nil {
+		return err
+	}
+	var children int
+	if err := r.db.Model(&Area{}).Where("path LIKE ?", a.Path+pathSep+"%").Count(&children).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if children > 0 {
+		return errors.NewBadParameterError("area", id.String()).Expected("no child areas")
+	}
+	tx := r.db.Delete(Area{ID: id})
+	if err := tx.Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("area", id.String())
+	}
+	return nil
+}
+
+// List returns all areas of the project. Parents always come before their
+// children.
+// returns InternalError
+func (r *GormRepository) List(ctx context.Context, projectID uuid.UUID) ([]*Area, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "area", "list"}, time.Now())
+
+	var rows []*Area
+	if err := r.db.Where("project_id = ?", projectID).Order("path").Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return rows, nil
+}
+
+// Subtree returns the area with the given id and all its descendants. Parents
+// always come before their children.
+// returns NotFoundError or InternalError
+func (r *GormRepository) Subtree(ctx context.Context, id uuid.UUID) ([]*Area, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "area", "subtree"}, time.Now())
+
+	a, err := r.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var rows []*Area
+	if err := r.db.Where("path = ? OR path LIKE ?", a.Path, a.Path+pathSep+"%").Order("path").Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return rows, nil
+}
+
+// parentPath returns the path of the given parent area, which must belong to
+// the given project, or the empty path if no parent is given
+func (r *GormRepository) parentPath(ctx context.Context, projectID uuid.UUID, parentID *uuid.UUID) (string, error) {
+	if parentID == nil {
+		return "", nil
+	}
+	parent, err := r.Load(ctx, *parentID)
+	if err != nil {
+		if _, ok := err.(errors.NotFoundError); ok {
+			return "", errors.NewBadParameterError("parent", parentID.String()).Expected("existing area")
+		}
+		return "", err
+	}
+	if !uuid.Equal(parent.ProjectID, projectID) {
+		return "", errors.NewBadParameterError("parent", parentID.String()).Expected("area of the same project")
+	}
+	return parent.Path, nil
+}
+
+// checkSiblingName makes sure no other area with the same parent has the name
+// of the given area
+func (r *GormRepository) checkSiblingName(a Area) error {
+	parentPath := strings.TrimSuffix(a.Path, pathSep+a.ID.String())
+	var count int
+	tx := r.db.Model(&Area{}).Where("project_id = ? AND name = ? AND id <> ? AND path LIKE ? AND path NOT LIKE ?",
+		a.ProjectID, a.Name, a.ID, parentPath+pathSep+"%", parentPath+pathSep+"%"+pathSep+"%")
+	if err := tx.Count(&count).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if count > 0 {
+		return errors.NewBadParameterError("name", a.Name).Expected("unique among the siblings of the area")
+	}
+	return nil
+}
+
+// convertError turns constraint violations into BadParameterErrors
+func convertError(err error, a Area) error {
+	if gormsupport.IsCheckViolation(err, "areas_name_check") {
+		return errors.NewBadParameterError("name", a.Name).Expected("not empty")
+	}
+	return errors.NewInternalError(err.Error())
+}
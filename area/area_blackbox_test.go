@@ -0,0 +1,152 @@
+package area_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/area"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+func TestRunAreaRepoBBTest(t *testing.T) {
+	suite.Run(t, &areaRepoBBTest{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+type areaRepoBBTest struct {
+	gormsupport.DBTestSuite
+	clean   func()
+	repo    area.Repository
+	project *project.Project
+}
+
+func (test *areaRepoBBTest) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+	test.repo = area.NewRepository(test.DB)
+	p, err := project.NewRepository(test.DB).Create(context.Background(), "area-test-"+uuid.NewV4().String())
+	require.Nil(test.T(), err)
+	test.project = p
+}
+
+func (test *areaRepoBBTest) TearDownTest() {
+	test.clean()
+}
+
+func (test *areaRepoBBTest) create(name string, parent *area.Area) *area.Area {
+	a := area.Area{ProjectID: test.project.ID, Name: name}
+	var parentID *uuid.UUID
+	if parent != nil {
+		parentID = &parent.ID
+	}
+	require.Nil(test.T(), test.repo.Create(context.Background(), &a, parentID))
+	return &a
+}
+
+func (test *areaRepoBBTest) TestCreateAndLoad() {
+	backend := test.create("backend", nil)
+	db := test.create("db", backend)
+	assert.Equal(test.T(), "/"+backend.ID.String(), backend.Path)
+	assert.Equal(test.T(), backend.Path+"/"+db.ID.String(), db.Path)
+	assert.Nil(test.T(), backend.ParentID())
+	require.NotNil(test.T(), db.ParentID())
+	assert.Equal(test.T(), backend.ID, *db.ParentID())
+
+	loaded, err := test.repo.Load(context.Background(), db.ID)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), "db", loaded.Name)
+	assert.Equal(test.T(), db.Path, loaded.Path)
+
+	_, err = test.repo.Load(context.Background(), uuid.NewV4())
+	assert.IsType(test.T(), errors.NotFoundError{}, err)
+}
+
+func (test *areaRepoBBTest) TestCreateFail() {
+	backend := test.create("backend", nil)
+	test.create("db", backend)
+	// names are unique among siblings only
+	test.create("db", nil)
+
+	err := test.repo.Create(context.Background(), &area.Area{ProjectID: test.project.ID, Name: "db"}, &backend.ID)
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+
+	err = test.repo.Create(context.Background(), &area.Area{ProjectID: test.project.ID, Name: ""}, nil)
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+
+	unknown := uuid.NewV4()
+	err = test.repo.Create(context.Background(), &area.Area{ProjectID: test.project.ID, Name: "orphan"}, &unknown)
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+
+	other, err := project.NewRepository(test.DB).Create(context.Background(), "area-test-"+uuid.NewV4().String())
+	require.Nil(test.T(), err)
+	err = test.repo.Create(context.Background(), &area.Area{ProjectID: other.ID, Name: "foreign"}, &backend.ID)
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+}
+
+func (test *areaRepoBBTest) TestMoveRepathsDescendants() {
+	backend := test.create("backend", nil)
+	db := test.create("db", backend)
+	schema := test.create("schema", db)
+	frontend := test.create("frontend", nil)
+
+	moved, err := test.repo.Move(context.Background(), db.ID, &frontend.ID)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), frontend.Path+"/"+db.ID.String(), moved.Path)
+
+	loaded, err := test.repo.Load(context.Background(), schema.ID)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), moved.Path+"/"+schema.ID.String(), loaded.Path)
+
+	subtree, err := test.repo.Subtree(context.Background(), frontend.ID)
+	require.Nil(test.T(), err)
+	assert.Len(test.T(), subtree, 3)
+	subtree, err = test.repo.Subtree(context.Background(), backend.ID)
+	require.Nil(test.T(), err)
+	assert.Len(test.T(), subtree, 1)
+
+	// moving to the root
+	moved, err = test.repo.Move(context.Background(), db.ID, nil)
+	require.Nil(test.T(), err)
+	assert.Nil(test.T(), moved.ParentID())
+}
+
+func (test *areaRepoBBTest) TestMoveFail() {
+	backend := test.create("backend", nil)
+	db := test.create("db", backend)
+
+	_, err := test.repo.Move(context.Background(), backend.ID, &db.ID)
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+	_, err = test.repo.Move(context.Background(), backend.ID, &backend.ID)
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+
+	test.create("db", nil)
+	_, err = test.repo.Move(context.Background(), db.ID, nil)
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+}
+
+func (test *areaRepoBBTest) TestSaveListAndDelete() {
+	backend := test.create("backend", nil)
+	db := test.create("db", backend)
+
+	db.Name = "database"
+	saved, err := test.repo.Save(context.Background(), *db)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), "database", saved.Name)
+	assert.Equal(test.T(), db.Path, saved.Path)
+	_, err = test.repo.Save(context.Background(), *db)
+	assert.IsType(test.T(), errors.VersionConflictError{}, err)
+
+	areas, err := test.repo.List(context.Background(), test.project.ID)
+	require.Nil(test.T(), err)
+	require.Len(test.T(), areas, 2)
+	assert.Equal(test.T(), backend.ID, areas[0].ID)
+
+	assert.IsType(test.T(), errors.BadParameterError{}, test.repo.Delete(context.Background(), backend.ID))
+	require.Nil(test.T(), test.repo.Delete(context.Background(), db.ID))
+	require.Nil(test.T(), test.repo.Delete(context.Background(), backend.ID))
+	assert.IsType(test.T(), errors.NotFoundError{}, test.repo.Delete(context.Background(), backend.ID))
+}
@@ -0,0 +1,145 @@
+package main_test
+
+import (
+	"testing"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestAreaREST struct {
+	gormsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	clean func()
+}
+
+func TestRunAreaREST(t *testing.T) {
+	suite.Run(t, &TestAreaREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestAreaREST) SetupTest() {
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = gormsupport.DeleteCreatedEntities(rest.DB)
+}
+
+func (rest *TestAreaREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestAreaREST) service() *goa.Service {
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+	return testsupport.ServiceAsUser("Area-Service", almtoken.NewManager(pub, priv), account.TestIdentity)
+}
+
+func (rest *TestAreaREST) createArea(svc *goa.Service, projectID string, name string, parent *uuid.UUID) *app.Area {
+	_, a := test.CreateProjectAreasCreated(rest.T(), svc.Context, svc, NewProjectAreasController(svc, rest.db), projectID, &app.CreateAreaPayload{
+		Data: &app.AreaData{
+			Type:       "areas",
+			Attributes: &app.AreaAttributes{Name: &name, Parent: parent},
+		},
+	})
+	return a
+}
+
+func (rest *TestAreaREST) TestManageTree() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc := rest.service()
+	name := "TestAreaREST-" + uuid.NewV4().String()
+	_, p := test.CreateProjectCreated(t, svc.Context, svc, NewProjectController(svc, rest.db), createProjectPayload(&name, nil))
+	projectID := p.Data.ID.String()
+	ctrl := NewAreaController(svc, rest.db)
+
+	backend := rest.createArea(svc, projectID, "backend", nil)
+	db := rest.createArea(svc, projectID, "db", backend.Data.ID)
+	frontend := rest.createArea(svc, projectID, "frontend", nil)
+	assert.Nil(t, backend.Data.Attributes.Parent)
+	assert.Equal(t, *backend.Data.ID, *db.Data.Attributes.Parent)
+
+	_, moved := test.MoveAreaOK(t, svc.Context, svc, ctrl, db.Data.ID.String(), &app.MoveAreaPayload{
+		Data: &app.AreaData{
+			Type:       "areas",
+			Attributes: &app.AreaAttributes{Parent: frontend.Data.ID},
+		},
+	})
+	assert.Equal(t, *frontend.Data.ID, *moved.Data.Attributes.Parent)
+	assert.Equal(t, *frontend.Data.Attributes.Path+"/"+db.Data.ID.String(), *moved.Data.Attributes.Path)
+
+	test.MoveAreaBadRequest(t, svc.Context, svc, ctrl, frontend.Data.ID.String(), &app.MoveAreaPayload{
+		Data: &app.AreaData{
+			Type:       "areas",
+			Attributes: &app.AreaAttributes{Parent: db.Data.ID},
+		},
+	})
+
+	_, subtree := test.SubtreeAreaOK(t, svc.Context, svc, ctrl, frontend.Data.ID.String())
+	assert.Len(t, subtree.Data, 2)
+	_, list := test.ListProjectAreasOK(t, svc.Context, svc, NewProjectAreasController(svc, rest.db), projectID)
+	assert.Len(t, list.Data, 3)
+
+	newName := "client"
+	_, renamed := test.UpdateAreaOK(t, svc.Context, svc, ctrl, frontend.Data.ID.String(), &app.UpdateAreaPayload{
+		Data: &app.AreaData{
+			Type:       "areas",
+			Attributes: &app.AreaAttributes{Name: &newName, Version: frontend.Data.Attributes.Version},
+		},
+	})
+	assert.Equal(t, newName, *renamed.Data.Attributes.Name)
+
+	test.DeleteAreaBadRequest(t, svc.Context, svc, ctrl, frontend.Data.ID.String())
+	test.DeleteAreaOK(t, svc.Context, svc, ctrl, db.Data.ID.String())
+	test.ShowAreaNotFound(t, svc.Context, svc, ctrl, db.Data.ID.String())
+}
+
+func (rest *TestAreaREST) TestListWorkItemsInSubtree() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc := rest.service()
+	name := "TestAreaREST-" + uuid.NewV4().String()
+	_, p := test.CreateProjectCreated(t, svc.Context, svc, NewProjectController(svc, rest.db), createProjectPayload(&name, nil))
+	projectID := p.Data.ID.String()
+	backend := rest.createArea(svc, projectID, "backend", nil)
+	db := rest.createArea(svc, projectID, "db", backend.Data.ID)
+	frontend := rest.createArea(svc, projectID, "frontend", nil)
+
+	ctrl := NewProjectWorkItemsController(svc, rest.db)
+	for _, a := range []*app.Area{backend, db, frontend} {
+		test.CreateProjectWorkItemsCreated(t, svc.Context, svc, ctrl, projectID, &app.CreateWorkItemPayload{
+			Type: workitem.SystemBug,
+			Fields: map[string]interface{}{
+				workitem.SystemTitle: *a.Data.Attributes.Name,
+				workitem.SystemState: workitem.SystemStateNew,
+				workitem.SystemArea:  a.Data.ID.String(),
+			},
+		})
+	}
+
+	backendID := backend.Data.ID.String()
+	_, list := test.ListProjectWorkItemsOK(t, svc.Context, svc, ctrl, projectID, &backendID, nil, nil, nil, nil)
+	assert.Len(t, list.Data, 2)
+
+	dbID := db.Data.ID.String()
+	_, list = test.ListProjectWorkItemsOK(t, svc.Context, svc, ctrl, projectID, &dbID, nil, nil, nil, nil)
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, "db", list.Data[0].Fields[workitem.SystemTitle])
+
+	unknown := uuid.NewV4().String()
+	test.ListProjectWorkItemsNotFound(t, svc.Context, svc, ctrl, projectID, &unknown, nil, nil, nil, nil)
+}
@@ -0,0 +1,532 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/remoteworkitem"
+	"github.com/almighty/almighty-core/token"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// projectResolver returns the ID of the project the current request operates on
+type projectResolver func(ctx context.Context, appl application.Application) (uuid.UUID, error)
+
+// actionRule describes the permission an identity needs in the project
+// returned by the resolver in order to run an action.
+type actionRule struct {
+	permission string
+	project    projectResolver
+}
+
+// actionRules maps controller and action names to the rule that protects the
+// action. Only actions that require a JWT are subject to authorization, all
+// other actions are public. Lists spanning all projects are checked against
+// the system project and filtered by their controllers with
+// readableProjects.
+var actionRules = map[string]map[string]actionRule{
+	// also used by the workitem.2 resource
+	"WorkitemController": {
+		"show":   {Permissions.ReadWorkItem, projectOfWorkItemParam("id")},
+		"list":   {Permissions.ReadWorkItem, systemProject},
+		"create": {Permissions.CreateWorkItem, projectQueryParam("project")},
+		"update": {Permissions.UpdateWorkItem, projectOfWorkItemParam("id")},
+		"delete": {Permissions.DeleteWorkItem, projectOfWorkItemParam("id")},
+	},
+	"ProjectWorkItemsController": {
+		"list":   {Permissions.ReadWorkItem, projectParam("id")},
+		"create": {Permissions.CreateWorkItem, projectParam("id")},
+	},
+	"ProjectWorkItemTypesController": {
+		"create": {Permissions.ManageProject, projectParam("id")},
+	},
+	"WorkitemtypeController": {
+		"create": {Permissions.ManageProject, projectQueryParam("project")},
+	},
+	"ProjectTrackerQueriesController": {
+		"list": {Permissions.ReadWorkItem, projectParam("id")},
+	},
+	// moving a tracker query to another project is checked by the controller
+	"TrackerqueryController": {
+		"create": {Permissions.ManageProject, projectOfTrackerQueryPayload},
+		"update": {Permissions.ManageProject, projectOfTrackerQueryParam("id")},
+		"delete": {Permissions.ManageProject, projectOfTrackerQueryParam("id")},
+		"resync": {Permissions.ManageProject, projectOfTrackerQueryParam("id")},
+		"run":    {Permissions.ManageProject, projectOfTrackerQueryParam("id")},
+		"runs":   {Permissions.ReadWorkItem, projectOfTrackerQueryParam("id")},
+	},
+	"SearchController": {
+		"show": {Permissions.ReadWorkItem, systemProject},
+	},
+	"WorkItemLinkController": {
+		"show":   {Permissions.ReadWorkItem, projectOfWorkItemLinkParam("linkId")},
+		"list":   {Permissions.ReadWorkItem, systemProject},
+		"create": {Permissions.UpdateWorkItem, projectOfLinkPayloadSource},
+		"update": {Permissions.UpdateWorkItem, projectOfWorkItemLinkParam("linkId")},
+		"delete": {Permissions.UpdateWorkItem, projectOfWorkItemLinkParam("linkId")},
+	},
+	"WorkItemRelationshipsLinksController": {
+		"show":   {Permissions.ReadWorkItem, projectOfWorkItemLinkParam("linkId")},
+		"list":   {Permissions.ReadWorkItem, projectOfWorkItemParam("id")},
+		"create": {Permissions.UpdateWorkItem, projectOfWorkItemParam("id")},
+		"update": {Permissions.UpdateWorkItem, projectOfWorkItemParam("id")},
+		"delete": {Permissions.UpdateWorkItem, projectOfWorkItemParam("id")},
+	},
+	"WorkItemRelationshipsCommentsController": {
+		"list":   {Permissions.ReadWorkItem, projectOfWorkItemParam("id")},
+		"create": {Permissions.UpdateWorkItem, projectOfWorkItemParam("id")},
+	},
+	"CommentsController": {
+		"show":   {Permissions.ReadWorkItem, projectOfCommentParam("commentID")},
+		"list":   {Permissions.ReadWorkItem, projectOfCommentParentParams("filter[parentType]", "filter[parentID]")},
+		"create": {Permissions.UpdateWorkItem, projectOfCommentPayloadParent},
+	},
+	"ProjectController": {
+		"update": {Permissions.ManageProject, projectParam("id")},
+		"delete": {Permissions.ManageProject, projectParam("id")},
+	},
+	"ProjectMembershipsController": {
+		"list":   {Permissions.ReadWorkItem, projectParam("id")},
+		"create": {Permissions.ManageProject, projectParam("id")},
+		"delete": {Permissions.ManageProject, projectParam("id")},
+	},
+	"ProjectIterationsController": {
+		"create": {Permissions.ManageProject, projectParam("id")},
+	},
+	"IterationController": {
+		"update": {Permissions.ManageProject, projectOfIterationParam("id")},
+		"delete": {Permissions.ManageProject, projectOfIterationParam("id")},
+	},
+	"ProjectAreasController": {
+		"create": {Permissions.ManageProject, projectParam("id")},
+	},
+	"AreaController": {
+		"update": {Permissions.ManageProject, projectOfAreaParam("id")},
+		"move":   {Permissions.ManageProject, projectOfAreaParam("id")},
+		"delete": {Permissions.ManageProject, projectOfAreaParam("id")},
+	},
+	"ProjectMilestonesController": {
+		"create": {Permissions.ManageProject, projectParam("id")},
+	},
+	"MilestoneController": {
+		"update": {Permissions.ManageProject, projectOfMilestoneParam("id")},
+		"delete": {Permissions.ManageProject, projectOfMilestoneParam("id")},
+	},
+	"ProjectTeamsController": {
+		"create": {Permissions.ManageProject, projectParam("id")},
+		"delete": {Permissions.ManageProject, projectParam("id")},
+	},
+	// owners of the system project administer the installation
+	"login": {
+		"createPasswordReset": {Permissions.ManageProject, systemProject},
+	},
+	"TrackerController": {
+		"create": {Permissions.ManageProject, systemProject},
+		"update": {Permissions.ManageProject, systemProject},
+		"delete": {Permissions.ManageProject, systemProject},
+	},
+	"WorkItemLinkCategoryController": {
+		"create": {Permissions.ManageProject, systemProject},
+		"update": {Permissions.ManageProject, systemProject},
+		"delete": {Permissions.ManageProject, systemProject},
+	},
+	"WorkItemLinkTypeController": {
+		"create": {Permissions.ManageProject, systemProject},
+		"update": {Permissions.ManageProject, systemProject},
+		"delete": {Permissions.ManageProject, systemProject},
+	},
+	"TeamController": {
+		"create": {Permissions.ManageProject, systemProject},
+		"update": {Permissions.ManageProject, systemProject},
+		"delete": {Permissions.ManageProject, systemProject},
+	},
+	"TeamMembersController": {
+		"create": {Permissions.ManageProject, systemProject},
+		"delete": {Permissions.ManageProject, systemProject},
+	},
+}
+
+// accountControllers are the controllers whose actions manage identities and
+// their credentials rather than project data. Access tokens need the
+// ManageAccount scope for them.
+var accountControllers = map[string]bool{
+	"UserController":                 true,
+	"AccessTokenController":          true,
+	"ServiceAccountController":       true,
+	"ServiceAccountTokensController": true,
+}
+
+// NewAuthorizer returns a middleware that checks that the identity making the
+// request has a role in the affected project which grants the permission
+// required by the requested action. Requests made with an access token
+// additionally need the token to be active and to have the permission in its
+// scopes. It must run after the JWT middleware, i.e. as its validation
+// function, so that the token can be checked for expiry and revocation and
+// the identity can be looked up with tokenManager.Locate.
+// Denied requests fail with an errors.ForbiddenError which
+// jsonapi.ErrorHandler turns into a 403 response.
+func NewAuthorizer(db application.DB, tokenManager token.Manager) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if err := tokenManager.Validate(ctx); err != nil {
+				return goa.ErrUnauthorized(err.Error())
+			}
+			identityID, err := tokenManager.Locate(ctx)
+			if err != nil {
+				return goa.ErrUnauthorized(err.Error())
+			}
+			controller := goa.ContextController(ctx)
+			rule, ok := actionRules[controller][goa.ContextAction(ctx)]
+			accessTokenID, isAccessToken := token.AccessTokenID(ctx)
+			if !ok && !isAccessToken {
+				return h(ctx, rw, req)
+			}
+			err = application.Transactional(db, func(appl application.Application) error {
+				if isAccessToken {
+					scope := rule.permission
+					if !ok && accountControllers[controller] {
+						scope = Permissions.ManageAccount
+					}
+					if err := checkAccessToken(ctx, appl, accessTokenID, identityID, scope); err != nil {
+						return err
+					}
+				}
+				if !ok {
+					return nil
+				}
+				projectID, err := rule.project(ctx, appl)
+				if err != nil {
+					return err
+				}
+				return checkPermission(ctx, appl, projectID, identityID, rule.permission)
+			})
+			if err != nil {
+				return err
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
+
+// checkPermission returns an errors.ForbiddenError unless the identity has a
+// role in the project that grants the permission, either by its own
+// membership or through one of its teams. Every identity implicitly is a
+// contributor of the system project.
+func checkPermission(ctx context.Context, appl application.Application, projectID, identityID uuid.UUID, permission string) error {
+	var roles []string
+	m, err := appl.ProjectMemberships().Load(ctx, projectID, identityID)
+	switch err.(type) {
+	case nil:
+		roles = append(roles, m.Role)
+	case errors.NotFoundError:
+		if uuid.Equal(projectID, project.SystemProject) {
+			roles = append(roles, project.RoleContributor)
+		}
+	default:
+		return err
+	}
+	teamRoles, err := appl.Teams().RolesOf(ctx, projectID, identityID)
+	if err != nil {
+		return err
+	}
+	for _, role := range append(roles, teamRoles...) {
+		if RoleHasPermission(role, permission) {
+			return nil
+		}
+	}
+	return errors.NewForbiddenError(fmt.Sprintf("identity %s lacks permission %s in project %s", identityID, permission, projectID))
+}
+
+// checkCurrentPermission returns an errors.ForbiddenError unless the identity
+// making the request has the permission in the project, for actions that
+// affect more projects than the one checked by their rule
+func checkCurrentPermission(ctx context.Context, appl application.Application, projectID uuid.UUID, permission string) error {
+	identityID, err := currentIdentityID(ctx)
+	if err != nil {
+		return err
+	}
+	return checkPermission(ctx, appl, projectID, identityID, permission)
+}
+
+// readableProjects returns the IDs of the projects the identity making the
+// request may read work items in, for lists and searches spanning all
+// projects
+func readableProjects(ctx context.Context, appl application.Application) ([]uuid.UUID, error) {
+	identityID, err := currentIdentityID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	projects, _, err := appl.Projects().List(ctx, nil, nil)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	result := []uuid.UUID{}
+	for _, p := range projects {
+		switch err := checkPermission(ctx, appl, p.ID, identityID, Permissions.ReadWorkItem).(type) {
+		case nil:
+			result = append(result, p.ID)
+		case errors.ForbiddenError:
+		default:
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// inProjects restricts work items to the given projects
+func inProjects(projectIDs []uuid.UUID) criteria.Expression {
+	if len(projectIDs) == 0 {
+		return criteria.Literal(false)
+	}
+	var result criteria.Expression
+	for _, id := range projectIDs {
+		current := criteria.Equals(criteria.Field("Project"), criteria.Literal(id.String()))
+		if result == nil {
+			result = current
+		} else {
+			result = criteria.Or(result, current)
+		}
+	}
+	return result
+}
+
+// currentIdentityID returns the ID of the identity making the request or an
+// errors.UnauthorizedError
+func currentIdentityID(ctx context.Context) (uuid.UUID, error) {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return uuid.Nil, errors.NewUnauthorizedError(err.Error())
+	}
+	identityID, err := uuid.FromString(currentUser)
+	if err != nil {
+		return uuid.Nil, errors.NewUnauthorizedError(err.Error())
+	}
+	return identityID, nil
+}
+
+// checkAccessToken records the use of the access token and returns an error
+// unless it is active, belongs to the identity and has the given scope. Tokens
+// limited to scopes may not run actions without a scope, i.e. without a rule;
+// tokens without scopes may run them.
+func checkAccessToken(ctx context.Context, appl application.Application, id, identityID uuid.UUID, scope string) error {
+	t, err := appl.AccessTokens().Use(ctx, id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !uuid.Equal(t.IdentityID, identityID) {
+		return errors.NewUnauthorizedError(fmt.Sprintf("access token %s does not belong to identity %s", id, identityID))
+	}
+	if scope == "" && len(t.ScopeList()) > 0 {
+		return errors.NewForbiddenError(fmt.Sprintf("access token %s is limited to scopes %s", id, t.Scopes))
+	}
+	if scope != "" && !t.HasScope(scope) {
+		return errors.NewForbiddenError(fmt.Sprintf("access token %s lacks scope %s", id, scope))
+	}
+	return nil
+}
+
+// projectOfWorkItem returns the ID of the project the work item belongs to
+func projectOfWorkItem(ctx context.Context, appl application.Application, id string) (uuid.UUID, error) {
+	wi, err := appl.WorkItems().Load(ctx, id)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if wi.Project == nil {
+		return project.SystemProject, nil
+	}
+	return *wi.Project, nil
+}
+
+// projectOfWorkItemLink returns the ID of the project the source work item of
+// the link belongs to
+func projectOfWorkItemLink(ctx context.Context, appl application.Application, id string) (uuid.UUID, error) {
+	wil, err := appl.WorkItemLinks().Load(ctx, id)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	src, _ := getSrcTgt(wil.Data)
+	if src == nil {
+		return uuid.Nil, errors.NewInternalError(fmt.Sprintf("work item link %s has no source", id))
+	}
+	return projectOfWorkItem(ctx, appl, *src)
+}
+
+// systemProject resolves every request to the system project
+func systemProject(ctx context.Context, appl application.Application) (uuid.UUID, error) {
+	return project.SystemProject, nil
+}
+
+// projectParam resolves requests to the project whose ID is in the given
+// path parameter
+func projectParam(name string) projectResolver {
+	return func(ctx context.Context, appl application.Application) (uuid.UUID, error) {
+		p, err := loadProject(ctx, appl, goa.ContextRequest(ctx).Params.Get(name))
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return p.ID, nil
+	}
+}
+
+// projectQueryParam resolves requests to the project whose ID is in the given
+// optional query parameter, to the system project without the parameter
+func projectQueryParam(name string) projectResolver {
+	return func(ctx context.Context, appl application.Application) (uuid.UUID, error) {
+		id := goa.ContextRequest(ctx).Params.Get(name)
+		if id == "" {
+			return project.SystemProject, nil
+		}
+		p, err := loadProject(ctx, appl, id)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return p.ID, nil
+	}
+}
+
+// projectOfTrackerQueryParam resolves requests to the project of the tracker
+// query whose ID is in the given path parameter
+func projectOfTrackerQueryParam(name string) projectResolver {
+	return func(ctx context.Context, appl application.Application) (uuid.UUID, error) {
+		id := goa.ContextRequest(ctx).Params.Get(name)
+		tq, err := appl.TrackerQueries().Load(ctx, id)
+		switch err.(type) {
+		case nil:
+			return tq.ProjectID, nil
+		case remoteworkitem.NotFoundError:
+			return uuid.Nil, errors.NewNotFoundError("tracker query", id)
+		default:
+			return uuid.Nil, errors.NewInternalError(err.Error())
+		}
+	}
+}
+
+// projectOfTrackerQueryPayload resolves requests to the project the tracker
+// query in the payload imports into, the system project if none is given
+func projectOfTrackerQueryPayload(ctx context.Context, appl application.Application) (uuid.UUID, error) {
+	payload, ok := goa.ContextRequest(ctx).Payload.(*app.CreateTrackerQueryAlternatePayload)
+	if !ok || payload.ProjectID == nil {
+		return project.SystemProject, nil
+	}
+	p, err := appl.Projects().Load(ctx, *payload.ProjectID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return p.ID, nil
+}
+
+// projectOfWorkItemParam resolves requests to the project of the work item
+// whose ID is in the given path parameter
+func projectOfWorkItemParam(name string) projectResolver {
+	return func(ctx context.Context, appl application.Application) (uuid.UUID, error) {
+		return projectOfWorkItem(ctx, appl, goa.ContextRequest(ctx).Params.Get(name))
+	}
+}
+
+// projectOfWorkItemLinkParam resolves requests to the project of the work item
+// link whose ID is in the given path parameter
+func projectOfWorkItemLinkParam(name string) projectResolver {
+	return func(ctx context.Context, appl application.Application) (uuid.UUID, error) {
+		return projectOfWorkItemLink(ctx, appl, goa.ContextRequest(ctx).Params.Get(name))
+	}
+}
+
+// projectOfCommentParam resolves requests to the project of the parent of the
+// comment whose ID is in the given path parameter
+func projectOfCommentParam(name string) projectResolver {
+	return func(ctx context.Context, appl application.Application) (uuid.UUID, error) {
+		id := goa.ContextRequest(ctx).Params.Get(name)
+		commentID, err := uuid.FromString(id)
+		if err != nil {
+			return uuid.Nil, errors.NewNotFoundError("comment", id)
+		}
+		c, err := appl.WorkItemComments().Load(ctx, commentID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return checkCommentParent(ctx, appl, c.ParentType, c.ParentID)
+	}
+}
+
+// projectOfCommentParentParams resolves requests to the project of the parent
+// item whose type and ID are in the given query parameters
+func projectOfCommentParentParams(typeName, idName string) projectResolver {
+	return func(ctx context.Context, appl application.Application) (uuid.UUID, error) {
+		params := goa.ContextRequest(ctx).Params
+		return checkCommentParent(ctx, appl, params.Get(typeName), params.Get(idName))
+	}
+}
+
+// projectOfIterationParam resolves requests to the project of the iteration
+// whose ID is in the given path parameter
+func projectOfIterationParam(name string) projectResolver {
+	return func(ctx context.Context, appl application.Application) (uuid.UUID, error) {
+		i, err := loadIteration(ctx, appl, goa.ContextRequest(ctx).Params.Get(name))
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return i.ProjectID, nil
+	}
+}
+
+// projectOfAreaParam resolves requests to the project of the area whose ID
+// is in the given path parameter
+func projectOfAreaParam(name string) projectResolver {
+	return func(ctx context.Context, appl application.Application) (uuid.UUID, error) {
+		a, err := loadArea(ctx, appl, goa.ContextRequest(ctx).Params.Get(name))
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return a.ProjectID, nil
+	}
+}
+
+// projectOfMilestoneParam resolves requests to the project of the milestone
+// whose ID is in the given path parameter
+func projectOfMilestoneParam(name string) projectResolver {
+	return func(ctx context.Context, appl application.Application) (uuid.UUID, error) {
+		m, err := loadMilestone(ctx, appl, goa.ContextRequest(ctx).Params.Get(name))
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return m.ProjectID, nil
+	}
+}
+
+// projectOfLinkPayloadSource resolves requests to the project of the source
+// work item of the work item link in the payload
+func projectOfLinkPayloadSource(ctx context.Context, appl application.Application) (uuid.UUID, error) {
+	payload, ok := goa.ContextRequest(ctx).Payload.(*app.CreateWorkItemLinkPayload)
+	if !ok {
+		return uuid.Nil, errors.NewBadParameterError("data", nil)
+	}
+	src, _ := getSrcTgt(payload.Data)
+	if src == nil {
+		return uuid.Nil, errors.NewBadParameterError("data.relationships.source", nil)
+	}
+	return projectOfWorkItem(ctx, appl, *src)
+}
+
+// projectOfCommentPayloadParent resolves requests to the project of the
+// parent of the comment in the payload
+func projectOfCommentPayloadParent(ctx context.Context, appl application.Application) (uuid.UUID, error) {
+	payload, ok := goa.ContextRequest(ctx).Payload.(*app.CreateCommentsPayload)
+	if !ok {
+		return uuid.Nil, errors.NewBadParameterError("data", nil)
+	}
+	relationships := payload.Data.Relationships
+	if relationships == nil || relationships.Parent == nil {
+		return uuid.Nil, errors.NewBadParameterError("data.relationships.parent", nil)
+	}
+	return checkCommentParent(ctx, appl, relationships.Parent.Data.Type, relationships.Parent.Data.ID)
+}
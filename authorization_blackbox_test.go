@@ -0,0 +1,447 @@
+package main_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/remoteworkitem"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/team"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/workitem"
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestAuthorizer struct {
+	gormsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	clean func()
+}
+
+func TestRunAuthorizer(t *testing.T) {
+	suite.Run(t, &TestAuthorizer{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (s *TestAuthorizer) SetupTest() {
+	s.db = gormapplication.NewGormDB(s.DB)
+	s.clean = gormsupport.DeleteCreatedEntities(s.DB)
+}
+
+func (s *TestAuthorizer) TearDownTest() {
+	s.clean()
+}
+
+// authorize runs the authorizer for the given controller action on behalf of
+// the identity and returns whether the wrapped handler got called.
+func (s *TestAuthorizer) authorize(controller, action string, params url.Values, identityID uuid.UUID) (bool, error) {
+	return s.authorizeWith(controller, action, params, func(ctx context.Context) context.Context {
+		return testsupport.WithIdentity(ctx, account.Identity{ID: identityID, FullName: "Test", ImageURL: "http://example.com"})
+	})
+}
+
+// authorizeToken runs the authorizer for the given controller action with the
+// given signed token.
+func (s *TestAuthorizer) authorizeToken(controller, action string, params url.Values, tokenString string) (bool, error) {
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	tk, err := jwt.Parse(tokenString, func(*jwt.Token) (interface{}, error) { return pub, nil })
+	require.Nil(s.T(), err)
+	return s.authorizeWith(controller, action, params, func(ctx context.Context) context.Context {
+		return goajwt.WithJWT(ctx, tk)
+	})
+}
+
+func (s *TestAuthorizer) authorizeWith(controller, action string, params url.Values, withToken func(context.Context) context.Context) (bool, error) {
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+
+	svc := goa.New("Authorizer-Service")
+	rw := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/", nil)
+	ctx := goa.NewContext(goa.WithAction(svc.NewController(controller).Context, action), rw, req, params)
+	ctx = withToken(ctx)
+
+	called := false
+	handler := NewAuthorizer(s.db, almtoken.NewManager(pub, priv))(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		called = true
+		return nil
+	})
+	err := handler(ctx, rw, req)
+	return called, err
+}
+
+// createProjectWithMember creates a project in which the identity has the given role
+func (s *TestAuthorizer) createProjectWithMember(identityID uuid.UUID, role string) uuid.UUID {
+	var projectID uuid.UUID
+	err := application.Transactional(s.db, func(appl application.Application) error {
+		p, err := appl.Projects().Create(context.Background(), "authorizer-test-"+uuid.NewV4().String())
+		if err != nil {
+			return err
+		}
+		projectID = p.ID
+		_, err = appl.ProjectMemberships().Create(context.Background(), p.ID, identityID, role)
+		return err
+	})
+	require.Nil(s.T(), err)
+	return projectID
+}
+
+func (s *TestAuthorizer) TestRolesInProject() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	owner := uuid.NewV4()
+	projectID := s.createProjectWithMember(owner, project.RoleOwner)
+	viewer := uuid.NewV4()
+	err := application.Transactional(s.db, func(appl application.Application) error {
+		_, err := appl.ProjectMemberships().Create(context.Background(), projectID, viewer, project.RoleViewer)
+		return err
+	})
+	require.Nil(t, err)
+	params := url.Values{"id": []string{projectID.String()}}
+
+	called, err := s.authorize("ProjectWorkItemsController", "create", params, owner)
+	assert.Nil(t, err)
+	assert.True(t, called)
+
+	called, err = s.authorize("ProjectWorkItemsController", "create", params, viewer)
+	assert.IsType(t, errors.ForbiddenError{}, err)
+	assert.False(t, called)
+
+	called, err = s.authorize("ProjectWorkItemsController", "create", params, uuid.NewV4())
+	assert.IsType(t, errors.ForbiddenError{}, err)
+	assert.False(t, called)
+
+	called, err = s.authorize("ProjectMembershipsController", "create", params, owner)
+	assert.Nil(t, err)
+	assert.True(t, called)
+}
+
+func (s *TestAuthorizer) TestRolesThroughTeams() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	owner := uuid.NewV4()
+	projectID := s.createProjectWithMember(owner, project.RoleOwner)
+	// team members have to be known identities
+	var members []uuid.UUID
+	for _, name := range []string{"Test Viewer", "Test Contributor"} {
+		identity := account.Identity{FullName: name}
+		require.Nil(t, account.NewIdentityRepository(s.DB).Create(context.Background(), &identity))
+		members = append(members, identity.ID)
+	}
+	viewer, contributor := members[0], members[1]
+	err := application.Transactional(s.db, func(appl application.Application) error {
+		ctx := context.Background()
+		_, err := appl.ProjectMemberships().Create(ctx, projectID, viewer, project.RoleViewer)
+		if err != nil {
+			return err
+		}
+		parent := team.Team{Name: "authorizer-" + uuid.NewV4().String()[:8]}
+		if err := appl.Teams().Create(ctx, &parent); err != nil {
+			return err
+		}
+		child := team.Team{Name: "authorizer-" + uuid.NewV4().String()[:8], ParentID: &parent.ID}
+		if err := appl.Teams().Create(ctx, &child); err != nil {
+			return err
+		}
+		for _, identityID := range []uuid.UUID{viewer, contributor} {
+			if _, err := appl.Teams().AddMember(ctx, child.ID, identityID); err != nil {
+				return err
+			}
+		}
+		_, err = appl.Teams().GrantRole(ctx, projectID, parent.ID, project.RoleContributor)
+		return err
+	})
+	require.Nil(t, err)
+	params := url.Values{"id": []string{projectID.String()}}
+
+	// the role of the team counts in addition to the own membership
+	for _, identityID := range []uuid.UUID{viewer, contributor} {
+		called, err := s.authorize("ProjectWorkItemsController", "create", params, identityID)
+		assert.Nil(t, err)
+		assert.True(t, called)
+	}
+	called, err := s.authorize("ProjectTeamsController", "create", params, contributor)
+	assert.IsType(t, errors.ForbiddenError{}, err)
+	assert.False(t, called)
+	called, err = s.authorize("ProjectTeamsController", "create", params, owner)
+	assert.Nil(t, err)
+	assert.True(t, called)
+}
+
+func (s *TestAuthorizer) TestWorkItemInProject() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	contributor := uuid.NewV4()
+	projectID := s.createProjectWithMember(contributor, project.RoleContributor)
+	var wiID string
+	err := application.Transactional(s.db, func(appl application.Application) error {
+		wi, err := appl.WorkItems().Create(context.Background(), projectID, workitem.SystemBug, map[string]interface{}{
+			workitem.SystemTitle: "authorized",
+			workitem.SystemState: "new",
+		}, contributor.String())
+		if err != nil {
+			return err
+		}
+		wiID = wi.ID
+		return nil
+	})
+	require.Nil(t, err)
+	params := url.Values{"id": []string{wiID}}
+
+	called, err := s.authorize("WorkitemController", "update", params, contributor)
+	assert.Nil(t, err)
+	assert.True(t, called)
+
+	called, err = s.authorize("WorkitemController", "delete", params, uuid.NewV4())
+	assert.IsType(t, errors.ForbiddenError{}, err)
+	assert.False(t, called)
+
+	called, err = s.authorize("WorkItemRelationshipsCommentsController", "create", params, uuid.NewV4())
+	assert.IsType(t, errors.ForbiddenError{}, err)
+	assert.False(t, called)
+}
+
+func (s *TestAuthorizer) TestReadWorkItemInProject() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	owner := uuid.NewV4()
+	projectID := s.createProjectWithMember(owner, project.RoleOwner)
+	viewer := uuid.NewV4()
+	var wiID string
+	var commentID uuid.UUID
+	err := application.Transactional(s.db, func(appl application.Application) error {
+		ctx := context.Background()
+		if _, err := appl.ProjectMemberships().Create(ctx, projectID, viewer, project.RoleViewer); err != nil {
+			return err
+		}
+		wi, err := appl.WorkItems().Create(ctx, projectID, workitem.SystemBug, map[string]interface{}{
+			workitem.SystemTitle: "readable",
+			workitem.SystemState: "new",
+		}, owner.String())
+		if err != nil {
+			return err
+		}
+		wiID = wi.ID
+		c := comment.Comment{ParentType: comment.ParentTypeWorkItem, ParentID: wi.ID, Body: "readable", CreatedBy: owner}
+		if err := appl.WorkItemComments().Create(ctx, &c); err != nil {
+			return err
+		}
+		commentID = c.ID
+		return nil
+	})
+	require.Nil(t, err)
+	nonMember := uuid.NewV4()
+	reads := []struct {
+		controller string
+		action     string
+		params     url.Values
+	}{
+		{"WorkitemController", "show", url.Values{"id": []string{wiID}}},
+		{"WorkItemRelationshipsCommentsController", "list", url.Values{"id": []string{wiID}}},
+		{"WorkItemRelationshipsLinksController", "list", url.Values{"id": []string{wiID}}},
+		{"CommentsController", "show", url.Values{"commentID": []string{commentID.String()}}},
+		{"CommentsController", "list", url.Values{"filter[parentType]": []string{comment.ParentTypeWorkItem}, "filter[parentID]": []string{wiID}}},
+		{"ProjectMembershipsController", "list", url.Values{"id": []string{projectID.String()}}},
+	}
+
+	// viewers read the work items, comments and members of the project, non
+	// members do not
+	for _, read := range reads {
+		called, err := s.authorize(read.controller, read.action, read.params, viewer)
+		assert.Nil(t, err, read.controller)
+		assert.True(t, called, read.controller)
+		called, err = s.authorize(read.controller, read.action, read.params, nonMember)
+		assert.IsType(t, errors.ForbiddenError{}, err, read.controller)
+		assert.False(t, called, read.controller)
+	}
+
+	// anonymous callers are rejected before any rule is checked
+	for _, read := range reads {
+		called, err := s.authorizeWith(read.controller, read.action, read.params, func(ctx context.Context) context.Context {
+			return ctx
+		})
+		require.NotNil(t, err, read.controller)
+		assert.Equal(t, http.StatusUnauthorized, err.(goa.ServiceError).ResponseStatus(), read.controller)
+		assert.False(t, called, read.controller)
+	}
+}
+
+func (s *TestAuthorizer) TestProjectConfiguration() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	owner := uuid.NewV4()
+	projectID := s.createProjectWithMember(owner, project.RoleOwner)
+	viewer := uuid.NewV4()
+	var queryID string
+	err := application.Transactional(s.db, func(appl application.Application) error {
+		ctx := context.Background()
+		if _, err := appl.ProjectMemberships().Create(ctx, projectID, viewer, project.RoleViewer); err != nil {
+			return err
+		}
+		tr, err := appl.Trackers().Create(ctx, "https://api.github.com/", remoteworkitem.ProviderGithub)
+		if err != nil {
+			return err
+		}
+		tq, err := appl.TrackerQueries().Create(ctx, "is:open", "0 0 0 * * *", tr.ID, projectID)
+		if err != nil {
+			return err
+		}
+		queryID = tq.ID
+		return nil
+	})
+	require.Nil(t, err)
+	nonMember := uuid.NewV4()
+	projectParams := url.Values{"id": []string{projectID.String()}}
+	queryParams := url.Values{"id": []string{queryID}}
+
+	// viewers read the work items and tracker queries of the project, non members do not
+	for _, controller := range []string{"ProjectWorkItemsController", "ProjectTrackerQueriesController"} {
+		called, err := s.authorize(controller, "list", projectParams, viewer)
+		assert.Nil(t, err)
+		assert.True(t, called)
+		called, err = s.authorize(controller, "list", projectParams, nonMember)
+		assert.IsType(t, errors.ForbiddenError{}, err)
+		assert.False(t, called)
+	}
+
+	// only owners change the tracker queries of the project
+	for _, action := range []string{"update", "delete", "resync", "run"} {
+		for _, identityID := range []uuid.UUID{viewer, nonMember} {
+			called, err := s.authorize("TrackerqueryController", action, queryParams, identityID)
+			assert.IsType(t, errors.ForbiddenError{}, err)
+			assert.False(t, called)
+		}
+		called, err := s.authorize("TrackerqueryController", action, queryParams, owner)
+		assert.Nil(t, err)
+		assert.True(t, called)
+	}
+	called, err := s.authorize("TrackerqueryController", "delete", url.Values{"id": []string{"100000000"}}, owner)
+	assert.IsType(t, errors.NotFoundError{}, err)
+	assert.False(t, called)
+
+	// and its work item types
+	called, err = s.authorize("WorkitemtypeController", "create", url.Values{"project": []string{projectID.String()}}, nonMember)
+	assert.IsType(t, errors.ForbiddenError{}, err)
+	assert.False(t, called)
+	called, err = s.authorize("WorkitemtypeController", "create", url.Values{"project": []string{projectID.String()}}, owner)
+	assert.Nil(t, err)
+	assert.True(t, called)
+
+	// trackers, system types and link types are administered by owners of the system project
+	for _, controller := range []string{"TrackerController", "WorkitemtypeController", "WorkItemLinkTypeController", "WorkItemLinkCategoryController"} {
+		called, err := s.authorize(controller, "create", url.Values{}, owner)
+		assert.IsType(t, errors.ForbiddenError{}, err)
+		assert.False(t, called)
+	}
+}
+
+func (s *TestAuthorizer) TestSystemProjectAndUnprotectedActions() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	// every identity contributes to the system project
+	called, err := s.authorize("WorkitemController", "create", url.Values{}, uuid.NewV4())
+	assert.Nil(t, err)
+	assert.True(t, called)
+
+	// actions without a rule are not checked
+	called, err = s.authorize("login", "logout", url.Values{}, uuid.NewV4())
+	assert.Nil(t, err)
+	assert.True(t, called)
+
+	// unknown projects result in a not found error
+	called, err = s.authorize("ProjectWorkItemsController", "create", url.Values{"id": []string{uuid.NewV4().String()}}, uuid.NewV4())
+	assert.IsType(t, errors.NotFoundError{}, err)
+	assert.False(t, called)
+
+	// only owners of the system project administer the local login
+	called, err = s.authorize("login", "createPasswordReset", url.Values{}, uuid.NewV4())
+	assert.IsType(t, errors.ForbiddenError{}, err)
+	assert.False(t, called)
+	admin := account.Identity{FullName: "Test Admin"}
+	require.Nil(t, account.NewIdentityRepository(s.DB).Create(context.Background(), &admin))
+	err = application.Transactional(s.db, func(appl application.Application) error {
+		_, err := appl.ProjectMemberships().Create(context.Background(), project.SystemProject, admin.ID, project.RoleOwner)
+		return err
+	})
+	require.Nil(t, err)
+	called, err = s.authorize("login", "createPasswordReset", url.Values{}, admin.ID)
+	assert.Nil(t, err)
+	assert.True(t, called)
+}
+
+func (s *TestAuthorizer) TestAccessTokens() {
+	t := s.T()
+	resource.Require(t, resource.Database)
+
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+	tokenManager := almtoken.NewManager(pub, priv)
+	bot := account.Identity{FullName: "Test Bot", ServiceAccount: true}
+	require.Nil(t, account.NewIdentityRepository(s.DB).Create(context.Background(), &bot))
+	projectID := s.createProjectWithMember(bot.ID, project.RoleOwner)
+	params := url.Values{"id": []string{projectID.String()}}
+
+	accessToken := account.AccessToken{IdentityID: bot.ID, Name: "ci", Scopes: Permissions.CreateWorkItem}
+	require.Nil(t, account.NewAccessTokenRepository(s.DB).Create(context.Background(), &accessToken))
+	tokenString, err := tokenManager.GenerateAccessToken(bot, accessToken)
+	require.Nil(t, err)
+
+	called, err := s.authorizeToken("ProjectWorkItemsController", "create", params, tokenString)
+	assert.Nil(t, err)
+	assert.True(t, called)
+	loaded, err := account.NewAccessTokenRepository(s.DB).Load(context.Background(), accessToken.ID)
+	require.Nil(t, err)
+	assert.NotNil(t, loaded.LastUsedAt)
+
+	// the role would allow it, the token scopes do not
+	called, err = s.authorizeToken("ProjectMembershipsController", "create", params, tokenString)
+	assert.IsType(t, errors.ForbiddenError{}, err)
+	assert.False(t, called)
+	called, err = s.authorizeToken("AccessTokenController", "create", url.Values{}, tokenString)
+	assert.IsType(t, errors.ForbiddenError{}, err)
+	assert.False(t, called)
+
+	// actions without a rule are denied to tokens limited to scopes
+	called, err = s.authorizeToken("login", "logout", url.Values{}, tokenString)
+	assert.IsType(t, errors.ForbiddenError{}, err)
+	assert.False(t, called)
+
+	// and only need an active token without scopes
+	unlimited := account.AccessToken{IdentityID: bot.ID, Name: "unlimited"}
+	require.Nil(t, account.NewAccessTokenRepository(s.DB).Create(context.Background(), &unlimited))
+	unlimitedString, err := tokenManager.GenerateAccessToken(bot, unlimited)
+	require.Nil(t, err)
+	called, err = s.authorizeToken("login", "logout", url.Values{}, unlimitedString)
+	assert.Nil(t, err)
+	assert.True(t, called)
+
+	require.Nil(t, account.NewAccessTokenRepository(s.DB).Revoke(context.Background(), accessToken.ID))
+	called, err = s.authorizeToken("login", "logout", url.Values{}, tokenString)
+	assert.IsType(t, errors.UnauthorizedError{}, err)
+	assert.False(t, called)
+}
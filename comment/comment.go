@@ -5,25 +5,40 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/goadesign/goa"
 	"github.com/jinzhu/gorm"
 	uuid "github.com/satori/go.uuid"
 )
 
+// Parent types a comment can be attached to. The values match the JSONAPI
+// resource types of the respective endpoints.
+const (
+	ParentTypeWorkItem     = "workitems"
+	ParentTypeWorkItemLink = "workitemlinks"
+	ParentTypeWorkItemType = "workitemtypes"
+	ParentTypeProject      = "projects"
+	ParentTypeIteration    = "iterations"
+	ParentTypeArea         = "areas"
+)
+
 // Comment describes a single comment
 type Comment struct {
 	gormsupport.Lifecycle
-	ID        uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
-	ParentID  string
-	CreatedBy uuid.UUID `sql:"type:uuid"` // Belongs To Identity
-	Body      string
+	ID         uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	ParentType string    // One of the ParentType* constants
+	ParentID   string
+	CreatedBy  uuid.UUID `sql:"type:uuid"` // Belongs To Identity
+	Body       string
 }
 
 // Repository describes interactions with comments
 type Repository interface {
 	Create(ctx context.Context, u *Comment) error
-	List(ctx context.Context, parent string) ([]*Comment, error)
+	Load(ctx context.Context, id uuid.UUID) (*Comment, error)
+	Save(ctx context.Context, u *Comment) error
+	List(ctx context.Context, parentType string, parentID string) ([]*Comment, error)
 }
 
 // NewCommentRepository creates a new storage type.
@@ -46,6 +61,9 @@ func (m *GormCommentRepository) TableName() string {
 func (m *GormCommentRepository) Create(ctx context.Context, u *Comment) error {
 	defer goa.MeasureSince([]string{"goa", "db", "comment", "create"}, time.Now())
 
+	if u.ParentType == "" {
+		return errors.NewBadParameterError("parentType", u.ParentType)
+	}
 	u.ID = uuid.NewV4()
 
 	err := m.db.Create(u).Error
@@ -57,12 +75,46 @@ func (m *GormCommentRepository) Create(ctx context.Context, u *Comment) error {
 	return nil
 }
 
-// List all comments related to a single item
-func (m *GormCommentRepository) List(ctx context.Context, parent string) ([]*Comment, error) {
+// Load returns the comment for the given id
+func (m *GormCommentRepository) Load(ctx context.Context, id uuid.UUID) (*Comment, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "comment", "load"}, time.Now())
+	var obj Comment
+
+	tx := m.db.Table(m.TableName()).Where("id = ?", id).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("comment", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// Save updates the body of the given comment, the other attributes of a
+// comment do not change once created
+// returns NotFoundError or InternalError
+func (m *GormCommentRepository) Save(ctx context.Context, u *Comment) error {
+	defer goa.MeasureSince([]string{"goa", "db", "comment", "save"}, time.Now())
+
+	stored, err := m.Load(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	stored.Body = u.Body
+	if err := m.db.Save(stored).Error; err != nil {
+		goa.LogError(ctx, "error updating Comment", "error", err.Error())
+		return errors.NewInternalError(err.Error())
+	}
+	*u = *stored
+	return nil
+}
+
+// List all comments related to a single item identified by its type and id
+func (m *GormCommentRepository) List(ctx context.Context, parentType string, parentID string) ([]*Comment, error) {
 	defer goa.MeasureSince([]string{"goa", "db", "comment", "query"}, time.Now())
 	var objs []*Comment
 
-	err := m.db.Table(m.TableName()).Where("parent_id = ?", parent).Order("created_at").Find(&objs).Error
+	err := m.db.Table(m.TableName()).Where("parent_type = ? AND parent_id = ?", parentType, parentID).Order("created_at").Find(&objs).Error
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return nil, err
 	}
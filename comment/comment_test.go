@@ -7,9 +7,12 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/resource"
 	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -38,9 +41,10 @@ func (test *TestCommentRepository) TestCreateComment() {
 	repo := comment.NewCommentRepository(test.DB)
 
 	c := &comment.Comment{
-		ParentID:  "A",
-		Body:      "Test A",
-		CreatedBy: uuid.NewV4(),
+		ParentType: comment.ParentTypeWorkItem,
+		ParentID:   "A",
+		Body:       "Test A",
+		CreatedBy:  uuid.NewV4(),
 	}
 
 	repo.Create(context.Background(), c)
@@ -53,6 +57,33 @@ func (test *TestCommentRepository) TestCreateComment() {
 	}
 }
 
+func (test *TestCommentRepository) TestSaveComment() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := comment.NewCommentRepository(test.DB)
+	c := &comment.Comment{
+		ParentType: comment.ParentTypeWorkItem,
+		ParentID:   "A",
+		Body:       "Test A",
+		CreatedBy:  uuid.NewV4(),
+	}
+	require.Nil(t, repo.Create(context.Background(), c))
+
+	edited := &comment.Comment{ID: c.ID, Body: "Test A, edited"}
+	require.Nil(t, repo.Save(context.Background(), edited))
+	assert.Equal(t, "Test A, edited", edited.Body)
+	assert.Equal(t, c.CreatedBy, edited.CreatedBy)
+	assert.False(t, edited.UpdatedAt.Before(c.UpdatedAt))
+
+	loaded, err := repo.Load(context.Background(), c.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "Test A, edited", loaded.Body)
+
+	err = repo.Save(context.Background(), &comment.Comment{ID: uuid.NewV4(), Body: "unknown"})
+	assert.IsType(t, errors.NotFoundError{}, err)
+}
+
 func (test *TestCommentRepository) TestListComments() {
 	t := test.T()
 	resource.Require(t, resource.Database)
@@ -64,14 +95,22 @@ func (test *TestCommentRepository) TestListComments() {
 
 	cs := []*comment.Comment{
 		&comment.Comment{
-			ParentID:  parentID,
-			Body:      body,
-			CreatedBy: uuid.NewV4(),
+			ParentType: comment.ParentTypeWorkItem,
+			ParentID:   parentID,
+			Body:       body,
+			CreatedBy:  uuid.NewV4(),
+		},
+		&comment.Comment{
+			ParentType: comment.ParentTypeWorkItem,
+			ParentID:   "B",
+			Body:       "Test B",
+			CreatedBy:  uuid.NewV4(),
 		},
 		&comment.Comment{
-			ParentID:  "B",
-			Body:      "Test B",
-			CreatedBy: uuid.NewV4(),
+			ParentType: comment.ParentTypeProject,
+			ParentID:   parentID,
+			Body:       "Test C",
+			CreatedBy:  uuid.NewV4(),
 		},
 	}
 
@@ -79,13 +118,13 @@ func (test *TestCommentRepository) TestListComments() {
 		repo.Create(context.Background(), c)
 	}
 
-	cl, err := repo.List(context.Background(), parentID)
+	cl, err := repo.List(context.Background(), comment.ParentTypeWorkItem, parentID)
 	if err != nil {
 		t.Error("Failed to List", err.Error())
 	}
 
 	if len(cl) != 1 {
-		t.Error("List returned more then expected based on parentID")
+		t.Error("List returned more then expected based on parentType and parentID")
 	}
 
 	c := cl[0]
@@ -93,3 +132,49 @@ func (test *TestCommentRepository) TestListComments() {
 		t.Error("List returned unexpected comment")
 	}
 }
+
+func (test *TestCommentRepository) TestCreateCommentWithoutParentType() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := comment.NewCommentRepository(test.DB)
+
+	c := &comment.Comment{
+		ParentID:  "A",
+		Body:      "Test A",
+		CreatedBy: uuid.NewV4(),
+	}
+
+	err := repo.Create(context.Background(), c)
+	if err == nil {
+		t.Error("Comment without parent type should not be created")
+	}
+}
+
+func (test *TestCommentRepository) TestLoadComment() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	repo := comment.NewCommentRepository(test.DB)
+
+	c := &comment.Comment{
+		ParentType: comment.ParentTypeWorkItemType,
+		ParentID:   "system.bug",
+		Body:       "Test A",
+		CreatedBy:  uuid.NewV4(),
+	}
+	repo.Create(context.Background(), c)
+
+	loaded, err := repo.Load(context.Background(), c.ID)
+	if err != nil {
+		t.Error("Failed to Load", err.Error())
+	}
+	if loaded.ParentType != comment.ParentTypeWorkItemType || loaded.ParentID != "system.bug" {
+		t.Error("Load returned comment with unexpected parent")
+	}
+
+	_, err = repo.Load(context.Background(), uuid.NewV4())
+	if err == nil {
+		t.Error("Load of unknown comment should fail")
+	}
+}
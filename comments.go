@@ -0,0 +1,170 @@
+package main
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// commentParentLoaders maps every commentable parent type to a function that
+// returns the ID of the project the parent with the given ID belongs to, or an
+// error if no item of that type exists for the given ID.
+var commentParentLoaders = map[string]func(ctx context.Context, appl application.Application, id string) (uuid.UUID, error){
+	comment.ParentTypeWorkItem: func(ctx context.Context, appl application.Application, id string) (uuid.UUID, error) {
+		return projectOfWorkItem(ctx, appl, id)
+	},
+	comment.ParentTypeWorkItemLink: func(ctx context.Context, appl application.Application, id string) (uuid.UUID, error) {
+		return projectOfWorkItemLink(ctx, appl, id)
+	},
+	comment.ParentTypeWorkItemType: func(ctx context.Context, appl application.Application, id string) (uuid.UUID, error) {
+		wit, err := appl.WorkItemTypes().Load(ctx, id)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return *wit.Project, nil
+	},
+	comment.ParentTypeProject: func(ctx context.Context, appl application.Application, id string) (uuid.UUID, error) {
+		p, err := loadProject(ctx, appl, id)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return p.ID, nil
+	},
+	comment.ParentTypeIteration: func(ctx context.Context, appl application.Application, id string) (uuid.UUID, error) {
+		i, err := loadIteration(ctx, appl, id)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return i.ProjectID, nil
+	},
+	comment.ParentTypeArea: func(ctx context.Context, appl application.Application, id string) (uuid.UUID, error) {
+		a, err := loadArea(ctx, appl, id)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return a.ProjectID, nil
+	},
+}
+
+// checkCommentParent returns the ID of the project the parent belongs to. It
+// returns an error if the given parent type is not commentable or if the
+// parent item does not exist.
+func checkCommentParent(ctx context.Context, appl application.Application, parentType, parentID string) (uuid.UUID, error) {
+	loader, ok := commentParentLoaders[parentType]
+	if !ok {
+		return uuid.Nil, errors.NewBadParameterError("parentType", parentType)
+	}
+	return loader(ctx, appl, parentID)
+}
+
+// CommentsController implements the comments resource.
+type CommentsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewCommentsController creates a comments controller.
+func NewCommentsController(service *goa.Service, db application.DB) *CommentsController {
+	if db == nil {
+		panic("db must not be nil")
+	}
+	return &CommentsController{
+		Controller: service.NewController("CommentsController"),
+		db:         db,
+	}
+}
+
+// Show runs the show action.
+func (c *CommentsController) Show(ctx *app.ShowCommentsContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		cmt, err := appl.WorkItemComments().Load(ctx, ctx.CommentID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(&app.CommentSingle{
+			Data: toAPI(cmt),
+		})
+	})
+}
+
+// List runs the list action.
+func (c *CommentsController) List(ctx *app.ListCommentsContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		_, err := checkCommentParent(ctx, appl, ctx.FilterParentType, ctx.FilterParentID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+
+		comments, err := appl.WorkItemComments().List(ctx, ctx.FilterParentType, ctx.FilterParentID)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.InternalServerError(jerrors)
+		}
+		res := &app.CommentArray{}
+		res.Data = []*app.Comment{}
+		for _, cmt := range comments {
+			res.Data = append(res.Data, toAPI(cmt))
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Create runs the create action.
+func (c *CommentsController) Create(ctx *app.CreateCommentsContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+	currentUserID, err := uuid.FromString(currentUser)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+
+	reqComment := ctx.Payload.Data
+	if reqComment.Relationships == nil || reqComment.Relationships.Parent == nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("data.relationships.parent", nil))
+		return ctx.BadRequest(jerrors)
+	}
+	parent := reqComment.Relationships.Parent.Data
+
+	return application.Transactional(c.db, func(appl application.Application) error {
+		_, err := checkCommentParent(ctx, appl, parent.Type, parent.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+
+		newComment := comment.Comment{
+			ParentType: parent.Type,
+			ParentID:   parent.ID,
+			Body:       reqComment.Attributes.Body,
+			CreatedBy:  currentUserID,
+		}
+		err = appl.WorkItemComments().Create(ctx, &newComment)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.InternalServerError(jerrors)
+		}
+		_, err = appl.Teams().RecordMentions(ctx, &newComment)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.InternalServerError(jerrors)
+		}
+
+		ctx.ResponseData.Header().Set("Location", app.CommentsHref(newComment.ID))
+		return ctx.Created(&app.CommentSingle{
+			Data: toAPI(&newComment),
+		})
+	})
+}
@@ -0,0 +1,197 @@
+package main_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/area"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestCommentsREST struct {
+	gormsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	clean func()
+}
+
+func TestRunCommentsREST(t *testing.T) {
+	suite.Run(t, &TestCommentsREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestCommentsREST) SetupTest() {
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = gormsupport.DeleteCreatedEntities(rest.DB)
+}
+
+func (rest *TestCommentsREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestCommentsREST) SecuredController() (*goa.Service, *CommentsController) {
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+
+	svc := testsupport.ServiceAsUser("Comments-Service", almtoken.NewManager(pub, priv), account.TestIdentity)
+	return svc, NewCommentsController(svc, rest.db)
+}
+
+func (rest *TestCommentsREST) UnSecuredController() (*goa.Service, *CommentsController) {
+	svc := goa.New("Comments-Service")
+	return svc, NewCommentsController(svc, rest.db)
+}
+
+func (rest *TestCommentsREST) createProject() string {
+	var projectID uuid.UUID
+	err := application.Transactional(rest.db, func(appl application.Application) error {
+		p, err := appl.Projects().Create(context.Background(), "comments-test-"+uuid.NewV4().String())
+		if err != nil {
+			return err
+		}
+		projectID = p.ID
+		return nil
+	})
+	require.Nil(rest.T(), err)
+	return projectID.String()
+}
+
+func (rest *TestCommentsREST) TestCreateCommentOnWorkItem() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	wiid, err := createWorkItem(rest.db)
+	require.Nil(t, err)
+
+	svc, ctrl := rest.SecuredController()
+	_, c := test.CreateCommentsCreated(t, svc.Context, svc, ctrl, createParentComment("Test", comment.ParentTypeWorkItem, wiid))
+	assertComment(t, c.Data)
+	assert.Equal(t, wiid, c.Data.Relationships.Parent.Data.ID)
+
+	_, shown := test.ShowCommentsOK(t, svc.Context, svc, ctrl, *c.Data.ID)
+	assertComment(t, shown.Data)
+}
+
+func (rest *TestCommentsREST) TestCreateAndListCommentsOnProject() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	projectID := rest.createProject()
+
+	svc, ctrl := rest.SecuredController()
+	_, c := test.CreateCommentsCreated(t, svc.Context, svc, ctrl, createParentComment("Test", comment.ParentTypeProject, projectID))
+	assert.Equal(t, comment.ParentTypeProject, c.Data.Relationships.Parent.Data.Type)
+	assert.Equal(t, projectID, c.Data.Relationships.Parent.Data.ID)
+
+	_, cs := test.ListCommentsOK(t, svc.Context, svc, ctrl, projectID, comment.ParentTypeProject)
+	require.Len(t, cs.Data, 1)
+	assert.Equal(t, *c.Data.ID, *cs.Data[0].ID)
+}
+
+func (rest *TestCommentsREST) TestCreateAndListCommentsOnArea() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	projectID, err := uuid.FromString(rest.createProject())
+	require.Nil(t, err)
+	a := area.Area{ProjectID: projectID, Name: "comments-test"}
+	err = application.Transactional(rest.db, func(appl application.Application) error {
+		return appl.Areas().Create(context.Background(), &a, nil)
+	})
+	require.Nil(t, err)
+
+	svc, ctrl := rest.SecuredController()
+	_, c := test.CreateCommentsCreated(t, svc.Context, svc, ctrl, createParentComment("Test", comment.ParentTypeArea, a.ID.String()))
+	assert.Equal(t, comment.ParentTypeArea, c.Data.Relationships.Parent.Data.Type)
+	assert.Equal(t, a.ID.String(), c.Data.Relationships.Parent.Data.ID)
+
+	_, cs := test.ListCommentsOK(t, svc.Context, svc, ctrl, a.ID.String(), comment.ParentTypeArea)
+	require.Len(t, cs.Data, 1)
+	assert.Equal(t, *c.Data.ID, *cs.Data[0].ID)
+}
+
+func (rest *TestCommentsREST) TestCreateCommentMissingParent() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc, ctrl := rest.SecuredController()
+	test.CreateCommentsNotFound(t, svc.Context, svc, ctrl, createParentComment("Test", comment.ParentTypeWorkItem, "0000000"))
+	test.CreateCommentsNotFound(t, svc.Context, svc, ctrl, createParentComment("Test", comment.ParentTypeProject, uuid.NewV4().String()))
+}
+
+func (rest *TestCommentsREST) TestCreateCommentWithoutParent() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	p := &app.CreateCommentsPayload{
+		Data: &app.CreateComment{
+			Type: "comments",
+			Attributes: &app.CreateCommentAttributes{
+				Body: "Test",
+			},
+		},
+	}
+	svc, ctrl := rest.SecuredController()
+	test.CreateCommentsBadRequest(t, svc.Context, svc, ctrl, p)
+}
+
+func (rest *TestCommentsREST) TestCreateCommentNotAuthorized() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	wiid, err := createWorkItem(rest.db)
+	require.Nil(t, err)
+
+	svc, ctrl := rest.UnSecuredController()
+	test.CreateCommentsUnauthorized(t, svc.Context, svc, ctrl, createParentComment("Test", comment.ParentTypeWorkItem, wiid))
+}
+
+func (rest *TestCommentsREST) TestListCommentsMissingParent() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc, ctrl := rest.UnSecuredController()
+	test.ListCommentsNotFound(t, svc.Context, svc, ctrl, "0000000", comment.ParentTypeWorkItem)
+}
+
+func (rest *TestCommentsREST) TestShowMissingComment() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc, ctrl := rest.UnSecuredController()
+	test.ShowCommentsNotFound(t, svc.Context, svc, ctrl, uuid.NewV4())
+}
+
+func createParentComment(body, parentType, parentID string) *app.CreateCommentsPayload {
+	return &app.CreateCommentsPayload{
+		Data: &app.CreateComment{
+			Type: "comments",
+			Attributes: &app.CreateCommentAttributes{
+				Body: body,
+			},
+			Relationships: &app.CreateCommentRelations{
+				Parent: &app.CommentParent{
+					Data: &app.CommentParentData{
+						Type: parentType,
+						ID:   parentID,
+					},
+				},
+			},
+		},
+	}
+}
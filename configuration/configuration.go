@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode"
 
 	yaml "gopkg.in/yaml.v2"
 
@@ -73,8 +74,28 @@ const (
 	varGithubSecret                 = "github.secret"
 	varGithubClientID               = "github.client.id"
 	varGithubAuthToken              = "github.auth.token"
+	varGithubAuthHosts              = "github.auth.hosts"
+	varJiraAuthUsername             = "jira.auth.username"
+	varJiraAuthPassword             = "jira.auth.password"
+	varJiraAuthHosts                = "jira.auth.hosts"
+	varGitlabAuthToken              = "gitlab.auth.token"
+	varGitlabAuthHosts              = "gitlab.auth.hosts"
+	varBugzillaAuthAPIKey           = "bugzilla.auth.apikey"
+	varBugzillaAuthHosts            = "bugzilla.auth.hosts"
+	varLoginDefaultProvider         = "login.default.provider"
+	varLoginOIDCProviders           = "login.oidc.providers"
+	varLoginRedirectAllowed         = "login.redirect.allowed"
+	varLoginStateLifetime           = "login.state.lifetime"
+	varLoginLocalEnabled            = "login.local.enabled"
+	varLoginLocalLockoutAttempts    = "login.local.lockout.attempts"
+	varLoginLocalLockoutDuration    = "login.local.lockout.duration"
+	varLoginLocalResetLifetime      = "login.local.reset.lifetime"
 	varTokenPublicKey               = "token.publickey"
 	varTokenPrivateKey              = "token.privatekey"
+	varTokenRetiredPublicKeys       = "token.retired.publickeys"
+	varTokenAudience                = "token.audience"
+	varTokenLifetime                = "token.lifetime"
+	varTokenRefreshLifetime         = "token.refresh.lifetime"
 )
 
 func setConfigDefaults() {
@@ -110,9 +131,25 @@ func setConfigDefaults() {
 	// Auth-related defaults
 	viper.SetDefault(varTokenPublicKey, defaultTokenPublicKey)
 	viper.SetDefault(varTokenPrivateKey, defaultTokenPrivateKey)
+	viper.SetDefault(varTokenRetiredPublicKeys, "")
+	viper.SetDefault(varTokenAudience, "almighty-core")
+	viper.SetDefault(varTokenLifetime, time.Duration(time.Hour))
+	viper.SetDefault(varTokenRefreshLifetime, time.Duration(30*24*time.Hour))
 	viper.SetDefault(varGithubClientID, defaultGithubClientID)
 	viper.SetDefault(varGithubSecret, defaultGithubSecret)
 	viper.SetDefault(varGithubAuthToken, defaultActualToken)
+	viper.SetDefault(varGithubAuthHosts, "api.github.com")
+	viper.SetDefault(varJiraAuthHosts, "")
+	viper.SetDefault(varGitlabAuthHosts, "")
+	viper.SetDefault(varBugzillaAuthHosts, "")
+	viper.SetDefault(varLoginDefaultProvider, "github")
+	viper.SetDefault(varLoginOIDCProviders, "")
+	viper.SetDefault(varLoginRedirectAllowed, "")
+	viper.SetDefault(varLoginStateLifetime, time.Duration(10*time.Minute))
+	viper.SetDefault(varLoginLocalEnabled, false)
+	viper.SetDefault(varLoginLocalLockoutAttempts, 5)
+	viper.SetDefault(varLoginLocalLockoutDuration, time.Duration(15*time.Minute))
+	viper.SetDefault(varLoginLocalResetLifetime, time.Duration(24*time.Hour))
 }
 
 // GetPostgresHost returns the postgres host as set via default, config file, or environment variable
@@ -199,6 +236,36 @@ func GetTokenPublicKey() []byte {
 	return []byte(viper.GetString(varTokenPublicKey))
 }
 
+// GetTokenRetiredPublicKeys returns the concatenated public keys (as set via config file or environment variable)
+// of former token signing keys. Tokens signed with them are accepted until they expire.
+func GetTokenRetiredPublicKeys() []byte {
+	return []byte(viper.GetString(varTokenRetiredPublicKeys))
+}
+
+// GetDefaultTokenPublicKey returns the public key of the signing key that is compiled into
+// the binary. Since its private key is known to everybody, it must only be used in developer mode.
+func GetDefaultTokenPublicKey() []byte {
+	return []byte(defaultTokenPublicKey)
+}
+
+// GetTokenAudience returns the audience (as set via default, config file, or environment variable)
+// that the authentication tokens are issued for. Tokens for other audiences are rejected.
+func GetTokenAudience() string {
+	return viper.GetString(varTokenAudience)
+}
+
+// GetTokenLifetime returns the duration (as set via default, config file, or environment variable)
+// for which a login token is valid.
+func GetTokenLifetime() time.Duration {
+	return viper.GetDuration(varTokenLifetime)
+}
+
+// GetTokenRefreshLifetime returns the duration (as set via default, config file, or environment variable)
+// for which a refresh token is valid.
+func GetTokenRefreshLifetime() time.Duration {
+	return viper.GetDuration(varTokenRefreshLifetime)
+}
+
 // GetGithubSecret returns the Github secret(as set via config file or environment variable)
 // that is used to make authorized Github API Calls.
 func GetGithubSecret() string {
@@ -216,6 +283,142 @@ func GetGithubAuthToken() string {
 	return viper.GetString(varGithubAuthToken)
 }
 
+// GetGithubAuthHosts returns the hosts (as set via default, config file, or environment variable)
+// the Github token is sent to, separated by spaces or commas. Requests to other hosts go out without it.
+func GetGithubAuthHosts() []string {
+	return strings.FieldsFunc(viper.GetString(varGithubAuthHosts), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// GetJiraAuthUsername returns the user name used to push changes to Jira
+func GetJiraAuthUsername() string {
+	return viper.GetString(varJiraAuthUsername)
+}
+
+// GetJiraAuthPassword returns the password used to push changes to Jira
+func GetJiraAuthPassword() string {
+	return viper.GetString(varJiraAuthPassword)
+}
+
+// GetJiraAuthHosts returns the hosts (as set via config file or environment variable) the Jira
+// credentials are sent to, separated by spaces or commas. Requests to other hosts go out without them.
+func GetJiraAuthHosts() []string {
+	return strings.FieldsFunc(viper.GetString(varJiraAuthHosts), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// GetGitlabAuthToken returns the private token used to fetch GitLab issues
+func GetGitlabAuthToken() string {
+	return viper.GetString(varGitlabAuthToken)
+}
+
+// GetGitlabAuthHosts returns the hosts (as set via config file or environment variable) the GitLab
+// token is sent to, separated by spaces or commas. Requests to other hosts go out without it.
+func GetGitlabAuthHosts() []string {
+	return strings.FieldsFunc(viper.GetString(varGitlabAuthHosts), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// GetBugzillaAuthAPIKey returns the API key used to fetch Bugzilla bugs
+func GetBugzillaAuthAPIKey() string {
+	return viper.GetString(varBugzillaAuthAPIKey)
+}
+
+// GetBugzillaAuthHosts returns the hosts (as set via config file or environment variable) the Bugzilla
+// API key is sent to, separated by spaces or commas. Requests to other hosts go out without it.
+func GetBugzillaAuthHosts() []string {
+	return strings.FieldsFunc(viper.GetString(varBugzillaAuthHosts), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// GetLoginDefaultProvider returns the name of the login provider (as set via default, config file, or environment variable)
+// that is used when the login request does not select one.
+func GetLoginDefaultProvider() string {
+	return viper.GetString(varLoginDefaultProvider)
+}
+
+// GetLoginAllowedRedirects returns the URLs (as set via config file or environment variable) the login may
+// redirect to with the token, separated by spaces or commas. None are configured by default.
+func GetLoginAllowedRedirects() []string {
+	return strings.FieldsFunc(viper.GetString(varLoginRedirectAllowed), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// GetLoginStateLifetime returns how long (as set via default, config file, or environment variable)
+// a user has to complete the login with the provider.
+func GetLoginStateLifetime() time.Duration {
+	return viper.GetDuration(varLoginStateLifetime)
+}
+
+// IsLoginLocalEnabled returns if the login with email and password (as set via default, config file, or
+// environment variable) is available as provider "local".
+func IsLoginLocalEnabled() bool {
+	return viper.GetBool(varLoginLocalEnabled)
+}
+
+// GetLoginLocalLockoutAttempts returns after how many failed local logins in a row (as set via default,
+// config file, or environment variable) the email gets locked. 0 disables the lockout.
+func GetLoginLocalLockoutAttempts() int {
+	return viper.GetInt(varLoginLocalLockoutAttempts)
+}
+
+// GetLoginLocalLockoutDuration returns how long (as set via default, config file, or environment variable)
+// an email stays locked after too many failed local logins.
+func GetLoginLocalLockoutDuration() time.Duration {
+	return viper.GetDuration(varLoginLocalLockoutDuration)
+}
+
+// GetLoginLocalResetLifetime returns how long (as set via default, config file, or environment variable)
+// a password reset code is valid.
+func GetLoginLocalResetLifetime() time.Duration {
+	return viper.GetDuration(varLoginLocalResetLifetime)
+}
+
+// OIDCProvider holds the settings of an OpenID Connect login provider
+type OIDCProvider struct {
+	Name          string
+	Issuer        string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string
+	Scopes        []string
+	NameClaim     string
+	EmailClaim    string
+	ImageURLClaim string
+	TrustEmail    bool
+}
+
+// GetOIDCProviders returns the OpenID Connect login providers (as set via config file or environment variable).
+// The names of the providers are listed in login.oidc.providers, separated by spaces or commas. The settings
+// of a provider named "keycloak" are read from login.oidc.keycloak.issuer, login.oidc.keycloak.client.id, etc.
+func GetOIDCProviders() []OIDCProvider {
+	var res []OIDCProvider
+	names := strings.FieldsFunc(viper.GetString(varLoginOIDCProviders), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	for _, name := range names {
+		prefix := "login.oidc." + name + "."
+		res = append(res, OIDCProvider{
+			Name:          name,
+			Issuer:        viper.GetString(prefix + "issuer"),
+			ClientID:      viper.GetString(prefix + "client.id"),
+			ClientSecret:  viper.GetString(prefix + "client.secret"),
+			RedirectURL:   viper.GetString(prefix + "redirect.url"),
+			Scopes:        strings.Fields(viper.GetString(prefix + "scopes")),
+			NameClaim:     viper.GetString(prefix + "claims.name"),
+			EmailClaim:    viper.GetString(prefix + "claims.email"),
+			ImageURLClaim: viper.GetString(prefix + "claims.imageurl"),
+			TrustEmail:    viper.GetBool(prefix + "trust.email"),
+		})
+	}
+	return res
+}
+
 // Auth-related defaults
 
 // RSAPrivateKey for signing JWT Tokens
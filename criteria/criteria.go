@@ -43,6 +43,7 @@ type ExpressionVisitor interface {
 	And(a *AndExpression) interface{}
 	Or(a *OrExpression) interface{}
 	Equals(e *EqualsExpression) interface{}
+	MemberOf(m *MemberOfExpression) interface{}
 	Parameter(v *ParameterExpression) interface{}
 	Literal(c *LiteralExpression) interface{}
 }
@@ -200,3 +201,21 @@ func (t *EqualsExpression) Accept(visitor ExpressionVisitor) interface{} {
 func Equals(left Expression, right Expression) Expression {
 	return reparent(&EqualsExpression{binaryExpression{expression{}, left, right}})
 }
+
+// member of
+
+// MemberOfExpression tests whether the identity on the left is a member of
+// the team on the right or of one of its sub-teams
+type MemberOfExpression struct {
+	binaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *MemberOfExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.MemberOf(t)
+}
+
+// MemberOf constructs a MemberOfExpression
+func MemberOf(left Expression, right Expression) Expression {
+	return reparent(&MemberOfExpression{binaryExpression{expression{}, left, right}})
+}
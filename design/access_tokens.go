@@ -0,0 +1,327 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+//#############################################################################
+//
+// 			access token
+//
+//#############################################################################
+
+// CreateAccessTokenPayload defines the structure of access token payload in JSONAPI format during creation
+var CreateAccessTokenPayload = a.Type("CreateAccessTokenPayload", func() {
+	a.Attribute("data", AccessTokenData)
+	a.Required("data")
+})
+
+// AccessTokenData is the JSONAPI store for the data of an access token.
+var AccessTokenData = a.Type("AccessTokenData", func() {
+	a.Description(`JSONAPI store for the data of an access token.
+See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("access-tokens")
+	})
+	a.Attribute("id", d.UUID, "ID of the access token (ignored during creation)", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", AccessTokenAttributes)
+	a.Attribute("links", GenericLinks)
+	a.Required("type", "attributes")
+})
+
+// AccessTokenAttributes is the JSONAPI store for all the "attributes" of an access token.
+var AccessTokenAttributes = a.Type("AccessTokenAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of an access token.
+See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("name", d.String, "What the access token is used for (required on creation)", func() {
+		a.Example("nightly import bot")
+	})
+	a.Attribute("scopes", a.ArrayOf(d.String, func() {
+		a.Enum("create.workitem", "read.workitem", "update.workitem", "delete.workitem", "manage.project", "manage.account")
+	}), "The permissions the access token may use. The permissions of the identity still apply.")
+	a.Attribute("expires-at", d.DateTime, "When the access token expires. Tokens without expiry are valid until revoked.", func() {
+		a.Example("2017-12-24T00:00:00Z")
+	})
+	a.Attribute("token", d.String, "The token to send in the Authorization header (only returned on creation)")
+	a.Attribute("identity", d.UUID, "ID of the identity the access token authenticates (read-only)")
+	a.Attribute("last-used-at", d.DateTime, "When the access token was used last (read-only)")
+	a.Attribute("revoked-at", d.DateTime, "When the access token got revoked (read-only)")
+	a.Attribute("created-at", d.DateTime, "When the access token was created (read-only)")
+
+	// IMPORTANT: We cannot require any field here because these "attributes" will be used
+	// during the creation as well as in responses.
+	// The controller needs to check for required fields.
+})
+
+// AccessToken is the media type for a single access token
+var AccessToken = a.MediaType("application/vnd.access-token+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("AccessToken")
+	a.Description("A long-lived token an identity can use instead of a login token")
+	a.Attributes(func() {
+		a.Attribute("data", AccessTokenData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+// AccessTokenArray is the media type for a list of access tokens
+var AccessTokenArray = a.MediaType("application/vnd.access-token-array+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("AccessTokenArray")
+	a.Description("Holds the response to an access token list request")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(AccessTokenData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+//#############################################################################
+//
+// 			service account
+//
+//#############################################################################
+
+// CreateServiceAccountPayload defines the structure of service account payload in JSONAPI format during creation
+var CreateServiceAccountPayload = a.Type("CreateServiceAccountPayload", func() {
+	a.Attribute("data", ServiceAccountData)
+	a.Required("data")
+})
+
+// ServiceAccountData is the JSONAPI store for the data of a service account.
+var ServiceAccountData = a.Type("ServiceAccountData", func() {
+	a.Description(`JSONAPI store for the data of a service account.
+See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("service-accounts")
+	})
+	a.Attribute("id", d.UUID, "ID of the identity of the service account (ignored during creation)", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", ServiceAccountAttributes)
+	a.Attribute("links", GenericLinks)
+	a.Required("type", "attributes")
+})
+
+// ServiceAccountAttributes is the JSONAPI store for all the "attributes" of a service account.
+var ServiceAccountAttributes = a.Type("ServiceAccountAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a service account.
+See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("name", d.String, "Name of the service account (required on creation)", func() {
+		a.Example("CI bot")
+	})
+	a.Attribute("owner", d.UUID, "ID of the identity managing the service account (read-only)")
+	a.Attribute("created-at", d.DateTime, "When the service account was created (read-only)")
+})
+
+// ServiceAccount is the media type for a single service account
+var ServiceAccount = a.MediaType("application/vnd.service-account+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("ServiceAccount")
+	a.Description("A service account is the identity of a bot that is managed by a person")
+	a.Attributes(func() {
+		a.Attribute("data", ServiceAccountData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+// ServiceAccountArray is the media type for a list of service accounts
+var ServiceAccountArray = a.MediaType("application/vnd.service-account-array+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("ServiceAccountArray")
+	a.Description("Holds the response to a service account list request")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(ServiceAccountData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var _ = a.Resource("access-token", func() {
+	a.BasePath("/tokens")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the access tokens of the authenticated identity.")
+		a.Response(d.OK, func() {
+			a.Media(AccessTokenArray)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Description("Retrieve the access token with the given id. The token itself is not returned.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(AccessToken)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Create an access token for the authenticated identity.")
+		a.Payload(CreateAccessTokenPayload)
+		a.Response(d.Created, "/tokens/.*", func() {
+			a.Media(AccessToken)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("revoke", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:id"),
+		)
+		a.Description("Revoke the access token with the given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("service-account", func() {
+	a.BasePath("/service-accounts")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the service accounts owned by the authenticated identity.")
+		a.Response(d.OK, func() {
+			a.Media(ServiceAccountArray)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Description("Retrieve the service account with given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(ServiceAccount)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Create a service account owned by the authenticated identity.")
+		a.Payload(CreateServiceAccountPayload)
+		a.Response(d.Created, "/service-accounts/.*", func() {
+			a.Media(ServiceAccount)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:id"),
+		)
+		a.Description("Delete the service account with the given id and revoke all its access tokens.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("service-account-tokens", func() {
+	a.BasePath("/tokens")
+	a.Parent("service-account")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the access tokens of the given service account.")
+		a.Response(d.OK, func() {
+			a.Media(AccessTokenArray)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given service account does not exist.")
+		})
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Create an access token for the given service account.")
+		a.Payload(CreateAccessTokenPayload)
+		a.Response(d.Created, "/tokens/.*", func() {
+			a.Media(AccessToken)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given service account does not exist.")
+		})
+	})
+})
@@ -0,0 +1,236 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+//#############################################################################
+//
+// 			area
+//
+//#############################################################################
+
+// CreateAreaPayload defines the structure of area payload in JSONAPI format during creation
+var CreateAreaPayload = a.Type("CreateAreaPayload", func() {
+	a.Attribute("data", AreaData)
+	a.Required("data")
+})
+
+// UpdateAreaPayload defines the structure of area payload in JSONAPI format during update
+var UpdateAreaPayload = a.Type("UpdateAreaPayload", func() {
+	a.Attribute("data", AreaData)
+	a.Required("data")
+})
+
+// MoveAreaPayload defines the structure of area payload in JSONAPI format when
+// moving an area to another parent
+var MoveAreaPayload = a.Type("MoveAreaPayload", func() {
+	a.Attribute("data", AreaData)
+	a.Required("data")
+})
+
+// AreaData is the JSONAPI store for the data of an area.
+var AreaData = a.Type("AreaData", func() {
+	a.Description(`JSONAPI store for the data of an area.
+See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("areas")
+	})
+	a.Attribute("id", d.UUID, "ID of the area (ignored during creation)", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", AreaAttributes)
+	a.Attribute("links", GenericLinks)
+	a.Required("type", "attributes")
+})
+
+// AreaAttributes is the JSONAPI store for all the "attributes" of an area.
+var AreaAttributes = a.Type("AreaAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of an area.
+See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("name", d.String, "Name of the area (required on creation, optional on update)", func() {
+		a.Example("frontend")
+	})
+	a.Attribute("parent", d.UUID, "ID of the parent area (used on creation and move, root areas have none)", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("path", d.String, "IDs of all ancestors of the area followed by its own ID, each prefixed by '/' (read-only)", func() {
+		a.Example("/40bbdd3d-8b5d-4fd6-ac90-7236b669af04/0e5a6c2a-7d3b-4c56-9a3f-2b5f0c8e1d44")
+	})
+	a.Attribute("project", d.UUID, "ID of the project the area belongs to (read-only)", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("version", d.Integer, "Version for optimistic concurrency control (required on update)", func() {
+		a.Example(0)
+	})
+	a.Attribute("created-at", d.DateTime, "When the area was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("updated-at", d.DateTime, "When the area was updated", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+
+	// IMPORTANT: We cannot require any field here because these "attributes" will be used
+	// during the creation, the update and the move of an area.
+	// The controller needs to check for required fields.
+})
+
+// Area is the media type for a single area
+var Area = a.MediaType("application/vnd.area+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("Area")
+	a.Description("An area classifies work items of a project, areas form a tree")
+	a.Attributes(func() {
+		a.Attribute("data", AreaData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+// AreaArray is the media type for a list of areas
+var AreaArray = a.MediaType("application/vnd.area-array+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("AreaArray")
+	a.Description("Holds the response to an area list request, parents always come before their children")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(AreaData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var _ = a.Resource("area", func() {
+	a.BasePath("/areas")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Description("Retrieve area with given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(Area)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("subtree", func() {
+		a.Routing(
+			a.GET("/:id/subtree"),
+		)
+		a.Description("List the area with the given id and all its descendants.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(AreaArray)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:id"),
+		)
+		a.Description("Rename the area with the given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Payload(UpdateAreaPayload)
+		a.Response(d.OK, func() {
+			a.Media(Area)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("move", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:id/move"),
+		)
+		a.Description("Move the area with the given id and all its descendants below another parent, or to the root if no parent is given.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Payload(MoveAreaPayload)
+		a.Response(d.OK, func() {
+			a.Media(Area)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:id"),
+		)
+		a.Description("Delete the area with the given id. Areas with child areas can not be deleted.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("project-areas", func() {
+	a.BasePath("/areas")
+	a.Parent("project")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the areas of the given project.")
+		a.Response(d.OK, func() {
+			a.Media(AreaArray)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist.")
+		})
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Create an area in the given project.")
+		a.Payload(CreateAreaPayload)
+		a.Response(d.Created, "/areas/.*", func() {
+			a.Media(Area)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist.")
+		})
+	})
+})
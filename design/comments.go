@@ -24,6 +24,7 @@ var createComment = a.Type("CreateComment", func() {
 		a.Enum("comments")
 	})
 	a.Attribute("attributes", createCommentAttributes)
+	a.Attribute("relationships", createCommentRelationships)
 	a.Required("type", "attributes")
 })
 
@@ -48,6 +49,26 @@ var createCommentAttributes = a.Type("CreateCommentAttributes", func() {
 
 var commentRelationships = a.Type("CommentRelations", func() {
 	a.Attribute("created-by", commentCreatedBy, "This defines the created by relation")
+	a.Attribute("parent", commentParent, "This defines the item the comment is attached to")
+})
+
+var createCommentRelationships = a.Type("CreateCommentRelations", func() {
+	a.Attribute("parent", commentParent, "The item the comment is attached to (required when not created through a parent specific endpoint)")
+})
+
+var commentParent = a.Type("CommentParent", func() {
+	a.Attribute("data", commentParentData)
+	a.Required("data")
+})
+
+var commentParentData = a.Type("CommentParentData", func() {
+	a.Attribute("id", d.String, "ID of the item the comment is attached to", func() {
+		a.Example("42")
+	})
+	a.Attribute("type", d.String, "type of the item the comment is attached to", func() {
+		a.Enum("workitems", "workitemlinks", "workitemtypes", "projects", "iterations", "areas")
+	})
+	a.Required("type", "id")
 })
 
 var commentCreatedBy = a.Type("CommentCreatedBy", func() {
@@ -106,6 +127,7 @@ var _ = a.Resource("work-item-comments", func() {
 	a.Parent("workitem")
 
 	a.Action("list", func() {
+		a.Security("jwt")
 		a.Routing(
 			a.GET(""),
 		)
@@ -114,6 +136,7 @@ var _ = a.Resource("work-item-comments", func() {
 			a.Media(commentArray)
 		})
 		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
 		a.Response(d.InternalServerError, JSONAPIErrors)
 		a.Response(d.NotFound, JSONAPIErrors)
 	})
@@ -134,3 +157,63 @@ var _ = a.Resource("work-item-comments", func() {
 		a.Response(d.NotFound, JSONAPIErrors)
 	})
 })
+
+var _ = a.Resource("comments", func() {
+	a.BasePath("/comments")
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:commentID"),
+		)
+		a.Description("Retrieve comment with given id.")
+		a.Params(func() {
+			a.Param("commentID", d.UUID, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(commentSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List comments associated with the given parent item")
+		a.Params(func() {
+			a.Param("filter[parentType]", d.String, "type of the item the comments are attached to", func() {
+				a.Enum("workitems", "workitemlinks", "workitemtypes", "projects", "iterations", "areas")
+			})
+			a.Param("filter[parentID]", d.String, "id of the item the comments are attached to")
+			a.Required("filter[parentType]", "filter[parentID]")
+		})
+		a.Response(d.OK, func() {
+			a.Media(commentArray)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Create a comment attached to the parent item given in the relationships of the payload")
+		a.Payload(createSingleComment)
+		a.Response(d.Created, "/comments/.*", func() {
+			a.Media(commentSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,204 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+//#############################################################################
+//
+// 			iteration
+//
+//#############################################################################
+
+// CreateIterationPayload defines the structure of iteration payload in JSONAPI format during creation
+var CreateIterationPayload = a.Type("CreateIterationPayload", func() {
+	a.Attribute("data", IterationData)
+	a.Required("data")
+})
+
+// UpdateIterationPayload defines the structure of iteration payload in JSONAPI format during update
+var UpdateIterationPayload = a.Type("UpdateIterationPayload", func() {
+	a.Attribute("data", IterationData)
+	a.Required("data")
+})
+
+// IterationData is the JSONAPI store for the data of an iteration.
+var IterationData = a.Type("IterationData", func() {
+	a.Description(`JSONAPI store for the data of an iteration.
+See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("iterations")
+	})
+	a.Attribute("id", d.UUID, "ID of the iteration (ignored during creation)", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", IterationAttributes)
+	a.Attribute("links", GenericLinks)
+	a.Required("type", "attributes")
+})
+
+// IterationAttributes is the JSONAPI store for all the "attributes" of an iteration.
+var IterationAttributes = a.Type("IterationAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of an iteration.
+See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("name", d.String, "Name of the iteration (required on creation, optional on update)", func() {
+		a.Example("Sprint #24")
+	})
+	a.Attribute("start-at", d.DateTime, "When the iteration starts", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("end-at", d.DateTime, "When the iteration ends", func() {
+		a.Example("2016-12-13T23:18:14Z")
+	})
+	a.Attribute("state", d.String, "State of the iteration", func() {
+		a.Enum("new", "start", "close")
+		a.Example("start")
+	})
+	a.Attribute("parent", d.UUID, "ID of the iteration this iteration is nested in", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("project", d.UUID, "ID of the project the iteration belongs to (read-only)", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("version", d.Integer, "Version for optimistic concurrency control (required on update)", func() {
+		a.Example(0)
+	})
+	a.Attribute("created-at", d.DateTime, "When the iteration was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("updated-at", d.DateTime, "When the iteration was updated", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+
+	// IMPORTANT: We cannot require any field here because these "attributes" will be used
+	// during the creation as well as the update of an iteration.
+	// The controller needs to check for required fields.
+})
+
+// Iteration is the media type for a single iteration
+var Iteration = a.MediaType("application/vnd.iteration+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("Iteration")
+	a.Description("An iteration is a time box of a project in which work items are planned")
+	a.Attributes(func() {
+		a.Attribute("data", IterationData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+// IterationArray is the media type for the iterations of a project
+var IterationArray = a.MediaType("application/vnd.iteration-array+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("IterationArray")
+	a.Description("Holds the response to an iteration list request")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(IterationData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var _ = a.Resource("iteration", func() {
+	a.BasePath("/iterations")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Description("Retrieve iteration with given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(Iteration)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:id"),
+		)
+		a.Description("Update the iteration with the given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Payload(UpdateIterationPayload)
+		a.Response(d.OK, func() {
+			a.Media(Iteration)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:id"),
+		)
+		a.Description("Delete the iteration with the given id. Iterations with child iterations can not be deleted.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("project-iterations", func() {
+	a.BasePath("/iterations")
+	a.Parent("project")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the iterations of the given project.")
+		a.Params(func() {
+			a.Param("current", d.Boolean, "Only list the iterations that are current right now")
+		})
+		a.Response(d.OK, func() {
+			a.Media(IterationArray)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist.")
+		})
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Create an iteration in the given project.")
+		a.Payload(CreateIterationPayload)
+		a.Response(d.Created, "/iterations/.*", func() {
+			a.Media(Iteration)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist.")
+		})
+	})
+})
@@ -29,10 +29,43 @@ var AuthToken = a.MediaType("application/vnd.authtoken+json", func() {
 	a.Description("JWT Token")
 	a.Attributes(func() {
 		a.Attribute("token", d.String, "JWT Token")
+		a.Attribute("refresh_token", d.String, "JWT Token that can be used once to get a new pair of tokens")
 		a.Required("token")
 	})
 	a.View("default", func() {
 		a.Attribute("token")
+		a.Attribute("refresh_token")
+	})
+})
+
+// jsonWebKey represents a public key tokens can be verified with
+var jsonWebKey = a.Type("JSONWebKey", func() {
+	a.Description("A public key in JSON Web Key format, see https://tools.ietf.org/html/rfc7517")
+	a.Attribute("kty", d.String, "The key type", func() {
+		a.Enum("RSA")
+	})
+	a.Attribute("alg", d.String, "The algorithm the key is used with", func() {
+		a.Enum("RS256")
+	})
+	a.Attribute("use", d.String, "The intended use of the key", func() {
+		a.Enum("sig")
+	})
+	a.Attribute("kid", d.String, "The ID of the key, as found in the header of the tokens signed with it")
+	a.Attribute("n", d.String, "The base64url encoded modulus")
+	a.Attribute("e", d.String, "The base64url encoded exponent")
+	a.Required("kty", "alg", "use", "kid", "n", "e")
+})
+
+// JSONWebKeySet holds all public keys tokens can be verified with
+var JSONWebKeySet = a.MediaType("application/jwk-set+json", func() {
+	a.TypeName("JSONWebKeySet")
+	a.Description("The public keys tokens can be verified with")
+	a.Attributes(func() {
+		a.Attribute("keys", a.ArrayOf(jsonWebKey))
+		a.Required("keys")
+	})
+	a.View("default", func() {
+		a.Attribute("keys")
 	})
 })
 
@@ -44,6 +77,7 @@ var workItem = a.MediaType("application/vnd.workitem+json", func() {
 	a.Attribute("version", d.Integer, "Version for optimistic concurrency control")
 	a.Attribute("type", d.String, "Name of the type of this work item")
 	a.Attribute("fields", a.HashOf(d.String, d.Any), "The field values, according to the field type")
+	a.Attribute("project", d.UUID, "ID of the project the work item belongs to")
 
 	a.Required("id")
 	a.Required("version")
@@ -55,6 +89,7 @@ var workItem = a.MediaType("application/vnd.workitem+json", func() {
 		a.Attribute("version")
 		a.Attribute("type")
 		a.Attribute("fields")
+		a.Attribute("project")
 	})
 })
 
@@ -144,6 +179,7 @@ var workItemType = a.MediaType("application/vnd.workitemtype+json", func() {
 	a.Attribute("version", d.Integer, "Version for optimistic concurrency control")
 	a.Attribute("name", d.String, "User Readable Name of this item type")
 	a.Attribute("fields", a.HashOf(d.String, fieldDefinition), "Definitions of fields in this work item type")
+	a.Attribute("project", d.UUID, "ID of the project that owns this work item type")
 
 	a.Required("version")
 	a.Required("name")
@@ -153,6 +189,7 @@ var workItemType = a.MediaType("application/vnd.workitemtype+json", func() {
 		a.Attribute("version")
 		a.Attribute("name")
 		a.Attribute("fields")
+		a.Attribute("project")
 	})
 	a.View("link", func() {
 		a.Attribute("name")
@@ -167,6 +204,13 @@ var Tracker = a.MediaType("application/vnd.tracker+json", func() {
 	a.Attribute("id", d.String, "unique id per tracker")
 	a.Attribute("url", d.String, "URL of the tracker")
 	a.Attribute("type", d.String, "Type of the tracker")
+	a.Attribute("push", d.Boolean, "Whether local edits of imported work items are pushed back to the tracker")
+	a.Attribute("fieldOwnership", a.HashOf(d.String, d.String), "Who wins conflicting edits of a work item field: remote, local or last-writer")
+	a.Attribute("fieldMappings", a.ArrayOf(trackerFieldMapping), "Mappings of remote attributes to work item fields, the defaults of the tracker type are used if there are none")
+	a.Attribute("webhook", d.Boolean, "Whether the tracker accepts webhook events")
+	a.Attribute("webhookSecret", d.String, "Secret authenticating webhook events, only ever written")
+	a.Attribute("identityMappings", a.HashOf(d.String, d.String), "Identities the remote users are imported as, keyed by their remote names")
+	a.Attribute("linkTypes", a.HashOf(d.String, d.String), "Work item link types remote issue links are imported as, keyed by their remote names")
 
 	a.Required("id")
 	a.Required("url")
@@ -176,6 +220,41 @@ var Tracker = a.MediaType("application/vnd.tracker+json", func() {
 		a.Attribute("id")
 		a.Attribute("url")
 		a.Attribute("type")
+		a.Attribute("push")
+		a.Attribute("fieldOwnership")
+		a.Attribute("fieldMappings")
+		a.Attribute("webhook")
+		a.Attribute("identityMappings")
+		a.Attribute("linkTypes")
+	})
+})
+
+// TrackerConflict is a work item field edited locally and remotely between two synchronizations
+var TrackerConflict = a.MediaType("application/vnd.trackerconflict+json", func() {
+	a.TypeName("TrackerConflict")
+	a.Description("Conflicting edits of a work item field and how they were resolved")
+	a.Attribute("id", d.String, "unique id per conflict")
+	a.Attribute("workItemID", d.String, "ID of the work item")
+	a.Attribute("field", d.String, "Name of the work item field")
+	a.Attribute("baseValue", d.String, "Value last known on both sides")
+	a.Attribute("localValue", d.String, "Local value")
+	a.Attribute("remoteValue", d.String, "Remote value")
+	a.Attribute("policy", d.String, "Ownership policy of the field")
+	a.Attribute("resolution", d.String, "The side that won: remote or local")
+	a.Attribute("createdAt", d.DateTime, "When the conflict was detected")
+
+	a.Required("id", "workItemID", "field", "policy", "resolution", "createdAt")
+
+	a.View("default", func() {
+		a.Attribute("id")
+		a.Attribute("workItemID")
+		a.Attribute("field")
+		a.Attribute("baseValue")
+		a.Attribute("localValue")
+		a.Attribute("remoteValue")
+		a.Attribute("policy")
+		a.Attribute("resolution")
+		a.Attribute("createdAt")
 	})
 })
 
@@ -187,17 +266,63 @@ var TrackerQuery = a.MediaType("application/vnd.trackerquery+json", func() {
 	a.Attribute("query", d.String, "Search query")
 	a.Attribute("schedule", d.String, "Schedule for fetch and import")
 	a.Attribute("trackerID", d.String, "Tracker ID")
+	a.Attribute("projectID", d.UUID, "Project ID")
+	a.Attribute("workItemType", d.String, "Type of the work items remote items are imported as")
+	a.Attribute("workItemTypeRules", a.ArrayOf(trackerQueryTypeRule), "Rules choosing the type of imported work items from remote attributes")
+	a.Attribute("lastUpdatedAt", d.DateTime, "Latest update time of the imported remote items, later fetches only ask for items updated since then")
 
 	a.Required("id")
 	a.Required("query")
 	a.Required("schedule")
 	a.Required("trackerID")
+	a.Required("projectID")
 
 	a.View("default", func() {
 		a.Attribute("id")
 		a.Attribute("query")
 		a.Attribute("schedule")
 		a.Attribute("trackerID")
+		a.Attribute("projectID")
+		a.Attribute("workItemType")
+		a.Attribute("workItemTypeRules")
+		a.Attribute("lastUpdatedAt")
+	})
+})
+
+// TrackerQueryRun is a run of a tracker query
+var TrackerQueryRun = a.MediaType("application/vnd.trackerqueryrun+json", func() {
+	a.TypeName("TrackerQueryRun")
+	a.Description("Run of a tracker query fetching and importing remote items")
+	a.Attribute("id", d.String, "unique id per run")
+	a.Attribute("trackerQueryID", d.String, "ID of the tracker query")
+	a.Attribute("dryRun", d.Boolean, "Whether the run only previewed the import, changing nothing")
+	a.Attribute("status", d.String, "running, succeeded or failed")
+	a.Attribute("startedAt", d.DateTime, "When the run started")
+	a.Attribute("finishedAt", d.DateTime, "When the run finished")
+	a.Attribute("fetched", d.Integer, "Number of remote items fetched")
+	a.Attribute("created", d.Integer, "Number of work items created")
+	a.Attribute("updated", d.Integer, "Number of work items updated")
+	a.Attribute("unchanged", d.Integer, "Number of remote items that changed no work item")
+	a.Attribute("failed", d.Integer, "Number of remote items that failed to import")
+	a.Attribute("error", d.String, "Why the fetch stopped before all remote items were fetched")
+	a.Attribute("items", a.ArrayOf(trackerQueryRunItem), "The failed items, on a dry run all items")
+
+	a.Required("id", "trackerQueryID", "dryRun", "status", "startedAt", "fetched", "created", "updated", "unchanged", "failed")
+
+	a.View("default", func() {
+		a.Attribute("id")
+		a.Attribute("trackerQueryID")
+		a.Attribute("dryRun")
+		a.Attribute("status")
+		a.Attribute("startedAt")
+		a.Attribute("finishedAt")
+		a.Attribute("fetched")
+		a.Attribute("created")
+		a.Attribute("updated")
+		a.Attribute("unchanged")
+		a.Attribute("failed")
+		a.Attribute("error")
+		a.Attribute("items")
 	})
 })
 
@@ -207,10 +332,48 @@ var User = a.MediaType("application/vnd.user+json", func() {
 	a.Description("ALM User")
 	a.Attribute("fullName", d.String, "The users full name")
 	a.Attribute("imageURL", d.String, "The avatar image for the user")
+	a.Attribute("bio", d.String, "A short description the user gives of themselves")
+	a.Attribute("timezone", d.String, "The IANA time zone of the user")
 
 	a.View("default", func() {
 		a.Attribute("fullName")
 		a.Attribute("imageURL")
+		a.Attribute("bio")
+		a.Attribute("timezone")
+	})
+})
+
+// UserEmail represents an email registered for a user
+var UserEmail = a.MediaType("application/vnd.user-email+json", func() {
+	a.TypeName("UserEmail")
+	a.Description("An email registered for an ALM User")
+	a.Attribute("id", d.UUID, "ID of the email")
+	a.Attribute("email", d.String, "The email address")
+	a.Attribute("primary", d.Boolean, "Whether the user signed up with this email")
+	a.Attribute("verified", d.Boolean, "Whether the user proved to own this email")
+	a.Required("id", "email", "primary", "verified")
+
+	a.View("default", func() {
+		a.Attribute("id")
+		a.Attribute("email")
+		a.Attribute("primary")
+		a.Attribute("verified")
+	})
+})
+
+// PasswordReset represents a code to set the password for the local login
+var PasswordReset = a.MediaType("application/vnd.password-reset+json", func() {
+	a.TypeName("PasswordReset")
+	a.Description("A code to set the password for the local login with an email")
+	a.Attribute("email", d.String, "The email the code is for")
+	a.Attribute("code", d.String, "The code to hand over to the user")
+	a.Attribute("expires_at", d.DateTime, "When the code expires")
+	a.Required("email", "code", "expires_at")
+
+	a.View("default", func() {
+		a.Attribute("email")
+		a.Attribute("code")
+		a.Attribute("expires_at")
 	})
 })
 
@@ -236,16 +399,28 @@ var identityArray = a.MediaType("application/vnd.identity-array+json", func() {
 	a.TypeName("IdentityArray")
 	a.Description("ALM User Identity Array")
 	a.Attributes(func() {
+		a.Attribute("links", pagingLinks)
+		a.Attribute("meta", identityArrayMeta)
 		a.Attribute("data", a.ArrayOf(identityData))
 		a.Required("data")
 
 	})
 	a.View("default", func() {
+		a.Attribute("links")
+		a.Attribute("meta")
 		a.Attribute("data")
 		a.Required("data")
 	})
 })
 
+// identityArrayMeta holds the total number of identities matching a list request
+var identityArrayMeta = a.Type("IdentityArrayMeta", func() {
+	a.Attribute("totalCount", d.Integer, func() {
+		a.Minimum(0)
+	})
+	a.Required("totalCount")
+})
+
 var searchResponse = a.MediaType("application/vnd.search+json", func() {
 	a.TypeName("SearchResponse")
 	a.Description("Holds the paginated response to a search request")
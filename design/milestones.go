@@ -0,0 +1,252 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+//#############################################################################
+//
+// 			milestone
+//
+//#############################################################################
+
+// CreateMilestonePayload defines the structure of milestone payload in JSONAPI format during creation
+var CreateMilestonePayload = a.Type("CreateMilestonePayload", func() {
+	a.Attribute("data", MilestoneData)
+	a.Required("data")
+})
+
+// UpdateMilestonePayload defines the structure of milestone payload in JSONAPI format during update
+var UpdateMilestonePayload = a.Type("UpdateMilestonePayload", func() {
+	a.Attribute("data", MilestoneData)
+	a.Required("data")
+})
+
+// MilestoneData is the JSONAPI store for the data of a milestone.
+var MilestoneData = a.Type("MilestoneData", func() {
+	a.Description(`JSONAPI store for the data of a milestone.
+See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("milestones")
+	})
+	a.Attribute("id", d.UUID, "ID of the milestone (ignored during creation)", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", MilestoneAttributes)
+	a.Attribute("links", GenericLinks)
+	a.Required("type", "attributes")
+})
+
+// MilestoneAttributes is the JSONAPI store for all the "attributes" of a milestone.
+var MilestoneAttributes = a.Type("MilestoneAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a milestone.
+See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("name", d.String, "Name of the milestone (required on creation, optional on update)", func() {
+		a.Example("1.0")
+	})
+	a.Attribute("description", d.String, "Description of the milestone", func() {
+		a.Example("First public release")
+	})
+	a.Attribute("due-at", d.DateTime, "When the milestone is due", func() {
+		a.Example("2016-12-24T00:00:00Z")
+	})
+	a.Attribute("state", d.String, "State of the milestone", func() {
+		a.Enum("open", "closed")
+		a.Example("open")
+	})
+	a.Attribute("move-open-items-to", d.UUID, `ID of another open milestone of the project that all work items which are not done
+get moved to when closing the milestone (update only). Closing a milestone that still has open work items fails without it.`, func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("project", d.UUID, "ID of the project the milestone belongs to (read-only)", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("version", d.Integer, "Version for optimistic concurrency control (required on update)", func() {
+		a.Example(0)
+	})
+	a.Attribute("created-at", d.DateTime, "When the milestone was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("updated-at", d.DateTime, "When the milestone was updated", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+
+	// IMPORTANT: We cannot require any field here because these "attributes" will be used
+	// during the creation as well as the update of a milestone.
+	// The controller needs to check for required fields.
+})
+
+// Milestone is the media type for a single milestone
+var Milestone = a.MediaType("application/vnd.milestone+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("Milestone")
+	a.Description("A milestone is a release date of a project that work items can target")
+	a.Attributes(func() {
+		a.Attribute("data", MilestoneData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+// MilestoneArray is the media type for the milestones of a project
+var MilestoneArray = a.MediaType("application/vnd.milestone-array+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("MilestoneArray")
+	a.Description("Holds the response to a milestone list request")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(MilestoneData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+// MilestoneProgressData is the JSONAPI store for the progress of a milestone.
+var MilestoneProgressData = a.Type("MilestoneProgressData", func() {
+	a.Attribute("type", d.String, func() {
+		a.Enum("milestone-progress")
+	})
+	a.Attribute("id", d.UUID, "ID of the milestone")
+	a.Attribute("attributes", MilestoneProgressAttributes)
+	a.Required("type", "id", "attributes")
+})
+
+// MilestoneProgressAttributes is the JSONAPI store for all the "attributes" of the progress of a milestone.
+var MilestoneProgressAttributes = a.Type("MilestoneProgressAttributes", func() {
+	a.Attribute("total", d.Integer, "Number of work items targeting the milestone")
+	a.Attribute("done", d.Integer, "Number of work items that are resolved or closed")
+	a.Attribute("percent-done", d.Number, "Share of done work items in percent")
+	a.Attribute("state-counts", a.HashOf(d.String, d.Integer), "Number of work items per system.state")
+	a.Attribute("overdue", a.ArrayOf(d.String), "IDs of the work items that are not done although the milestone is due")
+	a.Required("total", "done", "percent-done", "state-counts", "overdue")
+})
+
+// MilestoneProgress is the media type for the progress of a milestone
+var MilestoneProgress = a.MediaType("application/vnd.milestone-progress+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("MilestoneProgress")
+	a.Description("Progress of a milestone computed from the work items targeting it")
+	a.Attributes(func() {
+		a.Attribute("data", MilestoneProgressData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var _ = a.Resource("milestone", func() {
+	a.BasePath("/milestones")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Description("Retrieve milestone with given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(Milestone)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("progress", func() {
+		a.Routing(
+			a.GET("/:id/progress"),
+		)
+		a.Description("Retrieve the progress of the milestone with given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(MilestoneProgress)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:id"),
+		)
+		a.Description("Update the milestone with the given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Payload(UpdateMilestonePayload)
+		a.Response(d.OK, func() {
+			a.Media(Milestone)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:id"),
+		)
+		a.Description("Delete the milestone with the given id. Milestones targeted by work items can not be deleted.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("project-milestones", func() {
+	a.BasePath("/milestones")
+	a.Parent("project")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the milestones of the given project.")
+		a.Response(d.OK, func() {
+			a.Media(MilestoneArray)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist.")
+		})
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Create a milestone in the given project.")
+		a.Payload(CreateMilestonePayload)
+		a.Response(d.Created, "/milestones/.*", func() {
+			a.Media(Milestone)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist.")
+		})
+	})
+})
@@ -0,0 +1,448 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+//#############################################################################
+//
+// 			project
+//
+//#############################################################################
+
+// CreateProjectPayload defines the structure of project payload in JSONAPI format during creation
+var CreateProjectPayload = a.Type("CreateProjectPayload", func() {
+	a.Attribute("data", ProjectData)
+	a.Required("data")
+})
+
+// UpdateProjectPayload defines the structure of project payload in JSONAPI format during update
+var UpdateProjectPayload = a.Type("UpdateProjectPayload", func() {
+	a.Attribute("data", ProjectData)
+	a.Required("data")
+})
+
+// ProjectArrayMeta holds meta information for a project array response
+var ProjectArrayMeta = a.Type("ProjectArrayMeta", func() {
+	a.Attribute("totalCount", d.Integer, func() {
+		a.Minimum(0)
+	})
+	a.Required("totalCount")
+})
+
+// ProjectData is the JSONAPI store for the data of a project.
+var ProjectData = a.Type("ProjectData", func() {
+	a.Description(`JSONAPI store for the data of a project.
+See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("projects")
+	})
+	a.Attribute("id", d.UUID, "ID of the project (optional during creation)", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", ProjectAttributes)
+	a.Attribute("links", GenericLinks)
+	a.Required("type", "attributes")
+})
+
+// ProjectAttributes is the JSONAPI store for all the "attributes" of a project.
+var ProjectAttributes = a.Type("ProjectAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a project.
+See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("name", d.String, "Name of the project (required on creation, optional on update)", func() {
+		a.Example("Almighty")
+	})
+	a.Attribute("version", d.Integer, "Version for optimistic concurrency control (required on update)", func() {
+		a.Example(0)
+	})
+	a.Attribute("created-at", d.DateTime, "When the project was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("updated-at", d.DateTime, "When the project was updated", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+
+	// IMPORTANT: We cannot require any field here because these "attributes" will be used
+	// during the creation as well as the update of a project.
+	// During creation, the "name" field is required but not during update.
+	// The controller needs to check for required fields.
+})
+
+// GenericLinks holds the self link of a JSONAPI resource object
+var GenericLinks = a.Type("GenericLinks", func() {
+	a.Attribute("self", d.String)
+})
+
+// Project is the media type for a single project
+var Project = a.MediaType("application/vnd.project+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("Project")
+	a.Description("A project holds work items and the configuration of how to work with them")
+	a.Attributes(func() {
+		a.Attribute("data", ProjectData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+// ProjectArray is the media type for a paged collection of projects
+var ProjectArray = a.MediaType("application/vnd.project-array+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("ProjectArray")
+	a.Description("Holds the paginated response to a project list request")
+	a.Attributes(func() {
+		a.Attribute("links", pagingLinks)
+		a.Attribute("meta", ProjectArrayMeta)
+		a.Attribute("data", a.ArrayOf(ProjectData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("links")
+		a.Attribute("meta")
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var _ = a.Resource("project", func() {
+	a.BasePath("/projects")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List projects.")
+		a.Params(func() {
+			a.Param("page[offset]", d.String, "Paging start position")
+			a.Param("page[limit]", d.Integer, "Paging size")
+		})
+		a.Response(d.OK, func() {
+			a.Media(ProjectArray)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Description("Retrieve project with given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(Project)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Create a project")
+		a.Payload(CreateProjectPayload)
+		a.Response(d.Created, "/projects/.*", func() {
+			a.Media(Project)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:id"),
+		)
+		a.Description("Delete a project with the given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:id"),
+		)
+		a.Description("Update the project with the given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Payload(UpdateProjectPayload)
+		a.Response(d.OK, func() {
+			a.Media(Project)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("project-work-items", func() {
+	a.BasePath("/workitems")
+	a.Parent("project")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the work items of the given project.")
+		a.Params(func() {
+			a.Param("area", d.String, "only list work items classified with the area with the given ID or one of its descendants")
+			a.Param("filter", d.String, "a query language expression restricting the set of found work items")
+			a.Param("iteration", d.String, "only list work items planned for the iteration with the given ID, or for any current iteration of the project if 'current'")
+			a.Param("page[offset]", d.String, "Paging start position")
+			a.Param("page[limit]", d.Integer, "Paging size")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemListResponse)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist.")
+		})
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Create a work item in the given project.")
+		a.Payload(CreateWorkItemPayload)
+		a.Response(d.Created, "/workitems/.*", func() {
+			a.Media(workItem)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist.")
+		})
+	})
+})
+
+var _ = a.Resource("project-work-item-types", func() {
+	a.BasePath("/workitemtypes")
+	a.Parent("project")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:name"),
+		)
+		a.Description("Retrieve the work item type with the given name usable in the given project.")
+		a.Params(func() {
+			a.Param("name", d.String, "name")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemType)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist or the type is not usable in it.")
+		})
+	})
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the work item types usable in the given project.")
+		a.Params(func() {
+			a.Param("page", d.String, "Paging in the format <start>,<limit>")
+		})
+		a.Response(d.OK, func() {
+			a.Media(a.CollectionOf(workItemType))
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist.")
+		})
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Create a work item type in the given project.")
+		a.Payload(CreateWorkItemTypePayload)
+		a.Response(d.Created, "/projects/.*/workitemtypes/.*", func() {
+			a.Media(workItemType)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist.")
+		})
+	})
+})
+
+var _ = a.Resource("project-tracker-queries", func() {
+	a.BasePath("/trackerqueries")
+	a.Parent("project")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the tracker queries importing into the given project.")
+		a.Response(d.OK, func() {
+			a.Media(a.CollectionOf(TrackerQuery))
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist.")
+		})
+	})
+})
+
+// CreateProjectMembershipPayload defines the structure of a project membership payload in JSONAPI format during creation
+var CreateProjectMembershipPayload = a.Type("CreateProjectMembershipPayload", func() {
+	a.Attribute("data", ProjectMembershipData)
+	a.Required("data")
+})
+
+// ProjectMembershipData is the JSONAPI store for the data of a project membership.
+var ProjectMembershipData = a.Type("ProjectMembershipData", func() {
+	a.Description(`JSONAPI store for the data of a project membership.
+See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("project-memberships")
+	})
+	a.Attribute("id", d.UUID, "ID of the membership (ignored during creation)", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", ProjectMembershipAttributes)
+	a.Required("type", "attributes")
+})
+
+// ProjectMembershipAttributes is the JSONAPI store for all the "attributes" of a project membership.
+var ProjectMembershipAttributes = a.Type("ProjectMembershipAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a project membership.
+See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("identity", d.UUID, "ID of the identity that is member of the project", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("role", d.String, "Role of the identity in the project", func() {
+		a.Enum("owner", "contributor", "viewer")
+		a.Example("contributor")
+	})
+	a.Attribute("created-at", d.DateTime, "When the membership was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Required("identity", "role")
+})
+
+// ProjectMembership is the media type for a single project membership
+var ProjectMembership = a.MediaType("application/vnd.project-membership+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("ProjectMembership")
+	a.Description("A project membership gives an identity a role in a project")
+	a.Attributes(func() {
+		a.Attribute("data", ProjectMembershipData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+// ProjectMembershipArray is the media type for all memberships of a project
+var ProjectMembershipArray = a.MediaType("application/vnd.project-membership-array+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("ProjectMembershipArray")
+	a.Description("Holds the response to a project membership list request")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(ProjectMembershipData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var _ = a.Resource("project-memberships", func() {
+	a.BasePath("/memberships")
+	a.Parent("project")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the memberships of the given project.")
+		a.Response(d.OK, func() {
+			a.Media(ProjectMembershipArray)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist.")
+		})
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Give an identity a role in the given project.")
+		a.Payload(CreateProjectMembershipPayload)
+		a.Response(d.Created, func() {
+			a.Media(ProjectMembership)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist.")
+		})
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:identityID"),
+		)
+		a.Description("Remove an identity from the given project.")
+		a.Params(func() {
+			a.Param("identityID", d.UUID, "ID of the identity to remove")
+		})
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
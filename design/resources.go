@@ -9,6 +9,7 @@ var _ = a.Resource("workitem", func() {
 	a.BasePath("/workitems")
 
 	a.Action("show", func() {
+		a.Security("jwt")
 		a.Routing(
 			a.GET("/:id"),
 		)
@@ -22,13 +23,15 @@ var _ = a.Resource("workitem", func() {
 		a.Response(d.BadRequest, JSONAPIErrors)
 		a.Response(d.InternalServerError, JSONAPIErrors)
 		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
 	})
 
 	a.Action("list", func() {
+		a.Security("jwt")
 		a.Routing(
 			a.GET(""),
 		)
-		a.Description("List work items.")
+		a.Description("List the work items of the projects the user may read.")
 		a.Params(func() {
 			a.Param("filter", d.String, "a query language expression restricting the set of found work items")
 			a.Param("page", d.String, "Paging in the format <start>,<limit>")
@@ -38,6 +41,7 @@ var _ = a.Resource("workitem", func() {
 		})
 		a.Response(d.BadRequest, JSONAPIErrors)
 		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
 	})
 
 	a.Action("create", func() {
@@ -46,6 +50,9 @@ var _ = a.Resource("workitem", func() {
 			a.POST(""),
 		)
 		a.Description("create work item with type and id.")
+		a.Params(func() {
+			a.Param("project", d.UUID, "ID of the project the work item is created in, the system project if not given")
+		})
 		a.Payload(CreateWorkItemPayload)
 		a.Response(d.Created, "/workitems/.*", func() {
 			a.Media(workItem)
@@ -53,6 +60,9 @@ var _ = a.Resource("workitem", func() {
 		a.Response(d.BadRequest, JSONAPIErrors)
 		a.Response(d.InternalServerError, JSONAPIErrors)
 		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist.")
+		})
 	})
 	a.Action("delete", func() {
 		a.Security("jwt")
@@ -94,10 +104,11 @@ var _ = a.Resource("workitem", func() {
 var _ = a.Resource("workitem.2", func() {
 	a.BasePath("/workitems.2")
 	a.Action("list", func() {
+		a.Security("jwt")
 		a.Routing(
 			a.GET(""),
 		)
-		a.Description("List work items.")
+		a.Description("List the work items of the projects the user may read.")
 		a.Params(func() {
 			a.Param("filter", d.String, "a query language expression restricting the set of found work items")
 			a.Param("page[offset]", d.String, "Paging start position")
@@ -108,6 +119,7 @@ var _ = a.Resource("workitem.2", func() {
 		})
 		a.Response(d.BadRequest, JSONAPIErrors)
 		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
 	})
 
 	a.Action("update", func() {
@@ -157,6 +169,9 @@ var _ = a.Resource("workitemtype", func() {
 			a.POST(""),
 		)
 		a.Description("Create work item type.")
+		a.Params(func() {
+			a.Param("project", d.UUID, "ID of the project owning the work item type, the system project if not given")
+		})
 		a.Payload(CreateWorkItemTypePayload)
 		a.Response(d.Created, "/workitemtypes/.*", func() {
 			a.Media(workItemType)
@@ -164,6 +179,9 @@ var _ = a.Resource("workitemtype", func() {
 		a.Response(d.BadRequest, JSONAPIErrors)
 		a.Response(d.InternalServerError, JSONAPIErrors)
 		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist.")
+		})
 	})
 
 	a.Action("list", func() {
@@ -173,12 +191,16 @@ var _ = a.Resource("workitemtype", func() {
 		a.Description("List work item types.")
 		a.Params(func() {
 			a.Param("page", d.String, "Paging in the format <start>,<limit>")
+			a.Param("project", d.UUID, "ID of the project whose usable work item types are listed, the system project if not given")
 		})
 		a.Response(d.OK, func() {
 			a.Media(a.CollectionOf(workItemType))
 		})
 		a.Response(d.BadRequest, JSONAPIErrors)
 		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist.")
+		})
 	})
 })
 
@@ -199,6 +221,118 @@ var _ = a.Resource("user", func() {
 		a.Response(d.Unauthorized, JSONAPIErrors)
 	})
 
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH(""),
+		)
+		a.Description("Update the profile of the authenticated user")
+		a.Payload(UpdateUserPayload)
+		a.Response(d.OK, func() {
+			a.Media(User)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("listEmails", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/emails"),
+		)
+		a.Description("List the emails of the authenticated user")
+		a.Response(d.OK, func() {
+			a.Media(a.CollectionOf(UserEmail))
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("addEmail", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/emails"),
+		)
+		a.Description("Register a secondary email for the authenticated user. It can be used once it is verified.")
+		a.Payload(AddUserEmailPayload)
+		a.Response(d.Created, func() {
+			a.Media(UserEmail)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("verifyEmail", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/emails/:id/verify"),
+		)
+		a.Description("Verify a secondary email of the authenticated user")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Payload(VerifyUserEmailPayload)
+		a.Response(d.OK, func() {
+			a.Media(UserEmail)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("removeEmail", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/emails/:id"),
+		)
+		a.Description("Remove a secondary email of the authenticated user")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("merge", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/merge"),
+		)
+		a.Description(`Merge another identity of the same person into the authenticated one. Work items created by
+or assigned to the other identity, its comments, emails and project memberships are moved over and the other
+identity is deleted.`)
+		a.Payload(MergeUserPayload)
+		a.Response(d.OK, func() {
+			a.Media(User)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the identity to merge does not exist (anymore).")
+		})
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("setPassword", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("/password"),
+		)
+		a.Description("Set the password for the local login with the primary email of the authenticated user")
+		a.Payload(SetPasswordPayload)
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
 })
 
 var _ = a.Resource("identity", func() {
@@ -208,7 +342,14 @@ var _ = a.Resource("identity", func() {
 		a.Routing(
 			a.GET(""),
 		)
-		a.Description("List all identities.")
+		a.Description("List identities ordered by full name.")
+		a.Params(func() {
+			a.Param("filter[name]", d.String, "only list identities whose full name starts with the given prefix (case insensitive)")
+			a.Param("filter[email]", d.String, "only list identities with an email starting with the given prefix (case insensitive)")
+			a.Param("filter[project]", d.UUID, "only list identities that are members of the project with the given ID")
+			a.Param("page[offset]", d.String, "Paging start position")
+			a.Param("page[limit]", d.Integer, "Paging size")
+		})
 		a.Response(d.OK, func() {
 			a.Media(identityArray)
 		})
@@ -241,6 +382,11 @@ var _ = a.Resource("login", func() {
 			a.GET("authorize"),
 		)
 		a.Description("Authorize with the ALM")
+		a.Params(func() {
+			a.Param("provider", d.String, "Name of the login provider to use, e.g. github or the name of a configured OpenID Connect provider. Defaults to the configured default provider.")
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
 		a.Response(d.Unauthorized, JSONAPIErrors)
 		a.Response(d.TemporaryRedirect)
 	})
@@ -255,6 +401,74 @@ var _ = a.Resource("login", func() {
 		})
 		a.Response(d.Unauthorized, JSONAPIErrors)
 	})
+
+	a.Action("refresh", func() {
+		a.Routing(
+			a.POST("refresh"),
+		)
+		a.Description("Exchanges a refresh token for a new login token and refresh token. Each refresh token can be used once.")
+		a.Payload(RefreshTokenPayload)
+		a.Response(d.OK, func() {
+			a.Media(AuthToken)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("logout", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("logout"),
+		)
+		a.Description("Revokes the token of the request. For a login token the refresh token of the same login goes with it.")
+		a.Response(d.OK)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("createPasswordReset", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("local/resets"),
+		)
+		a.Description(`Creates the code needed to set the password for the local login with the given email. Emails that
+are not known yet are registered for a new identity, which is how users are invited. Only owners of the system
+project may create codes, which they hand over to the user.`)
+		a.Payload(CreatePasswordResetPayload)
+		a.Response(d.Created, func() {
+			a.Media(PasswordReset)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("resetPassword", func() {
+		a.Routing(
+			a.POST("local/reset"),
+		)
+		a.Description("Sets the password for the local login with the given email using a code created by createPasswordReset. Each code can be used once.")
+		a.Payload(ResetPasswordPayload)
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("jwks", func() {
+
+	a.Action("show", func() {
+		a.Routing(
+			// the well-known location is outside of the API base path
+			a.GET("//.well-known/jwks.json"),
+		)
+		a.Description("List the public keys that tokens can be verified with, so that other services can accept them.")
+		a.Response(d.OK, func() {
+			a.Media(JSONWebKeySet)
+		})
+	})
 })
 
 var _ = a.Resource("tracker", func() {
@@ -339,6 +553,58 @@ var _ = a.Resource("tracker", func() {
 		a.Response(d.Unauthorized, JSONAPIErrors)
 	})
 
+	a.Action("conflicts", func() {
+		a.Routing(
+			a.GET("/:id/conflicts"),
+		)
+		a.Description("List the conflicting edits detected while synchronizing with the tracker.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(a.CollectionOf(TrackerConflict))
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("webhook", func() {
+	a.BasePath("/trackers/:id/hooks")
+	a.Params(func() {
+		a.Param("id", d.String, "id of the tracker")
+	})
+	a.Action("github", func() {
+		a.Routing(
+			a.POST("/github"),
+		)
+		a.Description("Import the issue of a Github issues or issue_comment event signed with the webhook secret of the tracker.")
+		a.Headers(func() {
+			a.Header("X-GitHub-Event", d.String, "Type of the event")
+			a.Header("X-Hub-Signature", d.String, "HMAC-SHA1 of the body keyed with the webhook secret")
+			a.Header("X-Hub-Signature-256", d.String, "HMAC-SHA256 of the body keyed with the webhook secret")
+		})
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+	a.Action("jira", func() {
+		a.Routing(
+			a.POST("/jira"),
+		)
+		a.Description("Import the issue of a Jira issue event, authenticated with the webhook secret of the tracker.")
+		a.Params(func() {
+			a.Param("secret", d.String, "Webhook secret of the tracker")
+		})
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
 })
 
 var _ = a.Resource("trackerquery", func() {
@@ -403,6 +669,68 @@ var _ = a.Resource("trackerquery", func() {
 		a.Response(d.NotFound, JSONAPIErrors)
 		a.Response(d.Unauthorized, JSONAPIErrors)
 	})
+	a.Action("resync", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:id/resync"),
+		)
+		a.Description("Fetch and import all remote items again on the next run instead of the ones updated since the last run.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(TrackerQuery)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+	a.Action("runs", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:id/runs"),
+		)
+		a.Description("List the runs of the tracker query, most recent first.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+			a.Param("limit", d.Integer, "Maximum number of runs listed", func() {
+				a.Minimum(1)
+				a.Default(20)
+			})
+		})
+		a.Response(d.OK, func() {
+			a.Media(a.CollectionOf(TrackerQueryRun))
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+	a.Action("run", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:id/runs"),
+		)
+		a.Description("Run the tracker query now. The run continues in the background, a dry run previews what importing the fetched items would create or change without changing anything. The runs action shows the outcome once finished.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+			a.Param("dryRun", d.Boolean, "Only preview the import", func() {
+				a.Default(false)
+			})
+			a.Param("limit", d.Integer, "Maximum number of remote items previewed on a dry run", func() {
+				a.Minimum(1)
+				a.Default(50)
+			})
+		})
+		a.Response(d.Accepted, func() {
+			a.Media(TrackerQueryRun)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
 	a.Action("list", func() {
 		a.Routing(
 			a.GET(""),
@@ -421,10 +749,11 @@ var _ = a.Resource("search", func() {
 	a.BasePath("/search")
 
 	a.Action("show", func() {
+		a.Security("jwt")
 		a.Routing(
 			a.GET(""),
 		)
-		a.Description("Search by ID, URL, full text capability")
+		a.Description("Search by ID, URL, full text capability in the work items of the projects the user may read")
 		a.Params(func() {
 			a.Param("q", d.String,
 				`Following are valid input for seach query
@@ -443,6 +772,8 @@ var _ = a.Resource("search", func() {
 		a.Response(d.BadRequest, JSONAPIErrors)
 
 		a.Response(d.InternalServerError, JSONAPIErrors)
+
+		a.Response(d.Unauthorized, JSONAPIErrors)
 	})
 })
 
@@ -644,6 +975,7 @@ var _ = a.Resource("work-item-relationships-links", func() {
 // of work item links.
 func listWorkItemLinks() {
 	a.Description("Retrieve work item link (as JSONAPI) for the given link ID.")
+	a.Security("jwt")
 	a.Routing(
 		a.GET(""),
 	)
@@ -652,10 +984,12 @@ func listWorkItemLinks() {
 	})
 	a.Response(d.BadRequest, JSONAPIErrors)
 	a.Response(d.InternalServerError, JSONAPIErrors)
+	a.Response(d.Unauthorized, JSONAPIErrors)
 }
 
 func showWorkItemLink() {
 	a.Description("Retrieve work item link (as JSONAPI) for the given link ID.")
+	a.Security("jwt")
 	a.Routing(
 		a.GET("/:linkId"),
 	)
@@ -668,6 +1002,7 @@ func showWorkItemLink() {
 	a.Response(d.BadRequest, JSONAPIErrors)
 	a.Response(d.InternalServerError, JSONAPIErrors)
 	a.Response(d.NotFound, JSONAPIErrors)
+	a.Response(d.Unauthorized, JSONAPIErrors)
 }
 
 func createWorkItemLink() {
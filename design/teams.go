@@ -0,0 +1,435 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+//#############################################################################
+//
+// 			team
+//
+//#############################################################################
+
+// CreateTeamPayload defines the structure of team payload in JSONAPI format during creation
+var CreateTeamPayload = a.Type("CreateTeamPayload", func() {
+	a.Attribute("data", TeamData)
+	a.Required("data")
+})
+
+// UpdateTeamPayload defines the structure of team payload in JSONAPI format during update
+var UpdateTeamPayload = a.Type("UpdateTeamPayload", func() {
+	a.Attribute("data", TeamData)
+	a.Required("data")
+})
+
+// TeamData is the JSONAPI store for the data of a team.
+var TeamData = a.Type("TeamData", func() {
+	a.Description(`JSONAPI store for the data of a team.
+See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("teams")
+	})
+	a.Attribute("id", d.UUID, "ID of the team (ignored during creation)", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", TeamAttributes)
+	a.Attribute("links", GenericLinks)
+	a.Required("type", "attributes")
+})
+
+// TeamAttributes is the JSONAPI store for all the "attributes" of a team.
+var TeamAttributes = a.Type("TeamAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a team.
+See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("name", d.String, `Name of the team, unique regardless of case (required on creation, optional on update).
+Comments mention the team with @name.`, func() {
+		a.Pattern("^[A-Za-z0-9][A-Za-z0-9_.-]*$")
+		a.Example("frontend")
+	})
+	a.Attribute("description", d.String, "Description of the team", func() {
+		a.Example("Everyone working on the web UI")
+	})
+	a.Attribute("parent", d.UUID, "ID of the parent team; members of the team also count as members of the parent", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("version", d.Integer, "Version for optimistic concurrency control (required on update)", func() {
+		a.Example(0)
+	})
+	a.Attribute("created-at", d.DateTime, "When the team was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("updated-at", d.DateTime, "When the team was updated", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+
+	// IMPORTANT: We cannot require any field here because these "attributes" will be used
+	// during the creation as well as the update of a team.
+	// The controller needs to check for required fields.
+})
+
+// Team is the media type for a single team
+var Team = a.MediaType("application/vnd.team+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("Team")
+	a.Description("A team is a named group of identities")
+	a.Attributes(func() {
+		a.Attribute("data", TeamData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+// TeamArray is the media type for a list of teams
+var TeamArray = a.MediaType("application/vnd.team-array+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("TeamArray")
+	a.Description("Holds the response to a team list request")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(TeamData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+// CreateTeamMemberPayload defines the structure of a team member payload in JSONAPI format during creation
+var CreateTeamMemberPayload = a.Type("CreateTeamMemberPayload", func() {
+	a.Attribute("data", TeamMemberData)
+	a.Required("data")
+})
+
+// TeamMemberData is the JSONAPI store for the data of a team member.
+var TeamMemberData = a.Type("TeamMemberData", func() {
+	a.Description(`JSONAPI store for the data of a team member.
+See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("team-members")
+	})
+	a.Attribute("id", d.UUID, "ID of the membership (ignored during creation)", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", TeamMemberAttributes)
+	a.Required("type", "attributes")
+})
+
+// TeamMemberAttributes is the JSONAPI store for all the "attributes" of a team member.
+var TeamMemberAttributes = a.Type("TeamMemberAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a team member.
+See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("identity", d.UUID, "ID of the identity that is member of the team", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("created-at", d.DateTime, "When the identity joined the team", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Required("identity")
+})
+
+// TeamMember is the media type for a single team member
+var TeamMember = a.MediaType("application/vnd.team-member+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("TeamMember")
+	a.Description("A team member makes an identity a member of a team")
+	a.Attributes(func() {
+		a.Attribute("data", TeamMemberData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+// TeamMemberArray is the media type for the direct members of a team
+var TeamMemberArray = a.MediaType("application/vnd.team-member-array+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("TeamMemberArray")
+	a.Description("Holds the response to a team member list request")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(TeamMemberData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+// CreateProjectTeamPayload defines the structure of a project team role payload in JSONAPI format during creation
+var CreateProjectTeamPayload = a.Type("CreateProjectTeamPayload", func() {
+	a.Attribute("data", ProjectTeamData)
+	a.Required("data")
+})
+
+// ProjectTeamData is the JSONAPI store for the data of a role granted to a team in a project.
+var ProjectTeamData = a.Type("ProjectTeamData", func() {
+	a.Description(`JSONAPI store for the data of a role granted to a team in a project.
+See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("project-teams")
+	})
+	a.Attribute("id", d.UUID, "ID of the project team role (ignored during creation)", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", ProjectTeamAttributes)
+	a.Required("type", "attributes")
+})
+
+// ProjectTeamAttributes is the JSONAPI store for all the "attributes" of a role granted to a team in a project.
+var ProjectTeamAttributes = a.Type("ProjectTeamAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a role granted to a team in a project.
+See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("team", d.UUID, "ID of the team whose members and sub-team members get the role", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("role", d.String, "Role of the team in the project", func() {
+		a.Enum("owner", "contributor", "viewer")
+		a.Example("contributor")
+	})
+	a.Attribute("created-at", d.DateTime, "When the role was granted", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Required("team", "role")
+})
+
+// ProjectTeam is the media type for a single role granted to a team in a project
+var ProjectTeam = a.MediaType("application/vnd.project-team+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("ProjectTeam")
+	a.Description("A project team role gives the members of a team a role in a project")
+	a.Attributes(func() {
+		a.Attribute("data", ProjectTeamData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+// ProjectTeamArray is the media type for all roles granted to teams in a project
+var ProjectTeamArray = a.MediaType("application/vnd.project-team-array+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("ProjectTeamArray")
+	a.Description("Holds the response to a project team list request")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(ProjectTeamData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var _ = a.Resource("team", func() {
+	a.BasePath("/teams")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List all teams.")
+		a.Response(d.OK, func() {
+			a.Media(TeamArray)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Description("Retrieve team with given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(Team)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Create a team.")
+		a.Payload(CreateTeamPayload)
+		a.Response(d.Created, "/teams/.*", func() {
+			a.Media(Team)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:id"),
+		)
+		a.Description("Update the team with the given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Payload(UpdateTeamPayload)
+		a.Response(d.OK, func() {
+			a.Media(Team)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:id"),
+		)
+		a.Description("Delete the team with the given id. Teams with sub-teams or work items assigned to them can not be deleted.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("mentions", func() {
+		a.Routing(
+			a.GET("/:id/mentions"),
+		)
+		a.Description("List the comments mentioning the team with @name, newest first.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(commentArray)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("team-members", func() {
+	a.BasePath("/members")
+	a.Parent("team")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the direct members of the given team.")
+		a.Response(d.OK, func() {
+			a.Media(TeamMemberArray)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given team does not exist.")
+		})
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Add an identity to the given team.")
+		a.Payload(CreateTeamMemberPayload)
+		a.Response(d.Created, func() {
+			a.Media(TeamMember)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given team does not exist.")
+		})
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:identityID"),
+		)
+		a.Description("Remove an identity from the given team.")
+		a.Params(func() {
+			a.Param("identityID", d.UUID, "ID of the identity to remove")
+		})
+		a.Response(d.OK)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("project-teams", func() {
+	a.BasePath("/teams")
+	a.Parent("project")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the roles granted to teams in the given project.")
+		a.Response(d.OK, func() {
+			a.Media(ProjectTeamArray)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist.")
+		})
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Give all members of a team and of its sub-teams a role in the given project.")
+		a.Payload(CreateProjectTeamPayload)
+		a.Response(d.Created, func() {
+			a.Media(ProjectTeam)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given project does not exist.")
+		})
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:teamID"),
+		)
+		a.Description("Revoke the role of a team in the given project.")
+		a.Params(func() {
+			a.Param("teamID", d.UUID, "ID of the team")
+		})
+		a.Response(d.OK)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
@@ -129,6 +129,25 @@ var CreateWorkItemTypePayload = a.Type("CreateWorkItemTypePayload", func() {
 	a.Required("name", "fields")
 })
 
+// trackerFieldMapping maps an attribute of a remote item to a work item field
+var trackerFieldMapping = a.Type("TrackerFieldMapping", func() {
+	a.Attribute("path", d.String, "Attribute expression in the flattened remote item", func() {
+		a.Example("fields.status.name")
+		a.MinLength(1)
+	})
+	a.Attribute("converter", d.String, "Conversion applied to the remote value", func() {
+		a.Enum("string", "translate")
+	})
+	a.Attribute("field", d.String, "Work item field the converted value is stored in", func() {
+		a.Example("system.state")
+		a.MinLength(1)
+	})
+	a.Attribute("values", a.HashOf(d.String, d.String), "Translation of remote values into local ones for the translate converter", func() {
+		a.Example(map[string]string{"In Progress": "in progress", "Done": "closed"})
+	})
+	a.Required("path", "converter", "field")
+})
+
 // CreateTrackerAlternatePayload defines the structure of tracker payload for create
 var CreateTrackerAlternatePayload = a.Type("CreateTrackerAlternatePayload", func() {
 	a.Attribute("url", d.String, "URL of the tracker", func() {
@@ -140,6 +159,18 @@ var CreateTrackerAlternatePayload = a.Type("CreateTrackerAlternatePayload", func
 		a.Pattern("^[\\p{L}]+$")
 		a.MinLength(1)
 	})
+	a.Attribute("push", d.Boolean, "Whether local edits of imported work items are pushed back to the tracker")
+	a.Attribute("fieldOwnership", a.HashOf(d.String, d.String), "Who wins conflicting edits of a work item field: remote, local or last-writer", func() {
+		a.Example(map[string]string{"system.title": "last-writer", "system.state": "local"})
+	})
+	a.Attribute("fieldMappings", a.ArrayOf(trackerFieldMapping), "Mappings of remote attributes to work item fields, the defaults of the tracker type are used if not given")
+	a.Attribute("webhookSecret", d.String, "Secret authenticating webhook events, an empty one disables webhooks")
+	a.Attribute("identityMappings", a.HashOf(d.String, d.String), "Identities the remote users are imported as, keyed by their remote names", func() {
+		a.Example(map[string]string{"sbose78": "a1b2c3d4-0000-4000-8000-000000000000"})
+	})
+	a.Attribute("linkTypes", a.HashOf(d.String, d.String), "Work item link types remote issue links are imported as, keyed by their remote names; links of other types are not imported", func() {
+		a.Example(map[string]string{"Blocks": "25c326a7-6d03-4f5a-b23b-86a9ee4171e9", "cross-reference": "25c326a7-6d03-4f5a-b23b-86a9ee4171e9"})
+	})
 	a.Required("url", "type")
 })
 
@@ -154,9 +185,49 @@ var UpdateTrackerAlternatePayload = a.Type("UpdateTrackerAlternatePayload", func
 		a.MinLength(1)
 		a.Pattern("^[\\p{L}]+$")
 	})
+	a.Attribute("push", d.Boolean, "Whether local edits of imported work items are pushed back to the tracker")
+	a.Attribute("fieldOwnership", a.HashOf(d.String, d.String), "Who wins conflicting edits of a work item field: remote, local or last-writer", func() {
+		a.Example(map[string]string{"system.title": "last-writer", "system.state": "local"})
+	})
+	a.Attribute("fieldMappings", a.ArrayOf(trackerFieldMapping), "Mappings of remote attributes to work item fields, the defaults of the tracker type are used if not given")
+	a.Attribute("webhookSecret", d.String, "Secret authenticating webhook events, an empty one disables webhooks")
+	a.Attribute("identityMappings", a.HashOf(d.String, d.String), "Identities the remote users are imported as, keyed by their remote names", func() {
+		a.Example(map[string]string{"sbose78": "a1b2c3d4-0000-4000-8000-000000000000"})
+	})
+	a.Attribute("linkTypes", a.HashOf(d.String, d.String), "Work item link types remote issue links are imported as, keyed by their remote names; links of other types are not imported", func() {
+		a.Example(map[string]string{"Blocks": "25c326a7-6d03-4f5a-b23b-86a9ee4171e9", "cross-reference": "25c326a7-6d03-4f5a-b23b-86a9ee4171e9"})
+	})
 	a.Required("url", "type")
 })
 
+// trackerQueryTypeRule chooses the work item type of imported items by a remote attribute
+var trackerQueryTypeRule = a.Type("TrackerQueryTypeRule", func() {
+	a.Attribute("path", d.String, "Attribute expression in the flattened remote item, a * stands for any index of a list", func() {
+		a.Example("labels.*.name")
+		a.MinLength(1)
+	})
+	a.Attribute("value", d.String, "Value of the remote attribute, compared ignoring case", func() {
+		a.Example("enhancement")
+	})
+	a.Attribute("workItemType", d.String, "Type of the work items matching the rule", func() {
+		a.Example("system.userstory")
+		a.MinLength(1)
+	})
+	a.Required("path", "value", "workItemType")
+})
+
+// trackerQueryRunItem is the outcome of importing a remote item in a run of a tracker query
+var trackerQueryRunItem = a.Type("TrackerQueryRunItem", func() {
+	a.Attribute("remoteItemID", d.String, "ID of the remote item")
+	a.Attribute("action", d.String, "What importing the item did or, on a dry run, would do", func() {
+		a.Enum("create", "update", "unchanged", "failed")
+	})
+	a.Attribute("workItemID", d.String, "ID of the updated work item")
+	a.Attribute("fields", a.ArrayOf(d.String), "Work item fields set by the import")
+	a.Attribute("error", d.String, "Why the import failed")
+	a.Required("remoteItemID", "action")
+})
+
 // CreateTrackerQueryAlternatePayload defines the structure of tracker query payload for create
 var CreateTrackerQueryAlternatePayload = a.Type("CreateTrackerQueryAlternatePayload", func() {
 	a.Attribute("query", d.String, "Search query", func() {
@@ -173,6 +244,14 @@ var CreateTrackerQueryAlternatePayload = a.Type("CreateTrackerQueryAlternatePayl
 		a.MinLength(1)
 		a.Pattern("^[\\p{N}]+$")
 	})
+	a.Attribute("projectID", d.UUID, "ID of the project into which remote items are imported", func() {
+		a.Example("2e0698d8-753e-4cef-bb7c-f027634824a2")
+	})
+	a.Attribute("workItemType", d.String, "Type of the work items remote items are imported as", func() {
+		a.Example("system.bug")
+		a.MinLength(1)
+	})
+	a.Attribute("workItemTypeRules", a.ArrayOf(trackerQueryTypeRule), "Rules choosing the type of imported work items from remote attributes, the first matching one wins")
 	a.Required("query", "schedule", "trackerID")
 })
 
@@ -192,6 +271,14 @@ var UpdateTrackerQueryAlternatePayload = a.Type("UpdateTrackerQueryAlternatePayl
 		a.MinLength(1)
 		a.Pattern("[\\p{N}]+")
 	})
+	a.Attribute("projectID", d.UUID, "ID of the project into which remote items are imported", func() {
+		a.Example("2e0698d8-753e-4cef-bb7c-f027634824a2")
+	})
+	a.Attribute("workItemType", d.String, "Type of the work items remote items are imported as", func() {
+		a.Example("system.bug")
+		a.MinLength(1)
+	})
+	a.Attribute("workItemTypeRules", a.ArrayOf(trackerQueryTypeRule), "Rules choosing the type of imported work items from remote attributes, the first matching one wins")
 	a.Required("query", "schedule", "trackerID")
 })
 
@@ -199,6 +286,87 @@ var UpdateTrackerQueryAlternatePayload = a.Type("UpdateTrackerQueryAlternatePayl
 var identityDataAttributes = a.Type("IdentityDataAttributes", func() {
 	a.Attribute("fullName", d.String, "The users full name")
 	a.Attribute("imageURL", d.String, "The avatar image for the user")
+	a.Attribute("bio", d.String, "A short description the user gives of themselves")
+	a.Attribute("timezone", d.String, "The IANA time zone of the user")
+})
+
+// UpdateUserPayload defines the profile attributes of the authenticated user
+// that can be changed. Attributes that are not given are left untouched.
+var UpdateUserPayload = a.Type("UpdateUserPayload", func() {
+	a.Attribute("fullName", d.String, "The users full name", func() {
+		a.MinLength(1)
+	})
+	a.Attribute("imageURL", d.String, "The avatar image for the user", func() {
+		a.Example("https://avatars.githubusercontent.com/u/1")
+	})
+	a.Attribute("bio", d.String, "A short description the user gives of themselves")
+	a.Attribute("timezone", d.String, "The IANA time zone of the user", func() {
+		a.Example("Europe/Berlin")
+	})
+})
+
+// AddUserEmailPayload defines the secondary email to register for the authenticated user
+var AddUserEmailPayload = a.Type("AddUserEmailPayload", func() {
+	a.Attribute("email", d.String, "The email to register", func() {
+		a.Format("email")
+		a.Example("jane.doe@example.com")
+	})
+	a.Required("email")
+})
+
+// VerifyUserEmailPayload carries the code proving that the user owns an email
+var VerifyUserEmailPayload = a.Type("VerifyUserEmailPayload", func() {
+	a.Attribute("code", d.String, "The verification code sent to the email", func() {
+		a.MinLength(1)
+	})
+	a.Required("code")
+})
+
+// MergeUserPayload identifies the identity to merge into the authenticated one
+var MergeUserPayload = a.Type("MergeUserPayload", func() {
+	a.Attribute("token", d.String, `An access token of the identity to merge. Presenting it proves that the
+identity belongs to the same person.`, func() {
+		a.MinLength(1)
+	})
+	a.Required("token")
+})
+
+// RefreshTokenPayload holds the refresh token to exchange for new tokens
+var RefreshTokenPayload = a.Type("RefreshTokenPayload", func() {
+	a.Attribute("refresh_token", d.String, "The refresh token handed out along with the login token", func() {
+		a.MinLength(1)
+	})
+	a.Required("refresh_token")
+})
+
+// SetPasswordPayload holds the new password for the local login of the authenticated user
+var SetPasswordPayload = a.Type("SetPasswordPayload", func() {
+	a.Attribute("current_password", d.String, "The password set so far. Required unless no password was set yet.")
+	a.Attribute("new_password", d.String, "The password to set", func() {
+		a.MinLength(8)
+	})
+	a.Required("new_password")
+})
+
+// CreatePasswordResetPayload identifies the email to create a password reset code for
+var CreatePasswordResetPayload = a.Type("CreatePasswordResetPayload", func() {
+	a.Attribute("email", d.String, "The email the user logs in with", func() {
+		a.Format("email")
+	})
+	a.Attribute("fullName", d.String, "The full name of the new identity if the email is not known yet")
+	a.Required("email")
+})
+
+// ResetPasswordPayload carries the password reset code and the new password
+var ResetPasswordPayload = a.Type("ResetPasswordPayload", func() {
+	a.Attribute("email", d.String, "The email the user logs in with")
+	a.Attribute("code", d.String, "The password reset code", func() {
+		a.MinLength(1)
+	})
+	a.Attribute("password", d.String, "The password to set", func() {
+		a.MinLength(8)
+	})
+	a.Required("email", "code", "password")
 })
 
 // identityData represents an identified user object
@@ -89,3 +89,25 @@ func (err NotFoundError) Error() string {
 func NewNotFoundError(entity string, id string) NotFoundError {
 	return NotFoundError{entity: entity, ID: id}
 }
+
+// ForbiddenError means that the identity is known but lacks the permission
+// required for the operation
+type ForbiddenError struct {
+	simpleError
+}
+
+// NewForbiddenError returns the custom defined error of type ForbiddenError.
+func NewForbiddenError(msg string) ForbiddenError {
+	return ForbiddenError{simpleError{msg}}
+}
+
+// UnauthorizedError means that the credentials presented for the operation
+// are missing or not valid (anymore)
+type UnauthorizedError struct {
+	simpleError
+}
+
+// NewUnauthorizedError returns the custom defined error of type UnauthorizedError.
+func NewUnauthorizedError(msg string) UnauthorizedError {
+	return UnauthorizedError{simpleError{msg}}
+}
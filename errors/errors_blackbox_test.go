@@ -47,3 +47,19 @@ func TestNewNotFoundError(t *testing.T) {
 	err := errors.NewNotFoundError(param, value)
 	assert.Equal(t, fmt.Sprintf("%s with id '%s' not found", param, value), err.Error())
 }
+
+func TestNewForbiddenError(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	msg := "missing permission"
+	err := errors.NewForbiddenError(msg)
+	assert.Equal(t, msg, err.Error())
+}
+
+func TestNewUnauthorizedError(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	msg := "access token is revoked"
+	err := errors.NewUnauthorizedError(msg)
+	assert.Equal(t, msg, err.Error())
+}
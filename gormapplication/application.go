@@ -6,10 +6,14 @@ import (
 
 	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/area"
 	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/milestone"
 	"github.com/almighty/almighty-core/project"
 	"github.com/almighty/almighty-core/remoteworkitem"
 	"github.com/almighty/almighty-core/search"
+	"github.com/almighty/almighty-core/team"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
 	"github.com/jinzhu/gorm"
@@ -77,6 +81,31 @@ func (g *GormBase) Projects() project.Repository {
 	return project.NewRepository(g.db)
 }
 
+// ProjectMemberships returns a project membership repository
+func (g *GormBase) ProjectMemberships() project.MembershipRepository {
+	return project.NewMembershipRepository(g.db)
+}
+
+// Iterations returns an iteration repository
+func (g *GormBase) Iterations() iteration.Repository {
+	return iteration.NewRepository(g.db)
+}
+
+// Areas returns an area repository
+func (g *GormBase) Areas() area.Repository {
+	return area.NewRepository(g.db)
+}
+
+// Milestones returns a milestone repository
+func (g *GormBase) Milestones() milestone.Repository {
+	return milestone.NewRepository(g.db)
+}
+
+// Teams returns a team repository
+func (g *GormBase) Teams() team.Repository {
+	return team.NewRepository(g.db)
+}
+
 func (g *GormBase) Trackers() application.TrackerRepository {
 	return remoteworkitem.NewTrackerRepository(g.db)
 }
@@ -92,6 +121,16 @@ func (g *GormBase) Identities() application.IdentityRepository {
 	return account.NewIdentityRepository(g.db)
 }
 
+// Users returns a user repository
+func (g *GormBase) Users() account.UserRepository {
+	return account.NewUserRepository(g.db)
+}
+
+// AccessTokens returns an access token repository
+func (g *GormBase) AccessTokens() account.AccessTokenRepository {
+	return account.NewAccessTokenRepository(g.db)
+}
+
 // WorkItemLinkCategories returns a work item link category repository
 func (g *GormBase) WorkItemLinkCategories() link.WorkItemLinkCategoryRepository {
 	return link.NewWorkItemLinkCategoryRepository(g.db)
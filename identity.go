@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 
+	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
 	"github.com/almighty/almighty-core/jsonapi"
@@ -22,12 +23,24 @@ func NewIdentityController(service *goa.Service, db application.DB) *IdentityCon
 
 // List runs the list action.
 func (c *IdentityController) List(ctx *app.ListIdentityContext) error {
+	offset, limit := computePagingLimits(ctx.PageOffset, ctx.PageLimit)
+	filter := account.IdentityFilter{ProjectID: ctx.FilterProject}
+	if ctx.FilterName != nil {
+		filter.NamePrefix = *ctx.FilterName
+	}
+	if ctx.FilterEmail != nil {
+		filter.EmailPrefix = *ctx.FilterEmail
+	}
 	return application.Transactional(c.db, func(appl application.Application) error {
-		result, err := appl.Identities().List(ctx.Context)
+		result, c, err := appl.Identities().List(ctx.Context, filter, &offset, &limit)
 		if err != nil {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(fmt.Sprintf("Error listing identities: %s", err.Error())))
 			return ctx.InternalServerError(jerrors)
 		}
+		count := int(c)
+		result.Links = &app.PagingLinks{}
+		result.Meta = &app.IdentityArrayMeta{TotalCount: count}
+		setPagingLinks(result.Links, buildAbsoluteURL(ctx.RequestData), len(result.Data), offset, limit, count)
 		return ctx.OK(result)
 	})
 }
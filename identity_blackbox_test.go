@@ -1,6 +1,8 @@
 package main_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	. "github.com/almighty/almighty-core"
@@ -9,6 +11,7 @@ import (
 	"github.com/almighty/almighty-core/app/test"
 	"github.com/almighty/almighty-core/gormapplication"
 	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
 	"github.com/almighty/almighty-core/resource"
 	"github.com/goadesign/goa"
 	uuid "github.com/satori/go.uuid"
@@ -23,7 +26,7 @@ func TestListIdentities(t *testing.T) {
 
 	service := goa.New("Test-Identities")
 	identityController := NewIdentityController(service, gormapplication.NewGormDB(DB))
-	_, ic := test.ListIdentityOK(t, service.Context, service, identityController)
+	_, ic := test.ListIdentityOK(t, service.Context, service, identityController, nil, nil, nil, nil, nil)
 	require.NotNil(t, ic)
 
 	numberOfCurrentIdent := len(ic.Data)
@@ -40,7 +43,7 @@ func TestListIdentities(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	_, ic2 := test.ListIdentityOK(t, service.Context, service, identityController)
+	_, ic2 := test.ListIdentityOK(t, service.Context, service, identityController, nil, nil, nil, nil, nil)
 	require.NotNil(t, ic2)
 
 	assert.Equal(t, numberOfCurrentIdent+1, len(ic2.Data))
@@ -57,7 +60,7 @@ func TestListIdentities(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	_, ic3 := test.ListIdentityOK(t, service.Context, service, identityController)
+	_, ic3 := test.ListIdentityOK(t, service.Context, service, identityController, nil, nil, nil, nil, nil)
 	require.NotNil(t, ic3)
 	assert.Equal(t, numberOfCurrentIdent+2, len(ic3.Data))
 
@@ -65,6 +68,53 @@ func TestListIdentities(t *testing.T) {
 	assertIdent(t, findIdent(identity2.ID, ic3.Data), identity2.FullName, identity2.ImageURL)
 }
 
+func TestListIdentitiesFiltered(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer gormsupport.DeleteCreatedEntities(DB)()
+
+	service := goa.New("Test-Identities")
+	identityController := NewIdentityController(service, gormapplication.NewGormDB(DB))
+	ctx := context.Background()
+	identityRepo := account.NewIdentityRepository(DB)
+	userRepo := account.NewUserRepository(DB)
+
+	prefix := "Filter-" + uuid.NewV4().String()
+	var identities []account.Identity
+	for i := 0; i < 3; i++ {
+		identity := account.Identity{FullName: fmt.Sprintf("%s %d", prefix, i)}
+		require.Nil(t, identityRepo.Create(ctx, &identity))
+		require.Nil(t, userRepo.Create(ctx, &account.User{Email: fmt.Sprintf("%d-%s@example.com", i, prefix), IdentityID: identity.ID}))
+		identities = append(identities, identity)
+	}
+
+	name := strings.ToLower(prefix)
+	limit := 2
+	_, page := test.ListIdentityOK(t, service.Context, service, identityController, nil, &name, nil, &limit, nil)
+	require.Len(t, page.Data, 2)
+	assert.Equal(t, 3, page.Meta.TotalCount)
+	assertIdent(t, page.Data[0], identities[0].FullName, identities[0].ImageURL)
+	require.NotNil(t, page.Links.Next)
+	assert.Contains(t, *page.Links.Next, "page[offset]=2")
+
+	email := "1-" + prefix
+	_, byEmail := test.ListIdentityOK(t, service.Context, service, identityController, &email, nil, nil, nil, nil)
+	require.Len(t, byEmail.Data, 1)
+	assert.Equal(t, identities[1].ID.String(), *byEmail.Data[0].ID)
+
+	// a prefix with LIKE wildcards only matches literally
+	wildcard := "%" + prefix
+	_, none := test.ListIdentityOK(t, service.Context, service, identityController, nil, &wildcard, nil, nil, nil)
+	assert.Len(t, none.Data, 0)
+
+	p, err := project.NewRepository(DB).Create(ctx, prefix)
+	require.Nil(t, err)
+	_, err = project.NewMembershipRepository(DB).Create(ctx, p.ID, identities[2].ID, project.RoleViewer)
+	require.Nil(t, err)
+	_, members := test.ListIdentityOK(t, service.Context, service, identityController, nil, nil, &p.ID, nil, nil)
+	require.Len(t, members.Data, 1)
+	assert.Equal(t, identities[2].ID.String(), *members.Data[0].ID)
+}
+
 func findIdent(id uuid.UUID, idents []*app.IdentityData) *app.IdentityData {
 	for _, ident := range idents {
 		if *ident.ID == id.String() {
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// IterationController implements the iteration resource.
+type IterationController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewIterationController creates an iteration controller.
+func NewIterationController(service *goa.Service, db application.DB) *IterationController {
+	if db == nil {
+		panic("db must not be nil")
+	}
+	return &IterationController{Controller: service.NewController("IterationController"), db: db}
+}
+
+// Show runs the show action.
+func (c *IterationController) Show(ctx *app.ShowIterationContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		i, err := loadIteration(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(&app.Iteration{
+			Data: convertIterationFromModel(ctx.RequestData, i),
+		})
+	})
+}
+
+// Update runs the update action.
+func (c *IterationController) Update(ctx *app.UpdateIterationContext) error {
+	attributes := ctx.Payload.Data.Attributes
+	if attributes == nil || attributes.Version == nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("data.attributes.version", nil).Expected("not nil"))
+		return ctx.BadRequest(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		i, err := loadIteration(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		i.Version = *attributes.Version
+		applyIterationAttributes(i, attributes)
+		i, err = appl.Iterations().Save(ctx.Context, *i)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(&app.Iteration{
+			Data: convertIterationFromModel(ctx.RequestData, i),
+		})
+	})
+}
+
+// Delete runs the delete action.
+func (c *IterationController) Delete(ctx *app.DeleteIterationContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		i, err := loadIteration(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		err = appl.Iterations().Delete(ctx.Context, i.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// applyIterationAttributes copies the attributes given in a payload to the
+// iteration. Attributes that are not given are left untouched.
+func applyIterationAttributes(i *iteration.Iteration, attributes *app.IterationAttributes) {
+	if attributes.Name != nil {
+		i.Name = *attributes.Name
+	}
+	if attributes.StartAt != nil {
+		i.StartAt = attributes.StartAt
+	}
+	if attributes.EndAt != nil {
+		i.EndAt = attributes.EndAt
+	}
+	if attributes.State != nil {
+		i.State = *attributes.State
+	}
+	if attributes.Parent != nil {
+		i.ParentID = attributes.Parent
+	}
+}
+
+// convertIterationFromModel converts between internal and external REST representation
+func convertIterationFromModel(request *goa.RequestData, i *iteration.Iteration) *app.IterationData {
+	selfURL := absoluteURL(request, app.IterationHref(i.ID))
+	return &app.IterationData{
+		ID:   &i.ID,
+		Type: "iterations",
+		Attributes: &app.IterationAttributes{
+			Name:      &i.Name,
+			StartAt:   i.StartAt,
+			EndAt:     i.EndAt,
+			State:     &i.State,
+			Parent:    i.ParentID,
+			Project:   &i.ProjectID,
+			Version:   &i.Version,
+			CreatedAt: &i.CreatedAt,
+			UpdatedAt: &i.UpdatedAt,
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
+
+// loadIteration loads the iteration with the given ID, treating an ID that is
+// not a valid UUID like an unknown one.
+func loadIteration(ctx context.Context, appl application.Application, id string) (*iteration.Iteration, error) {
+	iterationID, err := uuid.FromString(id)
+	if err != nil {
+		return nil, errors.NewNotFoundError("iteration", id)
+	}
+	return appl.Iterations().Load(ctx, iterationID)
+}
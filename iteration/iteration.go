@@ -0,0 +1,258 @@
+package iteration
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// States an iteration can be in
+const (
+	StateNew   = "new"
+	StateStart = "start"
+	StateClose = "close"
+)
+
+// States holds all known iteration states
+var States = []string{StateNew, StateStart, StateClose}
+
+// IsValidState returns true if the given state is one of the known states
+func IsValidState(state string) bool {
+	for _, s := range States {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// Iteration is a time box of a project in which work items are planned.
+// Iterations can be nested in a parent iteration of the same project.
+type Iteration struct {
+	gormsupport.Lifecycle
+	ID        uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	Version   int
+	ProjectID uuid.UUID  `sql:"type:uuid"`
+	ParentID  *uuid.UUID `sql:"type:uuid"`
+	Name      string
+	StartAt   *time.Time
+	EndAt     *time.Time
+	State     string
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (i Iteration) TableName() string {
+	return "iterations"
+}
+
+// IsCurrent returns true if the iteration has been started or, while still
+// new, its dates span the given point in time. Closed iterations are never
+// current.
+func (i Iteration) IsCurrent(now time.Time) bool {
+	switch i.State {
+	case StateStart:
+		return true
+	case StateNew:
+		return i.StartAt != nil && i.EndAt != nil && !now.Before(*i.StartAt) && now.Before(*i.EndAt)
+	}
+	return false
+}
+
+// InIterations returns an expression that matches all work items planned for
+// one of the given iterations. No work item matches an empty list.
+func InIterations(iterations []*Iteration) criteria.Expression {
+	if len(iterations) == 0 {
+		return criteria.Literal(false)
+	}
+	var result criteria.Expression
+	for _, i := range iterations {
+		current := criteria.Equals(criteria.Field(workitem.SystemIteration), criteria.Literal(i.ID.String()))
+		if result == nil {
+			result = current
+		} else {
+			result = criteria.Or(result, current)
+		}
+	}
+	return result
+}
+
+// Repository encapsulate storage & retrieval of iterations
+type Repository interface {
+	Create(ctx context.Context, i *Iteration) error
+	Load(ctx context.Context, id uuid.UUID) (*Iteration, error)
+	Save(ctx context.Context, i Iteration) (*Iteration, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, projectID uuid.UUID) ([]*Iteration, error)
+	Current(ctx context.Context, projectID uuid.UUID, now time.Time) ([]*Iteration, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for iterations.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Create creates a new iteration. New iterations start in state "new" unless
+// a state is given.
+// returns BadParameterError or InternalError
+func (r *GormRepository) Create(ctx context.Context, i *Iteration) error {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration", "create"}, time.Now())
+
+	if i.State == "" {
+		i.State = StateNew
+	}
+	i.ID = uuid.NewV4()
+	if err := r.validate(ctx, *i); err != nil {
+		return err
+	}
+	tx := r.db.Create(i)
+	if err := tx.Error; err != nil {
+		return convertError(tx.Error, *i)
+	}
+	return nil
+}
+
+// Load returns the iteration for the given id
+// returns NotFoundError or InternalError
+func (r *GormRepository) Load(ctx context.Context, id uuid.UUID) (*Iteration, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration", "load"}, time.Now())
+
+	res := Iteration{}
+	tx := r.db.Where("id = ?", id).First(&res)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("iteration", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &res, nil
+}
+
+// Save updates the given iteration in the db. Version must be the same as the
+// one in the stored version. The project of an iteration can not be changed.
+// returns NotFoundError, BadParameterError, VersionConflictError or InternalError
+func (r *GormRepository) Save(ctx context.Context, i Iteration) (*Iteration, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration", "save"}, time.Now())
+
+	existing, err := r.Load(ctx, i.ID)
+	if err != nil {
+		return nil, err
+	}
+	i.ProjectID = existing.ProjectID
+	if err := r.validate(ctx, i); err != nil {
+		return nil, err
+	}
+	oldVersion := i.Version
+	i.Version++
+	tx := r.db.Where("Version = ?", oldVersion).Save(&i)
+	if err := tx.Error; err != nil {
+		return nil, convertError(tx.Error, i)
+	}
+	if tx.RowsAffected == 0 {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+	return &i, nil
+}
+
+// Delete deletes the iteration with the given id. Iterations that have child
+// iterations can not be deleted.
+// returns NotFoundError, BadParameterError or InternalError
+func (r *GormRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration", "delete"}, time.Now())
+
+	var children int
+	if err := r.db.Model(&Iteration{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if children > 0 {
+		return errors.NewBadParameterError("iteration", id.String()).Expected("no child iterations")
+	}
+	tx := r.db.Delete(Iteration{ID: id})
+	if err := tx.Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("iteration", id.String())
+	}
+	return nil
+}
+
+// List returns all iterations of the project ordered by their start date
+// returns InternalError
+func (r *GormRepository) List(ctx context.Context, projectID uuid.UUID) ([]*Iteration, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration", "list"}, time.Now())
+
+	var rows []*Iteration
+	if err := r.db.Where("project_id = ?", projectID).Order("start_at, created_at").Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return rows, nil
+}
+
+// Current returns the iterations of the project that are current at the given
+// point in time, see Iteration.IsCurrent
+// returns InternalError
+func (r *GormRepository) Current(ctx context.Context, projectID uuid.UUID, now time.Time) ([]*Iteration, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration", "current"}, time.Now())
+
+	var rows []*Iteration
+	tx := r.db.Where("project_id = ? AND (state = ? OR (state = ? AND start_at <= ? AND end_at > ?))", projectID, StateStart, StateNew, now, now)
+	if err := tx.Order("start_at, created_at").Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return rows, nil
+}
+
+// validate checks the state, the dates and the parent of the iteration. The
+// parent must belong to the same project and must not be a descendant of the
+// iteration itself.
+func (r *GormRepository) validate(ctx context.Context, i Iteration) error {
+	if !IsValidState(i.State) {
+		return errors.NewBadParameterError("state", i.State).Expected(States)
+	}
+	if i.StartAt != nil && i.EndAt != nil && !i.EndAt.After(*i.StartAt) {
+		return errors.NewBadParameterError("endAt", *i.EndAt).Expected("after startAt")
+	}
+	parentID := i.ParentID
+	for parentID != nil {
+		if uuid.Equal(*parentID, i.ID) {
+			return errors.NewBadParameterError("parent", i.ParentID.String()).Expected("not the iteration itself or one of its children")
+		}
+		parent, err := r.Load(ctx, *parentID)
+		if err != nil {
+			if _, ok := err.(errors.NotFoundError); ok {
+				return errors.NewBadParameterError("parent", i.ParentID.String()).Expected("existing iteration")
+			}
+			return err
+		}
+		if !uuid.Equal(parent.ProjectID, i.ProjectID) {
+			return errors.NewBadParameterError("parent", i.ParentID.String()).Expected("iteration of the same project")
+		}
+		parentID = parent.ParentID
+	}
+	return nil
+}
+
+// convertError turns constraint violations into BadParameterErrors
+func convertError(err error, i Iteration) error {
+	if gormsupport.IsCheckViolation(err, "iterations_name_check") {
+		return errors.NewBadParameterError("name", i.Name).Expected("not empty")
+	}
+	if gormsupport.IsUniqueViolation(err, "iterations_project_name_idx") {
+		return errors.NewBadParameterError("name", i.Name).Expected("unique in the project")
+	}
+	return errors.NewInternalError(err.Error())
+}
@@ -0,0 +1,202 @@
+package iteration_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+func TestRunIterationRepoBBTest(t *testing.T) {
+	suite.Run(t, &iterationRepoBBTest{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+type iterationRepoBBTest struct {
+	gormsupport.DBTestSuite
+	clean   func()
+	repo    iteration.Repository
+	project *project.Project
+}
+
+func (test *iterationRepoBBTest) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+	test.repo = iteration.NewRepository(test.DB)
+	p, err := project.NewRepository(test.DB).Create(context.Background(), "iteration-test-"+uuid.NewV4().String())
+	require.Nil(test.T(), err)
+	test.project = p
+}
+
+func (test *iterationRepoBBTest) TearDownTest() {
+	test.clean()
+}
+
+func (test *iterationRepoBBTest) create(name string, parentID *uuid.UUID) *iteration.Iteration {
+	i := iteration.Iteration{ProjectID: test.project.ID, ParentID: parentID, Name: name}
+	require.Nil(test.T(), test.repo.Create(context.Background(), &i))
+	return &i
+}
+
+func (test *iterationRepoBBTest) TestCreateAndLoad() {
+	start := time.Now().Add(-time.Hour).Round(time.Microsecond).UTC()
+	end := start.Add(14 * 24 * time.Hour)
+	i := iteration.Iteration{ProjectID: test.project.ID, Name: "Sprint 1", StartAt: &start, EndAt: &end}
+	require.Nil(test.T(), test.repo.Create(context.Background(), &i))
+	assert.Equal(test.T(), iteration.StateNew, i.State)
+
+	loaded, err := test.repo.Load(context.Background(), i.ID)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), "Sprint 1", loaded.Name)
+	assert.Equal(test.T(), test.project.ID, loaded.ProjectID)
+	assert.True(test.T(), start.Equal(*loaded.StartAt))
+
+	_, err = test.repo.Load(context.Background(), uuid.NewV4())
+	assert.IsType(test.T(), errors.NotFoundError{}, err)
+}
+
+func (test *iterationRepoBBTest) TestCreateFail() {
+	start := time.Now()
+	end := start.Add(-time.Hour)
+	err := test.repo.Create(context.Background(), &iteration.Iteration{ProjectID: test.project.ID, Name: "backwards", StartAt: &start, EndAt: &end})
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+
+	err = test.repo.Create(context.Background(), &iteration.Iteration{ProjectID: test.project.ID, Name: "unknown state", State: "done"})
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+
+	err = test.repo.Create(context.Background(), &iteration.Iteration{ProjectID: test.project.ID, Name: ""})
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+
+	test.create("Sprint 1", nil)
+	err = test.repo.Create(context.Background(), &iteration.Iteration{ProjectID: test.project.ID, Name: "Sprint 1"})
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+
+	unknownParent := uuid.NewV4()
+	err = test.repo.Create(context.Background(), &iteration.Iteration{ProjectID: test.project.ID, Name: "orphan", ParentID: &unknownParent})
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+}
+
+func (test *iterationRepoBBTest) TestParentInOtherProject() {
+	other, err := project.NewRepository(test.DB).Create(context.Background(), "iteration-test-"+uuid.NewV4().String())
+	require.Nil(test.T(), err)
+	parent := iteration.Iteration{ProjectID: other.ID, Name: "Release 1"}
+	require.Nil(test.T(), test.repo.Create(context.Background(), &parent))
+
+	err = test.repo.Create(context.Background(), &iteration.Iteration{ProjectID: test.project.ID, Name: "Sprint 1", ParentID: &parent.ID})
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+}
+
+func (test *iterationRepoBBTest) TestSaveRejectsCycles() {
+	release := test.create("Release 1", nil)
+	sprint := test.create("Sprint 1", &release.ID)
+
+	release.ParentID = &sprint.ID
+	_, err := test.repo.Save(context.Background(), *release)
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+
+	sprint.State = iteration.StateStart
+	saved, err := test.repo.Save(context.Background(), *sprint)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), iteration.StateStart, saved.State)
+	assert.Equal(test.T(), sprint.Version+1, saved.Version)
+
+	_, err = test.repo.Save(context.Background(), *sprint)
+	assert.IsType(test.T(), errors.VersionConflictError{}, err)
+}
+
+func (test *iterationRepoBBTest) TestListAndDelete() {
+	release := test.create("Release 1", nil)
+	sprint := test.create("Sprint 1", &release.ID)
+
+	iterations, err := test.repo.List(context.Background(), test.project.ID)
+	require.Nil(test.T(), err)
+	assert.Len(test.T(), iterations, 2)
+
+	assert.IsType(test.T(), errors.BadParameterError{}, test.repo.Delete(context.Background(), release.ID))
+	require.Nil(test.T(), test.repo.Delete(context.Background(), sprint.ID))
+	require.Nil(test.T(), test.repo.Delete(context.Background(), release.ID))
+	assert.IsType(test.T(), errors.NotFoundError{}, test.repo.Delete(context.Background(), release.ID))
+}
+
+func (test *iterationRepoBBTest) TestCurrent() {
+	now := time.Now()
+	started := test.create("started", nil)
+	started.State = iteration.StateStart
+	_, err := test.repo.Save(context.Background(), *started)
+	require.Nil(test.T(), err)
+
+	start := now.Add(-time.Hour)
+	end := now.Add(time.Hour)
+	scheduled := iteration.Iteration{ProjectID: test.project.ID, Name: "scheduled", StartAt: &start, EndAt: &end}
+	require.Nil(test.T(), test.repo.Create(context.Background(), &scheduled))
+	closed := iteration.Iteration{ProjectID: test.project.ID, Name: "closed", StartAt: &start, EndAt: &end, State: iteration.StateClose}
+	require.Nil(test.T(), test.repo.Create(context.Background(), &closed))
+	test.create("unscheduled", nil)
+
+	current, err := test.repo.Current(context.Background(), test.project.ID, now)
+	require.Nil(test.T(), err)
+	require.Len(test.T(), current, 2)
+	for _, i := range current {
+		assert.True(test.T(), i.IsCurrent(now))
+	}
+}
+
+func (test *iterationRepoBBTest) TestInIterations() {
+	sprint := test.create("Sprint 1", nil)
+	wir := workitem.NewWorkItemRepository(test.DB)
+	planned, err := wir.Create(context.Background(), test.project.ID, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle:     "planned",
+		workitem.SystemState:     workitem.SystemStateNew,
+		workitem.SystemIteration: sprint.ID.String(),
+	}, "xx")
+	require.Nil(test.T(), err)
+	_, err = wir.Create(context.Background(), test.project.ID, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle: "unplanned",
+		workitem.SystemState: workitem.SystemStateNew,
+	}, "xx")
+	require.Nil(test.T(), err)
+
+	inProject := criteria.Equals(criteria.Field("Project"), criteria.Literal(test.project.ID.String()))
+	items, _, err := wir.List(context.Background(), criteria.And(inProject, iteration.InIterations([]*iteration.Iteration{sprint})), nil, nil)
+	require.Nil(test.T(), err)
+	require.Len(test.T(), items, 1)
+	assert.Equal(test.T(), planned.ID, items[0].ID)
+
+	items, _, err = wir.List(context.Background(), criteria.And(inProject, iteration.InIterations(nil)), nil, nil)
+	require.Nil(test.T(), err)
+	assert.Len(test.T(), items, 0)
+}
+
+func (test *iterationRepoBBTest) TestWorkItemIterationReference() {
+	sprint := test.create("Sprint 1", nil)
+	other, err := project.NewRepository(test.DB).Create(context.Background(), "iteration-test-"+uuid.NewV4().String())
+	require.Nil(test.T(), err)
+	wir := workitem.NewWorkItemRepository(test.DB)
+	fields := func(iterationID string) map[string]interface{} {
+		return map[string]interface{}{
+			workitem.SystemTitle:     "planned",
+			workitem.SystemState:     workitem.SystemStateNew,
+			workitem.SystemIteration: iterationID,
+		}
+	}
+
+	// the iteration has to exist in the project of the work item
+	_, err = wir.Create(context.Background(), test.project.ID, workitem.SystemBug, fields(uuid.NewV4().String()), "xx")
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+	_, err = wir.Create(context.Background(), other.ID, workitem.SystemBug, fields(sprint.ID.String()), "xx")
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+
+	wi, err := wir.Create(context.Background(), test.project.ID, workitem.SystemBug, fields(sprint.ID.String()), "xx")
+	require.Nil(test.T(), err)
+	wi.Fields[workitem.SystemIteration] = uuid.NewV4().String()
+	_, err = wir.Save(context.Background(), *wi)
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+}
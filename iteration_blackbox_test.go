@@ -0,0 +1,153 @@
+package main_test
+
+import (
+	"testing"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/resource"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestIterationREST struct {
+	gormsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	clean func()
+}
+
+func TestRunIterationREST(t *testing.T) {
+	suite.Run(t, &TestIterationREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestIterationREST) SetupTest() {
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = gormsupport.DeleteCreatedEntities(rest.DB)
+}
+
+func (rest *TestIterationREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestIterationREST) service() *goa.Service {
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+	return testsupport.ServiceAsUser("Iteration-Service", almtoken.NewManager(pub, priv), account.TestIdentity)
+}
+
+func createIterationPayload(name string, parent *uuid.UUID) *app.CreateIterationPayload {
+	return &app.CreateIterationPayload{
+		Data: &app.IterationData{
+			Type: "iterations",
+			Attributes: &app.IterationAttributes{
+				Name:   &name,
+				Parent: parent,
+			},
+		},
+	}
+}
+
+func (rest *TestIterationREST) TestCreateUpdateAndDelete() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc := rest.service()
+	name := "TestIterationREST-" + uuid.NewV4().String()
+	_, p := test.CreateProjectCreated(t, svc.Context, svc, NewProjectController(svc, rest.db), createProjectPayload(&name, nil))
+	projectID := p.Data.ID.String()
+	projectCtrl := NewProjectIterationsController(svc, rest.db)
+	ctrl := NewIterationController(svc, rest.db)
+
+	_, release := test.CreateProjectIterationsCreated(t, svc.Context, svc, projectCtrl, projectID, createIterationPayload("Release 1", nil))
+	_, sprint := test.CreateProjectIterationsCreated(t, svc.Context, svc, projectCtrl, projectID, createIterationPayload("Sprint 1", release.Data.ID))
+	assert.Equal(t, iteration.StateNew, *sprint.Data.Attributes.State)
+	assert.Equal(t, *release.Data.ID, *sprint.Data.Attributes.Parent)
+	assert.Equal(t, *p.Data.ID, *sprint.Data.Attributes.Project)
+
+	test.CreateProjectIterationsBadRequest(t, svc.Context, svc, projectCtrl, projectID, &app.CreateIterationPayload{
+		Data: &app.IterationData{Type: "iterations", Attributes: &app.IterationAttributes{}},
+	})
+
+	state := iteration.StateStart
+	_, updated := test.UpdateIterationOK(t, svc.Context, svc, ctrl, sprint.Data.ID.String(), &app.UpdateIterationPayload{
+		Data: &app.IterationData{
+			Type: "iterations",
+			Attributes: &app.IterationAttributes{
+				State:   &state,
+				Version: sprint.Data.Attributes.Version,
+			},
+		},
+	})
+	assert.Equal(t, iteration.StateStart, *updated.Data.Attributes.State)
+
+	current := true
+	_, list := test.ListProjectIterationsOK(t, svc.Context, svc, projectCtrl, projectID, &current)
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, *sprint.Data.ID, *list.Data[0].ID)
+	_, list = test.ListProjectIterationsOK(t, svc.Context, svc, projectCtrl, projectID, nil)
+	assert.Len(t, list.Data, 2)
+
+	test.DeleteIterationBadRequest(t, svc.Context, svc, ctrl, release.Data.ID.String())
+	test.DeleteIterationOK(t, svc.Context, svc, ctrl, sprint.Data.ID.String())
+	test.ShowIterationNotFound(t, svc.Context, svc, ctrl, sprint.Data.ID.String())
+	test.ShowIterationNotFound(t, svc.Context, svc, ctrl, "not-a-uuid")
+}
+
+func (rest *TestIterationREST) TestListWorkItemsInCurrentIteration() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc := rest.service()
+	name := "TestIterationREST-" + uuid.NewV4().String()
+	_, p := test.CreateProjectCreated(t, svc.Context, svc, NewProjectController(svc, rest.db), createProjectPayload(&name, nil))
+	projectID := p.Data.ID.String()
+	_, sprint := test.CreateProjectIterationsCreated(t, svc.Context, svc, NewProjectIterationsController(svc, rest.db), projectID, createIterationPayload("Sprint 1", nil))
+	state := iteration.StateStart
+	test.UpdateIterationOK(t, svc.Context, svc, NewIterationController(svc, rest.db), sprint.Data.ID.String(), &app.UpdateIterationPayload{
+		Data: &app.IterationData{
+			Type:       "iterations",
+			Attributes: &app.IterationAttributes{State: &state, Version: sprint.Data.Attributes.Version},
+		},
+	})
+
+	ctrl := NewProjectWorkItemsController(svc, rest.db)
+	_, planned := test.CreateProjectWorkItemsCreated(t, svc.Context, svc, ctrl, projectID, &app.CreateWorkItemPayload{
+		Type: workitem.SystemBug,
+		Fields: map[string]interface{}{
+			workitem.SystemTitle:     "planned",
+			workitem.SystemState:     workitem.SystemStateNew,
+			workitem.SystemIteration: sprint.Data.ID.String(),
+		},
+	})
+	test.CreateProjectWorkItemsCreated(t, svc.Context, svc, ctrl, projectID, &app.CreateWorkItemPayload{
+		Type: workitem.SystemBug,
+		Fields: map[string]interface{}{
+			workitem.SystemTitle: "unplanned",
+			workitem.SystemState: workitem.SystemStateNew,
+		},
+	})
+
+	current := "current"
+	_, list := test.ListProjectWorkItemsOK(t, svc.Context, svc, ctrl, projectID, nil, nil, &current, nil, nil)
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, planned.ID, list.Data[0].ID)
+
+	sprintID := sprint.Data.ID.String()
+	_, list = test.ListProjectWorkItemsOK(t, svc.Context, svc, ctrl, projectID, nil, nil, &sprintID, nil, nil)
+	require.Len(t, list.Data, 1)
+
+	unknown := uuid.NewV4().String()
+	test.ListProjectWorkItemsNotFound(t, svc.Context, svc, ctrl, projectID, nil, nil, &unknown, nil, nil)
+}
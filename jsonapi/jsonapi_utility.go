@@ -18,6 +18,7 @@ const (
 	ErrorCodeConversionError   = "conversion_error"
 	ErrorCodeInternalError     = "internal_error"
 	ErrorCodeUnauthorizedError = "unauthorized_error"
+	ErrorCodeForbiddenError    = "forbidden_error"
 	ErrorCodeJWTSecurityError  = "jwt_security_error"
 )
 
@@ -47,6 +48,14 @@ func ErrorToJSONAPIError(err error) (app.JSONAPIError, int) {
 		code = ErrorCodeVersionConflict
 		title = "Version conflict error"
 		statusCode = http.StatusBadRequest
+	case errors.UnauthorizedError:
+		code = ErrorCodeUnauthorizedError
+		title = "Unauthorized error"
+		statusCode = http.StatusUnauthorized
+	case errors.ForbiddenError:
+		code = ErrorCodeForbiddenError
+		title = "Forbidden error"
+		statusCode = http.StatusForbidden
 	case errors.InternalError:
 		code = ErrorCodeInternalError
 		title = "Internal error"
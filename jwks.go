@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/token"
+	"github.com/goadesign/goa"
+)
+
+// JwksController implements the jwks resource.
+type JwksController struct {
+	*goa.Controller
+	tokenManager token.Manager
+}
+
+// NewJwksController creates a jwks controller.
+func NewJwksController(service *goa.Service, tokenManager token.Manager) *JwksController {
+	return &JwksController{
+		Controller:   service.NewController("JwksController"),
+		tokenManager: tokenManager,
+	}
+}
+
+// Show runs the show action.
+func (c *JwksController) Show(ctx *app.ShowJwksContext) error {
+	res := &app.JSONWebKeySet{Keys: []*app.JSONWebKey{}}
+	for _, key := range c.tokenManager.PublicKeys() {
+		res.Keys = append(res.Keys, &app.JSONWebKey{
+			Kty: "RSA",
+			Alg: "RS256",
+			Use: "sig",
+			Kid: key.KeyID,
+			N:   key.Modulus(),
+			E:   key.Exponent(),
+		})
+	}
+	return ctx.OK(res)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/resource"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/goadesign/goa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowJwksOK(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+	controller := NewJwksController(goa.New("TestShowJwksService"), almtoken.NewManager(pub, priv))
+	_, res := test.ShowJwksOK(t, nil, nil, controller)
+
+	require.Len(t, res.Keys, 1)
+	key := res.Keys[0]
+	assert.Equal(t, "RSA", key.Kty)
+	assert.Equal(t, almtoken.KeyID(pub), key.Kid)
+
+	// the key can be restored from its JSON Web Key
+	n, err := base64.RawURLEncoding.DecodeString(key.N)
+	require.Nil(t, err)
+	e, err := base64.RawURLEncoding.DecodeString(key.E)
+	require.Nil(t, err)
+	restored := rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	assert.Equal(t, 0, pub.N.Cmp(restored.N))
+	assert.Equal(t, pub.E, restored.E)
+}
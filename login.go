@@ -2,14 +2,19 @@ package main
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
 	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/login"
 	"github.com/almighty/almighty-core/token"
 	"github.com/goadesign/goa"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
 	uuid "github.com/satori/go.uuid"
 )
 
@@ -18,11 +23,12 @@ type LoginController struct {
 	*goa.Controller
 	auth         login.Service
 	tokenManager token.Manager
+	db           application.DB
 }
 
 // NewLoginController creates a login controller.
-func NewLoginController(service *goa.Service, auth login.Service, tokenManager token.Manager) *LoginController {
-	return &LoginController{Controller: service.NewController("login"), auth: auth, tokenManager: tokenManager}
+func NewLoginController(service *goa.Service, auth login.Service, tokenManager token.Manager, db application.DB) *LoginController {
+	return &LoginController{Controller: service.NewController("login"), auth: auth, tokenManager: tokenManager, db: db}
 }
 
 // Authorize runs the authorize action.
@@ -59,3 +65,101 @@ func (c *LoginController) Generate(ctx *app.GenerateLoginContext) error {
 	}
 	return ctx.OK(tokens)
 }
+
+// Refresh runs the refresh action.
+func (c *LoginController) Refresh(ctx *app.RefreshLoginContext) error {
+	identityID, err := c.tokenManager.Refresh(ctx, ctx.Payload.RefreshToken)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(fmt.Sprintf("Invalid refresh token: %s", err.Error())))
+		return ctx.Unauthorized(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		// load the identity again so that the new tokens carry the current
+		// profile and identities that got merged away cannot refresh
+		ident, err := loadIdentity(ctx, appl, identityID)
+		if _, ok := err.(errors.NotFoundError); ok {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(fmt.Sprintf("Refresh token contains id %s of unknown Identity", identityID)))
+			return ctx.Unauthorized(jerrors)
+		}
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.InternalServerError(jerrors)
+		}
+		tokenStr, refreshTokenStr, err := c.tokenManager.GeneratePair(*ident)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(fmt.Sprintf("Failed to generate token: %s", err.Error())))
+			return ctx.InternalServerError(jerrors)
+		}
+		return ctx.OK(&app.AuthToken{Token: tokenStr, RefreshToken: &refreshTokenStr})
+	})
+}
+
+// Logout runs the logout action.
+func (c *LoginController) Logout(ctx *app.LogoutLoginContext) error {
+	if accessTokenID, ok := token.AccessTokenID(ctx); ok {
+		return application.Transactional(c.db, func(appl application.Application) error {
+			if err := appl.AccessTokens().Revoke(ctx, accessTokenID); err != nil {
+				jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+				return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+			}
+			return ctx.OK([]byte{})
+		})
+	}
+	tk := goajwt.ContextJWT(ctx)
+	if tk == nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized("Missing token"))
+		return ctx.Unauthorized(jerrors)
+	}
+	if err := c.tokenManager.Revoke(ctx, tk); err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(fmt.Sprintf("Failed to revoke token: %s", err.Error())))
+		return ctx.InternalServerError(jerrors)
+	}
+	return ctx.OK([]byte{})
+}
+
+// CreatePasswordReset runs the createPasswordReset action. Unknown emails are
+// registered for a new identity, so that users can be invited to the local
+// login.
+func (c *LoginController) CreatePasswordReset(ctx *app.CreatePasswordResetLoginContext) error {
+	email := strings.TrimSpace(ctx.Payload.Email)
+	return application.Transactional(c.db, func(appl application.Application) error {
+		users, err := appl.Users().Query(account.UserByEmails([]string{email}), account.UserVerified())
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewInternalError(err.Error()))
+			return ctx.InternalServerError(jerrors)
+		}
+		if len(users) == 0 {
+			ident := account.Identity{FullName: email}
+			if ctx.Payload.FullName != nil && strings.TrimSpace(*ctx.Payload.FullName) != "" {
+				ident.FullName = strings.TrimSpace(*ctx.Payload.FullName)
+			}
+			if err := appl.Identities().Create(ctx, &ident); err != nil {
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewInternalError(err.Error()))
+				return ctx.InternalServerError(jerrors)
+			}
+			if err := appl.Users().Create(ctx, &account.User{Email: email, Identity: ident, Primary: true, Verified: true}); err != nil {
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewInternalError(err.Error()))
+				return ctx.InternalServerError(jerrors)
+			}
+		}
+		expiresAt := time.Now().Add(configuration.GetLoginLocalResetLifetime())
+		code, err := appl.Users().CreatePasswordReset(ctx, email, expiresAt)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.Created(&app.PasswordReset{Email: email, Code: code, ExpiresAt: expiresAt})
+	})
+}
+
+// ResetPassword runs the resetPassword action.
+func (c *LoginController) ResetPassword(ctx *app.ResetPasswordLoginContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		err := appl.Users().ResetPassword(ctx, ctx.Payload.Email, ctx.Payload.Code, ctx.Payload.Password)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK([]byte{})
+	})
+}
@@ -0,0 +1,61 @@
+package login
+
+import (
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/token"
+)
+
+// NewLocalLogin creates a new login.Service that checks the email and
+// password of the user against the ones stored for the local login, for
+// installations that can not reach any login provider.
+// The credentials are expected as HTTP basic authentication of the authorize
+// request. Requests without them are answered with a challenge, so that the
+// browser asks the user for them.
+func NewLocalLogin(users account.UserRepository, tokenManager token.Manager, states *States, lockout account.LockoutPolicy) Service {
+	return &localLogin{
+		users:        users,
+		tokenManager: tokenManager,
+		states:       states,
+		lockout:      lockout,
+	}
+}
+
+type localLogin struct {
+	users        account.UserRepository
+	tokenManager token.Manager
+	states       *States
+	lockout      account.LockoutPolicy
+}
+
+func (l *localLogin) Perform(ctx *app.AuthorizeLoginContext) error {
+	referer := ctx.RequestData.Header.Get("Referer")
+	if !l.states.IsAllowed(referer) {
+		return sendError(ctx, errors.NewBadParameterError("referer", referer))
+	}
+	email, password, ok := ctx.RequestData.BasicAuth()
+	if !ok {
+		return l.challenge(ctx, errors.NewUnauthorizedError("email and password required"))
+	}
+	user, err := l.users.Authenticate(ctx, email, password, l.lockout)
+	if err != nil {
+		if _, ok := err.(errors.UnauthorizedError); ok {
+			return l.challenge(ctx, err)
+		}
+		return sendError(ctx, err)
+	}
+
+	almtoken, refreshToken, err := l.tokenManager.GeneratePair(user.Identity)
+	if err != nil {
+		return sendError(ctx, errors.NewInternalError(err.Error()))
+	}
+	ctx.ResponseData.Header().Set("Location", referer+"?token="+almtoken+"&refresh_token="+refreshToken)
+	return ctx.TemporaryRedirect()
+}
+
+// challenge responds with the error and asks for the credentials
+func (l *localLogin) challenge(ctx *app.AuthorizeLoginContext, err error) error {
+	ctx.ResponseData.Header().Set("WWW-Authenticate", `Basic realm="almighty", charset="UTF-8"`)
+	return sendError(ctx, err)
+}
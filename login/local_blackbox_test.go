@@ -0,0 +1,81 @@
+package login_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	. "github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/token"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// authorizeLocal performs the login action with the given credentials, if any
+func authorizeLocal(t *testing.T, service Service, referer, email, password string) *httptest.ResponseRecorder {
+	rw := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/login/authorize", nil)
+	require.Nil(t, err)
+	req.Header.Add("referer", referer)
+	if email != "" {
+		req.SetBasicAuth(email, password)
+	}
+	goaCtx := goa.NewContext(goa.WithAction(context.Background(), "LoginTest"), rw, req, url.Values{"provider": {"local"}})
+	authorizeCtx, err := app.NewAuthorizeLoginContext(goaCtx, goa.New("LoginService"))
+	require.Nil(t, err)
+	service.Perform(authorizeCtx)
+	return rw
+}
+
+func TestLocalLogin(t *testing.T) {
+	resource.Require(t, resource.Database)
+	ctx := context.Background()
+	users := account.NewUserRepository(db)
+	identity := account.Identity{FullName: "Local User"}
+	require.Nil(t, account.NewIdentityRepository(db).Create(ctx, &identity))
+	user := account.User{Email: "local-" + uuid.NewV4().String() + "@example.com", IdentityID: identity.ID, Primary: true, Verified: true}
+	require.Nil(t, users.Create(ctx, &user))
+	require.Nil(t, users.SetPassword(ctx, user.ID, "correct horse"))
+
+	publicKey, _ := token.ParsePublicKey([]byte(token.RSAPublicKey))
+	privateKey, _ := token.ParsePrivateKey([]byte(token.RSAPrivateKey))
+	tokenManager := token.NewManager(publicKey, privateKey)
+	service := NewLocalLogin(users, tokenManager, loginStates, account.LockoutPolicy{MaxFailures: 2, Duration: time.Hour})
+	referer := "https://alm-url.example.org/path"
+
+	// the browser is asked for the credentials
+	rw := authorizeLocal(t, service, referer, "", "")
+	assert.Equal(t, 401, rw.Code)
+	assert.Contains(t, rw.Header().Get("WWW-Authenticate"), "Basic")
+
+	rw = authorizeLocal(t, service, "https://evil.example.com/", user.Email, "correct horse")
+	assert.Equal(t, 400, rw.Code)
+
+	rw = authorizeLocal(t, service, referer, user.Email, "correct horse")
+	require.Equal(t, 307, rw.Code)
+	location, err := url.Parse(rw.Header().Get("Location"))
+	require.Nil(t, err)
+	assert.Equal(t, "alm-url.example.org", location.Host)
+	assert.NotEmpty(t, location.Query().Get("refresh_token"))
+	ident, err := tokenManager.Extract(location.Query().Get("token"))
+	require.Nil(t, err)
+	assert.Equal(t, identity.ID, ident.ID)
+
+	for i := 0; i < 2; i++ {
+		rw = authorizeLocal(t, service, referer, user.Email, "wrong horse")
+		assert.Equal(t, 401, rw.Code)
+	}
+	// locked after too many failures
+	rw = authorizeLocal(t, service, referer, user.Email, "correct horse")
+	assert.Equal(t, 401, rw.Code)
+	assert.Contains(t, rw.Body.String(), "locked until")
+}
@@ -0,0 +1,376 @@
+package login
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/token"
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa"
+	"golang.org/x/net/context"
+	"golang.org/x/net/context/ctxhttp"
+	"golang.org/x/oauth2"
+)
+
+// InvalidIDTokenError could occure when the ID token returned by an OpenID Connect provider does not validate
+const InvalidIDTokenError string = "Invalid ID token"
+
+// OIDCConfig holds the settings of an OpenID Connect provider
+type OIDCConfig struct {
+	// Name is the name the provider is selected by
+	Name string
+	// Issuer is the URL of the provider. The discovery document is expected
+	// at <Issuer>/.well-known/openid-configuration.
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is where the provider sends the user back to. It defaults
+	// to the authorize action of the current request, selecting the provider.
+	RedirectURL string
+	// Scopes are requested in addition to "openid". Defaults to "email" and
+	// "profile".
+	Scopes []string
+	// NameClaim, EmailClaim and ImageURLClaim are the ID token claims a new
+	// identity is created from. They default to "name", "email" and "picture".
+	NameClaim     string
+	EmailClaim    string
+	ImageURLClaim string
+	// TrustEmail accepts emails the provider does not claim to have verified.
+	// Only enable it for providers that manage the emails themselves.
+	TrustEmail bool
+}
+
+// NewOpenIDConnect creates a new login.Service capable of using the given
+// OpenID Connect provider for authorization. The discovery document and the
+// keys of the provider are fetched on first use.
+func NewOpenIDConnect(config OIDCConfig, identities account.IdentityRepository, users account.UserRepository, tokenManager token.Manager, states *States) Service {
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"email", "profile"}
+	}
+	if config.NameClaim == "" {
+		config.NameClaim = "name"
+	}
+	if config.EmailClaim == "" {
+		config.EmailClaim = "email"
+	}
+	if config.ImageURLClaim == "" {
+		config.ImageURLClaim = "picture"
+	}
+	return &openIDConnect{
+		config:       config,
+		identities:   identities,
+		users:        users,
+		tokenManager: tokenManager,
+		states:       states,
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type openIDConnect struct {
+	config       OIDCConfig
+	identities   account.IdentityRepository
+	users        account.UserRepository
+	tokenManager token.Manager
+	states       *States
+	client       *http.Client
+
+	lock      sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]*rsa.PublicKey
+}
+
+// oidcDiscovery represents the needed parts of the discovery document
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcKeySet represents the needed parts of the JSON Web Key Set of the provider
+type oidcKeySet struct {
+	Keys []struct {
+		KeyType string `json:"kty"`
+		KeyID   string `json:"kid"`
+		N       string `json:"n"`
+		E       string `json:"e"`
+	} `json:"keys"`
+}
+
+func (p *openIDConnect) Perform(ctx *app.AuthorizeLoginContext) error {
+	state := ctx.Params.Get("state")
+	code := ctx.Params.Get("code")
+	referer := ctx.RequestData.Header.Get("Referer")
+
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		goa.LogError(ctx, "OpenID Connect discovery failed", "provider", p.config.Name, "err", err)
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(fmt.Sprintf("Login provider %s not available", p.config.Name)))
+		return ctx.Unauthorized(jerrors)
+	}
+	config := &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		Scopes:       append([]string{"openid"}, p.config.Scopes...),
+		RedirectURL:  p.redirectURL(ctx.RequestData),
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discovery.AuthorizationEndpoint,
+			TokenURL: discovery.TokenEndpoint,
+		},
+	}
+
+	if code != "" || ctx.Params.Get("error") != "" {
+		// After redirect from the provider
+		knownReferer, err := p.states.Take(ctx, state)
+		if err != nil {
+			return sendError(ctx, err)
+		}
+		if knownReferer == "" {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized("State or known referer was empty"))
+			return ctx.Unauthorized(jerrors)
+		}
+		if code == "" {
+			// the user or the provider declined the login
+			return redirectWithError(ctx, knownReferer, ctx.Params.Get("error"))
+		}
+
+		oauthToken, err := config.Exchange(ctx, code)
+		if err != nil {
+			goa.LogError(ctx, "code exchange failed", "provider", p.config.Name, "err", err)
+			return redirectWithError(ctx, knownReferer, InvalidCodeError)
+		}
+		rawIDToken, _ := oauthToken.Extra("id_token").(string)
+		claims, err := p.verifyIDToken(ctx, discovery, rawIDToken, nonceOf(state))
+		if err != nil {
+			goa.LogError(ctx, "ID token validation failed", "provider", p.config.Name, "err", err)
+			return redirectWithError(ctx, knownReferer, InvalidIDTokenError)
+		}
+		identity, err := p.identityOf(ctx, claims)
+		if err != nil {
+			goa.LogError(ctx, "no identity for ID token", "provider", p.config.Name, "err", err)
+			return redirectWithError(ctx, knownReferer, err.Error())
+		}
+
+		almtoken, refreshToken, err := p.tokenManager.GeneratePair(identity)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+			return ctx.Unauthorized(jerrors)
+		}
+		ctx.ResponseData.Header().Set("Location", knownReferer+"?token="+almtoken+"&refresh_token="+refreshToken)
+		return ctx.TemporaryRedirect()
+	}
+
+	// First time access, redirect to the provider
+	state, err = p.states.Remember(ctx, referer)
+	if err != nil {
+		return sendError(ctx, err)
+	}
+	redirectURL := config.AuthCodeURL(state, oauth2.AccessTypeOnline, oauth2.SetAuthURLParam("nonce", nonceOf(state)))
+	ctx.ResponseData.Header().Set("Location", redirectURL)
+	return ctx.TemporaryRedirect()
+}
+
+// redirectURL returns the configured redirect URL or the one of the authorize
+// action of the request
+func (p *openIDConnect) redirectURL(req *goa.RequestData) string {
+	if p.config.RedirectURL != "" {
+		return p.config.RedirectURL
+	}
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s%s?provider=%s", scheme, req.Host, req.URL.Path, url.QueryEscape(p.config.Name))
+}
+
+// discover returns the discovery document of the provider, fetching it on
+// first use
+func (p *openIDConnect) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+	issuer := strings.TrimSuffix(p.config.Issuer, "/")
+	var discovery oidcDiscovery
+	if err := p.getJSON(ctx, issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("discovery document of %s is issued by %s", issuer, discovery.Issuer)
+	}
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+// key returns the public key of the provider with the given ID. The keys are
+// fetched again when the ID is unknown, as the provider may have rotated its
+// keys.
+func (p *openIDConnect) key(ctx context.Context, discovery *oidcDiscovery, kid string) (*rsa.PublicKey, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if key := p.lookupKey(kid); key != nil {
+		return key, nil
+	}
+	var keySet oidcKeySet
+	if err := p.getJSON(ctx, discovery.JWKSURI, &keySet); err != nil {
+		return nil, err
+	}
+	p.keys = map[string]*rsa.PublicKey{}
+	for _, k := range keySet.Keys {
+		if k.KeyType != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		p.keys[k.KeyID] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	if key := p.lookupKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %s", kid)
+}
+
+// lookupKey returns the known key with the given ID. Tokens without key ID
+// can only be verified if the provider has a single key.
+func (p *openIDConnect) lookupKey(kid string) *rsa.PublicKey {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+	return p.keys[kid]
+}
+
+// verifyIDToken checks the signature, issuer, audience, expiry and nonce of
+// the ID token and returns its claims
+func (p *openIDConnect) verifyIDToken(ctx context.Context, discovery *oidcDiscovery, rawIDToken string, nonce string) (jwt.MapClaims, error) {
+	if rawIDToken == "" {
+		return nil, errors.New("token response lacks the ID token")
+	}
+	idToken, err := jwt.Parse(rawIDToken, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, discovery, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	claims := idToken.Claims.(jwt.MapClaims)
+	if !claims.VerifyIssuer(discovery.Issuer, true) {
+		return nil, fmt.Errorf("ID token not issued by %s", discovery.Issuer)
+	}
+	if !hasAudience(claims, p.config.ClientID) {
+		return nil, fmt.Errorf("ID token not issued for %s", p.config.ClientID)
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("ID token does not expire")
+	}
+	if claims["nonce"] != nonce {
+		return nil, errors.New("ID token has an unexpected nonce")
+	}
+	return claims, nil
+}
+
+// identityOf returns the identity of the verified email claimed by the ID
+// token. An identity is created if the email is unknown.
+func (p *openIDConnect) identityOf(ctx context.Context, claims jwt.MapClaims) (account.Identity, error) {
+	email, _ := claims[p.config.EmailClaim].(string)
+	if email == "" {
+		return account.Identity{}, errors.New(PrimaryEmailNotFoundError)
+	}
+	if verified, _ := claims["email_verified"].(bool); !verified && !p.config.TrustEmail {
+		return account.Identity{}, errors.New("Email not verified")
+	}
+	users, err := p.users.Query(account.UserByEmails([]string{email}), account.UserVerified(), account.UserWithIdentity())
+	if err != nil {
+		return account.Identity{}, err
+	}
+	if len(users) > 0 {
+		return users[0].Identity, nil
+	}
+
+	name, _ := claims[p.config.NameClaim].(string)
+	if name == "" {
+		name, _ = claims["preferred_username"].(string)
+	}
+	if name == "" {
+		name = email
+	}
+	imageURL, _ := claims[p.config.ImageURLClaim].(string)
+	identity := account.Identity{FullName: name, ImageURL: imageURL}
+	if err := p.identities.Create(ctx, &identity); err != nil {
+		return account.Identity{}, err
+	}
+	if err := p.users.Create(ctx, &account.User{Email: email, Identity: identity, Primary: true, Verified: true}); err != nil {
+		return account.Identity{}, err
+	}
+	return identity, nil
+}
+
+func (p *openIDConnect) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := ctxhttp.Do(ctx, p.client, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// hasAudience returns true if the "aud" claim, which is either a single
+// string or a list, contains the given audience
+func hasAudience(claims jwt.MapClaims, audience string) bool {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nonceOf derives the nonce of the ID token from the state of the login, so
+// that an ID token is only accepted for the login it was requested for
+func nonceOf(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// redirectWithError sends the user back to the referer, telling why the login
+// failed
+func redirectWithError(ctx *app.AuthorizeLoginContext, referer, reason string) error {
+	ctx.ResponseData.Header().Set("Location", referer+"?error="+url.QueryEscape(reason))
+	return ctx.TemporaryRedirect()
+}
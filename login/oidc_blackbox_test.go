@@ -0,0 +1,214 @@
+package login_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	. "github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/token"
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// oidcStandIn is a local stand-in for an OpenID Connect provider. Its token
+// endpoint hands out an ID token with the claims set by the test.
+type oidcStandIn struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	claims jwt.MapClaims
+}
+
+func newOIDCStandIn(t *testing.T) *oidcStandIn {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+	s := &oidcStandIn{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 s.URL,
+			"authorization_endpoint": s.URL + "/auth",
+			"token_endpoint":         s.URL + "/token",
+			"jwks_uri":               s.URL + "/certs",
+		})
+	})
+	mux.HandleFunc("/certs", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test-key",
+				"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "valid-code" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, s.claims)
+		idToken.Header["kid"] = "test-key"
+		signed, err := idToken.SignedString(key)
+		require.Nil(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "provider-access-token",
+			"token_type":   "Bearer",
+			"expires_in":   300,
+			"id_token":     signed,
+		})
+	})
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// validClaims returns the claims of a valid ID token for the given nonce
+func (s *oidcStandIn) validClaims(nonce, email string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            s.URL,
+		"sub":            uuid.NewV4().String(),
+		"aud":            []string{"almighty"},
+		"exp":            time.Now().Add(time.Minute).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          nonce,
+		"email":          email,
+		"email_verified": true,
+		"name":           "Keycloak User",
+	}
+}
+
+func newOIDCService(issuer string) Service {
+	publicKey, _ := token.ParsePublicKey([]byte(token.RSAPublicKey))
+	privateKey, _ := token.ParsePrivateKey([]byte(token.RSAPrivateKey))
+	return NewOpenIDConnect(OIDCConfig{
+		Name:         "keycloak",
+		Issuer:       issuer,
+		ClientID:     "almighty",
+		ClientSecret: "secret",
+	}, account.NewIdentityRepository(db), account.NewUserRepository(db), token.NewManager(publicKey, privateKey), loginStates)
+}
+
+// authorize performs the login action with the given parameters and returns
+// the redirect location
+func authorize(t *testing.T, service Service, prms url.Values) *url.URL {
+	rw := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/login/authorize", nil)
+	require.Nil(t, err)
+	req.Header.Add("referer", "https://alm-url.example.org/path")
+	goaCtx := goa.NewContext(goa.WithAction(context.Background(), "LoginTest"), rw, req, prms)
+	authorizeCtx, err := app.NewAuthorizeLoginContext(goaCtx, goa.New("LoginService"))
+	require.Nil(t, err)
+
+	require.Nil(t, service.Perform(authorizeCtx))
+	require.Equal(t, 307, rw.Code)
+	location, err := url.Parse(rw.Header().Get("Location"))
+	require.Nil(t, err)
+	return location
+}
+
+func TestOIDCRedirectsToProvider(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	provider := newOIDCStandIn(t)
+	defer provider.Close()
+
+	location := authorize(t, newOIDCService(provider.URL), url.Values{"provider": {"keycloak"}})
+	assert.True(t, strings.HasPrefix(location.String(), provider.URL+"/auth"))
+	assert.Equal(t, "almighty", location.Query().Get("client_id"))
+	assert.Contains(t, location.Query().Get("scope"), "openid")
+	assert.NotEmpty(t, location.Query().Get("state"))
+	assert.NotEmpty(t, location.Query().Get("nonce"))
+	assert.Contains(t, location.Query().Get("redirect_uri"), "provider=keycloak")
+}
+
+func TestOIDCLogin(t *testing.T) {
+	resource.Require(t, resource.Database)
+	provider := newOIDCStandIn(t)
+	defer provider.Close()
+	service := newOIDCService(provider.URL)
+	email := "oidc-" + uuid.NewV4().String() + "@example.com"
+
+	login := func() *account.Identity {
+		location := authorize(t, service, url.Values{"provider": {"keycloak"}})
+		provider.claims = provider.validClaims(location.Query().Get("nonce"), email)
+		location = authorize(t, service, url.Values{"provider": {"keycloak"}, "code": {"valid-code"}, "state": {location.Query().Get("state")}})
+		assert.Equal(t, "alm-url.example.org", location.Host)
+		require.NotEmpty(t, location.Query().Get("token"), location.String())
+		assert.NotEmpty(t, location.Query().Get("refresh_token"))
+
+		publicKey, _ := token.ParsePublicKey([]byte(token.RSAPublicKey))
+		privateKey, _ := token.ParsePrivateKey([]byte(token.RSAPrivateKey))
+		ident, err := token.NewManager(publicKey, privateKey).Extract(location.Query().Get("token"))
+		require.Nil(t, err)
+		return ident
+	}
+	first := login()
+	assert.Equal(t, "Keycloak User", first.FullName)
+	// the verified email links later logins to the same identity
+	second := login()
+	assert.Equal(t, first.ID, second.ID)
+}
+
+func TestOIDCRejectsInvalidIDToken(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	provider := newOIDCStandIn(t)
+	defer provider.Close()
+	service := newOIDCService(provider.URL)
+
+	for name, tamper := range map[string]func(jwt.MapClaims){
+		"wrong nonce":    func(c jwt.MapClaims) { c["nonce"] = "other" },
+		"wrong audience": func(c jwt.MapClaims) { c["aud"] = "someone-else" },
+		"wrong issuer":   func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" },
+		"expired":        func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() },
+	} {
+		location := authorize(t, service, url.Values{"provider": {"keycloak"}})
+		provider.claims = provider.validClaims(location.Query().Get("nonce"), "oidc@example.com")
+		tamper(provider.claims)
+		location = authorize(t, service, url.Values{"provider": {"keycloak"}, "code": {"valid-code"}, "state": {location.Query().Get("state")}})
+		assert.Equal(t, InvalidIDTokenError, location.Query().Get("error"), name)
+		assert.Empty(t, location.Query().Get("token"), name)
+	}
+
+	location := authorize(t, service, url.Values{"provider": {"keycloak"}})
+	location = authorize(t, service, url.Values{"provider": {"keycloak"}, "code": {"invalid-code"}, "state": {location.Query().Get("state")}})
+	assert.Equal(t, InvalidCodeError, location.Query().Get("error"))
+}
+
+func TestRegistrySelectsProvider(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	provider := newOIDCStandIn(t)
+	defer provider.Close()
+	registry := NewRegistry("github")
+	registry.Register("github", loginService)
+	registry.Register("keycloak", newOIDCService(provider.URL))
+	assert.Equal(t, []string{"github", "keycloak"}, registry.Providers())
+
+	location := authorize(t, registry, url.Values{})
+	assert.Contains(t, location.String(), "https://github.com/login/oauth/authorize")
+	location = authorize(t, registry, url.Values{"provider": {"keycloak"}})
+	assert.True(t, strings.HasPrefix(location.String(), provider.URL+"/auth"))
+
+	rw := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/login/authorize", nil)
+	goaCtx := goa.NewContext(goa.WithAction(context.Background(), "LoginTest"), rw, req, url.Values{"provider": {"unknown"}})
+	authorizeCtx, err := app.NewAuthorizeLoginContext(goaCtx, goa.New("LoginService"))
+	require.Nil(t, err)
+	registry.Perform(authorizeCtx)
+	assert.Equal(t, 400, rw.Code)
+}
@@ -0,0 +1,53 @@
+package login
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+)
+
+// Registry is a Service that hands the login over to the provider selected
+// by the "provider" parameter of the request, or the default provider if the
+// request selects none.
+type Registry struct {
+	providers       map[string]Service
+	defaultProvider string
+}
+
+// NewRegistry creates an empty Registry falling back to the provider with the
+// given name
+func NewRegistry(defaultProvider string) *Registry {
+	return &Registry{providers: map[string]Service{}, defaultProvider: defaultProvider}
+}
+
+// Register makes the provider selectable by the given name
+func (r *Registry) Register(name string, provider Service) {
+	r.providers[name] = provider
+}
+
+// Providers returns the names of the registered providers in alphabetical order
+func (r *Registry) Providers() []string {
+	var names []string
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Perform runs the login with the selected provider
+func (r *Registry) Perform(ctx *app.AuthorizeLoginContext) error {
+	name := r.defaultProvider
+	if ctx.Provider != nil && *ctx.Provider != "" {
+		name = *ctx.Provider
+	}
+	provider, ok := r.providers[name]
+	if !ok {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("Unknown login provider %s, expected one of %v", name, r.Providers())))
+		return ctx.BadRequest(jerrors)
+	}
+	return provider.Perform(ctx)
+}
@@ -5,14 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"sync"
 
 	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/token"
 	"github.com/goadesign/goa"
-	uuid "github.com/satori/go.uuid"
 	"golang.org/x/net/context"
 	"golang.org/x/oauth2"
 )
@@ -30,12 +28,13 @@ type Service interface {
 }
 
 // NewGitHubOAuth creates a new login.Service capable of using GitHub for authorization
-func NewGitHubOAuth(config *oauth2.Config, identities account.IdentityRepository, users account.UserRepository, tokenManager token.Manager) Service {
+func NewGitHubOAuth(config *oauth2.Config, identities account.IdentityRepository, users account.UserRepository, tokenManager token.Manager, states *States) Service {
 	return &gitHubOAuth{
 		config:       config,
 		identities:   identities,
 		users:        users,
 		tokenManager: tokenManager,
+		states:       states,
 	}
 }
 
@@ -44,12 +43,9 @@ type gitHubOAuth struct {
 	identities   account.IdentityRepository
 	users        account.UserRepository
 	tokenManager token.Manager
+	states       *States
 }
 
-// TEMP: This will leak memory in the long run with many 'failed' redirect attemts
-var stateReferer = map[string]string{}
-var mapLock sync.RWMutex
-
 func (gh *gitHubOAuth) Perform(ctx *app.AuthorizeLoginContext) error {
 	state := ctx.Params.Get("state")
 	code := ctx.Params.Get("code")
@@ -59,13 +55,11 @@ func (gh *gitHubOAuth) Perform(ctx *app.AuthorizeLoginContext) error {
 		// After redirect from oauth provider
 
 		// validate known state
-		var knownReferer string
-		defer func() {
-			delete(stateReferer, state)
-		}()
-
-		knownReferer = stateReferer[state]
-		if state == "" || knownReferer == "" {
+		knownReferer, err := gh.states.Take(ctx, state)
+		if err != nil {
+			return sendError(ctx, err)
+		}
+		if knownReferer == "" {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized("State or known referer was empty"))
 			return ctx.Unauthorized(jerrors)
 		}
@@ -95,7 +89,7 @@ func (gh *gitHubOAuth) Perform(ctx *app.AuthorizeLoginContext) error {
 			ctx.ResponseData.Header().Set("Location", knownReferer+"?error="+PrimaryEmailNotFoundError)
 			return ctx.TemporaryRedirect()
 		}
-		users, err := gh.users.Query(account.UserByEmails([]string{primaryEmail}), account.UserWithIdentity())
+		users, err := gh.users.Query(account.UserByEmails([]string{primaryEmail}), account.UserVerified(), account.UserWithIdentity())
 		if err != nil {
 			ctx.ResponseData.Header().Set("Location", knownReferer+"?error=Associated user not found "+err.Error())
 			return ctx.TemporaryRedirect()
@@ -113,24 +107,24 @@ func (gh *gitHubOAuth) Perform(ctx *app.AuthorizeLoginContext) error {
 
 			identity = createIdentity(*ghUser)
 			gh.identities.Create(ctx, &identity)
-			gh.users.Create(ctx, &account.User{Email: primaryEmail, Identity: identity})
+			gh.users.Create(ctx, &account.User{Email: primaryEmail, Identity: identity, Primary: true, Verified: true})
 		} else {
 			identity = users[0].Identity
 		}
 
 		fmt.Println("Identity: ", identity)
 
-		// register other emails in User table?
+		gh.registerOtherEmails(ctx, identity, emails)
 
 		// generate token
-		almtoken, err := gh.tokenManager.Generate(identity)
+		almtoken, refreshToken, err := gh.tokenManager.GeneratePair(identity)
 		if err != nil {
 			fmt.Println("Failed to generate token", err)
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
 			return ctx.Unauthorized(jerrors)
 		}
 
-		ctx.ResponseData.Header().Set("Location", knownReferer+"?token="+almtoken)
+		ctx.ResponseData.Header().Set("Location", knownReferer+"?token="+almtoken+"&refresh_token="+refreshToken)
 		return ctx.TemporaryRedirect()
 	}
 
@@ -138,12 +132,10 @@ func (gh *gitHubOAuth) Perform(ctx *app.AuthorizeLoginContext) error {
 
 	// store referer id to state for redirect later
 	fmt.Println("Got Request from: ", referer)
-	state = uuid.NewV4().String()
-
-	mapLock.Lock()
-	defer mapLock.Unlock()
-
-	stateReferer[state] = referer
+	state, err := gh.states.Remember(ctx, referer)
+	if err != nil {
+		return sendError(ctx, err)
+	}
 
 	redirectURL := gh.config.AuthCodeURL(state, oauth2.AccessTypeOnline)
 	ctx.ResponseData.Header().Set("Location", redirectURL)
@@ -186,6 +178,39 @@ func createIdentity(ghUser ghUser) account.Identity {
 	}
 }
 
+// registerOtherEmails registers the secondary emails GitHub verified for the
+// user with the identity, unless they are known for the identity already or
+// verified for some other identity.
+func (gh gitHubOAuth) registerOtherEmails(ctx context.Context, identity account.Identity, emails []ghEmail) {
+	var others []string
+	for _, email := range emails {
+		if email.Verified && !email.Primary {
+			others = append(others, email.Email)
+		}
+	}
+	if len(others) == 0 {
+		return
+	}
+	known, err := gh.users.Query(account.UserByEmails(others))
+	if err != nil {
+		goa.LogError(ctx, "failed to look up emails", "err", err)
+		return
+	}
+	for _, email := range others {
+		registered := false
+		for _, u := range known {
+			registered = registered || (u.Email == email && (u.Verified || u.IdentityID == identity.ID))
+		}
+		if registered {
+			continue
+		}
+		err := gh.users.Create(ctx, &account.User{Email: email, IdentityID: identity.ID, Verified: true})
+		if err != nil {
+			goa.LogError(ctx, "failed to register email", "email", email, "err", err)
+		}
+	}
+}
+
 func filterPrimaryEmail(emails []ghEmail) string {
 	for _, email := range emails {
 		if email.Primary {
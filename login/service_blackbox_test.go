@@ -27,6 +27,7 @@ import (
 
 var db *gorm.DB
 var loginService Service
+var loginStates *States
 
 func TestMain(m *testing.M) {
 	if _, c := os.LookupEnv(resource.Database); c != false {
@@ -69,7 +70,11 @@ func TestMain(m *testing.M) {
 	tokenManager := token.NewManager(publicKey, privateKey)
 	userRepository := account.NewUserRepository(db)
 	identityRepository := account.NewIdentityRepository(db)
-	loginService = NewGitHubOAuth(oauth, identityRepository, userRepository, tokenManager)
+	loginStates, err = NewStates(NewMemoryStateStore(), DefaultStateLifetime, []string{"https://alm-url.example.org/"})
+	if err != nil {
+		panic(err)
+	}
+	loginService = NewGitHubOAuth(oauth, identityRepository, userRepository, tokenManager, loginStates)
 
 	os.Exit(m.Run())
 }
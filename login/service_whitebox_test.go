@@ -51,6 +51,7 @@ func setup() {
 		identities:   identityRepository,
 		users:        userRepository,
 		tokenManager: tokenManager,
+		states:       &States{store: NewMemoryStateStore(), lifetime: DefaultStateLifetime},
 	}
 }
 
@@ -0,0 +1,179 @@
+package login
+
+import (
+	"database/sql"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// DefaultStateLifetime is how long a user has to complete the login with the
+// provider before the state expires
+const DefaultStateLifetime = 10 * time.Minute
+
+// StateStore keeps the referers of the logins in progress, keyed by the OAuth
+// state parameter, until the provider redirects back to us.
+type StateStore interface {
+	// Save remembers the referer for the state until the given time.
+	Save(ctx context.Context, state, referer string, until time.Time) error
+	// Take returns the referer saved for the state and forgets it, so that
+	// every state can only be used once. Unknown and expired states result
+	// in an empty referer.
+	Take(ctx context.Context, state string) (string, error)
+}
+
+// NewMemoryStateStore creates a StateStore that lives in memory of the
+// current process only.
+func NewMemoryStateStore() StateStore {
+	return &memoryStateStore{states: map[string]memoryState{}}
+}
+
+type memoryState struct {
+	referer   string
+	expiresAt time.Time
+}
+
+type memoryStateStore struct {
+	lock   sync.Mutex
+	states map[string]memoryState
+}
+
+func (s *memoryStateStore) Save(ctx context.Context, state, referer string, until time.Time) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := time.Now()
+	for key, st := range s.states {
+		if st.expiresAt.Before(now) {
+			delete(s.states, key)
+		}
+	}
+	s.states[state] = memoryState{referer: referer, expiresAt: until}
+	return nil
+}
+
+func (s *memoryStateStore) Take(ctx context.Context, state string) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	st, ok := s.states[state]
+	delete(s.states, state)
+	if !ok || st.expiresAt.Before(time.Now()) {
+		return "", nil
+	}
+	return st.referer, nil
+}
+
+// NewGormStateStore creates a StateStore that is stored in the database and
+// thus shared by all instances of the service and kept across restarts.
+func NewGormStateStore(db *gorm.DB) StateStore {
+	return &GormStateStore{db: db}
+}
+
+// GormStateStore is the implementation of StateStore on top of the
+// oauth_states table.
+type GormStateStore struct {
+	db *gorm.DB
+}
+
+// Save stores the referer for the state. Expired states get purged along
+// the way.
+func (s *GormStateStore) Save(ctx context.Context, state, referer string, until time.Time) error {
+	err := s.db.Exec("INSERT INTO oauth_states (created_at, state, referer, expires_at) VALUES (now(), ?, ?, ?)", state, referer, until).Error
+	if err != nil {
+		return err
+	}
+	return s.db.Exec("DELETE FROM oauth_states WHERE expires_at < now()").Error
+}
+
+// Take deletes the state and returns its referer if it has not expired yet.
+func (s *GormStateStore) Take(ctx context.Context, state string) (string, error) {
+	var referer string
+	err := s.db.Raw("DELETE FROM oauth_states WHERE state = ? AND expires_at > now() RETURNING referer", state).Row().Scan(&referer)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return referer, err
+}
+
+// States hands out the OAuth states of the logins and remembers where to
+// send the user once the login completes. Only referers matching one of the
+// allowed redirect URLs are accepted, so that the login can not be abused to
+// send the token to somebody else.
+type States struct {
+	store    StateStore
+	lifetime time.Duration
+	allowed  []*url.URL
+}
+
+// NewStates creates States on top of the given store. The allowed redirects
+// are URLs the referer has to match in scheme and host and start with in
+// path. No allowed redirects accept any referer, which is only acceptable in
+// developer mode.
+func NewStates(store StateStore, lifetime time.Duration, allowedRedirects []string) (*States, error) {
+	s := &States{store: store, lifetime: lifetime}
+	for _, allowed := range allowedRedirects {
+		u, err := url.Parse(allowed)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, errors.NewBadParameterError("allowed redirect", allowed).Expected("absolute URL")
+		}
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		s.allowed = append(s.allowed, u)
+	}
+	return s, nil
+}
+
+// IsAllowed returns true if the user may be redirected to the referer
+func (s *States) IsAllowed(referer string) bool {
+	u, err := url.Parse(referer)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return false
+	}
+	if len(s.allowed) == 0 {
+		return true
+	}
+	for _, allowed := range s.allowed {
+		if u.Scheme == allowed.Scheme && strings.EqualFold(u.Host, allowed.Host) &&
+			(u.Path == allowed.Path || strings.HasPrefix(u.Path, allowed.Path+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// Remember creates a new state for a login started from the referer
+func (s *States) Remember(ctx context.Context, referer string) (string, error) {
+	if !s.IsAllowed(referer) {
+		return "", errors.NewBadParameterError("referer", referer)
+	}
+	state := uuid.NewV4().String()
+	if err := s.store.Save(ctx, state, referer, time.Now().Add(s.lifetime)); err != nil {
+		return "", errors.NewInternalError(err.Error())
+	}
+	return state, nil
+}
+
+// Take returns the referer the login with the state was started from, or an
+// empty string if the state is unknown, expired or has been used before
+func (s *States) Take(ctx context.Context, state string) (string, error) {
+	if state == "" {
+		return "", nil
+	}
+	referer, err := s.store.Take(ctx, state)
+	if err != nil {
+		return "", errors.NewInternalError(err.Error())
+	}
+	return referer, nil
+}
+
+// sendError responds to the authorize request with the given error
+func sendError(ctx *app.AuthorizeLoginContext, err error) error {
+	jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+	return ctx.ResponseData.Service.Send(ctx, httpStatusCode, jerrors)
+}
@@ -0,0 +1,105 @@
+package login_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	. "github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/goadesign/goa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestStatesAllowedRedirects(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	states, err := NewStates(NewMemoryStateStore(), DefaultStateLifetime, []string{"https://alm.example.org/", "http://localhost:8088/ui"})
+	require.Nil(t, err)
+
+	assert.True(t, states.IsAllowed("https://alm.example.org/"))
+	assert.True(t, states.IsAllowed("https://ALM.example.org/work/items?q=1"))
+	assert.True(t, states.IsAllowed("http://localhost:8088/ui"))
+	assert.True(t, states.IsAllowed("http://localhost:8088/ui/board"))
+	assert.False(t, states.IsAllowed("http://localhost:8088/uix"))
+	assert.False(t, states.IsAllowed("http://alm.example.org/"))
+	assert.False(t, states.IsAllowed("https://alm.example.org.evil.com/"))
+	assert.False(t, states.IsAllowed("https://evil.com/?https://alm.example.org/"))
+	assert.False(t, states.IsAllowed("/relative"))
+	assert.False(t, states.IsAllowed(""))
+
+	_, err = states.Remember(context.Background(), "https://evil.com/")
+	assert.NotNil(t, err)
+
+	_, err = NewStates(NewMemoryStateStore(), DefaultStateLifetime, []string{"alm.example.org"})
+	assert.NotNil(t, err)
+
+	// without allowed redirects any absolute referer is accepted
+	states, err = NewStates(NewMemoryStateStore(), DefaultStateLifetime, nil)
+	require.Nil(t, err)
+	assert.True(t, states.IsAllowed("https://anywhere.example.com/"))
+	assert.False(t, states.IsAllowed(""))
+}
+
+func testStateStore(t *testing.T, store StateStore) {
+	ctx := context.Background()
+	states, err := NewStates(store, DefaultStateLifetime, nil)
+	require.Nil(t, err)
+
+	state, err := states.Remember(ctx, "https://alm.example.org/path")
+	require.Nil(t, err)
+	other, err := states.Remember(ctx, "https://alm.example.org/other")
+	require.Nil(t, err)
+	assert.NotEqual(t, state, other)
+
+	referer, err := states.Take(ctx, state)
+	require.Nil(t, err)
+	assert.Equal(t, "https://alm.example.org/path", referer)
+	// every state can only be used once
+	referer, err = states.Take(ctx, state)
+	require.Nil(t, err)
+	assert.Equal(t, "", referer)
+
+	referer, err = states.Take(ctx, "unknown")
+	require.Nil(t, err)
+	assert.Equal(t, "", referer)
+
+	require.Nil(t, store.Save(ctx, "expired", "https://alm.example.org/", time.Now().Add(-time.Second)))
+	referer, err = store.Take(ctx, "expired")
+	require.Nil(t, err)
+	assert.Equal(t, "", referer)
+
+	referer, err = states.Take(ctx, other)
+	require.Nil(t, err)
+	assert.Equal(t, "https://alm.example.org/other", referer)
+}
+
+func TestMemoryStateStore(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	testStateStore(t, NewMemoryStateStore())
+}
+
+func TestGormStateStore(t *testing.T) {
+	resource.Require(t, resource.Database)
+	testStateStore(t, NewGormStateStore(db))
+}
+
+func TestLoginRefusesUnknownReferer(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	rw := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/login/authorize", nil)
+	require.Nil(t, err)
+	req.Header.Add("referer", "https://evil.example.com/steal")
+	goaCtx := goa.NewContext(goa.WithAction(context.Background(), "LoginTest"), rw, req, url.Values{})
+	authorizeCtx, err := app.NewAuthorizeLoginContext(goaCtx, goa.New("LoginService"))
+	require.Nil(t, err)
+
+	loginService.Perform(authorizeCtx)
+	assert.Equal(t, 400, rw.Code)
+	assert.Empty(t, rw.Header().Get("Location"))
+}
@@ -0,0 +1,134 @@
+package main_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	almtoken "github.com/almighty/almighty-core/token"
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+type TestLoginREST struct {
+	gormsupport.DBTestSuite
+
+	db           *gormapplication.GormDB
+	clean        func()
+	tokenManager almtoken.Manager
+	identity     account.Identity
+}
+
+func TestRunLoginREST(t *testing.T) {
+	suite.Run(t, &TestLoginREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestLoginREST) SetupTest() {
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = gormsupport.DeleteCreatedEntities(rest.DB)
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+	rest.tokenManager = almtoken.NewManagerWithConfig(pub, priv, almtoken.Config{
+		Audience:             almtoken.DefaultAudience,
+		LoginTokenLifetime:   time.Hour,
+		RefreshTokenLifetime: 24 * time.Hour,
+		Revocations:          almtoken.NewGormRevocationList(rest.DB),
+	})
+	rest.identity = account.Identity{FullName: "TestLoginREST"}
+	require.Nil(rest.T(), account.NewIdentityRepository(rest.DB).Create(context.Background(), &rest.identity))
+}
+
+func (rest *TestLoginREST) TearDownTest() {
+	rest.clean()
+}
+
+// controller returns a login controller whose service context holds the
+// given login token
+func (rest *TestLoginREST) controller(loginToken string) (*goa.Service, *LoginController) {
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	tk, err := jwt.Parse(loginToken, func(*jwt.Token) (interface{}, error) { return pub, nil })
+	require.Nil(rest.T(), err)
+	svc := goa.New("Login-Service")
+	svc.Context = goajwt.WithJWT(svc.Context, tk)
+	return svc, NewLoginController(svc, nil, rest.tokenManager, rest.db)
+}
+
+func (rest *TestLoginREST) TestRefresh() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	loginToken, refreshToken, err := rest.tokenManager.GeneratePair(rest.identity)
+	require.Nil(t, err)
+	svc, ctrl := rest.controller(loginToken)
+
+	_, tokens := test.RefreshLoginOK(t, svc.Context, svc, ctrl, &app.RefreshTokenPayload{RefreshToken: refreshToken})
+	require.NotNil(t, tokens.RefreshToken)
+	ident, err := rest.tokenManager.Extract(tokens.Token)
+	require.Nil(t, err)
+	assert.Equal(t, rest.identity.ID, ident.ID)
+
+	// refresh tokens can be used once only
+	test.RefreshLoginUnauthorized(t, svc.Context, svc, ctrl, &app.RefreshTokenPayload{RefreshToken: refreshToken})
+	test.RefreshLoginUnauthorized(t, svc.Context, svc, ctrl, &app.RefreshTokenPayload{RefreshToken: loginToken})
+	test.RefreshLoginOK(t, svc.Context, svc, ctrl, &app.RefreshTokenPayload{RefreshToken: *tokens.RefreshToken})
+}
+
+func (rest *TestLoginREST) TestLogout() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	loginToken, refreshToken, err := rest.tokenManager.GeneratePair(rest.identity)
+	require.Nil(t, err)
+	svc, ctrl := rest.controller(loginToken)
+
+	_, err = rest.tokenManager.Locate(svc.Context)
+	require.Nil(t, err)
+	test.LogoutLoginOK(t, svc.Context, svc, ctrl)
+
+	_, err = rest.tokenManager.Locate(svc.Context)
+	assert.NotNil(t, err)
+	_, err = rest.tokenManager.Extract(loginToken)
+	assert.NotNil(t, err)
+	test.RefreshLoginUnauthorized(t, svc.Context, svc, ctrl, &app.RefreshTokenPayload{RefreshToken: refreshToken})
+}
+
+func (rest *TestLoginREST) TestPasswordReset() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	loginToken, err := rest.tokenManager.Generate(rest.identity)
+	require.Nil(t, err)
+	svc, ctrl := rest.controller(loginToken)
+
+	// unknown emails are invited
+	email := "invited-" + uuid.NewV4().String() + "@example.com"
+	fullName := "Invited User"
+	_, reset := test.CreatePasswordResetLoginCreated(t, svc.Context, svc, ctrl, &app.CreatePasswordResetPayload{Email: email, FullName: &fullName})
+	assert.Equal(t, email, reset.Email)
+	assert.NotEmpty(t, reset.Code)
+	assert.True(t, reset.ExpiresAt.After(time.Now()))
+
+	test.ResetPasswordLoginBadRequest(t, svc.Context, svc, ctrl, &app.ResetPasswordPayload{Email: email, Code: "wrong", Password: "correct horse"})
+	test.ResetPasswordLoginOK(t, svc.Context, svc, ctrl, &app.ResetPasswordPayload{Email: email, Code: reset.Code, Password: "correct horse"})
+	test.ResetPasswordLoginBadRequest(t, svc.Context, svc, ctrl, &app.ResetPasswordPayload{Email: email, Code: reset.Code, Password: "correct horse"})
+
+	u, err := account.NewUserRepository(rest.DB).Authenticate(context.Background(), email, "correct horse", account.LockoutPolicy{})
+	require.Nil(t, err)
+	assert.Equal(t, fullName, u.Identity.FullName)
+
+	// known emails keep their identity
+	_, again := test.CreatePasswordResetLoginCreated(t, svc.Context, svc, ctrl, &app.CreatePasswordResetPayload{Email: email})
+	test.ResetPasswordLoginOK(t, svc.Context, svc, ctrl, &app.ResetPasswordPayload{Email: email, Code: again.Code, Password: "battery staple"})
+	same, err := account.NewUserRepository(rest.DB).Authenticate(context.Background(), email, "battery staple", account.LockoutPolicy{})
+	require.Nil(t, err)
+	assert.Equal(t, u.Identity.ID, same.Identity.ID)
+}
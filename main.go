@@ -1,6 +1,7 @@
 package main
 
 import (
+	"crypto/rsa"
 	"flag"
 	"fmt"
 	"log"
@@ -151,13 +152,35 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	retiredPublicKeys, err := token.ParsePublicKeys(configuration.GetTokenRetiredPublicKeys())
+	if err != nil {
+		panic(err)
+	}
 
 	// Setup Account/Login/Security
 	identityRepository := account.NewIdentityRepository(db)
 	userRepository := account.NewUserRepository(db)
 
-	tokenManager := token.NewManager(publicKey, privateKey)
-	app.UseJWTMiddleware(service, jwt.New(publicKey, nil, app.NewJWTSecurity()))
+	appDB := gormapplication.NewGormDB(db)
+
+	tokenManager := token.NewManagerWithConfig(publicKey, privateKey, token.Config{
+		Audience:             configuration.GetTokenAudience(),
+		LoginTokenLifetime:   configuration.GetTokenLifetime(),
+		RefreshTokenLifetime: configuration.GetTokenRefreshLifetime(),
+		Revocations:          token.NewGormRevocationList(db),
+		RetiredPublicKeys:    retiredPublicKeys,
+	})
+	// Everybody can sign tokens with the built-in keys
+	if !configuration.IsPostgresDeveloperModeEnabled() && usesDefaultTokenKey(tokenManager) {
+		panic("Refusing to run with the built-in token keys outside of developer mode. Configure token.privatekey and token.publickey.")
+	}
+	var validationKeys []*rsa.PublicKey
+	for _, key := range tokenManager.PublicKeys() {
+		validationKeys = append(validationKeys, key.Key)
+	}
+	// The authorizer runs as validation function of the JWT middleware in
+	// order to see the identity of the token.
+	app.UseJWTMiddleware(service, jwt.New(validationKeys, NewAuthorizer(appDB, tokenManager), app.NewJWTSecurity()))
 	service.Use(login.InjectTokenManager(tokenManager))
 
 	// Mount "login" controller
@@ -168,16 +191,49 @@ func main() {
 		Endpoint:     github.Endpoint,
 	}
 
-	loginService := login.NewGitHubOAuth(oauth, identityRepository, userRepository, tokenManager)
-	loginCtrl := NewLoginController(service, loginService, tokenManager)
+	// Without allowed redirects anybody could have the login send the token to them
+	if !configuration.IsPostgresDeveloperModeEnabled() && len(configuration.GetLoginAllowedRedirects()) == 0 {
+		panic("Refusing to run without allowed login redirects outside of developer mode. Configure login.redirect.allowed.")
+	}
+	loginStates, err := login.NewStates(login.NewGormStateStore(db), configuration.GetLoginStateLifetime(), configuration.GetLoginAllowedRedirects())
+	if err != nil {
+		panic(err)
+	}
+
+	loginService := login.NewRegistry(configuration.GetLoginDefaultProvider())
+	loginService.Register("github", login.NewGitHubOAuth(oauth, identityRepository, userRepository, tokenManager, loginStates))
+	for _, provider := range configuration.GetOIDCProviders() {
+		loginService.Register(provider.Name, login.NewOpenIDConnect(login.OIDCConfig{
+			Name:          provider.Name,
+			Issuer:        provider.Issuer,
+			ClientID:      provider.ClientID,
+			ClientSecret:  provider.ClientSecret,
+			RedirectURL:   provider.RedirectURL,
+			Scopes:        provider.Scopes,
+			NameClaim:     provider.NameClaim,
+			EmailClaim:    provider.EmailClaim,
+			ImageURLClaim: provider.ImageURLClaim,
+			TrustEmail:    provider.TrustEmail,
+		}, identityRepository, userRepository, tokenManager, loginStates))
+	}
+	lockout := account.LockoutPolicy{
+		MaxFailures: configuration.GetLoginLocalLockoutAttempts(),
+		Duration:    configuration.GetLoginLocalLockoutDuration(),
+	}
+	if configuration.IsLoginLocalEnabled() {
+		loginService.Register("local", login.NewLocalLogin(userRepository, tokenManager, loginStates, lockout))
+	}
+	loginCtrl := NewLoginController(service, loginService, tokenManager, appDB)
 	app.MountLoginController(service, loginCtrl)
 
+	// Mount "jwks" controller
+	jwksCtrl := NewJwksController(service, tokenManager)
+	app.MountJwksController(service, jwksCtrl)
+
 	// Mount "status" controller
 	statusCtrl := NewStatusController(service, db)
 	app.MountStatusController(service, statusCtrl)
 
-	appDB := gormapplication.NewGormDB(db)
-
 	// Mount "workitem" controller
 	workitemCtrl := NewWorkitemController(service, appDB)
 	app.MountWorkitemController(service, workitemCtrl)
@@ -205,22 +261,98 @@ func main() {
 	workItemCommentsCtrl := NewWorkItemCommentsController(service, appDB)
 	app.MountWorkItemCommentsController(service, workItemCommentsCtrl)
 
+	// Mount "comments" controller
+	commentsCtrl := NewCommentsController(service, appDB)
+	app.MountCommentsController(service, commentsCtrl)
+
 	// Mount "work item relationships links" controller
 	workItemRelationshipsLinksCtrl := NewWorkItemRelationshipsLinksController(service, appDB)
 	app.MountWorkItemRelationshipsLinksController(service, workItemRelationshipsLinksCtrl)
 
+	// Mount "project" controller
+	projectCtrl := NewProjectController(service, appDB)
+	app.MountProjectController(service, projectCtrl)
+
+	// Mount "project-work-items" controller
+	projectWorkItemsCtrl := NewProjectWorkItemsController(service, appDB)
+	app.MountProjectWorkItemsController(service, projectWorkItemsCtrl)
+
+	// Mount "project-work-item-types" controller
+	projectWorkItemTypesCtrl := NewProjectWorkItemTypesController(service, appDB)
+	app.MountProjectWorkItemTypesController(service, projectWorkItemTypesCtrl)
+
+	// Mount "project-tracker-queries" controller
+	projectTrackerQueriesCtrl := NewProjectTrackerQueriesController(service, appDB)
+	app.MountProjectTrackerQueriesController(service, projectTrackerQueriesCtrl)
+
+	// Mount "project-memberships" controller
+	projectMembershipsCtrl := NewProjectMembershipsController(service, appDB)
+	app.MountProjectMembershipsController(service, projectMembershipsCtrl)
+
+	// Mount "project-iterations" controller
+	projectIterationsCtrl := NewProjectIterationsController(service, appDB)
+	app.MountProjectIterationsController(service, projectIterationsCtrl)
+
+	// Mount "iteration" controller
+	iterationCtrl := NewIterationController(service, appDB)
+	app.MountIterationController(service, iterationCtrl)
+
+	// Mount "project-areas" controller
+	projectAreasCtrl := NewProjectAreasController(service, appDB)
+	app.MountProjectAreasController(service, projectAreasCtrl)
+
+	// Mount "area" controller
+	areaCtrl := NewAreaController(service, appDB)
+	app.MountAreaController(service, areaCtrl)
+
+	// Mount "project-milestones" controller
+	projectMilestonesCtrl := NewProjectMilestonesController(service, appDB)
+	app.MountProjectMilestonesController(service, projectMilestonesCtrl)
+
+	// Mount "milestone" controller
+	milestoneCtrl := NewMilestoneController(service, appDB)
+	app.MountMilestoneController(service, milestoneCtrl)
+
+	// Mount "team" controller
+	teamCtrl := NewTeamController(service, appDB)
+	app.MountTeamController(service, teamCtrl)
+
+	// Mount "team-members" controller
+	teamMembersCtrl := NewTeamMembersController(service, appDB)
+	app.MountTeamMembersController(service, teamMembersCtrl)
+
+	// Mount "project-teams" controller
+	projectTeamsCtrl := NewProjectTeamsController(service, appDB)
+	app.MountProjectTeamsController(service, projectTeamsCtrl)
+
 	// Mount "tracker" controller
 	c5 := NewTrackerController(service, appDB, scheduler)
 	app.MountTrackerController(service, c5)
 
+	// Mount "webhook" controller
+	webhookCtrl := NewWebhookController(service, scheduler)
+	app.MountWebhookController(service, webhookCtrl)
+
 	// Mount "trackerquery" controller
 	c6 := NewTrackerqueryController(service, appDB, scheduler)
 	app.MountTrackerqueryController(service, c6)
 
 	// Mount "user" controller
-	userCtrl := NewUserController(service, identityRepository, tokenManager)
+	userCtrl := NewUserControllerWithLockout(service, appDB, identityRepository, tokenManager, lockout)
 	app.MountUserController(service, userCtrl)
 
+	// Mount "access-token" controller
+	accessTokenCtrl := NewAccessTokenController(service, appDB, tokenManager)
+	app.MountAccessTokenController(service, accessTokenCtrl)
+
+	// Mount "service-account" controller
+	serviceAccountCtrl := NewServiceAccountController(service, appDB, tokenManager)
+	app.MountServiceAccountController(service, serviceAccountCtrl)
+
+	// Mount "service-account-tokens" controller
+	serviceAccountTokensCtrl := NewServiceAccountTokensController(service, appDB, tokenManager)
+	app.MountServiceAccountTokensController(service, serviceAccountTokensCtrl)
+
 	// Mount "search" controller
 	searchCtrl := NewSearchController(service, appDB)
 	app.MountSearchController(service, searchCtrl)
@@ -261,3 +393,19 @@ func printUserInfo() {
 		*/
 	}
 }
+
+// usesDefaultTokenKey returns true if any of the keys that tokens are
+// verified with is the built-in one
+func usesDefaultTokenKey(tokenManager token.Manager) bool {
+	defaultKey, err := token.ParsePublicKey(configuration.GetDefaultTokenPublicKey())
+	if err != nil {
+		panic(err)
+	}
+	defaultKeyID := token.KeyID(defaultKey)
+	for _, key := range tokenManager.PublicKeys() {
+		if key.KeyID == defaultKeyID {
+			return true
+		}
+	}
+	return false
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/project"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
 	"github.com/jinzhu/gorm"
@@ -111,6 +112,64 @@ func getMigrations() migrations {
 
 	// Version 11
 	m = append(m, steps{executeSQLFile("011-projects.sql")})
+
+	// Version 12
+	m = append(m, steps{executeSQLFile("012-comment-parent-type.sql")})
+
+	// Version 13
+	m = append(m, steps{executeSQLFile("013-project-scoping.sql")})
+
+	// Version 14
+	m = append(m, steps{executeSQLFile("014-project-memberships.sql")})
+
+	// Version 15
+	m = append(m, steps{executeSQLFile("015-iterations.sql")})
+
+	// Version 16
+	m = append(m, steps{executeSQLFile("016-areas.sql")})
+
+	// Version 17
+	m = append(m, steps{executeSQLFile("017-milestones.sql")})
+
+	// Version 18
+	m = append(m, steps{executeSQLFile("018-identity-profiles.sql")})
+
+	// Version 19
+	m = append(m, steps{executeSQLFile("019-access-tokens.sql")})
+
+	// Version 20
+	m = append(m, steps{executeSQLFile("020-revoked-tokens.sql")})
+
+	// Version 21
+	m = append(m, steps{executeSQLFile("021-oauth-states.sql")})
+
+	// Version 22
+	m = append(m, steps{executeSQLFile("022-local-passwords.sql")})
+
+	// Version 23
+	m = append(m, steps{executeSQLFile("023-teams.sql")})
+
+	// Version 24
+	m = append(m, steps{executeSQLFile("024-remote-sync.sql")})
+
+	// Version 25
+	m = append(m, steps{executeSQLFile("025-tracker-field-mappings.sql")})
+
+	// Version 26
+	m = append(m, steps{executeSQLFile("026-tracker-query-work-item-type.sql")})
+
+	// Version 27
+	m = append(m, steps{executeSQLFile("027-tracker-query-cursor.sql")})
+
+	// Version 28
+	m = append(m, steps{executeSQLFile("028-tracker-webhook-secret.sql")})
+
+	// Version 29
+	m = append(m, steps{executeSQLFile("029-remote-comments-and-links.sql")})
+
+	// Version 30
+	m = append(m, steps{executeSQLFile("030-tracker-query-runs.sql")})
+
 	// Version N
 	//
 	// In order to add an upgrade, simply append an array of MigrationFunc to the
@@ -326,6 +385,10 @@ func createOrUpdateSystemPlannerItemType(ctx context.Context, witr *workitem.Gor
 		workitem.SystemCreator:      app.FieldDefinition{Type: &app.FieldType{Kind: "user"}, Required: true},
 		workitem.SystemAssignee:     app.FieldDefinition{Type: &app.FieldType{Kind: "user"}, Required: false},
 		workitem.SystemRemoteItemID: app.FieldDefinition{Type: &app.FieldType{Kind: "string"}, Required: false},
+		workitem.SystemIteration:    app.FieldDefinition{Type: &app.FieldType{Kind: "iteration"}, Required: false},
+		workitem.SystemArea:         app.FieldDefinition{Type: &app.FieldType{Kind: "area"}, Required: false},
+		workitem.SystemMilestone:    app.FieldDefinition{Type: &app.FieldType{Kind: "milestone"}, Required: false},
+		workitem.SystemTeam:         app.FieldDefinition{Type: &app.FieldType{Kind: "team"}, Required: false},
 		workitem.SystemState: app.FieldDefinition{
 			Type: &app.FieldType{
 				BaseType: &stString,
@@ -355,7 +418,7 @@ func createOrUpdateType(typeName string, extendedTypeName *string, fields map[st
 	wit, err := witr.LoadTypeFromDB(typeName)
 	switch err.(type) {
 	case errors.NotFoundError:
-		_, err := witr.Create(ctx, extendedTypeName, typeName, fields)
+		_, err := witr.Create(ctx, project.SystemProject, extendedTypeName, typeName, fields)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,9 @@
+-- comments can be attached to more than just work items, so the parent of a
+-- comment is identified by the type of the parent and its ID.
+
+ALTER TABLE comments ADD COLUMN parent_type text;
+UPDATE comments SET parent_type = 'workitems' WHERE parent_type IS NULL;
+ALTER TABLE comments ALTER COLUMN parent_type SET NOT NULL;
+
+DROP INDEX ix_parent_id;
+CREATE INDEX ix_parent ON comments USING btree (parent_type, parent_id);
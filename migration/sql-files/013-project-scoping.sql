@@ -0,0 +1,13 @@
+-- work items, work item types and tracker queries belong to a project. All
+-- existing entries are moved into the system project which also owns the
+-- system defined work item types.
+
+INSERT INTO projects (created_at, updated_at, id, name) VALUES (now(), now(), '2e0698d8-753e-4cef-bb7c-f027634824a2', 'system.project');
+
+ALTER TABLE work_item_types ADD COLUMN project_id uuid DEFAULT '2e0698d8-753e-4cef-bb7c-f027634824a2' NOT NULL REFERENCES projects(id);
+ALTER TABLE work_items ADD COLUMN project_id uuid DEFAULT '2e0698d8-753e-4cef-bb7c-f027634824a2' NOT NULL REFERENCES projects(id);
+ALTER TABLE tracker_queries ADD COLUMN project_id uuid DEFAULT '2e0698d8-753e-4cef-bb7c-f027634824a2' NOT NULL REFERENCES projects(id);
+
+CREATE INDEX ix_work_item_types_project_id ON work_item_types USING btree (project_id);
+CREATE INDEX ix_work_items_project_id ON work_items USING btree (project_id);
+CREATE INDEX ix_tracker_queries_project_id ON tracker_queries USING btree (project_id);
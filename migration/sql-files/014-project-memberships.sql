@@ -0,0 +1,14 @@
+-- project memberships give an identity a role in a project
+
+CREATE TABLE project_memberships (
+    created_at  timestamp with time zone,
+    updated_at  timestamp with time zone,
+    deleted_at  timestamp with time zone DEFAULT NULL,
+
+    id          uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    project_id  uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    identity_id uuid NOT NULL,
+    role        text NOT NULL CHECK(role IN ('owner', 'contributor', 'viewer'))
+);
+CREATE UNIQUE INDEX project_memberships_project_identity_idx ON project_memberships (project_id, identity_id) WHERE deleted_at IS NULL;
+CREATE INDEX project_memberships_identity_idx ON project_memberships (identity_id);
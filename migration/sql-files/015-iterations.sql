@@ -0,0 +1,19 @@
+-- iterations are time boxes of a project, optionally nested in a parent iteration
+
+CREATE TABLE iterations (
+    created_at  timestamp with time zone,
+    updated_at  timestamp with time zone,
+    deleted_at  timestamp with time zone DEFAULT NULL,
+
+    id          uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    version     integer DEFAULT 0 NOT NULL,
+    project_id  uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    parent_id   uuid REFERENCES iterations(id) ON DELETE CASCADE,
+
+    name        text NOT NULL CHECK(name <> ''),
+    start_at    timestamp with time zone,
+    end_at      timestamp with time zone,
+    state       text NOT NULL DEFAULT 'new' CHECK(state IN ('new', 'start', 'close'))
+);
+CREATE UNIQUE INDEX iterations_project_name_idx ON iterations (project_id, name) WHERE deleted_at IS NULL;
+CREATE INDEX iterations_parent_idx ON iterations (parent_id);
@@ -0,0 +1,17 @@
+-- areas classify the work items of a project in a tree. The path of an area
+-- holds the IDs of all its ancestors and its own ID, separated by "/".
+
+CREATE TABLE areas (
+    created_at  timestamp with time zone,
+    updated_at  timestamp with time zone,
+    deleted_at  timestamp with time zone DEFAULT NULL,
+
+    id          uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    version     integer DEFAULT 0 NOT NULL,
+    project_id  uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+
+    name        text NOT NULL CHECK(name <> ''),
+    path        text NOT NULL
+);
+CREATE UNIQUE INDEX areas_path_idx ON areas (path) WHERE deleted_at IS NULL;
+CREATE INDEX areas_project_path_idx ON areas (project_id, path text_pattern_ops);
@@ -0,0 +1,20 @@
+-- milestones are release dates of a project that work items can target
+
+CREATE TABLE milestones (
+    created_at  timestamp with time zone,
+    updated_at  timestamp with time zone,
+    deleted_at  timestamp with time zone DEFAULT NULL,
+
+    id          uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    version     integer DEFAULT 0 NOT NULL,
+    project_id  uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+
+    name        text NOT NULL CHECK(name <> ''),
+    description text,
+    due_at      timestamp with time zone,
+    state       text NOT NULL DEFAULT 'open' CHECK(state IN ('open', 'closed'))
+);
+CREATE UNIQUE INDEX milestones_project_name_idx ON milestones (project_id, name) WHERE deleted_at IS NULL;
+
+-- find the work items targeting a milestone quickly
+CREATE INDEX ix_work_items_milestone ON work_items ((fields->>'system.milestone'));
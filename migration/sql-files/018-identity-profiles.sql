@@ -0,0 +1,16 @@
+-- profile attributes of an identity
+ALTER TABLE identities ADD COLUMN bio text;
+ALTER TABLE identities ADD COLUMN timezone text;
+
+-- an identity can have several emails; only verified ones are used to log in
+ALTER TABLE users ADD COLUMN primary_email boolean NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN verified boolean NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN verification_code text;
+
+-- all existing users were created from the verified primary GitHub email
+UPDATE users SET primary_email = true, verified = true;
+
+-- unverified emails may be claimed by several identities, removed ones may be re-added
+DROP INDEX uix_users_email;
+CREATE UNIQUE INDEX uix_users_email ON users (email) WHERE deleted_at IS NULL AND verified;
+CREATE INDEX ix_users_identity_id ON users (identity_id);
@@ -0,0 +1,22 @@
+-- service accounts are identities of bots that are owned by a person
+ALTER TABLE identities ADD COLUMN service_account boolean NOT NULL DEFAULT false;
+ALTER TABLE identities ADD COLUMN owner_id uuid REFERENCES identities(id);
+CREATE INDEX ix_identities_owner_id ON identities (owner_id);
+
+-- long-lived tokens that can be used instead of a login token
+CREATE TABLE access_tokens (
+    created_at   timestamp with time zone,
+    updated_at   timestamp with time zone,
+    deleted_at   timestamp with time zone DEFAULT NULL,
+
+    id           uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    identity_id  uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+
+    name         text NOT NULL CHECK(name <> ''),
+    -- space separated list of the permissions the token may use
+    scopes       text NOT NULL DEFAULT '',
+    expires_at   timestamp with time zone,
+    last_used_at timestamp with time zone,
+    revoked_at   timestamp with time zone
+);
+CREATE INDEX ix_access_tokens_identity_id ON access_tokens (identity_id);
@@ -0,0 +1,9 @@
+-- IDs of tokens and login sessions that must no longer be accepted. A row is
+-- only needed until the last token it affects has expired.
+CREATE TABLE revoked_tokens (
+    created_at  timestamp with time zone,
+
+    id          uuid primary key NOT NULL,
+    expires_at  timestamp with time zone NOT NULL
+);
+CREATE INDEX ix_revoked_tokens_expires_at ON revoked_tokens (expires_at);
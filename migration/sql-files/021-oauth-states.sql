@@ -0,0 +1,11 @@
+-- Logins in progress: the OAuth state handed to the login provider and the
+-- referer to send the user back to. A row is only needed until the provider
+-- redirects back or the state expires.
+CREATE TABLE oauth_states (
+    created_at  timestamp with time zone,
+
+    state       text primary key NOT NULL,
+    referer     text NOT NULL,
+    expires_at  timestamp with time zone NOT NULL
+);
+CREATE INDEX ix_oauth_states_expires_at ON oauth_states (expires_at);
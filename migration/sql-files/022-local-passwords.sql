@@ -0,0 +1,7 @@
+-- Credentials for the local login. The password belongs to the email it was
+-- set for, the reset code is only stored hashed.
+ALTER TABLE users ADD COLUMN password_hash text;
+ALTER TABLE users ADD COLUMN failed_logins integer NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN locked_until timestamp with time zone;
+ALTER TABLE users ADD COLUMN password_reset_hash text;
+ALTER TABLE users ADD COLUMN password_reset_expires_at timestamp with time zone;
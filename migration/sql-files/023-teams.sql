@@ -0,0 +1,55 @@
+-- teams are named groups of identities that can be nested in a parent team
+
+CREATE TABLE teams (
+    created_at  timestamp with time zone,
+    updated_at  timestamp with time zone,
+    deleted_at  timestamp with time zone DEFAULT NULL,
+
+    id          uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    version     integer DEFAULT 0 NOT NULL,
+    parent_id   uuid REFERENCES teams(id) ON DELETE CASCADE,
+
+    name        text NOT NULL CHECK(name ~ '^[A-Za-z0-9][A-Za-z0-9_.-]*$'),
+    description text
+);
+CREATE UNIQUE INDEX teams_name_idx ON teams (lower(name)) WHERE deleted_at IS NULL;
+CREATE INDEX teams_parent_idx ON teams (parent_id);
+
+CREATE TABLE team_members (
+    created_at  timestamp with time zone,
+    updated_at  timestamp with time zone,
+    deleted_at  timestamp with time zone DEFAULT NULL,
+
+    id          uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    team_id     uuid NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    identity_id uuid NOT NULL
+);
+CREATE UNIQUE INDEX team_members_team_identity_idx ON team_members (team_id, identity_id) WHERE deleted_at IS NULL;
+CREATE INDEX team_members_identity_idx ON team_members (identity_id);
+
+-- project team roles give all members of a team and its sub-teams a role in a project
+
+CREATE TABLE project_team_roles (
+    created_at  timestamp with time zone,
+    updated_at  timestamp with time zone,
+    deleted_at  timestamp with time zone DEFAULT NULL,
+
+    id          uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    project_id  uuid NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    team_id     uuid NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    role        text NOT NULL CHECK(role IN ('owner', 'contributor', 'viewer'))
+);
+CREATE UNIQUE INDEX project_team_roles_project_team_idx ON project_team_roles (project_id, team_id) WHERE deleted_at IS NULL;
+
+-- team mentions record the teams mentioned with @name in a comment
+
+CREATE TABLE team_mentions (
+    created_at  timestamp with time zone,
+    comment_id  uuid NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    team_id     uuid NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    PRIMARY KEY (comment_id, team_id)
+);
+CREATE INDEX team_mentions_team_idx ON team_mentions (team_id);
+
+-- find the work items assigned to a team quickly
+CREATE INDEX ix_work_items_team ON work_items ((fields->>'system.team'));
@@ -0,0 +1,37 @@
+-- trackers can push local edits of imported work items back to the remote
+-- tracker; the ownership decides per field who wins a conflicting edit
+
+ALTER TABLE trackers ADD COLUMN push boolean DEFAULT false NOT NULL;
+ALTER TABLE trackers ADD COLUMN field_ownership jsonb;
+
+-- the work item a tracker item was imported into and the field values last
+-- known to be equal on both sides
+ALTER TABLE tracker_items ADD COLUMN work_item_id bigint REFERENCES work_items(id) ON DELETE SET NULL;
+ALTER TABLE tracker_items ADD COLUMN synced_fields jsonb;
+ALTER TABLE tracker_items ADD COLUMN synced_at timestamp with time zone;
+CREATE INDEX tracker_items_work_item_id_idx ON tracker_items (work_item_id);
+
+-- local comments pushed to (or imported from) a remote tracker item
+CREATE TABLE remote_comments (
+    created_at        timestamp with time zone,
+    comment_id        uuid primary key REFERENCES comments(id) ON DELETE CASCADE,
+    tracker_id        bigint NOT NULL REFERENCES trackers(id) ON DELETE CASCADE,
+    remote_item_id    text NOT NULL,
+    remote_comment_id text NOT NULL
+);
+CREATE UNIQUE INDEX remote_comments_remote_idx ON remote_comments (tracker_id, remote_comment_id);
+
+-- conflicting edits of a field made on both sides between two synchronizations
+CREATE TABLE sync_conflicts (
+    created_at   timestamp with time zone,
+    id           bigserial primary key,
+    tracker_id   bigint NOT NULL REFERENCES trackers(id) ON DELETE CASCADE,
+    work_item_id bigint NOT NULL REFERENCES work_items(id) ON DELETE CASCADE,
+    field        text NOT NULL,
+    base_value   text,
+    local_value  text,
+    remote_value text,
+    policy       text NOT NULL CHECK(policy IN ('remote', 'local', 'last-writer')),
+    resolution   text NOT NULL CHECK(resolution IN ('remote', 'local'))
+);
+CREATE INDEX sync_conflicts_tracker_idx ON sync_conflicts (tracker_id, created_at);
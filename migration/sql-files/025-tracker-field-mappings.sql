@@ -0,0 +1,3 @@
+-- the mappings of remote attributes to work item fields of a tracker, trackers
+-- without mappings use the defaults of their type
+ALTER TABLE trackers ADD COLUMN field_mappings jsonb;
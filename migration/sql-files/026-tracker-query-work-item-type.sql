@@ -0,0 +1,4 @@
+-- the work item type remote items are imported as, optionally chosen by rules
+-- on remote attributes such as Github labels or the Jira issue type
+ALTER TABLE tracker_queries ADD COLUMN work_item_type text DEFAULT 'system.bug' NOT NULL;
+ALTER TABLE tracker_queries ADD COLUMN work_item_type_rules jsonb;
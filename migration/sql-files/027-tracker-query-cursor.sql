@@ -0,0 +1,3 @@
+-- the latest remote update time imported by a tracker query, later fetches
+-- only ask for items updated since then; NULL means a full fetch
+ALTER TABLE tracker_queries ADD COLUMN last_updated_at timestamp with time zone;
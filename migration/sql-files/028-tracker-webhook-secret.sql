@@ -0,0 +1,3 @@
+-- the secret webhook events of a tracker are authenticated with, webhooks are
+-- disabled without one
+ALTER TABLE trackers ADD COLUMN webhook_secret text;
@@ -0,0 +1,13 @@
+-- the identities remote users are imported as and the work item link types
+-- remote issue links are imported as, both keyed by their remote names
+ALTER TABLE trackers ADD COLUMN identity_mappings jsonb;
+ALTER TABLE trackers ADD COLUMN link_types jsonb;
+
+-- work item links imported from a remote tracker
+CREATE TABLE remote_links (
+    created_at     timestamp with time zone,
+    link_id        uuid primary key REFERENCES work_item_links(id) ON DELETE CASCADE,
+    tracker_id     bigint NOT NULL REFERENCES trackers(id) ON DELETE CASCADE,
+    remote_link_id text NOT NULL
+);
+CREATE UNIQUE INDEX remote_links_remote_idx ON remote_links (tracker_id, remote_link_id);
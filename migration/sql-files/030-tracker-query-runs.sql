@@ -0,0 +1,18 @@
+-- the runs of tracker queries and their outcome; the items hold the failed
+-- remote items, for dry runs the preview of all fetched items
+CREATE TABLE tracker_query_runs (
+    id               bigserial primary key,
+    tracker_query_id bigint NOT NULL REFERENCES tracker_queries(id) ON DELETE CASCADE,
+    dry_run          boolean DEFAULT false NOT NULL,
+    status           text NOT NULL CHECK(status IN ('running', 'succeeded', 'failed')),
+    started_at       timestamp with time zone NOT NULL,
+    finished_at      timestamp with time zone,
+    fetched          integer DEFAULT 0 NOT NULL,
+    created          integer DEFAULT 0 NOT NULL,
+    updated          integer DEFAULT 0 NOT NULL,
+    unchanged        integer DEFAULT 0 NOT NULL,
+    failed           integer DEFAULT 0 NOT NULL,
+    error            text,
+    items            jsonb
+);
+CREATE INDEX tracker_query_runs_query_idx ON tracker_query_runs (tracker_query_id, started_at);
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/milestone"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// MilestoneController implements the milestone resource.
+type MilestoneController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewMilestoneController creates a milestone controller.
+func NewMilestoneController(service *goa.Service, db application.DB) *MilestoneController {
+	if db == nil {
+		panic("db must not be nil")
+	}
+	return &MilestoneController{Controller: service.NewController("MilestoneController"), db: db}
+}
+
+// Show runs the show action.
+func (c *MilestoneController) Show(ctx *app.ShowMilestoneContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		m, err := loadMilestone(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(&app.Milestone{
+			Data: convertMilestoneFromModel(ctx.RequestData, m),
+		})
+	})
+}
+
+// Progress runs the progress action.
+func (c *MilestoneController) Progress(ctx *app.ProgressMilestoneContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		m, err := loadMilestone(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		progress, err := appl.Milestones().Progress(ctx.Context, m.ID, time.Now())
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(&app.MilestoneProgress{
+			Data: &app.MilestoneProgressData{
+				ID:   m.ID,
+				Type: "milestone-progress",
+				Attributes: &app.MilestoneProgressAttributes{
+					Total:       progress.Total,
+					Done:        progress.Done,
+					PercentDone: progress.PercentDone,
+					StateCounts: progress.StateCounts,
+					Overdue:     progress.Overdue,
+				},
+			},
+		})
+	})
+}
+
+// Update runs the update action.
+func (c *MilestoneController) Update(ctx *app.UpdateMilestoneContext) error {
+	attributes := ctx.Payload.Data.Attributes
+	if attributes == nil || attributes.Version == nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("data.attributes.version", nil).Expected("not nil"))
+		return ctx.BadRequest(jerrors)
+	}
+	// the error is returned from the transaction, which rolls back work items
+	// moved to another milestone when closing fails
+	var m *milestone.Milestone
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		m, err = loadMilestone(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			return err
+		}
+		m.Version = *attributes.Version
+		applyMilestoneAttributes(m, attributes)
+		m, err = appl.Milestones().Save(ctx.Context, *m, attributes.MoveOpenItemsTo)
+		return err
+	})
+	if err != nil {
+		jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+		return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+	}
+	return ctx.OK(&app.Milestone{
+		Data: convertMilestoneFromModel(ctx.RequestData, m),
+	})
+}
+
+// Delete runs the delete action.
+func (c *MilestoneController) Delete(ctx *app.DeleteMilestoneContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		m, err := loadMilestone(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		err = appl.Milestones().Delete(ctx.Context, m.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// applyMilestoneAttributes copies the attributes given in a payload to the
+// milestone. Attributes that are not given are left untouched.
+func applyMilestoneAttributes(m *milestone.Milestone, attributes *app.MilestoneAttributes) {
+	if attributes.Name != nil {
+		m.Name = *attributes.Name
+	}
+	if attributes.Description != nil {
+		m.Description = *attributes.Description
+	}
+	if attributes.DueAt != nil {
+		m.DueAt = attributes.DueAt
+	}
+	if attributes.State != nil {
+		m.State = *attributes.State
+	}
+}
+
+// convertMilestoneFromModel converts between internal and external REST representation
+func convertMilestoneFromModel(request *goa.RequestData, m *milestone.Milestone) *app.MilestoneData {
+	selfURL := absoluteURL(request, app.MilestoneHref(m.ID))
+	return &app.MilestoneData{
+		ID:   &m.ID,
+		Type: "milestones",
+		Attributes: &app.MilestoneAttributes{
+			Name:        &m.Name,
+			Description: &m.Description,
+			DueAt:       m.DueAt,
+			State:       &m.State,
+			Project:     &m.ProjectID,
+			Version:     &m.Version,
+			CreatedAt:   &m.CreatedAt,
+			UpdatedAt:   &m.UpdatedAt,
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
+
+// loadMilestone loads the milestone with the given ID, treating an ID that is
+// not a valid UUID like an unknown one.
+func loadMilestone(ctx context.Context, appl application.Application, id string) (*milestone.Milestone, error) {
+	milestoneID, err := uuid.FromString(id)
+	if err != nil {
+		return nil, errors.NewNotFoundError("milestone", id)
+	}
+	return appl.Milestones().Load(ctx, milestoneID)
+}
@@ -0,0 +1,305 @@
+package milestone
+
+import (
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// States a milestone can be in
+const (
+	StateOpen   = "open"
+	StateClosed = "closed"
+)
+
+// States holds all known milestone states
+var States = []string{StateOpen, StateClosed}
+
+// DoneStates holds the values of system.state of work items that count as done
+var DoneStates = []string{workitem.SystemStateResolved, workitem.SystemStateClosed}
+
+// IsValidState returns true if the given state is one of the known states
+func IsValidState(state string) bool {
+	for _, s := range States {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// isDone returns true if a work item in the given system.state counts as done
+func isDone(state string) bool {
+	for _, s := range DoneStates {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// Milestone is a release date of a project that work items can target by
+// referencing it in their system.milestone field.
+type Milestone struct {
+	gormsupport.Lifecycle
+	ID          uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	Version     int
+	ProjectID   uuid.UUID `sql:"type:uuid"`
+	Name        string
+	Description string
+	DueAt       *time.Time
+	State       string
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Milestone) TableName() string {
+	return "milestones"
+}
+
+// Progress sums up the work items targeting a milestone
+type Progress struct {
+	// Total is the number of work items targeting the milestone
+	Total int
+	// StateCounts holds the number of work items per system.state
+	StateCounts map[string]int
+	// Done is the number of work items in one of the DoneStates
+	Done int
+	// PercentDone is the share of done work items, 0 if there are no work items
+	PercentDone float64
+	// Overdue holds the IDs of the work items that are not done although the
+	// due date of the milestone has passed
+	Overdue []string
+}
+
+// Repository encapsulate storage & retrieval of milestones
+type Repository interface {
+	Create(ctx context.Context, m *Milestone) error
+	Load(ctx context.Context, id uuid.UUID) (*Milestone, error)
+	Save(ctx context.Context, m Milestone, moveOpenItemsTo *uuid.UUID) (*Milestone, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, projectID uuid.UUID) ([]*Milestone, error)
+	Progress(ctx context.Context, id uuid.UUID, now time.Time) (*Progress, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for milestones.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Create creates a new milestone. New milestones are open unless a state is
+// given.
+// returns BadParameterError or InternalError
+func (r *GormRepository) Create(ctx context.Context, m *Milestone) error {
+	defer goa.MeasureSince([]string{"goa", "db", "milestone", "create"}, time.Now())
+
+	if m.State == "" {
+		m.State = StateOpen
+	}
+	if !IsValidState(m.State) {
+		return errors.NewBadParameterError("state", m.State).Expected(States)
+	}
+	m.ID = uuid.NewV4()
+	tx := r.db.Create(m)
+	if err := tx.Error; err != nil {
+		return convertError(tx.Error, *m)
+	}
+	return nil
+}
+
+// Load returns the milestone for the given id
+// returns NotFoundError or InternalError
+func (r *GormRepository) Load(ctx context.Context, id uuid.UUID) (*Milestone, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "milestone", "load"}, time.Now())
+
+	res := Milestone{}
+	tx := r.db.Where("id = ?", id).First(&res)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("milestone", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &res, nil
+}
+
+// Save updates the given milestone in the db. Version must be the same as the
+// one in the stored version. The project of a milestone can not be changed.
+//
+// A milestone can only be closed while no work items that are not done target
+// it. If moveOpenItemsTo is given, those work items are re-targeted to that
+// open milestone of the same project before closing; otherwise closing fails.
+// returns NotFoundError, BadParameterError, VersionConflictError or InternalError
+func (r *GormRepository) Save(ctx context.Context, m Milestone, moveOpenItemsTo *uuid.UUID) (*Milestone, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "milestone", "save"}, time.Now())
+
+	// the row stays locked until the transaction ends, the version can not
+	// change between the check and the update then
+	existing := Milestone{}
+	tx := r.db.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", m.ID).First(&existing)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("milestone", m.ID.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	// checked before the open work items are moved, which must not happen for a stale version
+	if existing.Version != m.Version {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+	m.ProjectID = existing.ProjectID
+	if !IsValidState(m.State) {
+		return nil, errors.NewBadParameterError("state", m.State).Expected(States)
+	}
+	if m.State == StateClosed && existing.State != StateClosed {
+		if err := r.handleOpenItems(ctx, m, moveOpenItemsTo); err != nil {
+			return nil, err
+		}
+	}
+	oldVersion := m.Version
+	m.Version++
+	tx = r.db.Where("Version = ?", oldVersion).Save(&m)
+	if err := tx.Error; err != nil {
+		return nil, convertError(tx.Error, m)
+	}
+	if tx.RowsAffected == 0 {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+	return &m, nil
+}
+
+// handleOpenItems re-targets the work items of the milestone that are not
+// done to the given milestone or fails if there are such work items but no
+// milestone to move them to is given
+func (r *GormRepository) handleOpenItems(ctx context.Context, m Milestone, moveOpenItemsTo *uuid.UUID) error {
+	open := r.workItems(m.ID).Where("(Fields->>? NOT IN (?) OR Fields->>? IS NULL)", workitem.SystemState, DoneStates, workitem.SystemState)
+	if moveOpenItemsTo == nil {
+		var count int
+		if err := open.Count(&count).Error; err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+		if count > 0 {
+			return errors.NewBadParameterError("state", m.State).Expected("no open work items targeting the milestone or a milestone to move them to")
+		}
+		return nil
+	}
+	target, err := r.Load(ctx, *moveOpenItemsTo)
+	if err != nil {
+		if _, ok := err.(errors.NotFoundError); ok {
+			return errors.NewBadParameterError("moveOpenItemsTo", moveOpenItemsTo.String()).Expected("existing milestone")
+		}
+		return err
+	}
+	if uuid.Equal(target.ID, m.ID) || !uuid.Equal(target.ProjectID, m.ProjectID) || target.State != StateOpen {
+		return errors.NewBadParameterError("moveOpenItemsTo", moveOpenItemsTo.String()).Expected("another open milestone of the same project")
+	}
+	tx := open.UpdateColumns(map[string]interface{}{
+		"fields":     gorm.Expr("jsonb_set(fields, ?::text[], to_jsonb(?::text))", "{"+workitem.SystemMilestone+"}", target.ID.String()),
+		"version":    gorm.Expr("version + 1"),
+		"updated_at": gorm.NowFunc(),
+	})
+	if err := tx.Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Delete deletes the milestone with the given id. Milestones that are still
+// targeted by work items can not be deleted.
+// returns NotFoundError, BadParameterError or InternalError
+func (r *GormRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "milestone", "delete"}, time.Now())
+
+	var count int
+	if err := r.workItems(id).Count(&count).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if count > 0 {
+		return errors.NewBadParameterError("milestone", id.String()).Expected("no work items targeting the milestone")
+	}
+	tx := r.db.Delete(Milestone{ID: id})
+	if err := tx.Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("milestone", id.String())
+	}
+	return nil
+}
+
+// List returns all milestones of the project ordered by their due date
+// returns InternalError
+func (r *GormRepository) List(ctx context.Context, projectID uuid.UUID) ([]*Milestone, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "milestone", "list"}, time.Now())
+
+	var rows []*Milestone
+	if err := r.db.Where("project_id = ?", projectID).Order("due_at, created_at").Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return rows, nil
+}
+
+// Progress computes the progress of the milestone from the work items
+// targeting it. Work items are overdue if they are not done while the due date
+// of the milestone lies before now.
+// returns NotFoundError or InternalError
+func (r *GormRepository) Progress(ctx context.Context, id uuid.UUID, now time.Time) (*Progress, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "milestone", "progress"}, time.Now())
+
+	m, err := r.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var items []workitem.WorkItem
+	if err := r.workItems(m.ID).Order("id").Find(&items).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	overdue := m.DueAt != nil && m.DueAt.Before(now)
+	res := Progress{
+		StateCounts: map[string]int{},
+		Overdue:     []string{},
+	}
+	for _, wi := range items {
+		state, _ := wi.Fields[workitem.SystemState].(string)
+		res.Total++
+		res.StateCounts[state]++
+		if isDone(state) {
+			res.Done++
+		} else if overdue {
+			res.Overdue = append(res.Overdue, strconv.FormatUint(wi.ID, 10))
+		}
+	}
+	if res.Total > 0 {
+		res.PercentDone = float64(res.Done) * 100 / float64(res.Total)
+	}
+	return &res, nil
+}
+
+// workItems returns a query for all work items targeting the milestone
+func (r *GormRepository) workItems(id uuid.UUID) *gorm.DB {
+	return r.db.Model(&workitem.WorkItem{}).Where("Fields->>? = ?", workitem.SystemMilestone, id.String())
+}
+
+// convertError turns constraint violations into BadParameterErrors
+func convertError(err error, m Milestone) error {
+	if gormsupport.IsCheckViolation(err, "milestones_name_check") {
+		return errors.NewBadParameterError("name", m.Name).Expected("not empty")
+	}
+	if gormsupport.IsUniqueViolation(err, "milestones_project_name_idx") {
+		return errors.NewBadParameterError("name", m.Name).Expected("unique in the project")
+	}
+	return errors.NewInternalError(err.Error())
+}
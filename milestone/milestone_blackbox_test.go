@@ -0,0 +1,170 @@
+package milestone_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/milestone"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+func TestRunMilestoneRepoBBTest(t *testing.T) {
+	suite.Run(t, &milestoneRepoBBTest{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+type milestoneRepoBBTest struct {
+	gormsupport.DBTestSuite
+	clean   func()
+	repo    milestone.Repository
+	project *project.Project
+}
+
+func (test *milestoneRepoBBTest) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+	test.repo = milestone.NewRepository(test.DB)
+	p, err := project.NewRepository(test.DB).Create(context.Background(), "milestone-test-"+uuid.NewV4().String())
+	require.Nil(test.T(), err)
+	test.project = p
+}
+
+func (test *milestoneRepoBBTest) TearDownTest() {
+	test.clean()
+}
+
+func (test *milestoneRepoBBTest) create(name string, dueAt *time.Time) *milestone.Milestone {
+	m := milestone.Milestone{ProjectID: test.project.ID, Name: name, DueAt: dueAt}
+	require.Nil(test.T(), test.repo.Create(context.Background(), &m))
+	return &m
+}
+
+// createWorkItem creates a work item in the given state targeting the milestone
+func (test *milestoneRepoBBTest) createWorkItem(m *milestone.Milestone, state string) string {
+	wi, err := workitem.NewWorkItemRepository(test.DB).Create(context.Background(), test.project.ID, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle:     "targeting " + m.Name,
+		workitem.SystemState:     state,
+		workitem.SystemMilestone: m.ID.String(),
+	}, "xx")
+	require.Nil(test.T(), err)
+	return wi.ID
+}
+
+func (test *milestoneRepoBBTest) TestCreateAndLoad() {
+	m := test.create("1.0", nil)
+	assert.Equal(test.T(), milestone.StateOpen, m.State)
+
+	loaded, err := test.repo.Load(context.Background(), m.ID)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), "1.0", loaded.Name)
+
+	_, err = test.repo.Load(context.Background(), uuid.NewV4())
+	assert.IsType(test.T(), errors.NotFoundError{}, err)
+
+	err = test.repo.Create(context.Background(), &milestone.Milestone{ProjectID: test.project.ID, Name: "1.0"})
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+	err = test.repo.Create(context.Background(), &milestone.Milestone{ProjectID: test.project.ID, Name: "2.0", State: "shipped"})
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+}
+
+func (test *milestoneRepoBBTest) TestProgress() {
+	due := time.Now().Add(-time.Hour)
+	m := test.create("1.0", &due)
+	test.createWorkItem(m, workitem.SystemStateClosed)
+	test.createWorkItem(m, workitem.SystemStateResolved)
+	test.createWorkItem(m, workitem.SystemStateInProgress)
+	open := test.createWorkItem(m, workitem.SystemStateNew)
+
+	progress, err := test.repo.Progress(context.Background(), m.ID, time.Now())
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), 4, progress.Total)
+	assert.Equal(test.T(), 2, progress.Done)
+	assert.Equal(test.T(), 50.0, progress.PercentDone)
+	assert.Equal(test.T(), 1, progress.StateCounts[workitem.SystemStateNew])
+	assert.Len(test.T(), progress.Overdue, 2)
+	assert.Contains(test.T(), progress.Overdue, open)
+
+	// nothing is overdue before the due date
+	progress, err = test.repo.Progress(context.Background(), m.ID, due.Add(-time.Hour))
+	require.Nil(test.T(), err)
+	assert.Len(test.T(), progress.Overdue, 0)
+
+	empty := test.create("2.0", nil)
+	progress, err = test.repo.Progress(context.Background(), empty.ID, time.Now())
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), 0, progress.Total)
+	assert.Equal(test.T(), 0.0, progress.PercentDone)
+}
+
+func (test *milestoneRepoBBTest) TestCloseWithOpenItems() {
+	m := test.create("1.0", nil)
+	next := test.create("1.1", nil)
+	test.createWorkItem(m, workitem.SystemStateClosed)
+	test.createWorkItem(m, workitem.SystemStateNew)
+
+	m.State = milestone.StateClosed
+	_, err := test.repo.Save(context.Background(), *m, nil)
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+	_, err = test.repo.Save(context.Background(), *m, &m.ID)
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+
+	closed, err := test.repo.Save(context.Background(), *m, &next.ID)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), milestone.StateClosed, closed.State)
+
+	progress, err := test.repo.Progress(context.Background(), m.ID, time.Now())
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), 1, progress.Total)
+	progress, err = test.repo.Progress(context.Background(), next.ID, time.Now())
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), 1, progress.Total)
+	assert.Equal(test.T(), 1, progress.StateCounts[workitem.SystemStateNew])
+}
+
+func (test *milestoneRepoBBTest) TestCloseStaleVersion() {
+	m := test.create("1.0", nil)
+	next := test.create("1.1", nil)
+	test.createWorkItem(m, workitem.SystemStateNew)
+	stateless := test.createWorkItem(m, workitem.SystemStateNew)
+	require.Nil(test.T(), test.DB.Exec("UPDATE work_items SET fields = fields - ? WHERE id = ?", workitem.SystemState, stateless).Error)
+
+	// nothing is moved for a stale version
+	stale := *m
+	stale.Version--
+	stale.State = milestone.StateClosed
+	_, err := test.repo.Save(context.Background(), stale, &next.ID)
+	assert.IsType(test.T(), errors.VersionConflictError{}, err)
+	progress, err := test.repo.Progress(context.Background(), m.ID, time.Now())
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), 2, progress.Total)
+
+	// work items without a state are not done
+	m.State = milestone.StateClosed
+	_, err = test.repo.Save(context.Background(), *m, nil)
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+	_, err = test.repo.Save(context.Background(), *m, &next.ID)
+	require.Nil(test.T(), err)
+	progress, err = test.repo.Progress(context.Background(), next.ID, time.Now())
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), 2, progress.Total)
+}
+
+func (test *milestoneRepoBBTest) TestListAndDelete() {
+	m := test.create("1.0", nil)
+	empty := test.create("2.0", nil)
+	test.createWorkItem(m, workitem.SystemStateNew)
+
+	milestones, err := test.repo.List(context.Background(), test.project.ID)
+	require.Nil(test.T(), err)
+	assert.Len(test.T(), milestones, 2)
+
+	assert.IsType(test.T(), errors.BadParameterError{}, test.repo.Delete(context.Background(), m.ID))
+	require.Nil(test.T(), test.repo.Delete(context.Background(), empty.ID))
+	assert.IsType(test.T(), errors.NotFoundError{}, test.repo.Delete(context.Background(), empty.ID))
+}
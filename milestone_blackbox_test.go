@@ -0,0 +1,123 @@
+package main_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/milestone"
+	"github.com/almighty/almighty-core/resource"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestMilestoneREST struct {
+	gormsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	clean func()
+}
+
+func TestRunMilestoneREST(t *testing.T) {
+	suite.Run(t, &TestMilestoneREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestMilestoneREST) SetupTest() {
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = gormsupport.DeleteCreatedEntities(rest.DB)
+}
+
+func (rest *TestMilestoneREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestMilestoneREST) service() *goa.Service {
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+	return testsupport.ServiceAsUser("Milestone-Service", almtoken.NewManager(pub, priv), account.TestIdentity)
+}
+
+func (rest *TestMilestoneREST) createMilestone(svc *goa.Service, projectID string, name string, dueAt *time.Time) *app.Milestone {
+	_, m := test.CreateProjectMilestonesCreated(rest.T(), svc.Context, svc, NewProjectMilestonesController(svc, rest.db), projectID, &app.CreateMilestonePayload{
+		Data: &app.MilestoneData{
+			Type:       "milestones",
+			Attributes: &app.MilestoneAttributes{Name: &name, DueAt: dueAt},
+		},
+	})
+	return m
+}
+
+func (rest *TestMilestoneREST) TestProgressAndClose() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc := rest.service()
+	name := "TestMilestoneREST-" + uuid.NewV4().String()
+	_, p := test.CreateProjectCreated(t, svc.Context, svc, NewProjectController(svc, rest.db), createProjectPayload(&name, nil))
+	projectID := p.Data.ID.String()
+	due := time.Now().Add(-time.Hour)
+	release := rest.createMilestone(svc, projectID, "1.0", &due)
+	next := rest.createMilestone(svc, projectID, "1.1", nil)
+	assert.Equal(t, milestone.StateOpen, *release.Data.Attributes.State)
+
+	wiCtrl := NewProjectWorkItemsController(svc, rest.db)
+	for _, state := range []string{workitem.SystemStateClosed, workitem.SystemStateNew} {
+		test.CreateProjectWorkItemsCreated(t, svc.Context, svc, wiCtrl, projectID, &app.CreateWorkItemPayload{
+			Type: workitem.SystemBug,
+			Fields: map[string]interface{}{
+				workitem.SystemTitle:     "release work",
+				workitem.SystemState:     state,
+				workitem.SystemMilestone: release.Data.ID.String(),
+			},
+		})
+	}
+
+	ctrl := NewMilestoneController(svc, rest.db)
+	_, progress := test.ProgressMilestoneOK(t, svc.Context, svc, ctrl, release.Data.ID.String())
+	assert.Equal(t, 2, progress.Data.Attributes.Total)
+	assert.Equal(t, 1, progress.Data.Attributes.Done)
+	assert.Equal(t, 50.0, progress.Data.Attributes.PercentDone)
+	assert.Equal(t, 1, progress.Data.Attributes.StateCounts[workitem.SystemStateNew])
+	assert.Len(t, progress.Data.Attributes.Overdue, 1)
+
+	closed := milestone.StateClosed
+	test.UpdateMilestoneBadRequest(t, svc.Context, svc, ctrl, release.Data.ID.String(), &app.UpdateMilestonePayload{
+		Data: &app.MilestoneData{
+			Type:       "milestones",
+			Attributes: &app.MilestoneAttributes{State: &closed, Version: release.Data.Attributes.Version},
+		},
+	})
+	_, updated := test.UpdateMilestoneOK(t, svc.Context, svc, ctrl, release.Data.ID.String(), &app.UpdateMilestonePayload{
+		Data: &app.MilestoneData{
+			Type: "milestones",
+			Attributes: &app.MilestoneAttributes{
+				State:           &closed,
+				MoveOpenItemsTo: next.Data.ID,
+				Version:         release.Data.Attributes.Version,
+			},
+		},
+	})
+	assert.Equal(t, milestone.StateClosed, *updated.Data.Attributes.State)
+
+	_, progress = test.ProgressMilestoneOK(t, svc.Context, svc, ctrl, next.Data.ID.String())
+	assert.Equal(t, 1, progress.Data.Attributes.Total)
+	assert.Len(t, progress.Data.Attributes.Overdue, 0)
+
+	_, list := test.ListProjectMilestonesOK(t, svc.Context, svc, NewProjectMilestonesController(svc, rest.db), projectID)
+	assert.Len(t, list.Data, 2)
+
+	test.DeleteMilestoneBadRequest(t, svc.Context, svc, ctrl, next.Data.ID.String())
+	test.ProgressMilestoneNotFound(t, svc.Context, svc, ctrl, uuid.NewV4().String())
+	require.NotNil(t, list.Data[0].Attributes.Project)
+}
@@ -1,11 +1,17 @@
 package main
 
+import "github.com/almighty/almighty-core/project"
+
 // PermissionDefinition defines the Permissions available
 type PermissionDefinition struct {
 	CreateWorkItem string
 	ReadWorkItem   string
 	UpdateWorkItem string
 	DeleteWorkItem string
+	ManageProject  string
+	// ManageAccount is not granted by project roles. It is the scope access
+	// tokens need in order to manage identities and their credentials.
+	ManageAccount string
 }
 
 // CRUDWorkItem returns all CRUD permissions for a WorkItem
@@ -20,5 +26,34 @@ var (
 		ReadWorkItem:   "read.workitem",
 		UpdateWorkItem: "update.workitem",
 		DeleteWorkItem: "delete.workitem",
+		ManageProject:  "manage.project",
+		ManageAccount:  "manage.account",
+	}
+
+	// AccessTokenScopes lists the permissions an access token can be limited to
+	AccessTokenScopes = []string{
+		Permissions.CreateWorkItem,
+		Permissions.ReadWorkItem,
+		Permissions.UpdateWorkItem,
+		Permissions.DeleteWorkItem,
+		Permissions.ManageProject,
+		Permissions.ManageAccount,
+	}
+
+	// RolePermissions maps each project role to the permissions it grants
+	RolePermissions = map[string][]string{
+		project.RoleOwner:       append(Permissions.CRUDWorkItem(), Permissions.ManageProject),
+		project.RoleContributor: Permissions.CRUDWorkItem(),
+		project.RoleViewer:      []string{Permissions.ReadWorkItem},
 	}
 )
+
+// RoleHasPermission returns true if the given role grants the given permission
+func RoleHasPermission(role string, permission string) bool {
+	for _, p := range RolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
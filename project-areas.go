@@ -0,0 +1,67 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/area"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+)
+
+// ProjectAreasController implements the project-areas resource.
+type ProjectAreasController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectAreasController creates a project-areas controller.
+func NewProjectAreasController(service *goa.Service, db application.DB) *ProjectAreasController {
+	if db == nil {
+		panic("db must not be nil")
+	}
+	return &ProjectAreasController{Controller: service.NewController("ProjectAreasController"), db: db}
+}
+
+// List runs the list action.
+func (c *ProjectAreasController) List(ctx *app.ListProjectAreasContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := loadProject(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		areas, err := appl.Areas().List(ctx.Context, p.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(convertAreasFromModel(ctx.RequestData, areas))
+	})
+}
+
+// Create runs the create action.
+func (c *ProjectAreasController) Create(ctx *app.CreateProjectAreasContext) error {
+	attributes := ctx.Payload.Data.Attributes
+	if attributes == nil || attributes.Name == nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("data.attributes.name", nil).Expected("not nil"))
+		return ctx.BadRequest(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := loadProject(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		a := area.Area{ProjectID: p.ID, Name: *attributes.Name}
+		err = appl.Areas().Create(ctx.Context, &a, attributes.Parent)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		ctx.ResponseData.Header().Set("Location", app.AreaHref(a.ID))
+		return ctx.Created(&app.Area{
+			Data: convertAreaFromModel(ctx.RequestData, &a),
+		})
+	})
+}
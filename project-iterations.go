@@ -0,0 +1,81 @@
+package main
+
+import (
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+)
+
+// ProjectIterationsController implements the project-iterations resource.
+type ProjectIterationsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectIterationsController creates a project-iterations controller.
+func NewProjectIterationsController(service *goa.Service, db application.DB) *ProjectIterationsController {
+	if db == nil {
+		panic("db must not be nil")
+	}
+	return &ProjectIterationsController{Controller: service.NewController("ProjectIterationsController"), db: db}
+}
+
+// List runs the list action.
+func (c *ProjectIterationsController) List(ctx *app.ListProjectIterationsContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := loadProject(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		var iterations []*iteration.Iteration
+		if ctx.Current != nil && *ctx.Current {
+			iterations, err = appl.Iterations().Current(ctx.Context, p.ID, time.Now())
+		} else {
+			iterations, err = appl.Iterations().List(ctx.Context, p.ID)
+		}
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		res := &app.IterationArray{
+			Data: make([]*app.IterationData, len(iterations)),
+		}
+		for index, i := range iterations {
+			res.Data[index] = convertIterationFromModel(ctx.RequestData, i)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Create runs the create action.
+func (c *ProjectIterationsController) Create(ctx *app.CreateProjectIterationsContext) error {
+	attributes := ctx.Payload.Data.Attributes
+	if attributes == nil || attributes.Name == nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("data.attributes.name", nil).Expected("not nil"))
+		return ctx.BadRequest(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := loadProject(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		i := iteration.Iteration{ProjectID: p.ID}
+		applyIterationAttributes(&i, attributes)
+		err = appl.Iterations().Create(ctx.Context, &i)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		ctx.ResponseData.Header().Set("Location", app.IterationHref(i.ID))
+		return ctx.Created(&app.Iteration{
+			Data: convertIterationFromModel(ctx.RequestData, &i),
+		})
+	})
+}
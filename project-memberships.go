@@ -0,0 +1,121 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/project"
+	"github.com/goadesign/goa"
+)
+
+// ProjectMembershipsController implements the project-memberships resource.
+type ProjectMembershipsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectMembershipsController creates a project-memberships controller.
+func NewProjectMembershipsController(service *goa.Service, db application.DB) *ProjectMembershipsController {
+	if db == nil {
+		panic("db must not be nil")
+	}
+	return &ProjectMembershipsController{Controller: service.NewController("ProjectMembershipsController"), db: db}
+}
+
+// List runs the list action.
+func (c *ProjectMembershipsController) List(ctx *app.ListProjectMembershipsContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := loadProject(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		memberships, err := appl.ProjectMemberships().List(ctx.Context, p.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		res := &app.ProjectMembershipArray{
+			Data: make([]*app.ProjectMembershipData, len(memberships)),
+		}
+		for index, m := range memberships {
+			res.Data[index] = convertProjectMembershipFromModel(m)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Create runs the create action.
+func (c *ProjectMembershipsController) Create(ctx *app.CreateProjectMembershipsContext) error {
+	attributes := ctx.Payload.Data.Attributes
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := loadProject(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		if _, err := loadIdentity(ctx.Context, appl, attributes.Identity); err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		m, err := appl.ProjectMemberships().Create(ctx.Context, p.ID, attributes.Identity, attributes.Role)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.Created(&app.ProjectMembership{
+			Data: convertProjectMembershipFromModel(m),
+		})
+	})
+}
+
+// Delete runs the delete action. The last owner of a project can not be removed.
+func (c *ProjectMembershipsController) Delete(ctx *app.DeleteProjectMembershipsContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := loadProject(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		memberships, err := appl.ProjectMemberships().List(ctx.Context, p.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		owners := 0
+		removesOwner := false
+		for _, m := range memberships {
+			if m.Role == project.RoleOwner {
+				owners++
+				if m.IdentityID == ctx.IdentityID {
+					removesOwner = true
+				}
+			}
+		}
+		if removesOwner && owners == 1 {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("identityID", ctx.IdentityID).Expected("not the last owner of the project"))
+			return ctx.BadRequest(jerrors)
+		}
+
+		err = appl.ProjectMemberships().Delete(ctx.Context, p.ID, ctx.IdentityID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// convertProjectMembershipFromModel converts between internal and external REST representation
+func convertProjectMembershipFromModel(m *project.Membership) *app.ProjectMembershipData {
+	return &app.ProjectMembershipData{
+		ID:   &m.ID,
+		Type: "project-memberships",
+		Attributes: &app.ProjectMembershipAttributes{
+			Identity:  m.IdentityID,
+			Role:      m.Role,
+			CreatedAt: &m.CreatedAt,
+		},
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/milestone"
+	"github.com/goadesign/goa"
+)
+
+// ProjectMilestonesController implements the project-milestones resource.
+type ProjectMilestonesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectMilestonesController creates a project-milestones controller.
+func NewProjectMilestonesController(service *goa.Service, db application.DB) *ProjectMilestonesController {
+	if db == nil {
+		panic("db must not be nil")
+	}
+	return &ProjectMilestonesController{Controller: service.NewController("ProjectMilestonesController"), db: db}
+}
+
+// List runs the list action.
+func (c *ProjectMilestonesController) List(ctx *app.ListProjectMilestonesContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := loadProject(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		milestones, err := appl.Milestones().List(ctx.Context, p.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		res := &app.MilestoneArray{
+			Data: make([]*app.MilestoneData, len(milestones)),
+		}
+		for index, m := range milestones {
+			res.Data[index] = convertMilestoneFromModel(ctx.RequestData, m)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Create runs the create action.
+func (c *ProjectMilestonesController) Create(ctx *app.CreateProjectMilestonesContext) error {
+	attributes := ctx.Payload.Data.Attributes
+	if attributes == nil || attributes.Name == nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("data.attributes.name", nil).Expected("not nil"))
+		return ctx.BadRequest(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := loadProject(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		m := milestone.Milestone{ProjectID: p.ID}
+		applyMilestoneAttributes(&m, attributes)
+		err = appl.Milestones().Create(ctx.Context, &m)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		ctx.ResponseData.Header().Set("Location", app.MilestoneHref(m.ID))
+		return ctx.Created(&app.Milestone{
+			Data: convertMilestoneFromModel(ctx.RequestData, &m),
+		})
+	})
+}
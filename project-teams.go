@@ -0,0 +1,96 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/team"
+	"github.com/goadesign/goa"
+)
+
+// ProjectTeamsController implements the project-teams resource.
+type ProjectTeamsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectTeamsController creates a project-teams controller.
+func NewProjectTeamsController(service *goa.Service, db application.DB) *ProjectTeamsController {
+	if db == nil {
+		panic("db must not be nil")
+	}
+	return &ProjectTeamsController{Controller: service.NewController("ProjectTeamsController"), db: db}
+}
+
+// List runs the list action.
+func (c *ProjectTeamsController) List(ctx *app.ListProjectTeamsContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := loadProject(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		roles, err := appl.Teams().ListRoles(ctx.Context, p.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		res := &app.ProjectTeamArray{
+			Data: make([]*app.ProjectTeamData, len(roles)),
+		}
+		for index, r := range roles {
+			res.Data[index] = convertProjectTeamFromModel(r)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Create runs the create action.
+func (c *ProjectTeamsController) Create(ctx *app.CreateProjectTeamsContext) error {
+	attributes := ctx.Payload.Data.Attributes
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := loadProject(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		r, err := appl.Teams().GrantRole(ctx.Context, p.ID, attributes.Team, attributes.Role)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.Created(&app.ProjectTeam{
+			Data: convertProjectTeamFromModel(r),
+		})
+	})
+}
+
+// Delete runs the delete action.
+func (c *ProjectTeamsController) Delete(ctx *app.DeleteProjectTeamsContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := loadProject(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		err = appl.Teams().RevokeRole(ctx.Context, p.ID, ctx.TeamID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// convertProjectTeamFromModel converts between internal and external REST representation
+func convertProjectTeamFromModel(r *team.ProjectRole) *app.ProjectTeamData {
+	return &app.ProjectTeamData{
+		ID:   &r.ID,
+		Type: "project-teams",
+		Attributes: &app.ProjectTeamAttributes{
+			Team:      r.TeamID,
+			Role:      r.Role,
+			CreatedAt: &r.CreatedAt,
+		},
+	}
+}
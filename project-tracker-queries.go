@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/goadesign/goa"
+)
+
+// ProjectTrackerQueriesController implements the project-tracker-queries resource.
+type ProjectTrackerQueriesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectTrackerQueriesController creates a project-tracker-queries controller.
+func NewProjectTrackerQueriesController(service *goa.Service, db application.DB) *ProjectTrackerQueriesController {
+	if db == nil {
+		panic("db must not be nil")
+	}
+	return &ProjectTrackerQueriesController{Controller: service.NewController("ProjectTrackerQueriesController"), db: db}
+}
+
+// List runs the list action.
+func (c *ProjectTrackerQueriesController) List(ctx *app.ListProjectTrackerQueriesContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := loadProject(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		result, err := appl.TrackerQueries().List(ctx.Context, &p.ID)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(fmt.Sprintf("Error listing tracker queries: %s", err.Error())))
+			return ctx.InternalServerError(jerrors)
+		}
+		return ctx.OK(result)
+	})
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/project"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ProjectWorkItemTypesController implements the project-work-item-types resource.
+type ProjectWorkItemTypesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectWorkItemTypesController creates a project-work-item-types controller.
+func NewProjectWorkItemTypesController(service *goa.Service, db application.DB) *ProjectWorkItemTypesController {
+	if db == nil {
+		panic("db must not be nil")
+	}
+	return &ProjectWorkItemTypesController{Controller: service.NewController("ProjectWorkItemTypesController"), db: db}
+}
+
+// Show runs the show action. Types that are not usable in the project are
+// treated as unknown.
+func (c *ProjectWorkItemTypesController) Show(ctx *app.ShowProjectWorkItemTypesContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := loadProject(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		wit, err := appl.WorkItemTypes().Load(ctx.Context, ctx.Name)
+		if err == nil && wit.Project != nil && !uuid.Equal(*wit.Project, p.ID) && !uuid.Equal(*wit.Project, project.SystemProject) {
+			err = errors.NewNotFoundError("work item type", ctx.Name)
+		}
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(wit)
+	})
+}
+
+// List runs the list action. The result contains the types defined in the
+// project as well as the system defined types.
+func (c *ProjectWorkItemTypesController) List(ctx *app.ListProjectWorkItemTypesContext) error {
+	start, limit, err := parseLimit(ctx.Page)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("could not parse paging: %s", err.Error())))
+		return ctx.BadRequest(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := loadProject(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		result, err := appl.WorkItemTypes().List(ctx.Context, p.ID, start, &limit)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("Error listing work item types: %s", err.Error())))
+			return ctx.BadRequest(jerrors)
+		}
+		return ctx.OK(result)
+	})
+}
+
+// Create runs the create action.
+func (c *ProjectWorkItemTypesController) Create(ctx *app.CreateProjectWorkItemTypesContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := loadProject(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		var fields = map[string]app.FieldDefinition{}
+		for key, fd := range ctx.Payload.Fields {
+			fields[key] = *fd
+		}
+		wit, err := appl.WorkItemTypes().Create(ctx.Context, p.ID, ctx.Payload.ExtendedTypeName, ctx.Payload.Name, fields)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		ctx.ResponseData.Header().Set("Location", app.ProjectWorkItemTypesHref(p.ID, wit.Name))
+		return ctx.Created(wit)
+	})
+}
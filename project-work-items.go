@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/area"
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	query "github.com/almighty/almighty-core/query/simple"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ProjectWorkItemsController implements the project-work-items resource.
+type ProjectWorkItemsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectWorkItemsController creates a project-work-items controller.
+func NewProjectWorkItemsController(service *goa.Service, db application.DB) *ProjectWorkItemsController {
+	if db == nil {
+		panic("db must not be nil")
+	}
+	return &ProjectWorkItemsController{Controller: service.NewController("ProjectWorkItemsController"), db: db}
+}
+
+// List runs the list action.
+func (c *ProjectWorkItemsController) List(ctx *app.ListProjectWorkItemsContext) error {
+	exp, err := query.Parse(ctx.Filter)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("could not parse filter: %s", err.Error())))
+		return ctx.BadRequest(jerrors)
+	}
+	offset, limit := computePagingLimits(ctx.PageOffset, ctx.PageLimit)
+
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := loadProject(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		exp = criteria.And(exp, criteria.Equals(criteria.Field("Project"), criteria.Literal(p.ID.String())))
+		if ctx.Iteration != nil {
+			iterations, err := projectIterations(ctx.Context, appl, p.ID, *ctx.Iteration)
+			if err != nil {
+				jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+				return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+			}
+			exp = criteria.And(exp, iteration.InIterations(iterations))
+		}
+		if ctx.Area != nil {
+			areas, err := projectAreaSubtree(ctx.Context, appl, p.ID, *ctx.Area)
+			if err != nil {
+				jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+				return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+			}
+			exp = criteria.And(exp, area.InAreas(areas))
+		}
+
+		result, c, err := appl.WorkItems().List(ctx.Context, exp, &offset, &limit)
+		count := int(c)
+		if err != nil {
+			switch err := err.(type) {
+			case errors.BadParameterError:
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("Error listing work items: %s", err.Error())))
+				return ctx.BadRequest(jerrors)
+			default:
+				log.Printf("Error listing work items: %s", err.Error())
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(fmt.Sprintf("Error listing work items: %s", err.Error())))
+				return ctx.InternalServerError(jerrors)
+			}
+		}
+
+		response := app.WorkItemListResponse{
+			Links: &app.PagingLinks{},
+			Meta:  &app.WorkItemListResponseMeta{TotalCount: count},
+			Data:  result,
+		}
+		setPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), len(result), offset, limit, count)
+
+		return ctx.OK(&response)
+	})
+}
+
+// Create runs the create action.
+func (c *ProjectWorkItemsController) Create(ctx *app.CreateProjectWorkItemsContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := loadProject(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		wi, err := appl.WorkItems().Create(ctx.Context, p.ID, ctx.Payload.Type, ctx.Payload.Fields, currentUser)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		ctx.ResponseData.Header().Set("Location", app.WorkitemHref(wi.ID))
+		return ctx.Created(wi)
+	})
+}
+
+// projectIterations returns the iterations of the project a work item list
+// should be restricted to: either all current iterations if iterationID is
+// "current" or the single iteration with the given ID.
+func projectIterations(ctx context.Context, appl application.Application, projectID uuid.UUID, iterationID string) ([]*iteration.Iteration, error) {
+	if iterationID == "current" {
+		return appl.Iterations().Current(ctx, projectID, time.Now())
+	}
+	i, err := loadIteration(ctx, appl, iterationID)
+	if err != nil {
+		return nil, err
+	}
+	if !uuid.Equal(i.ProjectID, projectID) {
+		return nil, errors.NewNotFoundError("iteration", iterationID)
+	}
+	return []*iteration.Iteration{i}, nil
+}
+
+// projectAreaSubtree returns the area of the project with the given ID
+// together with all its descendants
+func projectAreaSubtree(ctx context.Context, appl application.Application, projectID uuid.UUID, areaID string) ([]*area.Area, error) {
+	a, err := loadArea(ctx, appl, areaID)
+	if err != nil {
+		return nil, err
+	}
+	if !uuid.Equal(a.ProjectID, projectID) {
+		return nil, errors.NewNotFoundError("area", areaID)
+	}
+	return appl.Areas().Subtree(ctx, a.ID)
+}
@@ -0,0 +1,179 @@
+package main_test
+
+import (
+	"testing"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestProjectWorkItemsREST struct {
+	gormsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	clean func()
+}
+
+func TestRunProjectWorkItemsREST(t *testing.T) {
+	suite.Run(t, &TestProjectWorkItemsREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestProjectWorkItemsREST) SetupTest() {
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = gormsupport.DeleteCreatedEntities(rest.DB)
+}
+
+func (rest *TestProjectWorkItemsREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestProjectWorkItemsREST) service() *goa.Service {
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+	return testsupport.ServiceAsUser("ProjectWorkItems-Service", almtoken.NewManager(pub, priv), account.TestIdentity)
+}
+
+func (rest *TestProjectWorkItemsREST) createProject(svc *goa.Service) string {
+	name := "TestProjectWorkItemsREST-" + uuid.NewV4().String()
+	_, p := test.CreateProjectCreated(rest.T(), svc.Context, svc, NewProjectController(svc, rest.db), createProjectPayload(&name, nil))
+	return p.Data.ID.String()
+}
+
+func (rest *TestProjectWorkItemsREST) TestCreateAndListWorkItemsInProject() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc := rest.service()
+	ctrl := NewProjectWorkItemsController(svc, rest.db)
+	projectA := rest.createProject(svc)
+	projectB := rest.createProject(svc)
+
+	payload := app.CreateWorkItemPayload{
+		Type: workitem.SystemBug,
+		Fields: map[string]interface{}{
+			workitem.SystemTitle: "project scoped",
+			workitem.SystemState: "new",
+		},
+	}
+	_, created := test.CreateProjectWorkItemsCreated(t, svc.Context, svc, ctrl, projectA, &payload)
+	require.NotNil(t, created.Project)
+	assert.Equal(t, projectA, created.Project.String())
+
+	_, listA := test.ListProjectWorkItemsOK(t, svc.Context, svc, ctrl, projectA, nil, nil, nil, nil, nil)
+	require.Len(t, listA.Data, 1)
+	assert.Equal(t, created.ID, listA.Data[0].ID)
+
+	_, listB := test.ListProjectWorkItemsOK(t, svc.Context, svc, ctrl, projectB, nil, nil, nil, nil, nil)
+	assert.Len(t, listB.Data, 0)
+}
+
+func (rest *TestProjectWorkItemsREST) TestWorkItemTypesInProject() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc := rest.service()
+	ctrl := NewProjectWorkItemTypesController(svc, rest.db)
+	projectA := rest.createProject(svc)
+	projectB := rest.createProject(svc)
+
+	name := "project-type-" + uuid.NewV4().String()
+	payload := app.CreateWorkItemTypePayload{
+		Fields: map[string]*app.FieldDefinition{},
+		Name:   name,
+	}
+	res, _ := test.CreateProjectWorkItemTypesCreated(t, svc.Context, svc, ctrl, projectA, &payload)
+	assert.Equal(t, app.ProjectWorkItemTypesHref(projectA, name), res.Header().Get("Location"))
+	_, wit := test.ShowProjectWorkItemTypesOK(t, svc.Context, svc, ctrl, projectA, name)
+	assert.Equal(t, name, wit.Name)
+	test.ShowProjectWorkItemTypesNotFound(t, svc.Context, svc, ctrl, projectB, name)
+
+	containsType := func(wits app.WorkItemTypeCollection, name string) bool {
+		for _, wit := range wits {
+			if wit.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	_, typesA := test.ListProjectWorkItemTypesOK(t, svc.Context, svc, ctrl, projectA, nil)
+	assert.True(t, containsType(typesA, name))
+	assert.True(t, containsType(typesA, workitem.SystemBug))
+
+	_, typesB := test.ListProjectWorkItemTypesOK(t, svc.Context, svc, ctrl, projectB, nil)
+	assert.False(t, containsType(typesB, name))
+	assert.True(t, containsType(typesB, workitem.SystemBug))
+
+	// a work item of a type that belongs to another project must not be created
+	wiPayload := app.CreateWorkItemPayload{
+		Type: name,
+		Fields: map[string]interface{}{
+			workitem.SystemTitle: "wrong project",
+			workitem.SystemState: "new",
+		},
+	}
+	test.CreateProjectWorkItemsBadRequest(t, svc.Context, svc, NewProjectWorkItemsController(svc, rest.db), projectB, &wiPayload)
+}
+
+func (rest *TestProjectWorkItemsREST) TestGlobalRoutesWithProject() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc := rest.service()
+	projectA := rest.createProject(svc)
+	projectID, err := uuid.FromString(projectA)
+	require.Nil(t, err)
+
+	payload := app.CreateWorkItemPayload{
+		Type: workitem.SystemBug,
+		Fields: map[string]interface{}{
+			workitem.SystemTitle: "project given as parameter",
+			workitem.SystemState: "new",
+		},
+	}
+	_, created := test.CreateWorkitemCreated(t, svc.Context, svc, NewWorkitemController(svc, rest.db), &projectID, &payload)
+	require.NotNil(t, created.Project)
+	assert.Equal(t, projectA, created.Project.String())
+
+	name := "project-type-" + uuid.NewV4().String()
+	typePayload := app.CreateWorkItemTypePayload{
+		Fields: map[string]*app.FieldDefinition{},
+		Name:   name,
+	}
+	typeCtrl := NewWorkitemtypeController(svc, rest.db)
+	res, _ := test.CreateWorkitemtypeCreated(t, svc.Context, svc, typeCtrl, &projectID, &typePayload)
+	assert.Equal(t, app.ProjectWorkItemTypesHref(projectA, name), res.Header().Get("Location"))
+	_, types := test.ListWorkitemtypeOK(t, svc.Context, svc, typeCtrl, nil, &projectID)
+	found := false
+	for _, wit := range types {
+		found = found || wit.Name == name
+	}
+	assert.True(t, found)
+
+	unknown := uuid.NewV4()
+	test.CreateWorkitemNotFound(t, svc.Context, svc, NewWorkitemController(svc, rest.db), &unknown, &payload)
+	test.ListWorkitemtypeNotFound(t, svc.Context, svc, typeCtrl, nil, &unknown)
+}
+
+func (rest *TestProjectWorkItemsREST) TestUnknownProject() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc := rest.service()
+	test.ListProjectWorkItemsNotFound(t, svc.Context, svc, NewProjectWorkItemsController(svc, rest.db), uuid.NewV4().String(), nil, nil, nil, nil, nil)
+	test.ListProjectWorkItemTypesNotFound(t, svc.Context, svc, NewProjectWorkItemTypesController(svc, rest.db), "not-a-uuid", nil)
+	test.ListProjectTrackerQueriesNotFound(t, svc.Context, svc, NewProjectTrackerQueriesController(svc, rest.db), uuid.NewV4().String())
+}
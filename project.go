@@ -0,0 +1,205 @@
+package main
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/project"
+	"github.com/goadesign/goa"
+	satoriuuid "github.com/satori/go.uuid"
+)
+
+// ProjectController implements the project resource.
+type ProjectController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewProjectController creates a project controller.
+func NewProjectController(service *goa.Service, db application.DB) *ProjectController {
+	if db == nil {
+		panic("db must not be nil")
+	}
+	return &ProjectController{
+		Controller: service.NewController("ProjectController"),
+		db:         db,
+	}
+}
+
+// Create runs the create action. The creator becomes the owner of the project.
+func (c *ProjectController) Create(ctx *app.CreateProjectContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+	currentUserID, err := satoriuuid.FromString(currentUser)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+	attributes := ctx.Payload.Data.Attributes
+	if attributes == nil || attributes.Name == nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("data.attributes.name", nil).Expected("not nil"))
+		return ctx.BadRequest(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := appl.Projects().Create(ctx.Context, *attributes.Name)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		_, err = appl.ProjectMemberships().Create(ctx.Context, p.ID, currentUserID, project.RoleOwner)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		ctx.ResponseData.Header().Set("Location", app.ProjectHref(p.ID))
+		return ctx.Created(&app.Project{
+			Data: convertProjectFromModel(ctx.RequestData, p),
+		})
+	})
+}
+
+// Delete runs the delete action.
+func (c *ProjectController) Delete(ctx *app.DeleteProjectContext) error {
+	id, err := satoriuuid.FromString(ctx.ID)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewNotFoundError("project", ctx.ID))
+		return ctx.NotFound(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		err = appl.Projects().Delete(ctx.Context, id)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// List runs the list action.
+func (c *ProjectController) List(ctx *app.ListProjectContext) error {
+	offset, limit := computePagingLimits(ctx.PageOffset, ctx.PageLimit)
+
+	return application.Transactional(c.db, func(appl application.Application) error {
+		projects, c, err := appl.Projects().List(ctx.Context, &offset, &limit)
+		count := int(c)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+
+		response := app.ProjectArray{
+			Links: &app.PagingLinks{},
+			Meta:  &app.ProjectArrayMeta{TotalCount: count},
+			Data:  make([]*app.ProjectData, len(projects)),
+		}
+		for index := range projects {
+			response.Data[index] = convertProjectFromModel(ctx.RequestData, &projects[index])
+		}
+		setPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), len(projects), offset, limit, count)
+
+		return ctx.OK(&response)
+	})
+}
+
+// Show runs the show action.
+func (c *ProjectController) Show(ctx *app.ShowProjectContext) error {
+	id, err := satoriuuid.FromString(ctx.ID)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewNotFoundError("project", ctx.ID))
+		return ctx.NotFound(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := appl.Projects().Load(ctx.Context, id)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(&app.Project{
+			Data: convertProjectFromModel(ctx.RequestData, p),
+		})
+	})
+}
+
+// Update runs the update action.
+func (c *ProjectController) Update(ctx *app.UpdateProjectContext) error {
+	id, err := satoriuuid.FromString(ctx.ID)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewNotFoundError("project", ctx.ID))
+		return ctx.NotFound(jerrors)
+	}
+	attributes := ctx.Payload.Data.Attributes
+	if attributes == nil || attributes.Version == nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("data.attributes.version", nil).Expected("not nil"))
+		return ctx.BadRequest(jerrors)
+	}
+
+	return application.Transactional(c.db, func(appl application.Application) error {
+		p, err := appl.Projects().Load(ctx.Context, id)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+
+		p.Version = *attributes.Version
+		if attributes.Name != nil {
+			p.Name = *attributes.Name
+		}
+
+		p, err = appl.Projects().Save(ctx.Context, *p)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(&app.Project{
+			Data: convertProjectFromModel(ctx.RequestData, p),
+		})
+	})
+}
+
+// convertProjectFromModel converts between internal and external REST representation
+func convertProjectFromModel(request *goa.RequestData, p *project.Project) *app.ProjectData {
+	selfURL := absoluteURL(request, app.ProjectHref(p.ID))
+	return &app.ProjectData{
+		ID:   &p.ID,
+		Type: "projects",
+		Attributes: &app.ProjectAttributes{
+			Name:      &p.Name,
+			Version:   &p.Version,
+			CreatedAt: &p.CreatedAt,
+			UpdatedAt: &p.UpdatedAt,
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
+
+// loadProject loads the project with the given ID, treating an ID that is not
+// a valid UUID like an unknown one.
+func loadProject(ctx context.Context, appl application.Application, id string) (*project.Project, error) {
+	projectID, err := satoriuuid.FromString(id)
+	if err != nil {
+		return nil, errors.NewNotFoundError("project", id)
+	}
+	return appl.Projects().Load(ctx, projectID)
+}
+
+// projectOrSystem returns the ID of the project given by the optional project
+// parameter of a global route, the system project if the parameter is missing
+func projectOrSystem(ctx context.Context, appl application.Application, id *satoriuuid.UUID) (satoriuuid.UUID, error) {
+	if id == nil {
+		return project.SystemProject, nil
+	}
+	p, err := appl.Projects().Load(ctx, *id)
+	if err != nil {
+		return satoriuuid.Nil, err
+	}
+	return p.ID, nil
+}
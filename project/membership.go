@@ -0,0 +1,134 @@
+package project
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	satoriuuid "github.com/satori/go.uuid"
+)
+
+// Roles an identity can have in a project
+const (
+	RoleOwner       = "owner"
+	RoleContributor = "contributor"
+	RoleViewer      = "viewer"
+)
+
+// Roles holds all known roles
+var Roles = []string{RoleOwner, RoleContributor, RoleViewer}
+
+// IsValidRole returns true if the given role is one of the known roles
+func IsValidRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Membership gives an identity a role in a project
+type Membership struct {
+	gormsupport.Lifecycle
+	ID         satoriuuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	ProjectID  satoriuuid.UUID `sql:"type:uuid"`
+	IdentityID satoriuuid.UUID `sql:"type:uuid"`
+	Role       string
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Membership) TableName() string {
+	return "project_memberships"
+}
+
+// MembershipRepository encapsulate storage & retrieval of project memberships
+type MembershipRepository interface {
+	Create(ctx context.Context, projectID, identityID satoriuuid.UUID, role string) (*Membership, error)
+	Load(ctx context.Context, projectID, identityID satoriuuid.UUID) (*Membership, error)
+	List(ctx context.Context, projectID satoriuuid.UUID) ([]*Membership, error)
+	Delete(ctx context.Context, projectID, identityID satoriuuid.UUID) error
+}
+
+// NewMembershipRepository creates a new storage type.
+func NewMembershipRepository(db *gorm.DB) MembershipRepository {
+	return &GormMembershipRepository{db: db}
+}
+
+// GormMembershipRepository is the implementation of the storage interface for
+// project memberships.
+type GormMembershipRepository struct {
+	db *gorm.DB
+}
+
+// Create gives the identity the role in the project. An identity can be member
+// of a project only once.
+// returns BadParameterError or InternalError
+func (r *GormMembershipRepository) Create(ctx context.Context, projectID, identityID satoriuuid.UUID, role string) (*Membership, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "projectmembership", "create"}, time.Now())
+
+	if !IsValidRole(role) {
+		return nil, errors.NewBadParameterError("role", role).Expected(Roles)
+	}
+	m := Membership{
+		ProjectID:  projectID,
+		IdentityID: identityID,
+		Role:       role,
+	}
+	tx := r.db.Create(&m)
+	if err := tx.Error; err != nil {
+		if gormsupport.IsUniqueViolation(tx.Error, "project_memberships_project_identity_idx") {
+			return nil, errors.NewBadParameterError("identity", identityID.String()).Expected("not yet a member of the project")
+		}
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &m, nil
+}
+
+// Load returns the membership of the identity in the project
+// returns NotFoundError or InternalError
+func (r *GormMembershipRepository) Load(ctx context.Context, projectID, identityID satoriuuid.UUID) (*Membership, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "projectmembership", "load"}, time.Now())
+
+	res := Membership{}
+	tx := r.db.Where("project_id = ? AND identity_id = ?", projectID, identityID).First(&res)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("project membership", identityID.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &res, nil
+}
+
+// List returns all memberships of the project
+// returns InternalError
+func (r *GormMembershipRepository) List(ctx context.Context, projectID satoriuuid.UUID) ([]*Membership, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "projectmembership", "list"}, time.Now())
+
+	var rows []*Membership
+	if err := r.db.Where("project_id = ?", projectID).Order("created_at").Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return rows, nil
+}
+
+// Delete removes the identity from the project
+// returns NotFoundError or InternalError
+func (r *GormMembershipRepository) Delete(ctx context.Context, projectID, identityID satoriuuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "projectmembership", "delete"}, time.Now())
+
+	tx := r.db.Where("project_id = ? AND identity_id = ?", projectID, identityID).Delete(&Membership{})
+	if err := tx.Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("project membership", identityID.String())
+	}
+	return nil
+}
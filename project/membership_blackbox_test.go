@@ -0,0 +1,84 @@
+package project_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	satoriuuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+func TestRunMembershipRepoBBTest(t *testing.T) {
+	suite.Run(t, &membershipRepoBBTest{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+type membershipRepoBBTest struct {
+	gormsupport.DBTestSuite
+	clean   func()
+	repo    project.MembershipRepository
+	project *project.Project
+}
+
+func (test *membershipRepoBBTest) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+	test.repo = project.NewMembershipRepository(test.DB)
+	p, err := project.NewRepository(test.DB).Create(context.Background(), "membership-test-"+satoriuuid.NewV4().String())
+	require.Nil(test.T(), err)
+	test.project = p
+}
+
+func (test *membershipRepoBBTest) TearDownTest() {
+	test.clean()
+}
+
+func (test *membershipRepoBBTest) TestCreateAndLoad() {
+	identityID := satoriuuid.NewV4()
+	m, err := test.repo.Create(context.Background(), test.project.ID, identityID, project.RoleContributor)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), project.RoleContributor, m.Role)
+
+	loaded, err := test.repo.Load(context.Background(), test.project.ID, identityID)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), m.ID, loaded.ID)
+	assert.Equal(test.T(), project.RoleContributor, loaded.Role)
+
+	_, err = test.repo.Load(context.Background(), test.project.ID, satoriuuid.NewV4())
+	assert.IsType(test.T(), errors.NotFoundError{}, err)
+}
+
+func (test *membershipRepoBBTest) TestCreateFail() {
+	identityID := satoriuuid.NewV4()
+	_, err := test.repo.Create(context.Background(), test.project.ID, identityID, "janitor")
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+
+	_, err = test.repo.Create(context.Background(), test.project.ID, identityID, project.RoleViewer)
+	require.Nil(test.T(), err)
+	_, err = test.repo.Create(context.Background(), test.project.ID, identityID, project.RoleOwner)
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+}
+
+func (test *membershipRepoBBTest) TestListAndDelete() {
+	owner := satoriuuid.NewV4()
+	viewer := satoriuuid.NewV4()
+	_, err := test.repo.Create(context.Background(), test.project.ID, owner, project.RoleOwner)
+	require.Nil(test.T(), err)
+	_, err = test.repo.Create(context.Background(), test.project.ID, viewer, project.RoleViewer)
+	require.Nil(test.T(), err)
+
+	memberships, err := test.repo.List(context.Background(), test.project.ID)
+	require.Nil(test.T(), err)
+	assert.Len(test.T(), memberships, 2)
+
+	require.Nil(test.T(), test.repo.Delete(context.Background(), test.project.ID, viewer))
+	assert.IsType(test.T(), errors.NotFoundError{}, test.repo.Delete(context.Background(), test.project.ID, viewer))
+
+	memberships, err = test.repo.List(context.Background(), test.project.ID)
+	require.Nil(test.T(), err)
+	require.Len(test.T(), memberships, 1)
+	assert.Equal(test.T(), owner, memberships[0].IdentityID)
+}
@@ -6,6 +6,11 @@ import (
 	satoriuuid "github.com/satori/go.uuid"
 )
 
+// SystemProject is the ID of the project that owns the system defined work item
+// types as well as all work items, work item types and tracker queries that
+// were created before those got scoped to projects.
+var SystemProject = satoriuuid.FromStringOrNil("2e0698d8-753e-4cef-bb7c-f027634824a2")
+
 // Project represents a project on the domain and db layer
 type Project struct {
 	gormsupport.Lifecycle
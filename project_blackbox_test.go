@@ -0,0 +1,211 @@
+package main_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestProjectREST struct {
+	gormsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	clean func()
+}
+
+func TestRunProjectREST(t *testing.T) {
+	suite.Run(t, &TestProjectREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestProjectREST) SetupTest() {
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = gormsupport.DeleteCreatedEntities(rest.DB)
+}
+
+func (rest *TestProjectREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestProjectREST) SecuredController() (*goa.Service, *ProjectController) {
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+
+	svc := testsupport.ServiceAsUser("Project-Service", almtoken.NewManager(pub, priv), account.TestIdentity)
+	return svc, NewProjectController(svc, rest.db)
+}
+
+func (rest *TestProjectREST) UnSecuredController() (*goa.Service, *ProjectController) {
+	svc := goa.New("Project-Service")
+	return svc, NewProjectController(svc, rest.db)
+}
+
+func (rest *TestProjectREST) TestCreateAndShowProject() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	name := "TestCreateAndShowProject-" + uuid.NewV4().String()
+	svc, ctrl := rest.SecuredController()
+	_, created := test.CreateProjectCreated(t, svc.Context, svc, ctrl, createProjectPayload(&name, nil))
+	require.NotNil(t, created.Data.ID)
+	assert.Equal(t, name, *created.Data.Attributes.Name)
+	assert.NotNil(t, created.Data.Links.Self)
+
+	_, shown := test.ShowProjectOK(t, svc.Context, svc, ctrl, created.Data.ID.String())
+	assert.Equal(t, *created.Data.ID, *shown.Data.ID)
+	assert.Equal(t, name, *shown.Data.Attributes.Name)
+}
+
+func (rest *TestProjectREST) TestCreateProjectMissingName() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc, ctrl := rest.SecuredController()
+	test.CreateProjectBadRequest(t, svc.Context, svc, ctrl, createProjectPayload(nil, nil))
+}
+
+func (rest *TestProjectREST) TestCreateProjectNotAuthorized() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	name := "TestCreateProjectNotAuthorized-" + uuid.NewV4().String()
+	svc, ctrl := rest.UnSecuredController()
+	test.CreateProjectUnauthorized(t, svc.Context, svc, ctrl, createProjectPayload(&name, nil))
+}
+
+func (rest *TestProjectREST) TestUpdateProject() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	name := "TestUpdateProject-" + uuid.NewV4().String()
+	svc, ctrl := rest.SecuredController()
+	_, created := test.CreateProjectCreated(t, svc.Context, svc, ctrl, createProjectPayload(&name, nil))
+
+	newName := name + "-updated"
+	_, updated := test.UpdateProjectOK(t, svc.Context, svc, ctrl, created.Data.ID.String(), updateProjectPayload(&newName, created.Data.Attributes.Version))
+	assert.Equal(t, newName, *updated.Data.Attributes.Name)
+	assert.Equal(t, *created.Data.Attributes.Version+1, *updated.Data.Attributes.Version)
+
+	// updating again with the old version must fail
+	test.UpdateProjectBadRequest(t, svc.Context, svc, ctrl, created.Data.ID.String(), updateProjectPayload(&newName, created.Data.Attributes.Version))
+}
+
+func (rest *TestProjectREST) TestListProjectsPaged() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc, ctrl := rest.SecuredController()
+	for i := 0; i < 3; i++ {
+		name := "TestListProjectsPaged-" + uuid.NewV4().String()
+		test.CreateProjectCreated(t, svc.Context, svc, ctrl, createProjectPayload(&name, nil))
+	}
+
+	offset := "0"
+	limit := 2
+	_, list := test.ListProjectOK(t, svc.Context, svc, ctrl, &limit, &offset)
+	assert.Len(t, list.Data, 2)
+	assert.True(t, list.Meta.TotalCount >= 3)
+	require.NotNil(t, list.Links)
+	assert.NotNil(t, list.Links.First)
+	assert.NotNil(t, list.Links.Next)
+}
+
+func (rest *TestProjectREST) TestDeleteProject() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	name := "TestDeleteProject-" + uuid.NewV4().String()
+	svc, ctrl := rest.SecuredController()
+	_, created := test.CreateProjectCreated(t, svc.Context, svc, ctrl, createProjectPayload(&name, nil))
+
+	test.DeleteProjectOK(t, svc.Context, svc, ctrl, created.Data.ID.String())
+	test.ShowProjectNotFound(t, svc.Context, svc, ctrl, created.Data.ID.String())
+	test.DeleteProjectNotFound(t, svc.Context, svc, ctrl, created.Data.ID.String())
+}
+
+func (rest *TestProjectREST) TestShowProjectNotFound() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc, ctrl := rest.UnSecuredController()
+	test.ShowProjectNotFound(t, svc.Context, svc, ctrl, uuid.NewV4().String())
+	test.ShowProjectNotFound(t, svc.Context, svc, ctrl, "not-a-uuid")
+}
+
+func (rest *TestProjectREST) TestProjectMemberships() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	name := "TestProjectMemberships-" + uuid.NewV4().String()
+	svc, ctrl := rest.SecuredController()
+	_, created := test.CreateProjectCreated(t, svc.Context, svc, ctrl, createProjectPayload(&name, nil))
+	projectID := created.Data.ID.String()
+
+	membershipsCtrl := NewProjectMembershipsController(svc, rest.db)
+	_, memberships := test.ListProjectMembershipsOK(t, svc.Context, svc, membershipsCtrl, projectID)
+	require.Len(t, memberships.Data, 1)
+	assert.Equal(t, account.TestIdentity.ID, memberships.Data[0].Attributes.Identity)
+	assert.Equal(t, project.RoleOwner, memberships.Data[0].Attributes.Role)
+
+	// the last owner can not leave the project
+	test.DeleteProjectMembershipsBadRequest(t, svc.Context, svc, membershipsCtrl, projectID, account.TestIdentity.ID)
+
+	identity := account.Identity{FullName: "Test Viewer"}
+	require.Nil(t, account.NewIdentityRepository(rest.DB).Create(context.Background(), &identity))
+	viewer := identity.ID
+	payload := &app.CreateProjectMembershipsPayload{
+		Data: &app.ProjectMembershipData{
+			Type: "project-memberships",
+			Attributes: &app.ProjectMembershipAttributes{
+				Identity: uuid.NewV4(),
+				Role:     project.RoleViewer,
+			},
+		},
+	}
+	// only existing identities become members
+	test.CreateProjectMembershipsNotFound(t, svc.Context, svc, membershipsCtrl, projectID, payload)
+	payload.Data.Attributes.Identity = viewer
+	test.CreateProjectMembershipsCreated(t, svc.Context, svc, membershipsCtrl, projectID, payload)
+	test.CreateProjectMembershipsBadRequest(t, svc.Context, svc, membershipsCtrl, projectID, payload)
+	test.DeleteProjectMembershipsOK(t, svc.Context, svc, membershipsCtrl, projectID, viewer)
+	test.DeleteProjectMembershipsNotFound(t, svc.Context, svc, membershipsCtrl, projectID, viewer)
+}
+
+func createProjectPayload(name *string, version *int) *app.CreateProjectPayload {
+	return &app.CreateProjectPayload{
+		Data: &app.ProjectData{
+			Type: "projects",
+			Attributes: &app.ProjectAttributes{
+				Name:    name,
+				Version: version,
+			},
+		},
+	}
+}
+
+func updateProjectPayload(name *string, version *int) *app.UpdateProjectPayload {
+	return &app.UpdateProjectPayload{
+		Data: &app.ProjectData{
+			Type: "projects",
+			Attributes: &app.ProjectAttributes{
+				Name:    name,
+				Version: version,
+			},
+		},
+	}
+}
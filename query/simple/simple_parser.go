@@ -5,11 +5,13 @@ package query
 
 import (
 	"encoding/json"
+	"fmt"
 
 	. "github.com/almighty/almighty-core/criteria"
 )
 
 // Parse parses strings of the form { "attribute1":value1,"attribute2":value2} into an expression of the form "attribute1=value1 and attribute2=value2"
+// A value of the form {"$memberOf": team} matches identities that are members of the team or of one of its sub-teams
 // returns the expression "true" if empty
 func Parse(exp *string) (Expression, error) {
 	if exp == nil || len(*exp) == 0 {
@@ -23,7 +25,10 @@ func Parse(exp *string) (Expression, error) {
 	var result *Expression
 	if len(unmarshalled) > 0 {
 		for key, value := range unmarshalled {
-			current := Equals(Field(key), Literal(value))
+			current, err := parseCondition(key, value)
+			if err != nil {
+				return nil, err
+			}
 			if result == nil {
 				result = &current
 			} else {
@@ -35,3 +40,16 @@ func Parse(exp *string) (Expression, error) {
 	}
 	return Literal(true), nil
 }
+
+// parseCondition returns the expression comparing the attribute with the value
+func parseCondition(key string, value interface{}) (Expression, error) {
+	operator, ok := value.(map[string]interface{})
+	if !ok {
+		return Equals(Field(key), Literal(value)), nil
+	}
+	team, ok := operator["$memberOf"]
+	if !ok || len(operator) != 1 {
+		return nil, fmt.Errorf("unknown operator in the value of %s, expected {\"$memberOf\": team}", key)
+	}
+	return MemberOf(Field(key), Literal(team)), nil
+}
@@ -0,0 +1,118 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/configuration"
+	"golang.org/x/net/context"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// bugzillaPageSize is the number of bugs requested per search
+const bugzillaPageSize = 20
+
+// BugzillaTracker represents the Bugzilla tracker provider
+type BugzillaTracker struct {
+	// URL is the base URL of the Bugzilla instance, e.g. https://bugzilla.redhat.com
+	URL string
+	// Query holds the parameters of a bug search of the REST API, e.g.
+	// product=Fedora&component=kernel&status=NEW
+	Query string
+	// Since restricts the fetch to the bugs changed since then, all bugs
+	// are fetched if it is nil
+	Since *time.Time
+}
+
+// bugzillaFetcher provides bug listing
+type bugzillaFetcher interface {
+	// listBugs returns the bugs of the search starting at the given offset
+	listBugs(ctx context.Context, query string, offset int) ([]map[string]interface{}, error)
+}
+
+// bugzillaBugFetcher searches bugs with the Bugzilla REST API
+type bugzillaBugFetcher struct {
+	client *http.Client
+	url    string
+}
+
+// listBugs lists the bugs found by the search
+func (f *bugzillaBugFetcher) listBugs(ctx context.Context, query string, offset int) ([]map[string]interface{}, error) {
+	u := fmt.Sprintf("%s/rest/bug?%s&limit=%d&offset=%d", strings.TrimSuffix(f.url, "/"), query, bugzillaPageSize, offset)
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := ctxhttp.Do(ctx, f.client, req)
+	if err != nil {
+		return nil, retryable(nil, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, retryable(resp, fmt.Errorf("searching Bugzilla bugs failed: %s", resp.Status))
+	}
+	var result struct {
+		Bugs []map[string]interface{} `json:"bugs"`
+	}
+	// keep the numeric bug ids as they are
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Bugs, nil
+}
+
+// Fetch tracker items from Bugzilla
+func (b *BugzillaTracker) Fetch(ctx context.Context) (chan TrackerItemContent, chan error) {
+	client := tokenClient(configuration.GetBugzillaAuthHosts(), "X-BUGZILLA-API-KEY", configuration.GetBugzillaAuthAPIKey())
+	f := bugzillaBugFetcher{client: client, url: b.URL}
+	return b.fetch(ctx, &f)
+}
+
+// query returns the search parameters ordered by the change time, the oldest
+// changes first so that an interrupted fetch resumes where it stopped, and
+// restricted to the bugs changed since the last fetch
+func (b *BugzillaTracker) query() string {
+	params, _ := url.ParseQuery(strings.TrimPrefix(b.Query, "?"))
+	params.Set("order", "changeddate")
+	if b.Since != nil {
+		params.Set("last_change_time", b.Since.UTC().Format(time.RFC3339))
+	}
+	return params.Encode()
+}
+
+func (b *BugzillaTracker) fetch(ctx context.Context, f bugzillaFetcher) (chan TrackerItemContent, chan error) {
+	return fetchItems(ctx, func(send func(TrackerItemContent) error) error {
+		query := b.query()
+		for offset := 0; ; offset += bugzillaPageSize {
+			var bugs []map[string]interface{}
+			err := fetchBackoff.do(ctx, func() error {
+				var err error
+				bugs, err = f.listBugs(ctx, query, offset)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			for _, bug := range bugs {
+				// bugs do not carry their URL, which identifies the remote item
+				self := fmt.Sprintf("%s/rest/bug/%v", strings.TrimSuffix(b.URL, "/"), bug["id"])
+				bug[BugzillaID] = self
+				id, _ := json.Marshal(self)
+				content, _ := json.Marshal(bug)
+				if err := send(TrackerItemContent{ID: string(id), Content: content}); err != nil {
+					return err
+				}
+			}
+			if len(bugs) < bugzillaPageSize {
+				return nil
+			}
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package remoteworkitem
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/dnaeon/go-vcr/recorder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBugzillaBugFetcher struct {
+	offsets []int
+}
+
+// listBugs returns a full page of bugs first and one bug after it
+func (f *fakeBugzillaBugFetcher) listBugs(ctx context.Context, query string, offset int) ([]map[string]interface{}, error) {
+	f.offsets = append(f.offsets, offset)
+	count := bugzillaPageSize
+	if offset > 0 {
+		count = 1
+	}
+	bugs := []map[string]interface{}{}
+	for i := 0; i < count; i++ {
+		bugs = append(bugs, map[string]interface{}{"id": strconv.Itoa(offset + i + 1)})
+	}
+	return bugs, nil
+}
+
+func TestBugzillaFetch(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	f := fakeBugzillaBugFetcher{}
+	b := BugzillaTracker{URL: "https://bugzilla.redhat.com/", Query: ""}
+	var items []TrackerItemContent
+	fetch, _ := b.fetch(context.Background(), &f)
+	for i := range fetch {
+		items = append(items, i)
+	}
+	require.Len(t, items, bugzillaPageSize+1)
+	assert.Equal(t, `"https://bugzilla.redhat.com/rest/bug/1"`, items[0].ID)
+	assert.Equal(t, `{"id":"1","self":"https://bugzilla.redhat.com/rest/bug/1"}`, string(items[0].Content))
+	assert.Equal(t, []int{0, bugzillaPageSize}, f.offsets)
+}
+
+func TestBugzillaQuery(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	b := BugzillaTracker{Query: "product=Fedora&status=NEW"}
+	assert.Equal(t, "order=changeddate&product=Fedora&status=NEW", b.query())
+	since := time.Date(2016, 11, 3, 10, 30, 0, 0, time.FixedZone("CET", 3600))
+	b.Since = &since
+	assert.Equal(t, "last_change_time=2016-11-03T09%3A30%3A00Z&order=changeddate&product=Fedora&status=NEW", b.query())
+}
+
+func TestBugzillaFetchWithRecording(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	r, err := recorder.New("../test/data/bugzilla_fetch_test")
+	if err != nil {
+		t.Error(err)
+	}
+	defer r.Stop()
+
+	h := &http.Client{
+		Timeout:   1 * time.Second,
+		Transport: r.Transport,
+	}
+
+	f := bugzillaBugFetcher{client: h, url: "https://bugzilla.redhat.com"}
+	b := &BugzillaTracker{URL: "https://bugzilla.redhat.com", Query: "product=Fedora&component=almighty-test"}
+	fetch, _ := b.fetch(context.Background(), &f)
+	i := <-fetch
+	if i.ID != `"https://bugzilla.redhat.com/rest/bug/1389311"` {
+		t.Errorf("ID is not matching: %#v", i.ID)
+	}
+	i2 := <-fetch
+	if i2.ID != `"https://bugzilla.redhat.com/rest/bug/1389877"` {
+		t.Errorf("ID is not matching: %#v", i2.ID)
+	}
+	if _, ok := <-fetch; ok {
+		t.Error("Channel should be closed")
+	}
+
+	remoteItem, err := NewBugzillaRemoteWorkItem(TrackerItem{Item: string(i.Content)})
+	require.Nil(t, err)
+	workItem, err := Map(remoteItem, WorkItemKeyMaps[ProviderBugzilla])
+	require.Nil(t, err)
+	assert.Equal(t, "flatten : test case : with assignee", workItem.Fields[workitem.SystemTitle])
+	assert.Equal(t, workitem.SystemStateOpen, workItem.Fields[workitem.SystemState])
+	assert.Equal(t, "aslak@redhat.com", workItem.Fields[workitem.SystemAssignee])
+	assert.Equal(t, "https://bugzilla.redhat.com/rest/bug/1389311", workItem.Fields[workitem.SystemRemoteItemID])
+}
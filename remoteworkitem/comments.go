@@ -0,0 +1,217 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/team"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// remoteIdentity is a user of a remote tracker
+type remoteIdentity struct {
+	// Name is the login of the user, it keys the identity mappings of the tracker
+	Name     string
+	FullName string
+	Email    string
+}
+
+// remoteComment is a comment of a remote item
+type remoteComment struct {
+	// ID is the ID of the comment on the remote tracker, the one a pushed
+	// comment is remembered with
+	ID     string
+	Author remoteIdentity
+	Body   string
+}
+
+// remoteCommentReaders extract the comments from the content of the remote
+// items of a tracker type. The items of other types are imported without comments.
+var remoteCommentReaders = map[string]func(content []byte) ([]remoteComment, error){
+	ProviderGithub: githubComments,
+	ProviderJira:   jiraComments,
+}
+
+// githubComments returns the comments Fetch adds to the content of a Github issue
+func githubComments(content []byte) ([]remoteComment, error) {
+	var issue struct {
+		Comments []struct {
+			ID   int64  `json:"id"`
+			Body string `json:"body"`
+			User struct {
+				Login string `json:"login"`
+				Name  string `json:"name"`
+				Email string `json:"email"`
+			} `json:"user"`
+		} `json:"comment_list"`
+	}
+	if err := json.Unmarshal(content, &issue); err != nil {
+		return nil, err
+	}
+	var comments []remoteComment
+	for _, c := range issue.Comments {
+		comments = append(comments, remoteComment{
+			ID:     strconv.FormatInt(c.ID, 10),
+			Author: remoteIdentity{Name: c.User.Login, FullName: c.User.Name, Email: c.User.Email},
+			Body:   c.Body,
+		})
+	}
+	return comments, nil
+}
+
+// jiraComments returns the comments of a Jira issue
+func jiraComments(content []byte) ([]remoteComment, error) {
+	var issue struct {
+		Fields struct {
+			Comment struct {
+				Comments []struct {
+					ID     string `json:"id"`
+					Body   string `json:"body"`
+					Author struct {
+						Name         string `json:"name"`
+						DisplayName  string `json:"displayName"`
+						EmailAddress string `json:"emailAddress"`
+					} `json:"author"`
+				} `json:"comments"`
+			} `json:"comment"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(content, &issue); err != nil {
+		return nil, err
+	}
+	var comments []remoteComment
+	for _, c := range issue.Fields.Comment.Comments {
+		comments = append(comments, remoteComment{
+			ID:     c.ID,
+			Author: remoteIdentity{Name: c.Author.Name, FullName: c.Author.DisplayName, Email: c.Author.EmailAddress},
+			Body:   c.Body,
+		})
+	}
+	return comments, nil
+}
+
+// importCommentsAndLinks imports the comments and the issue links of a remote
+// item into the work item it was converted to. Remote items the comments or
+// links of which can not be read are imported without them.
+func importCommentsAndLinks(db *gorm.DB, tracker *Tracker, wi *app.WorkItem, item TrackerItemContent, provider string) error {
+	if read, ok := remoteCommentReaders[provider]; ok {
+		comments, err := read(item.Content)
+		if err != nil {
+			log.Printf("reading the comments of %s failed: %v", item.ID, err)
+		} else if err := importComments(db, tracker, wi, comments); err != nil {
+			return err
+		}
+	}
+	if read, ok := remoteLinkReaders[provider]; ok {
+		links, err := read(item.ID, item.Content)
+		if err != nil {
+			log.Printf("reading the links of %s failed: %v", item.ID, err)
+		} else if err := importLinks(db, tracker, links); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importComments adds the remote comments to the given work item. Comments
+// imported or pushed before are recognized by their remote IDs and only get
+// their body updated.
+func importComments(db *gorm.DB, tracker *Tracker, wi *app.WorkItem, comments []remoteComment) error {
+	remoteItemID := syncValue(wi.Fields[workitem.SystemRemoteItemID])
+	cr := comment.NewCommentRepository(db)
+	tr := team.NewRepository(db)
+	for _, rc := range comments {
+		if rc.ID == "" {
+			continue
+		}
+		var stored RemoteComment
+		if !db.Where("tracker_id = ? AND remote_comment_id = ?", tracker.ID, rc.ID).Find(&stored).RecordNotFound() {
+			c, err := cr.Load(context.Background(), stored.CommentID)
+			if err != nil {
+				return InternalError{simpleError{err.Error()}}
+			}
+			if c.Body == rc.Body {
+				continue
+			}
+			c.Body = rc.Body
+			if err := cr.Save(context.Background(), c); err != nil {
+				return InternalError{simpleError{err.Error()}}
+			}
+			if _, err := tr.RecordMentions(context.Background(), c); err != nil {
+				return InternalError{simpleError{err.Error()}}
+			}
+			continue
+		}
+		creator, err := importIdentity(db, tracker, rc.Author)
+		if err != nil {
+			return err
+		}
+		c := comment.Comment{
+			ParentType: comment.ParentTypeWorkItem,
+			ParentID:   wi.ID,
+			CreatedBy:  creator,
+			Body:       rc.Body,
+		}
+		if err := cr.Create(context.Background(), &c); err != nil {
+			return InternalError{simpleError{err.Error()}}
+		}
+		if _, err := tr.RecordMentions(context.Background(), &c); err != nil {
+			return InternalError{simpleError{err.Error()}}
+		}
+		stored = RemoteComment{
+			CommentID:       c.ID,
+			TrackerID:       tracker.ID,
+			RemoteItemID:    remoteItemID,
+			RemoteCommentID: rc.ID,
+		}
+		if err := db.Create(&stored).Error; err != nil {
+			return InternalError{simpleError{err.Error()}}
+		}
+	}
+	return nil
+}
+
+// importIdentity returns the identity the given remote user is imported as:
+// the mapped one, else the one owning the email address of the user, else a
+// new one. The identity is added to the mappings of the tracker so that later
+// comments of the user do not create another one.
+func importIdentity(db *gorm.DB, tracker *Tracker, user remoteIdentity) (uuid.UUID, error) {
+	if id, ok := tracker.IdentityMappings[user.Name]; ok {
+		if identityID, err := uuid.FromString(id); err == nil {
+			return identityID, nil
+		}
+	}
+	var identityID uuid.UUID
+	var u account.User
+	// only verified emails tell who a remote user is
+	if user.Email != "" && !db.Scopes(account.UserVerified()).Where("lower(email) = ?", strings.ToLower(user.Email)).First(&u).RecordNotFound() {
+		identityID = u.IdentityID
+	} else {
+		identity := account.Identity{FullName: user.FullName, Bio: "Imported from " + tracker.URL}
+		if identity.FullName == "" {
+			identity.FullName = user.Name
+		}
+		if err := account.NewIdentityRepository(db).Create(context.Background(), &identity); err != nil {
+			return identityID, InternalError{simpleError{err.Error()}}
+		}
+		identityID = identity.ID
+	}
+	if tracker.IdentityMappings == nil {
+		tracker.IdentityMappings = NameMappings{}
+	}
+	tracker.IdentityMappings[user.Name] = identityID.String()
+	err := db.Model(&Tracker{}).Where("id = ?", tracker.ID).UpdateColumn("identity_mappings", tracker.IdentityMappings).Error
+	if err != nil {
+		return identityID, InternalError{simpleError{err.Error()}}
+	}
+	return identityID, nil
+}
@@ -0,0 +1,115 @@
+package remoteworkitem
+
+import (
+	"strconv"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGithubComments(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	comments, err := githubComments([]byte(`{"title":"title","comments":1,"comment_list":[{"id":42,"body":"first","user":{"login":"sbose78"}}]}`))
+	require.Nil(t, err)
+	assert.Equal(t, []remoteComment{{ID: "42", Author: remoteIdentity{Name: "sbose78"}, Body: "first"}}, comments)
+
+	comments, err = githubComments([]byte(`{"title":"title","comments":0}`))
+	require.Nil(t, err)
+	assert.Empty(t, comments)
+}
+
+func TestJiraComments(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	comments, err := jiraComments([]byte(`{"key":"ARQ-1","fields":{"comment":{"total":1,"comments":[{"id":"10001","body":"first","author":{"name":"aslak","displayName":"Aslak Knutsen","emailAddress":"aslak@example.com"}}]}}}`))
+	require.Nil(t, err)
+	assert.Equal(t, []remoteComment{{ID: "10001", Author: remoteIdentity{Name: "aslak", FullName: "Aslak Knutsen", Email: "aslak@example.com"}, Body: "first"}}, comments)
+
+	_, err = jiraComments([]byte(`not json`))
+	assert.NotNil(t, err)
+}
+
+func TestImportComments(t *testing.T) {
+	doWithTransaction(t, func(tx *gorm.DB) {
+		tr := Tracker{URL: "https://api.github.com/", Type: ProviderGithub}
+		require.Nil(t, tx.Create(&tr).Error)
+		tID := int(tr.ID)
+		issueURL := `"https://api.github.com/repos/almighty/test/issues/` + strconv.FormatUint(tr.ID, 10) + `"`
+		item := TrackerItemContent{
+			ID:      issueURL,
+			Content: []byte(`{"title":"title","url":` + issueURL + `,"state":"open","comment_list":[{"id":1,"body":"first","user":{"login":"sbose78"}},{"id":2,"body":"second","user":{"login":"sbose78"}}]}`),
+		}
+		require.Nil(t, upload(tx, tID, item))
+		wi, err := convert(tx, project.SystemProject, tID, item, ProviderGithub)
+		require.Nil(t, err)
+
+		comments, err := comment.NewCommentRepository(tx).List(context.Background(), comment.ParentTypeWorkItem, wi.ID)
+		require.Nil(t, err)
+		require.Len(t, comments, 2)
+		// both comments are attributed to one new identity
+		assert.Equal(t, comments[0].CreatedBy, comments[1].CreatedBy)
+		identity, err := account.NewIdentityRepository(tx).Load(context.Background(), comments[0].CreatedBy)
+		require.Nil(t, err)
+		assert.Equal(t, "sbose78", identity.FullName)
+		require.Nil(t, tx.First(&tr, tr.ID).Error)
+		assert.Equal(t, comments[0].CreatedBy.String(), tr.IdentityMappings["sbose78"])
+
+		// reimported comments are updated rather than added again
+		item.Content = []byte(`{"title":"title","url":` + issueURL + `,"state":"open","comment_list":[{"id":1,"body":"edited","user":{"login":"sbose78"}},{"id":2,"body":"second","user":{"login":"sbose78"}}]}`)
+		_, err = convert(tx, project.SystemProject, tID, item, ProviderGithub)
+		require.Nil(t, err)
+		comments, err = comment.NewCommentRepository(tx).List(context.Background(), comment.ParentTypeWorkItem, wi.ID)
+		require.Nil(t, err)
+		require.Len(t, comments, 2)
+		bodies := []string{comments[0].Body, comments[1].Body}
+		assert.Contains(t, bodies, "edited")
+		assert.Contains(t, bodies, "second")
+		var count int
+		require.Nil(t, tx.Model(&RemoteComment{}).Where("tracker_id = ?", tr.ID).Count(&count).Error)
+		assert.Equal(t, 2, count)
+	})
+}
+
+func TestImportIdentity(t *testing.T) {
+	doWithTransaction(t, func(tx *gorm.DB) {
+		tr := Tracker{URL: "https://issues.jboss.org", Type: ProviderJira}
+		require.Nil(t, tx.Create(&tr).Error)
+		identity := account.Identity{FullName: "Aslak Knutsen"}
+		require.Nil(t, account.NewIdentityRepository(tx).Create(context.Background(), &identity))
+		user := account.User{Email: "aslak-" + strconv.FormatUint(tr.ID, 10) + "@example.com", IdentityID: identity.ID}
+		require.Nil(t, tx.Create(&user).Error)
+
+		// unverified email addresses do not tell who a user is
+		id, err := importIdentity(tx, &tr, remoteIdentity{Name: "aslak-unverified", FullName: "Aslak", Email: user.Email})
+		require.Nil(t, err)
+		assert.NotEqual(t, identity.ID, id)
+
+		// users are matched by their verified email address
+		user.Verified = true
+		require.Nil(t, tx.Save(&user).Error)
+		id, err = importIdentity(tx, &tr, remoteIdentity{Name: "aslak", FullName: "Aslak", Email: "ASLAK-" + strconv.FormatUint(tr.ID, 10) + "@example.com"})
+		require.Nil(t, err)
+		assert.Equal(t, identity.ID, id)
+
+		// mapped users keep their identity
+		id, err = importIdentity(tx, &tr, remoteIdentity{Name: "aslak"})
+		require.Nil(t, err)
+		assert.Equal(t, identity.ID, id)
+
+		// other users get a new identity
+		id, err = importIdentity(tx, &tr, remoteIdentity{Name: "kwk", FullName: "Konrad Kleine"})
+		require.Nil(t, err)
+		assert.NotEqual(t, identity.ID, id)
+		created, err := account.NewIdentityRepository(tx).Load(context.Background(), id)
+		require.Nil(t, err)
+		assert.Equal(t, "Konrad Kleine", created.FullName)
+		assert.Equal(t, "Imported from https://issues.jboss.org", created.Bio)
+	})
+}
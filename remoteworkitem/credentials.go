@@ -0,0 +1,76 @@
+package remoteworkitem
+
+import (
+	"net/http"
+	"strings"
+)
+
+// hostBoundTransport sends the requests to the hosts the credentials of a
+// tracker type are configured for through the authorized transport and all
+// other requests without credentials. The URLs of trackers and remote items
+// are stored by users, the credentials must not follow them to other hosts.
+type hostBoundTransport struct {
+	hosts      []string
+	authorized http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *hostBoundTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for _, host := range t.hosts {
+		if strings.EqualFold(req.URL.Host, host) {
+			return t.authorized.RoundTrip(req)
+		}
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// basicAuthTransport authenticates every request with the given credentials
+type basicAuthTransport struct {
+	username string
+	password string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := cloneRequest(req)
+	r.SetBasicAuth(t.username, t.password)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// headerTransport authenticates every request with the given header, the way
+// API tokens are sent to GitLab and Bugzilla
+type headerTransport struct {
+	name  string
+	value string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := cloneRequest(req)
+	r.Header.Set(t.name, t.value)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// tokenClient returns a client sending the token in the given header to the
+// given hosts, a client without credentials if no token is configured
+func tokenClient(hosts []string, header, token string) *http.Client {
+	if token == "" {
+		return http.DefaultClient
+	}
+	return &http.Client{Transport: &hostBoundTransport{
+		hosts:      hosts,
+		authorized: &headerTransport{name: header, value: token},
+	}}
+}
+
+// cloneRequest returns a copy of the request with its own header, which a
+// RoundTripper may change without modifying the request it was given
+func cloneRequest(req *http.Request) *http.Request {
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	return r
+}
@@ -0,0 +1,58 @@
+package remoteworkitem
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostBoundTransport(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	var authorization string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	require.Nil(t, err)
+
+	authorized := &basicAuthTransport{username: "user", password: "secret"}
+	for host, sent := range map[string]bool{u.Host: true, "jira.example.com": false} {
+		client := &http.Client{Transport: &hostBoundTransport{hosts: []string{host}, authorized: authorized}}
+		authorization = ""
+		req, err := http.NewRequest("GET", ts.URL, nil)
+		require.Nil(t, err)
+		resp, err := client.Do(req)
+		require.Nil(t, err)
+		resp.Body.Close()
+		assert.Equal(t, sent, authorization != "", host)
+		// the request of the caller is left alone
+		assert.Empty(t, req.Header.Get("Authorization"))
+	}
+}
+
+func TestTokenClient(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	var token string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get("PRIVATE-TOKEN")
+	}))
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	require.Nil(t, err)
+
+	for host, sent := range map[string]string{u.Host: "secret", "gitlab.example.com": ""} {
+		token = ""
+		resp, err := tokenClient([]string{host}, "PRIVATE-TOKEN", "secret").Get(ts.URL)
+		require.Nil(t, err)
+		resp.Body.Close()
+		assert.Equal(t, sent, token, host)
+	}
+	// no token is configured
+	assert.Equal(t, http.DefaultClient, tokenClient([]string{u.Host}, "PRIVATE-TOKEN", ""))
+}
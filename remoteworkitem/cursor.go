@@ -0,0 +1,58 @@
+package remoteworkitem
+
+import (
+	"log"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// remoteUpdatedAt returns the time of the last change of the remote item or
+// nil if it is not known
+func remoteUpdatedAt(provider string, item TrackerItemContent) *time.Time {
+	newRemoteItem, ok := RemoteWorkItemImplRegistry[provider]
+	if !ok {
+		return nil
+	}
+	remoteItem, err := newRemoteItem(TrackerItem{Item: string(item.Content)})
+	if err != nil {
+		return nil
+	}
+	t := parseRemoteTime(remoteItem.Get(remoteUpdatedKeys[provider]))
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// laterOf returns the later of the two times, ignoring nil ones
+func laterOf(t1, t2 *time.Time) *time.Time {
+	if t1 == nil || (t2 != nil && t2.After(*t1)) {
+		return t2
+	}
+	return t1
+}
+
+// lastUpdatedAt returns the latest update time imported by the tracker query
+// with the given id, nil means that all remote items are fetched
+func lastUpdatedAt(db *gorm.DB, queryID uint64) *time.Time {
+	var tq TrackerQuery
+	if err := db.Select("last_updated_at").Where("id = ?", queryID).First(&tq).Error; err != nil {
+		log.Printf("loading the last update time of tracker query %d failed: %v", queryID, err)
+		return nil
+	}
+	return tq.LastUpdatedAt
+}
+
+// saveLastUpdatedAt remembers the latest update time imported by the tracker
+// query with the given id, it never moves back
+func saveLastUpdatedAt(db *gorm.DB, queryID uint64, since *time.Time, latest *time.Time) error {
+	if latest == nil || (since != nil && !latest.After(*since)) {
+		return nil
+	}
+	err := db.Model(&TrackerQuery{}).Where("id = ?", queryID).UpdateColumn("last_updated_at", *latest).Error
+	if err != nil {
+		return InternalError{simpleError{err.Error()}}
+	}
+	return nil
+}
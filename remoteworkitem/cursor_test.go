@@ -0,0 +1,109 @@
+package remoteworkitem
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncrementalQueries(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	since := time.Date(2016, 11, 3, 10, 30, 0, 0, time.FixedZone("CET", 3600))
+
+	g := GithubTracker{Query: "is:open user:almighty"}
+	assert.Equal(t, "is:open user:almighty", g.query())
+	g.Since = &since
+	assert.Equal(t, "is:open user:almighty updated:>=2016-11-03T09:30:00Z", g.query())
+
+	j := JiraTracker{Query: "project = ARQ ORDER BY created ASC"}
+	assert.Equal(t, "project = ARQ ORDER BY updated ASC", j.query(since))
+	j.Since = &since
+	assert.Equal(t, "(project = ARQ) AND updated >= -91m ORDER BY updated ASC", j.query(since.Add(90*time.Minute)))
+	j.Query = ""
+	assert.Equal(t, "updated >= -1m ORDER BY updated ASC", j.query(since))
+}
+
+func TestRemoteUpdatedAt(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	updated := remoteUpdatedAt(ProviderGithub, TrackerItemContent{Content: []byte(`{"updated_at":"2016-11-03T09:30:00Z"}`)})
+	require.NotNil(t, updated)
+	assert.Equal(t, time.Date(2016, 11, 3, 9, 30, 0, 0, time.UTC), updated.UTC())
+	updated = remoteUpdatedAt(ProviderJira, TrackerItemContent{Content: []byte(`{"fields":{"updated":"2016-11-03T10:30:00.000+0100"}}`)})
+	require.NotNil(t, updated)
+	assert.Equal(t, time.Date(2016, 11, 3, 9, 30, 0, 0, time.UTC), updated.UTC())
+	assert.Nil(t, remoteUpdatedAt(ProviderGithub, TrackerItemContent{Content: []byte(`{}`)}))
+
+	earlier := updated.Add(-time.Hour)
+	assert.Equal(t, updated, laterOf(&earlier, updated))
+	assert.Equal(t, updated, laterOf(updated, &earlier))
+	assert.Equal(t, updated, laterOf(nil, updated))
+	assert.Equal(t, updated, laterOf(updated, nil))
+}
+
+func TestLastUpdatedAt(t *testing.T) {
+	doWithTransaction(t, func(tx *gorm.DB) {
+		trackerRepo := NewTrackerRepository(tx)
+		queryRepo := NewTrackerQueryRepository(tx)
+		tracker, err := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub)
+		require.Nil(t, err)
+		query, err := queryRepo.Create(context.Background(), "is:open", "15 * * * * *", tracker.ID, project.SystemProject)
+		require.Nil(t, err)
+		assert.Nil(t, query.LastUpdatedAt)
+
+		id, _ := strconv.ParseUint(query.ID, 10, 64)
+		assert.Nil(t, lastUpdatedAt(tx, id))
+		latest := time.Now().Add(-time.Hour).Truncate(time.Second)
+		require.Nil(t, saveLastUpdatedAt(tx, id, nil, &latest))
+		require.NotNil(t, lastUpdatedAt(tx, id))
+		assert.True(t, latest.Equal(*lastUpdatedAt(tx, id)))
+
+		// the last update time never moves back
+		earlier := latest.Add(-time.Hour)
+		require.Nil(t, saveLastUpdatedAt(tx, id, &latest, &earlier))
+		assert.True(t, latest.Equal(*lastUpdatedAt(tx, id)))
+
+		// saving the query keeps it unless the query is changed
+		query, err = queryRepo.Save(context.Background(), *query)
+		require.Nil(t, err)
+		require.NotNil(t, query.LastUpdatedAt)
+		query.Query = "is:closed"
+		query, err = queryRepo.Save(context.Background(), *query)
+		require.Nil(t, err)
+		assert.Nil(t, query.LastUpdatedAt)
+
+		require.Nil(t, saveLastUpdatedAt(tx, id, nil, &latest))
+		query, err = queryRepo.Resync(context.Background(), query.ID)
+		require.Nil(t, err)
+		assert.Nil(t, query.LastUpdatedAt)
+		assert.Nil(t, lastUpdatedAt(tx, id))
+
+		_, err = queryRepo.Resync(context.Background(), "100000")
+		assert.IsType(t, NotFoundError{}, err)
+	})
+}
+
+func TestUploadChanged(t *testing.T) {
+	doWithTransaction(t, func(tx *gorm.DB) {
+		tr := Tracker{URL: "https://api.github.com/", Type: ProviderGithub}
+		require.Nil(t, tx.Create(&tr).Error)
+		item := TrackerItemContent{ID: "https://api.github.com/repos/almighty/test/issues/1", Content: []byte(`{"title":"title"}`)}
+		changed, err := uploadChanged(tx, int(tr.ID), item)
+		require.Nil(t, err)
+		assert.True(t, changed)
+		changed, err = uploadChanged(tx, int(tr.ID), item)
+		require.Nil(t, err)
+		assert.False(t, changed)
+		item.Content = []byte(`{"title":"new title"}`)
+		changed, err = uploadChanged(tx, int(tr.ID), item)
+		require.Nil(t, err)
+		assert.True(t, changed)
+	})
+}
@@ -0,0 +1,126 @@
+package remoteworkitem
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// backoff retries failed requests to a remote tracker with exponentially
+// growing delays
+type backoff struct {
+	// Retries is the number of retries after the first attempt
+	Retries int
+	// Initial is the delay before the first retry, it doubles with every
+	// further retry up to Max
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// fetchBackoff retries the requests fetching remote items
+var fetchBackoff = backoff{Retries: 5, Initial: 2 * time.Second, Max: 5 * time.Minute}
+
+// temporaryError is the error of a remote request that may succeed if
+// retried, not before the retry time unless it is zero
+type temporaryError struct {
+	err   error
+	retry time.Time
+}
+
+// Error implements the error interface
+func (e temporaryError) Error() string {
+	return e.err.Error()
+}
+
+// do calls the request until it succeeds, fails with an error that is not
+// temporary, the retries are exhausted or the context is done. A request asking
+// to wait longer than the maximum delay, like a rate limit reset an hour ahead,
+// is not retried; the next run of the query resumes the fetch instead.
+func (b backoff) do(ctx context.Context, request func() error) error {
+	delay := b.Initial
+	for attempt := 0; ; attempt++ {
+		err := request()
+		t, ok := err.(temporaryError)
+		if !ok {
+			return err
+		}
+		wait := delay
+		if d := t.retry.Sub(time.Now()); !t.retry.IsZero() && d > wait {
+			wait = d
+		}
+		if attempt >= b.Retries || wait > b.Max {
+			return t.err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		delay *= 2
+		if delay > b.Max {
+			delay = b.Max
+		}
+	}
+}
+
+// retryable returns the error of a failed request as a temporary error if the
+// request is worth retrying: it got no response, a server error or exceeded
+// a rate limit. The time to retry at is taken from the Retry-After header or
+// the rate limit reset headers of Github and GitLab.
+func retryable(resp *http.Response, err error) error {
+	if err == nil {
+		return nil
+	}
+	if resp == nil {
+		return temporaryError{err: err}
+	}
+	rateLimited := resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0")
+	if !rateLimited && resp.StatusCode < http.StatusInternalServerError {
+		return err
+	}
+	return temporaryError{err: err, retry: retryTime(resp.Header, time.Now())}
+}
+
+// retryTime returns the time a request may be retried at as given by the
+// headers of its response, the zero time if they do not tell
+func retryTime(header http.Header, now time.Time) time.Time {
+	if s := header.Get("Retry-After"); s != "" {
+		if seconds, err := strconv.Atoi(s); err == nil {
+			return now.Add(time.Duration(seconds) * time.Second)
+		}
+		if t, err := http.ParseTime(s); err == nil {
+			return t
+		}
+	}
+	for _, key := range []string{"X-RateLimit-Reset", "RateLimit-Reset"} {
+		if epoch, err := strconv.ParseInt(header.Get(key), 10, 64); err == nil {
+			return time.Unix(epoch, 0)
+		}
+	}
+	return time.Time{}
+}
+
+// fetchItems runs the fetch in the background. The fetch sends the remote
+// items to the returned item channel, which is closed once the fetch ended;
+// the error channel then yields the error the fetch stopped with, nil if it
+// completed. Sending fails once the context is done, callers that stop
+// reading items early have to cancel it.
+func fetchItems(ctx context.Context, fetch func(send func(TrackerItemContent) error) error) (chan TrackerItemContent, chan error) {
+	items := make(chan TrackerItemContent)
+	result := make(chan error, 1)
+	go func() {
+		defer close(items)
+		result <- fetch(func(item TrackerItemContent) error {
+			select {
+			case items <- item:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return items, result
+}
@@ -0,0 +1,114 @@
+package remoteworkitem
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	b := backoff{Retries: 2, Initial: time.Millisecond, Max: 10 * time.Millisecond}
+	failure := errors.New("failure")
+
+	// temporary errors are retried
+	calls := 0
+	err := b.do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return temporaryError{err: failure}
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, calls)
+
+	// until the retries are exhausted
+	calls = 0
+	err = b.do(context.Background(), func() error {
+		calls++
+		return temporaryError{err: failure}
+	})
+	assert.Equal(t, failure, err)
+	assert.Equal(t, 3, calls)
+
+	// other errors are not
+	calls = 0
+	err = b.do(context.Background(), func() error {
+		calls++
+		return failure
+	})
+	assert.Equal(t, failure, err)
+	assert.Equal(t, 1, calls)
+
+	// nor are requests to be retried after the maximum delay
+	calls = 0
+	err = b.do(context.Background(), func() error {
+		calls++
+		return temporaryError{err: failure, retry: time.Now().Add(time.Hour)}
+	})
+	assert.Equal(t, failure, err)
+	assert.Equal(t, 1, calls)
+
+	// cancelling stops waiting for the retry
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = backoff{Retries: 2, Initial: time.Hour, Max: time.Hour}.do(ctx, func() error {
+		return temporaryError{err: failure}
+	})
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestRetryable(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	failure := errors.New("failure")
+	assert.Nil(t, retryable(&http.Response{StatusCode: http.StatusOK}, nil))
+	assert.IsType(t, temporaryError{}, retryable(nil, failure))
+	assert.IsType(t, temporaryError{}, retryable(&http.Response{StatusCode: http.StatusBadGateway}, failure))
+	assert.IsType(t, temporaryError{}, retryable(&http.Response{StatusCode: http.StatusTooManyRequests}, failure))
+	assert.Equal(t, failure, retryable(&http.Response{StatusCode: http.StatusNotFound}, failure))
+	assert.Equal(t, failure, retryable(&http.Response{StatusCode: http.StatusForbidden, Header: http.Header{}}, failure))
+
+	// Github tells an exceeded rate limit by the remaining requests
+	resp := &http.Response{StatusCode: http.StatusForbidden, Header: http.Header{}}
+	resp.Header.Set("X-RateLimit-Remaining", "0")
+	resp.Header.Set("X-RateLimit-Reset", "1478165400")
+	err := retryable(resp, failure)
+	assert.Equal(t, temporaryError{err: failure, retry: time.Unix(1478165400, 0)}, err)
+}
+
+func TestRetryTime(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	now := time.Date(2016, 11, 3, 9, 30, 0, 0, time.UTC)
+	header := http.Header{}
+	assert.True(t, retryTime(header, now).IsZero())
+	header.Set("RateLimit-Reset", "1478165400")
+	assert.Equal(t, time.Unix(1478165400, 0), retryTime(header, now))
+	header.Set("Retry-After", "120")
+	assert.Equal(t, now.Add(2*time.Minute), retryTime(header, now))
+	header.Set("Retry-After", "Thu, 03 Nov 2016 09:35:00 GMT")
+	assert.True(t, now.Add(5*time.Minute).Equal(retryTime(header, now)))
+}
+
+func TestFetchItemsCancelled(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	ctx, cancel := context.WithCancel(context.Background())
+	items, fetchErr := fetchItems(ctx, func(send func(TrackerItemContent) error) error {
+		for {
+			if err := send(TrackerItemContent{ID: `"1"`}); err != nil {
+				return err
+			}
+		}
+	})
+	<-items
+	cancel()
+	for range items {
+	}
+	assert.Equal(t, context.Canceled, <-fetchErr)
+}
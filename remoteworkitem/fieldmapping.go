@@ -0,0 +1,209 @@
+package remoteworkitem
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/almighty/almighty-core/workitem"
+)
+
+// The kinds of converters a field mapping can apply to a remote value
+const (
+	// ConverterString takes the remote value as it is
+	ConverterString = "string"
+	// ConverterTranslate looks the remote value up in the translation table
+	// of the mapping, keeping values that are not listed
+	ConverterTranslate = "translate"
+)
+
+// FieldMapping maps an attribute of a remote item to a work item field
+type FieldMapping struct {
+	// Path is the attribute expression in the flattened remote item, e.g. fields.status.name
+	Path string `json:"path"`
+	// Converter is the kind of conversion applied to the remote value
+	Converter string `json:"converter"`
+	// Field is the work item field the converted value is stored in
+	Field string `json:"field"`
+	// Values translates remote values into local ones
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// FieldMappings are the field mappings of a tracker
+type FieldMappings []FieldMapping
+
+// Value implements the driver.Valuer interface
+func (m FieldMappings) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
+
+// Scan implements the sql.Scanner interface
+func (m *FieldMappings) Scan(src interface{}) error {
+	if src == nil {
+		*m = nil
+		return nil
+	}
+	s, ok := src.([]byte)
+	if !ok {
+		return errors.New("Scan source was not string")
+	}
+	return json.Unmarshal(s, m)
+}
+
+// DefaultFieldMappings are used for trackers without mappings of their own
+var DefaultFieldMappings = map[string]FieldMappings{
+	ProviderGithub: {
+		{Path: GithubTitle, Converter: ConverterString, Field: workitem.SystemTitle},
+		{Path: GithubDescription, Converter: ConverterString, Field: workitem.SystemDescription},
+		{Path: GithubState, Converter: ConverterTranslate, Field: workitem.SystemState, Values: map[string]string{
+			"open":   workitem.SystemStateOpen,
+			"closed": workitem.SystemStateClosed,
+		}},
+		{Path: GithubID, Converter: ConverterString, Field: workitem.SystemRemoteItemID},
+		{Path: GithubCreator, Converter: ConverterString, Field: workitem.SystemCreator},
+		{Path: GithubAssignee, Converter: ConverterString, Field: workitem.SystemAssignee},
+	},
+	ProviderJira: {
+		{Path: JiraTitle, Converter: ConverterString, Field: workitem.SystemTitle},
+		{Path: JiraBody, Converter: ConverterString, Field: workitem.SystemDescription},
+		{Path: JiraState, Converter: ConverterTranslate, Field: workitem.SystemState, Values: map[string]string{
+			"To Do":       workitem.SystemStateNew,
+			"Open":        workitem.SystemStateOpen,
+			"Reopened":    workitem.SystemStateOpen,
+			"In Progress": workitem.SystemStateInProgress,
+			"Resolved":    workitem.SystemStateResolved,
+			"Closed":      workitem.SystemStateClosed,
+			"Done":        workitem.SystemStateClosed,
+		}},
+		{Path: JiraID, Converter: ConverterString, Field: workitem.SystemRemoteItemID},
+		{Path: JiraCreator, Converter: ConverterString, Field: workitem.SystemCreator},
+		{Path: JiraAssignee, Converter: ConverterString, Field: workitem.SystemAssignee},
+	},
+	ProviderGitlab: {
+		{Path: GitlabTitle, Converter: ConverterString, Field: workitem.SystemTitle},
+		{Path: GitlabDescription, Converter: ConverterString, Field: workitem.SystemDescription},
+		{Path: GitlabState, Converter: ConverterTranslate, Field: workitem.SystemState, Values: map[string]string{
+			"opened": workitem.SystemStateOpen,
+			"closed": workitem.SystemStateClosed,
+		}},
+		{Path: GitlabID, Converter: ConverterString, Field: workitem.SystemRemoteItemID},
+		{Path: GitlabCreator, Converter: ConverterString, Field: workitem.SystemCreator},
+		{Path: GitlabAssignee, Converter: ConverterString, Field: workitem.SystemAssignee},
+	},
+	ProviderBugzilla: {
+		{Path: BugzillaTitle, Converter: ConverterString, Field: workitem.SystemTitle},
+		{Path: BugzillaState, Converter: ConverterTranslate, Field: workitem.SystemState, Values: map[string]string{
+			"UNCONFIRMED": workitem.SystemStateNew,
+			"NEW":         workitem.SystemStateNew,
+			"CONFIRMED":   workitem.SystemStateOpen,
+			"ASSIGNED":    workitem.SystemStateOpen,
+			"REOPENED":    workitem.SystemStateOpen,
+			"IN_PROGRESS": workitem.SystemStateInProgress,
+			"RESOLVED":    workitem.SystemStateResolved,
+			"VERIFIED":    workitem.SystemStateClosed,
+		}},
+		{Path: BugzillaID, Converter: ConverterString, Field: workitem.SystemRemoteItemID},
+		{Path: BugzillaCreator, Converter: ConverterString, Field: workitem.SystemCreator},
+		{Path: BugzillaAssignee, Converter: ConverterString, Field: workitem.SystemAssignee},
+	},
+}
+
+// orDefault returns the mappings, falling back to the default mappings of the
+// given provider if there are none
+func (m FieldMappings) orDefault(provider string) FieldMappings {
+	if len(m) > 0 {
+		return m
+	}
+	return DefaultFieldMappings[provider]
+}
+
+// TranslateConverter translates remote values with a table
+type TranslateConverter struct {
+	Values map[string]string
+}
+
+// Convert looks the value up in the table, exactly or ignoring case, and
+// keeps values that are not listed
+func (c *TranslateConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	return translate(c.Values, s), nil
+}
+
+func translate(values map[string]string, s string) string {
+	if v, ok := values[s]; ok {
+		return v
+	}
+	for k, v := range values {
+		if strings.EqualFold(k, s) {
+			return v
+		}
+	}
+	return s
+}
+
+// workItemMap returns the mappings in the form used by Map
+func (m FieldMappings) workItemMap() WorkItemMap {
+	result := WorkItemMap{}
+	for _, fm := range m {
+		var converter AttributeConverter = StringConverter{}
+		if fm.Converter == ConverterTranslate {
+			converter = &TranslateConverter{Values: fm.Values}
+		}
+		result[AttributeMapper{AttributeExpression(fm.Path), converter}] = fm.Field
+	}
+	return result
+}
+
+// translate converts a remote value of the given field the same way an import does
+func (m FieldMappings) translate(field string, value string) string {
+	for _, fm := range m {
+		if fm.Field == field && fm.Converter == ConverterTranslate {
+			return translate(fm.Values, value)
+		}
+	}
+	return value
+}
+
+// validate checks the mappings against the fields of the given work item
+// type. Every field is mapped at most once and the remote item ID, which
+// identifies reimported items, must be mapped.
+func (m FieldMappings) validate(wit *workitem.WorkItemType) error {
+	mapped := map[string]bool{}
+	for i, fm := range m {
+		parameter := fmt.Sprintf("fieldMappings[%d]", i)
+		if fm.Path == "" {
+			return BadParameterError{parameter: parameter + ".path", value: fm.Path}
+		}
+		def, ok := wit.Fields[fm.Field]
+		if !ok || mapped[fm.Field] {
+			return BadParameterError{parameter: parameter + ".field", value: fm.Field}
+		}
+		mapped[fm.Field] = true
+		switch fm.Converter {
+		case ConverterString:
+			if len(fm.Values) > 0 {
+				return BadParameterError{parameter: parameter + ".values", value: fm.Values}
+			}
+		case ConverterTranslate:
+			for _, v := range fm.Values {
+				if _, err := def.Type.ConvertToModel(v); err != nil {
+					return BadParameterError{parameter: parameter + ".values", value: v}
+				}
+			}
+		default:
+			return BadParameterError{parameter: parameter + ".converter", value: fm.Converter}
+		}
+	}
+	if !mapped[workitem.SystemRemoteItemID] {
+		return BadParameterError{parameter: "fieldMappings", value: "no mapping for " + workitem.SystemRemoteItemID}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package remoteworkitem
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateConverter(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	c := TranslateConverter{Values: DefaultFieldMappings[ProviderJira][2].Values}
+	for remote, local := range map[string]string{
+		"In Progress": workitem.SystemStateInProgress,
+		"done":        workitem.SystemStateClosed,
+		"Triaged":     "Triaged",
+	} {
+		converted, err := c.Convert(remote, nil)
+		require.Nil(t, err)
+		assert.Equal(t, local, converted)
+	}
+	converted, err := c.Convert(nil, nil)
+	require.Nil(t, err)
+	assert.Nil(t, converted)
+}
+
+func TestMapWithFieldMappings(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	item, err := NewJiraRemoteWorkItem(TrackerItem{Item: `{"self":"https://issues.jboss.org/rest/api/2/issue/1","fields":{"summary":"crash","status":{"name":"Verified"}}}`})
+	require.Nil(t, err)
+	mappings := FieldMappings{
+		{Path: JiraID, Converter: ConverterString, Field: workitem.SystemRemoteItemID},
+		{Path: JiraTitle, Converter: ConverterString, Field: workitem.SystemDescription},
+		{Path: JiraState, Converter: ConverterTranslate, Field: workitem.SystemState, Values: map[string]string{"verified": workitem.SystemStateClosed}},
+	}
+	wi, err := Map(item, mappings.workItemMap())
+	require.Nil(t, err)
+	assert.Equal(t, "crash", wi.Fields[workitem.SystemDescription])
+	assert.Equal(t, workitem.SystemStateClosed, wi.Fields[workitem.SystemState])
+	assert.NotContains(t, wi.Fields, workitem.SystemTitle)
+}
+
+func TestFieldMappingsValidate(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	wit := &workitem.WorkItemType{Fields: workitem.FieldDefinitions{
+		workitem.SystemTitle:        {Type: workitem.SimpleType{Kind: workitem.KindString}},
+		workitem.SystemRemoteItemID: {Type: workitem.SimpleType{Kind: workitem.KindString}},
+		workitem.SystemState: {Type: workitem.EnumType{
+			BaseType: workitem.SimpleType{Kind: workitem.KindString},
+			Values:   []interface{}{workitem.SystemStateOpen, workitem.SystemStateClosed},
+		}},
+	}}
+	remoteID := FieldMapping{Path: GithubID, Converter: ConverterString, Field: workitem.SystemRemoteItemID}
+	state := FieldMapping{Path: GithubState, Converter: ConverterTranslate, Field: workitem.SystemState, Values: map[string]string{"closed": workitem.SystemStateClosed}}
+	assert.Nil(t, FieldMappings{remoteID, state}.validate(wit))
+
+	for name, mappings := range map[string]FieldMappings{
+		"remote id not mapped": {state},
+		"unknown field":        {remoteID, {Path: "milestone.title", Converter: ConverterString, Field: "system.milestone"}},
+		"field mapped twice":   {remoteID, {Path: "html_url", Converter: ConverterString, Field: workitem.SystemRemoteItemID}},
+		"unknown converter":    {remoteID, {Path: GithubTitle, Converter: "upper", Field: workitem.SystemTitle}},
+		"values of string":     {remoteID, {Path: GithubTitle, Converter: ConverterString, Field: workitem.SystemTitle, Values: map[string]string{"a": "b"}}},
+		"invalid state":        {remoteID, {Path: GithubState, Converter: ConverterTranslate, Field: workitem.SystemState, Values: map[string]string{"open": "new"}}},
+		"empty path":           {remoteID, {Converter: ConverterString, Field: workitem.SystemTitle}},
+	} {
+		assert.IsType(t, BadParameterError{}, mappings.validate(wit), name)
+	}
+}
@@ -2,22 +2,39 @@ package remoteworkitem
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
+	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/workitem"
 	"github.com/google/go-github/github"
+	"golang.org/x/net/context"
 	"golang.org/x/oauth2"
 )
 
-// githubFetcher provides issue listing
+// GithubCommentList is the attribute the comments of an issue are added to
+// its content as, the search results only count them
+const GithubCommentList = "comment_list"
+
+// githubFetcher provides issue and comment listing
 type githubFetcher interface {
 	listIssues(query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error)
+	// listComments returns all comments of the issue with the given API URL
+	listComments(issueURL string) ([]github.IssueComment, error)
 }
 
 // GithubTracker represents the Github tracker provider
 type GithubTracker struct {
 	URL   string
 	Query string
+	// Mappings translate the states of Github issues into work item states
+	Mappings FieldMappings
+	// Since restricts the fetch to the issues updated since then, all issues
+	// are fetched if it is nil
+	Since *time.Time
 }
 
 // GithubIssueFetcher fetch issues from github
@@ -30,43 +47,196 @@ func (f *githubIssueFetcher) listIssues(query string, opts *github.SearchOptions
 	return f.client.Search.Issues(query, opts)
 }
 
+// listComments lists all comments of an issue
+func (f *githubIssueFetcher) listComments(issueURL string) ([]github.IssueComment, error) {
+	var comments []github.IssueComment
+	for page := 1; page != 0; {
+		req, err := f.client.NewRequest("GET", fmt.Sprintf("%s/comments?per_page=100&page=%d", issueURL, page), nil)
+		if err != nil {
+			return nil, err
+		}
+		var list []github.IssueComment
+		response, err := f.client.Do(req, &list)
+		if err != nil {
+			return nil, githubRetryable(response, err)
+		}
+		comments = append(comments, list...)
+		page = response.NextPage
+	}
+	return comments, nil
+}
+
 // Fetch tracker items from Github
-func (g *GithubTracker) Fetch() chan TrackerItemContent {
+func (g *GithubTracker) Fetch(ctx context.Context) (chan TrackerItemContent, chan error) {
 	f := githubIssueFetcher{}
+	f.client = githubClient()
+	return g.fetch(ctx, &f)
+}
+
+// githubClient returns a client authorized with the configured token on the
+// configured Github API hosts
+func githubClient() *github.Client {
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: configuration.GetGithubAuthToken()},
 	)
-	tc := oauth2.NewClient(oauth2.NoContext, ts)
-	f.client = github.NewClient(tc)
-	return g.fetch(&f)
+	tc := &http.Client{Transport: &hostBoundTransport{
+		hosts:      configuration.GetGithubAuthHosts(),
+		authorized: &oauth2.Transport{Source: ts},
+	}}
+	return github.NewClient(tc)
 }
 
-func (g *GithubTracker) fetch(f githubFetcher) chan TrackerItemContent {
-	item := make(chan TrackerItemContent)
-	go func() {
+// query returns the search query of the tracker, restricted to the issues
+// updated since the last fetch
+func (g *GithubTracker) query() string {
+	if g.Since == nil {
+		return g.Query
+	}
+	return g.Query + " updated:>=" + g.Since.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+func (g *GithubTracker) fetch(ctx context.Context, f githubFetcher) (chan TrackerItemContent, chan error) {
+	return fetchItems(ctx, func(send func(TrackerItemContent) error) error {
+		// the oldest changes come first so that an interrupted fetch
+		// resumes where it stopped
 		opts := &github.SearchOptions{
+			Sort:  "updated",
+			Order: "asc",
 			ListOptions: github.ListOptions{
 				PerPage: 20,
 			},
 		}
+		query := g.query()
 		for {
-			result, response, err := f.listIssues(g.Query, opts)
-			if _, ok := err.(*github.RateLimitError); ok {
-				log.Println("reached rate limit", err)
-				break
+			var result *github.IssuesSearchResult
+			var response *github.Response
+			err := fetchBackoff.do(ctx, func() error {
+				var err error
+				result, response, err = f.listIssues(query, opts)
+				return githubRetryable(response, err)
+			})
+			if err != nil {
+				return err
 			}
-			issues := result.Issues
-			for _, l := range issues {
+			for _, l := range result.Issues {
 				id, _ := json.Marshal(l.URL)
 				content, _ := json.Marshal(l)
-				item <- TrackerItemContent{ID: string(id), Content: content}
+				if l.Comments != nil && *l.Comments > 0 && l.URL != nil {
+					content = withGithubComments(ctx, f, *l.URL, content)
+				}
+				if err := send(TrackerItemContent{ID: string(id), Content: content}); err != nil {
+					return err
+				}
 			}
 			if response.NextPage == 0 {
-				break
+				return nil
 			}
 			opts.ListOptions.Page = response.NextPage
 		}
-		close(item)
-	}()
-	return item
+	})
+}
+
+// githubRetryable returns the error of a Github request as a temporary error
+// if it is worth retrying, an exceeded rate limit until its reset
+func githubRetryable(response *github.Response, err error) error {
+	if e, ok := err.(*github.RateLimitError); ok {
+		return temporaryError{err: e, retry: e.Rate.Reset.Time}
+	}
+	var resp *http.Response
+	if response != nil {
+		resp = response.Response
+	}
+	return retryable(resp, err)
+}
+
+// withGithubComments adds the comments of the issue with the given API URL to
+// its content. The content is left as it is if they can not be listed, the
+// comments are imported on a later fetch then.
+func withGithubComments(ctx context.Context, f githubFetcher, issueURL string, content []byte) []byte {
+	var comments []github.IssueComment
+	err := fetchBackoff.do(ctx, func() error {
+		var err error
+		comments, err = f.listComments(issueURL)
+		return err
+	})
+	if err != nil {
+		log.Printf("listing the comments of %s failed: %v", issueURL, err)
+		return content
+	}
+	var issue map[string]interface{}
+	if err := json.Unmarshal(content, &issue); err != nil {
+		return content
+	}
+	issue[GithubCommentList] = comments
+	result, err := json.Marshal(issue)
+	if err != nil {
+		return content
+	}
+	return result
+}
+
+// RemoteValue implements TrackerPusher; Github issues are either open or closed
+func (g *GithubTracker) RemoteValue(field string, value interface{}) interface{} {
+	if field != workitem.SystemState || value == nil {
+		return value
+	}
+	return g.Mappings.translate(workitem.SystemState, g.githubState(value))
+}
+
+// githubState returns the state of a Github issue for the given work item state
+func (g *GithubTracker) githubState(state interface{}) string {
+	switch state {
+	case workitem.SystemStateClosed, workitem.SystemStateResolved, g.Mappings.translate(workitem.SystemState, "closed"):
+		return "closed"
+	}
+	return "open"
+}
+
+// Update sets the given work item fields on the Github issue with the given API URL
+func (g *GithubTracker) Update(remoteItemID string, fields map[string]interface{}) error {
+	return g.update(githubClient(), remoteItemID, fields)
+}
+
+func (g *GithubTracker) update(client *github.Client, issueURL string, fields map[string]interface{}) error {
+	issue := github.IssueRequest{}
+	for field, value := range fields {
+		s := syncValue(value)
+		switch field {
+		case workitem.SystemTitle:
+			issue.Title = &s
+		case workitem.SystemDescription:
+			issue.Body = &s
+		case workitem.SystemState:
+			state := g.githubState(value)
+			issue.State = &state
+		case workitem.SystemAssignee:
+			issue.Assignee = &s
+		}
+	}
+	req, err := client.NewRequest("PATCH", issueURL, &issue)
+	if err != nil {
+		return err
+	}
+	_, err = client.Do(req, nil)
+	return err
+}
+
+// Comment adds a comment to the Github issue with the given API URL
+func (g *GithubTracker) Comment(remoteItemID string, body string) (string, error) {
+	return g.comment(githubClient(), remoteItemID, body)
+}
+
+func (g *GithubTracker) comment(client *github.Client, issueURL string, body string) (string, error) {
+	req, err := client.NewRequest("POST", issueURL+"/comments", &github.IssueComment{Body: &body})
+	if err != nil {
+		return "", err
+	}
+	created := github.IssueComment{}
+	if _, err = client.Do(req, &created); err != nil {
+		return "", err
+	}
+	if created.ID == nil {
+		return "", InternalError{simpleError{"comment created without an ID"}}
+	}
+	return strconv.Itoa(*created.ID), nil
 }
@@ -6,9 +6,12 @@ import (
 	"testing"
 	"time"
 
+	"golang.org/x/net/context"
+
 	"github.com/almighty/almighty-core/resource"
 	"github.com/dnaeon/go-vcr/recorder"
 	"github.com/google/go-github/github"
+	"github.com/stretchr/testify/assert"
 )
 
 type fakeGithubIssueFetcher struct{}
@@ -30,11 +33,15 @@ func (f *fakeGithubIssueFetcher) listIssues(query string, opts *github.SearchOpt
 
 }
 
+func (f *fakeGithubIssueFetcher) listComments(issueURL string) ([]github.IssueComment, error) {
+	return nil, nil
+}
+
 func TestGithubFetch(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
 	f := fakeGithubIssueFetcher{}
 	g := GithubTracker{URL: "", Query: ""}
-	fetch := g.fetch(&f)
+	fetch, _ := g.fetch(context.Background(), &f)
 	i := <-fetch
 	if string(i.Content) != `{"id":1}` {
 		t.Errorf("Content is not matching: %#v", string(i.Content))
@@ -42,26 +49,61 @@ func TestGithubFetch(t *testing.T) {
 
 }
 
-type fakeGithubIssueFetcherWithRateLimit struct{}
+type fakeGithubIssueFetcherWithRateLimit struct {
+	calls int
+}
 
 // ListIssues list all issues
 func (f *fakeGithubIssueFetcherWithRateLimit) listIssues(query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
-	isr := &github.IssuesSearchResult{}
-	r := &github.Response{}
-	r.NextPage = 0
+	f.calls++
 	e := &github.RateLimitError{}
-	return isr, r, e
+	return nil, nil, e
+
+}
 
+func (f *fakeGithubIssueFetcherWithRateLimit) listComments(issueURL string) ([]github.IssueComment, error) {
+	return nil, nil
 }
 
 func TestGithubFetchWithRateLimit(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
+	defer func(b backoff) { fetchBackoff = b }(fetchBackoff)
+	fetchBackoff = backoff{Retries: 2, Initial: time.Millisecond, Max: time.Millisecond}
 	f := fakeGithubIssueFetcherWithRateLimit{}
 	g := GithubTracker{URL: "", Query: ""}
-	fetch := g.fetch(&f)
-	if len(fetch) > 0 {
+	fetch, fetchErr := g.fetch(context.Background(), &f)
+	if _, ok := <-fetch; ok {
 		t.Error("Channel should not have any data")
 	}
+	// the rate limit error is returned once the retries are exhausted
+	assert.IsType(t, &github.RateLimitError{}, <-fetchErr)
+	assert.Equal(t, 3, f.calls)
+}
+
+type fakeGithubIssueFetcherWithComments struct{}
+
+func (f *fakeGithubIssueFetcherWithComments) listIssues(query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+	url := "https://api.github.com/repos/almighty/test/issues/1"
+	comments := 1
+	isr := &github.IssuesSearchResult{Issues: []github.Issue{{URL: &url, Comments: &comments}}}
+	return isr, &github.Response{}, nil
+}
+
+func (f *fakeGithubIssueFetcherWithComments) listComments(issueURL string) ([]github.IssueComment, error) {
+	id := 42
+	body := "comment of " + issueURL
+	return []github.IssueComment{{ID: &id, Body: &body}}, nil
+}
+
+func TestGithubFetchWithComments(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	f := fakeGithubIssueFetcherWithComments{}
+	g := GithubTracker{URL: "", Query: ""}
+	fetch, _ := g.fetch(context.Background(), &f)
+	i := <-fetch
+	if !strings.Contains(string(i.Content), `"comment_list":[{"id":42,"body":"comment of https://api.github.com/repos/almighty/test/issues/1"}]`) {
+		t.Errorf("Content is not matching: %#v", string(i.Content))
+	}
 }
 
 func TestGithubFetchWithRecording(t *testing.T) {
@@ -80,7 +122,7 @@ func TestGithubFetchWithRecording(t *testing.T) {
 	f := githubIssueFetcher{}
 	f.client = github.NewClient(h)
 	g := &GithubTracker{URL: "", Query: "is:open is:issue user:almighty-test"}
-	fetch := g.fetch(&f)
+	fetch, _ := g.fetch(context.Background(), &f)
 	i := <-fetch
 	if !strings.Contains(string(i.Content), `"html_url":"https://github.com/almighty-test/almighty-test-unit/issues/2"`) {
 		t.Errorf("Content is not matching: %#v", string(i.Content))
@@ -0,0 +1,119 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/configuration"
+	"golang.org/x/net/context"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// gitlabPageSize is the number of issues requested per page
+const gitlabPageSize = 20
+
+// GitlabTracker represents the GitLab tracker provider
+type GitlabTracker struct {
+	// URL is the base URL of the GitLab instance, e.g. https://gitlab.com
+	URL string
+	// Query is the issues API path with its parameters relative to
+	// /api/v4/, e.g. projects/13083/issues?state=opened&labels=bug
+	Query string
+	// Since restricts the fetch to the issues updated since then, all issues
+	// are fetched if it is nil
+	Since *time.Time
+}
+
+// gitlabFetcher provides issue listing
+type gitlabFetcher interface {
+	// listIssues returns a page of issues and the number of the next page, 0 after the last one
+	listIssues(ctx context.Context, query string, page int) ([]map[string]interface{}, int, error)
+}
+
+// gitlabIssueFetcher fetches issues from the GitLab issues API
+type gitlabIssueFetcher struct {
+	client *http.Client
+	url    string
+}
+
+// listIssues lists a page of issues
+func (f *gitlabIssueFetcher) listIssues(ctx context.Context, query string, page int) ([]map[string]interface{}, int, error) {
+	u := fmt.Sprintf("%s/api/v4/%s&per_page=%d&page=%d", strings.TrimSuffix(f.url, "/"), query, gitlabPageSize, page)
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := ctxhttp.Do(ctx, f.client, req)
+	if err != nil {
+		return nil, 0, retryable(nil, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, retryable(resp, fmt.Errorf("listing GitLab issues failed: %s", resp.Status))
+	}
+	var issues []map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&issues); err != nil {
+		return nil, 0, err
+	}
+	// X-Next-Page is empty on the last page
+	next, _ := strconv.Atoi(resp.Header.Get("X-Next-Page"))
+	return issues, next, nil
+}
+
+// Fetch tracker items from GitLab
+func (g *GitlabTracker) Fetch(ctx context.Context) (chan TrackerItemContent, chan error) {
+	client := tokenClient(configuration.GetGitlabAuthHosts(), "PRIVATE-TOKEN", configuration.GetGitlabAuthToken())
+	f := gitlabIssueFetcher{client: client, url: g.URL}
+	return g.fetch(ctx, &f)
+}
+
+// query returns the issues API path with its parameters, ordered by the
+// update time, the oldest changes first so that an interrupted fetch resumes
+// where it stopped, and restricted to the issues updated since the last fetch
+func (g *GitlabTracker) query() string {
+	path := g.Query
+	params := url.Values{}
+	if i := strings.Index(g.Query, "?"); i >= 0 {
+		path = g.Query[:i]
+		params, _ = url.ParseQuery(g.Query[i+1:])
+	}
+	params.Set("order_by", "updated_at")
+	params.Set("sort", "asc")
+	if g.Since != nil {
+		params.Set("updated_after", g.Since.UTC().Format(time.RFC3339))
+	}
+	return strings.TrimPrefix(path, "/") + "?" + params.Encode()
+}
+
+func (g *GitlabTracker) fetch(ctx context.Context, f gitlabFetcher) (chan TrackerItemContent, chan error) {
+	return fetchItems(ctx, func(send func(TrackerItemContent) error) error {
+		query := g.query()
+		for page := 1; page != 0; {
+			var issues []map[string]interface{}
+			next := 0
+			err := fetchBackoff.do(ctx, func() error {
+				var err error
+				issues, next, err = f.listIssues(ctx, query, page)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			for _, issue := range issues {
+				id, _ := json.Marshal(issue[GitlabID])
+				content, _ := json.Marshal(issue)
+				if err := send(TrackerItemContent{ID: string(id), Content: content}); err != nil {
+					return err
+				}
+			}
+			page = next
+		}
+		return nil
+	})
+}
@@ -0,0 +1,85 @@
+package remoteworkitem
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/dnaeon/go-vcr/recorder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGitlabIssueFetcher struct{}
+
+// listIssues returns an issue on the first page and none on the second
+func (f *fakeGitlabIssueFetcher) listIssues(ctx context.Context, query string, page int) ([]map[string]interface{}, int, error) {
+	if page == 1 {
+		return []map[string]interface{}{{"web_url": "https://gitlab.com/almighty-test/almighty-test-unit/issues/1"}}, 2, nil
+	}
+	return []map[string]interface{}{}, 0, nil
+}
+
+func TestGitlabFetch(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	f := fakeGitlabIssueFetcher{}
+	g := GitlabTracker{URL: "", Query: ""}
+	fetch, _ := g.fetch(context.Background(), &f)
+	i := <-fetch
+	if string(i.Content) != `{"web_url":"https://gitlab.com/almighty-test/almighty-test-unit/issues/1"}` {
+		t.Errorf("Content is not matching: %#v", string(i.Content))
+	}
+	if _, ok := <-fetch; ok {
+		t.Error("Channel should be closed")
+	}
+}
+
+func TestGitlabQuery(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	g := GitlabTracker{Query: "/projects/2146/issues?state=opened&labels=bug"}
+	assert.Equal(t, "projects/2146/issues?labels=bug&order_by=updated_at&sort=asc&state=opened", g.query())
+	since := time.Date(2016, 11, 3, 10, 30, 0, 0, time.FixedZone("CET", 3600))
+	g = GitlabTracker{Query: "issues", Since: &since}
+	assert.Equal(t, "issues?order_by=updated_at&sort=asc&updated_after=2016-11-03T09%3A30%3A00Z", g.query())
+}
+
+func TestGitlabFetchWithRecording(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	r, err := recorder.New("../test/data/gitlab_fetch_test")
+	if err != nil {
+		t.Error(err)
+	}
+	defer r.Stop()
+
+	h := &http.Client{
+		Timeout:   1 * time.Second,
+		Transport: r.Transport,
+	}
+
+	f := gitlabIssueFetcher{client: h, url: "https://gitlab.com"}
+	g := &GitlabTracker{URL: "https://gitlab.com", Query: "projects/2146/issues?state=opened"}
+	fetch, _ := g.fetch(context.Background(), &f)
+	i := <-fetch
+	if !strings.Contains(string(i.Content), `"web_url":"https://gitlab.com/almighty-test/almighty-test-unit/issues/2"`) {
+		t.Errorf("Content is not matching: %#v", string(i.Content))
+	}
+	// the second issue is on the next page
+	i2 := <-fetch
+	if i2.ID != `"https://gitlab.com/almighty-test/almighty-test-unit/issues/1"` {
+		t.Errorf("ID is not matching: %#v", i2.ID)
+	}
+
+	remoteItem, err := NewGitlabRemoteWorkItem(TrackerItem{Item: string(i.Content)})
+	require.Nil(t, err)
+	workItem, err := Map(remoteItem, WorkItemKeyMaps[ProviderGitlab])
+	require.Nil(t, err)
+	assert.Equal(t, "flatten : test case : with assignee", workItem.Fields[workitem.SystemTitle])
+	assert.Equal(t, workitem.SystemStateOpen, workItem.Fields[workitem.SystemState])
+	assert.Equal(t, "sbose78", workItem.Fields[workitem.SystemAssignee])
+	assert.Equal(t, "https://gitlab.com/almighty-test/almighty-test-unit/issues/2", workItem.Fields[workitem.SystemRemoteItemID])
+}
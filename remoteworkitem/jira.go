@@ -2,16 +2,32 @@ package remoteworkitem
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/workitem"
 	jira "github.com/andygrunwald/go-jira"
+	"golang.org/x/net/context"
 )
 
 // JiraTracker represents the Jira tracker provider
 type JiraTracker struct {
 	URL   string
 	Query string
+	// Mappings translate the names of Jira statuses into work item states
+	Mappings FieldMappings
+	// Since restricts the fetch to the issues updated since then, all issues
+	// are fetched if it is nil
+	Since *time.Time
 }
 
+// jiraPageSize is the number of issues requested per search
+const jiraPageSize = 50
+
 type jiraFetcher interface {
 	listIssues(jql string, options *jira.SearchOptions) ([]jira.Issue, *jira.Response, error)
 	getIssue(issueID string) (*jira.Issue, *jira.Response, error)
@@ -30,24 +46,196 @@ func (f *jiraIssueFetcher) getIssue(issueID string) (*jira.Issue, *jira.Response
 }
 
 // Fetch collects data from Jira
-func (j *JiraTracker) Fetch() chan TrackerItemContent {
+func (j *JiraTracker) Fetch(ctx context.Context) (chan TrackerItemContent, chan error) {
 	f := jiraIssueFetcher{}
 	client, _ := jira.NewClient(nil, j.URL)
 	f.client = client
-	return j.fetch(&f)
-}
-
-func (j *JiraTracker) fetch(f jiraFetcher) chan TrackerItemContent {
-	item := make(chan TrackerItemContent)
-	go func() {
-		issues, _, _ := f.listIssues(j.Query, nil)
-		for _, l := range issues {
-			id, _ := json.Marshal(l.Key)
-			issue, _, _ := f.getIssue(l.Key)
-			content, _ := json.Marshal(issue)
-			item <- TrackerItemContent{ID: string(id), Content: content}
+	return j.fetch(ctx, &f)
+}
+
+// jqlOrderBy matches the ORDER BY clause of a JQL query
+var jqlOrderBy = regexp.MustCompile(`(?i)\s+order\s+by\s.*$`)
+
+// query returns the JQL query of the tracker ordered by the update time, the
+// oldest changes first so that an interrupted fetch resumes where it stopped.
+// Since is given as a duration relative to the current time as JQL reads
+// absolute dates in the time zone of the Jira user.
+func (j *JiraTracker) query(now time.Time) string {
+	jql := jqlOrderBy.ReplaceAllString(strings.TrimSpace(j.Query), "")
+	if j.Since != nil {
+		minutes := int(now.Sub(*j.Since).Minutes()) + 1
+		if jql == "" {
+			jql = fmt.Sprintf("updated >= -%dm", minutes)
+		} else {
+			jql = fmt.Sprintf("(%s) AND updated >= -%dm", jql, minutes)
+		}
+	}
+	return strings.TrimSpace(jql + " ORDER BY updated ASC")
+}
+
+func (j *JiraTracker) fetch(ctx context.Context, f jiraFetcher) (chan TrackerItemContent, chan error) {
+	return fetchItems(ctx, func(send func(TrackerItemContent) error) error {
+		jql := j.query(time.Now())
+		for startAt := 0; ; {
+			var issues []jira.Issue
+			err := fetchBackoff.do(ctx, func() error {
+				var resp *jira.Response
+				var err error
+				issues, resp, err = f.listIssues(jql, &jira.SearchOptions{StartAt: startAt, MaxResults: jiraPageSize})
+				return jiraRetryable(resp, err)
+			})
+			if err != nil {
+				return err
+			}
+			for _, l := range issues {
+				id, _ := json.Marshal(l.Key)
+				var issue *jira.Issue
+				err := fetchBackoff.do(ctx, func() error {
+					var resp *jira.Response
+					var err error
+					issue, resp, err = f.getIssue(l.Key)
+					return jiraRetryable(resp, err)
+				})
+				if err != nil {
+					return err
+				}
+				content, _ := json.Marshal(issue)
+				if err := send(TrackerItemContent{ID: string(id), Content: content}); err != nil {
+					return err
+				}
+			}
+			if len(issues) < jiraPageSize {
+				return nil
+			}
+			startAt += len(issues)
 		}
-		close(item)
-	}()
-	return item
+	})
+}
+
+// jiraRetryable returns the error of a Jira request as a temporary error if it
+// is worth retrying
+func jiraRetryable(resp *jira.Response, err error) error {
+	if resp == nil {
+		return retryable(nil, err)
+	}
+	return retryable(resp.Response, err)
+}
+
+// jiraClient returns a client authorized with the configured credentials on
+// the configured Jira hosts
+func (j *JiraTracker) jiraClient() (*jira.Client, error) {
+	h := &http.Client{Transport: &hostBoundTransport{
+		hosts: configuration.GetJiraAuthHosts(),
+		authorized: &basicAuthTransport{
+			username: configuration.GetJiraAuthUsername(),
+			password: configuration.GetJiraAuthPassword(),
+		},
+	}}
+	return jira.NewClient(h, j.URL)
+}
+
+// RemoteValue implements TrackerPusher; the state names the status a Jira
+// issue transitions to.
+func (j *JiraTracker) RemoteValue(field string, value interface{}) interface{} {
+	return value
+}
+
+// Update sets the given work item fields on the Jira issue with the given API URL
+func (j *JiraTracker) Update(remoteItemID string, fields map[string]interface{}) error {
+	client, err := j.jiraClient()
+	if err != nil {
+		return err
+	}
+	return j.update(client, remoteItemID, fields)
+}
+
+func (j *JiraTracker) update(client *jira.Client, issueURL string, fields map[string]interface{}) error {
+	issue := map[string]interface{}{}
+	var state string
+	for field, value := range fields {
+		s := syncValue(value)
+		switch field {
+		case workitem.SystemTitle:
+			issue["summary"] = s
+		case workitem.SystemDescription:
+			issue["description"] = s
+		case workitem.SystemAssignee:
+			if s == "" {
+				issue["assignee"] = nil
+			} else {
+				issue["assignee"] = map[string]string{"name": s}
+			}
+		case workitem.SystemState:
+			state = s
+		}
+	}
+	if len(issue) > 0 {
+		req, err := client.NewRequest("PUT", issueURL, map[string]interface{}{"fields": issue})
+		if err != nil {
+			return err
+		}
+		if _, err := client.Do(req, nil); err != nil {
+			return err
+		}
+	}
+	if state == "" {
+		return nil
+	}
+	return j.transition(client, issueURL, state)
+}
+
+// transition moves the Jira issue into the status with the given name or the
+// status translating into the given state
+func (j *JiraTracker) transition(client *jira.Client, issueURL string, state string) error {
+	req, err := client.NewRequest("GET", issueURL+"/transitions", nil)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Transitions []struct {
+			ID string `json:"id"`
+			To struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if _, err := client.Do(req, &result); err != nil {
+		return err
+	}
+	for _, t := range result.Transitions {
+		if strings.EqualFold(t.To.Name, state) || j.Mappings.translate(workitem.SystemState, t.To.Name) == state {
+			req, err := client.NewRequest("POST", issueURL+"/transitions", map[string]interface{}{
+				"transition": map[string]string{"id": t.ID},
+			})
+			if err != nil {
+				return err
+			}
+			_, err = client.Do(req, nil)
+			return err
+		}
+	}
+	return BadParameterError{parameter: "state", value: state}
+}
+
+// Comment adds a comment to the Jira issue with the given API URL
+func (j *JiraTracker) Comment(remoteItemID string, body string) (string, error) {
+	client, err := j.jiraClient()
+	if err != nil {
+		return "", err
+	}
+	return j.comment(client, remoteItemID, body)
+}
+
+func (j *JiraTracker) comment(client *jira.Client, issueURL string, body string) (string, error) {
+	req, err := client.NewRequest("POST", issueURL+"/comment", map[string]string{"body": body})
+	if err != nil {
+		return "", err
+	}
+	created := struct {
+		ID string `json:"id"`
+	}{}
+	if _, err := client.Do(req, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
 }
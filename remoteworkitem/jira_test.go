@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"golang.org/x/net/context"
+
 	"github.com/almighty/almighty-core/resource"
 	jira "github.com/andygrunwald/go-jira"
 	"github.com/dnaeon/go-vcr/recorder"
@@ -24,11 +26,55 @@ func TestJiraFetch(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
 	f := fakeJiraIssueFetcher{}
 	j := JiraTracker{URL: "", Query: ""}
-	i := <-j.fetch(&f)
+	fetch, fetchErr := j.fetch(context.Background(), &f)
+	i := <-fetch
 	if string(i.Content) != `{"id":"1"}` {
 		t.Errorf("Content is not matching: %#v", string(i.Content))
 	}
+	if _, ok := <-fetch; ok {
+		t.Error("Channel should be closed")
+	}
+	if err := <-fetchErr; err != nil {
+		t.Error(err)
+	}
+}
+
+type fakePagedJiraIssueFetcher struct {
+	startAts []int
+}
+
+// listIssues returns a full page of issues first and one issue after it
+func (f *fakePagedJiraIssueFetcher) listIssues(jql string, options *jira.SearchOptions) ([]jira.Issue, *jira.Response, error) {
+	f.startAts = append(f.startAts, options.StartAt)
+	count := jiraPageSize
+	if options.StartAt > 0 {
+		count = 1
+	}
+	return make([]jira.Issue, count), &jira.Response{}, nil
+}
 
+func (f *fakePagedJiraIssueFetcher) getIssue(issueID string) (*jira.Issue, *jira.Response, error) {
+	return &jira.Issue{ID: "1"}, &jira.Response{}, nil
+}
+
+func TestJiraFetchPages(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	f := fakePagedJiraIssueFetcher{}
+	j := JiraTracker{URL: "", Query: ""}
+	fetch, fetchErr := j.fetch(context.Background(), &f)
+	count := 0
+	for range fetch {
+		count++
+	}
+	if err := <-fetchErr; err != nil {
+		t.Error(err)
+	}
+	if count != jiraPageSize+1 {
+		t.Errorf("Fetched %d issues", count)
+	}
+	if len(f.startAts) != 2 || f.startAts[1] != jiraPageSize {
+		t.Errorf("Pages are not matching: %v", f.startAts)
+	}
 }
 
 func TestJiraFetchWithRecording(t *testing.T) {
@@ -48,7 +94,7 @@ func TestJiraFetchWithRecording(t *testing.T) {
 	j := JiraTracker{URL: "https://issues.jboss.org", Query: "project = Arquillian AND status = Closed AND assignee = aslak AND fixVersion = 1.1.11.Final AND priority = Major ORDER BY created ASC"}
 	client, _ := jira.NewClient(h, j.URL)
 	f.client = client
-	fetch := j.fetch(&f)
+	fetch, _ := j.fetch(context.Background(), &f)
 
 	i := <-fetch
 	if i.ID != `"ARQ-1937"` {
@@ -0,0 +1,207 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem/link"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// GithubCrossReference is the link type name of the references of Github
+// issues to other issues, Github has no typed issue links
+const GithubCrossReference = "cross-reference"
+
+// remoteLink is a link between two remote items
+type remoteLink struct {
+	// ID identifies the link on the remote tracker
+	ID string
+	// Type is the name of the remote link type, the key of the link types of the tracker
+	Type string
+	// SourceID and TargetID are the remote IDs of the linked items
+	SourceID string
+	TargetID string
+}
+
+// RemoteLink relates a work item link to the remote link it was imported from
+type RemoteLink struct {
+	CreatedAt    time.Time
+	LinkID       uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	TrackerID    uint64
+	RemoteLinkID string
+}
+
+// TableName implements gorm.tabler
+func (l RemoteLink) TableName() string {
+	return "remote_links"
+}
+
+// remoteLinkReaders extract the links to other items from the content of the
+// remote item with the given ID. The items of other tracker types are imported
+// without links.
+var remoteLinkReaders = map[string]func(itemID string, content []byte) ([]remoteLink, error){
+	ProviderGithub: githubLinks,
+	ProviderJira:   jiraLinks,
+}
+
+// githubReference matches the references of Github issues in a text: #1,
+// owner/repo#1 and the URL of an issue
+var githubReference = regexp.MustCompile(`(?:^|[^\w/#])(?:([\w.-]+)/([\w.-]+))?#(\d+)\b|https://github\.com/([\w.-]+)/([\w.-]+)/issues/(\d+)`)
+
+// githubLinks returns the issues referenced by the body and the comments of a
+// Github issue as cross-references
+func githubLinks(itemID string, content []byte) ([]remoteLink, error) {
+	var issue struct {
+		URL      string `json:"url"`
+		Body     string `json:"body"`
+		Comments []struct {
+			Body string `json:"body"`
+		} `json:"comment_list"`
+	}
+	if err := json.Unmarshal(content, &issue); err != nil {
+		return nil, err
+	}
+	// the API URL of an issue is <api>/repos/<owner>/<repo>/issues/<number>
+	i := strings.LastIndex(issue.URL, "/repos/")
+	j := strings.LastIndex(issue.URL, "/issues/")
+	if i < 0 || j < i {
+		return nil, nil
+	}
+	api, repo := issue.URL[:i], issue.URL[i+len("/repos/"):j]
+	texts := []string{issue.Body}
+	for _, c := range issue.Comments {
+		texts = append(texts, c.Body)
+	}
+	var links []remoteLink
+	seen := map[string]bool{issue.URL: true}
+	for _, text := range texts {
+		for _, m := range githubReference.FindAllStringSubmatch(text, -1) {
+			target, number := repo, m[3]
+			if m[4] != "" {
+				target, number = m[4]+"/"+m[5], m[6]
+			} else if m[1] != "" {
+				target = m[1] + "/" + m[2]
+			}
+			targetURL := fmt.Sprintf("%s/repos/%s/issues/%s", api, target, number)
+			if seen[targetURL] {
+				continue
+			}
+			seen[targetURL] = true
+			targetID, _ := json.Marshal(targetURL)
+			links = append(links, remoteLink{
+				ID:       itemID + "->" + string(targetID),
+				Type:     GithubCrossReference,
+				SourceID: itemID,
+				TargetID: string(targetID),
+			})
+		}
+	}
+	return links, nil
+}
+
+// jiraLinks returns the issue links of a Jira issue. Both linked issues list
+// the link, an inward one is returned with the other issue as its source.
+func jiraLinks(itemID string, content []byte) ([]remoteLink, error) {
+	type linkedIssue struct {
+		Key string `json:"key"`
+	}
+	var issue struct {
+		Fields struct {
+			IssueLinks []struct {
+				ID   string `json:"id"`
+				Type struct {
+					Name string `json:"name"`
+				} `json:"type"`
+				InwardIssue  *linkedIssue `json:"inwardIssue"`
+				OutwardIssue *linkedIssue `json:"outwardIssue"`
+			} `json:"issuelinks"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(content, &issue); err != nil {
+		return nil, err
+	}
+	var links []remoteLink
+	for _, l := range issue.Fields.IssueLinks {
+		rl := remoteLink{ID: l.ID, Type: l.Type.Name}
+		switch {
+		case l.OutwardIssue != nil:
+			key, _ := json.Marshal(l.OutwardIssue.Key)
+			rl.SourceID, rl.TargetID = itemID, string(key)
+		case l.InwardIssue != nil:
+			key, _ := json.Marshal(l.InwardIssue.Key)
+			rl.SourceID, rl.TargetID = string(key), itemID
+		default:
+			continue
+		}
+		links = append(links, rl)
+	}
+	return links, nil
+}
+
+// importLinks creates the work item links of the remote links of a mapped
+// type between items imported from the tracker. Links to items not imported
+// yet are created once the linked item lists them, Jira issues list their
+// links on both sides. Links imported before are recognized by their remote
+// IDs, links the link type does not allow are logged and skipped.
+func importLinks(db *gorm.DB, tracker *Tracker, links []remoteLink) error {
+	lr := link.NewWorkItemLinkRepository(db)
+	for _, l := range links {
+		linkTypeID, err := uuid.FromString(tracker.LinkTypes[l.Type])
+		if err != nil {
+			continue
+		}
+		var count int
+		if err := db.Model(&RemoteLink{}).Where("tracker_id = ? AND remote_link_id = ?", tracker.ID, l.ID).Count(&count).Error; err != nil {
+			return InternalError{simpleError{err.Error()}}
+		}
+		if count > 0 {
+			continue
+		}
+		sourceID, ok := importedWorkItemID(db, tracker.ID, l.SourceID)
+		if !ok {
+			continue
+		}
+		targetID, ok := importedWorkItemID(db, tracker.ID, l.TargetID)
+		if !ok {
+			continue
+		}
+		created, err := lr.Create(context.Background(), sourceID, targetID, linkTypeID)
+		if err != nil {
+			// links are rejected before they are written, any other
+			// failure leaves the transaction unusable
+			switch err.(type) {
+			case errors.BadParameterError, errors.NotFoundError:
+				log.Printf("importing link %s of type %s failed: %v", l.ID, l.Type, err)
+				continue
+			}
+			return InternalError{simpleError{err.Error()}}
+		}
+		linkID, err := uuid.FromString(*created.Data.ID)
+		if err != nil {
+			return InternalError{simpleError{err.Error()}}
+		}
+		rl := RemoteLink{LinkID: linkID, TrackerID: tracker.ID, RemoteLinkID: l.ID}
+		if err := db.Create(&rl).Error; err != nil {
+			return InternalError{simpleError{err.Error()}}
+		}
+	}
+	return nil
+}
+
+// importedWorkItemID returns the ID of the work item the remote item with the
+// given ID was imported into from the tracker, if it was
+func importedWorkItemID(db *gorm.DB, trackerID uint64, remoteItemID string) (uint64, bool) {
+	var ti TrackerItem
+	if db.Where("remote_item_id = ? AND tracker_id = ?", remoteItemID, trackerID).Find(&ti).RecordNotFound() || ti.WorkItemID == nil {
+		return 0, false
+	}
+	return *ti.WorkItemID, true
+}
@@ -0,0 +1,102 @@
+package remoteworkitem
+
+import (
+	"strconv"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGithubLinks(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	itemID := `"https://api.github.com/repos/almighty/test/issues/1"`
+	links, err := githubLinks(itemID, []byte(`{
+		"url":"https://api.github.com/repos/almighty/test/issues/1",
+		"body":"Duplicate of #2, see also almighty/other#3 and #1.",
+		"comment_list":[{"body":"Fixed by https://github.com/almighty/other/issues/3 and #4"},{"body":"not an issue: abc#5, http://example.com/#6"}]
+	}`))
+	require.Nil(t, err)
+	var targets []string
+	for _, l := range links {
+		assert.Equal(t, GithubCrossReference, l.Type)
+		assert.Equal(t, itemID, l.SourceID)
+		assert.Equal(t, itemID+"->"+l.TargetID, l.ID)
+		targets = append(targets, l.TargetID)
+	}
+	assert.Equal(t, []string{
+		`"https://api.github.com/repos/almighty/test/issues/2"`,
+		`"https://api.github.com/repos/almighty/other/issues/3"`,
+		`"https://api.github.com/repos/almighty/test/issues/4"`,
+	}, targets)
+}
+
+func TestJiraLinks(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	links, err := jiraLinks(`"ARQ-1"`, []byte(`{"key":"ARQ-1","fields":{"issuelinks":[
+		{"id":"100","type":{"name":"Blocks"},"outwardIssue":{"key":"ARQ-2"}},
+		{"id":"101","type":{"name":"Duplicate"},"inwardIssue":{"key":"ARQ-3"}}
+	]}}`))
+	require.Nil(t, err)
+	assert.Equal(t, []remoteLink{
+		{ID: "100", Type: "Blocks", SourceID: `"ARQ-1"`, TargetID: `"ARQ-2"`},
+		{ID: "101", Type: "Duplicate", SourceID: `"ARQ-3"`, TargetID: `"ARQ-1"`},
+	}, links)
+}
+
+func TestImportLinks(t *testing.T) {
+	doWithTransaction(t, func(tx *gorm.DB) {
+		ctx := context.Background()
+		catName := "remote links " + uuid.NewV4().String()
+		cat, err := link.NewWorkItemLinkCategoryRepository(tx).Create(ctx, &catName, nil)
+		require.Nil(t, err)
+		catID, err := uuid.FromString(*cat.Data.ID)
+		require.Nil(t, err)
+		lt, err := link.NewWorkItemLinkTypeRepository(tx).Create(ctx, "blocks", nil, workitem.SystemBug, workitem.SystemBug, "blocks", "blocked by", link.TopologyNetwork, catID)
+		require.Nil(t, err)
+
+		tr := Tracker{URL: "https://issues.jboss.org", Type: ProviderJira, LinkTypes: NameMappings{"Blocks": *lt.Data.ID}}
+		require.Nil(t, tx.Create(&tr).Error)
+		tID := int(tr.ID)
+		prefix := "ARQ" + strconv.FormatUint(tr.ID, 10)
+		issue := func(n string, links string) TrackerItemContent {
+			key := prefix + "-" + n
+			return TrackerItemContent{
+				ID:      `"` + key + `"`,
+				Content: []byte(`{"key":"` + key + `","self":"https://issues.jboss.org/rest/api/2/issue/` + key + `","fields":{"summary":"` + key + `","issuelinks":[` + links + `]}}`),
+			}
+		}
+		// ARQ-1 blocks ARQ-2 and duplicates ARQ-3, whose link type is not imported
+		first := issue("1", `{"id":"100","type":{"name":"Blocks"},"outwardIssue":{"key":"`+prefix+`-2"}},{"id":"101","type":{"name":"Duplicate"},"outwardIssue":{"key":"`+prefix+`-3"}}`)
+		second := issue("2", `{"id":"100","type":{"name":"Blocks"},"inwardIssue":{"key":"`+prefix+`-1"}}`)
+
+		// the link is created once both issues are imported
+		require.Nil(t, upload(tx, tID, first))
+		source, err := convert(tx, project.SystemProject, tID, first, ProviderJira)
+		require.Nil(t, err)
+		var count int
+		require.Nil(t, tx.Model(&RemoteLink{}).Where("tracker_id = ?", tr.ID).Count(&count).Error)
+		assert.Equal(t, 0, count)
+
+		require.Nil(t, upload(tx, tID, second))
+		target, err := convert(tx, project.SystemProject, tID, second, ProviderJira)
+		require.Nil(t, err)
+		_, err = convert(tx, project.SystemProject, tID, first, ProviderJira)
+		require.Nil(t, err)
+
+		var links []link.WorkItemLink
+		require.Nil(t, tx.Where("source_id = ?", source.ID).Find(&links).Error)
+		require.Len(t, links, 1)
+		assert.Equal(t, target.ID, strconv.FormatUint(links[0].TargetID, 10))
+		require.Nil(t, tx.Model(&RemoteLink{}).Where("tracker_id = ?", tr.ID).Count(&count).Error)
+		assert.Equal(t, 1, count)
+	})
+}
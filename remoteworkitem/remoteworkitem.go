@@ -24,6 +24,7 @@ const (
 	GithubID          = "url"
 	GithubCreator     = "user.login"
 	GithubAssignee    = "assignee.login"
+	GithubUpdatedAt   = "updated_at"
 
 	// The keys in the flattened response JSON of a typical Jira issue.
 
@@ -33,70 +34,54 @@ const (
 	JiraID       = "self"
 	JiraCreator  = "fields.creator.key"
 	JiraAssignee = "fields.assignee"
-
-	ProviderGithub = "github"
-	ProviderJira   = "jira"
+	JiraUpdated  = "fields.updated"
+
+	// The keys in the flattened response JSON of a typical GitLab issue.
+
+	GitlabTitle       = "title"
+	GitlabDescription = "description"
+	GitlabState       = "state"
+	GitlabID          = "web_url"
+	GitlabCreator     = "author.username"
+	GitlabAssignee    = "assignee.username"
+	GitlabUpdatedAt   = "updated_at"
+
+	// The keys in the flattened response JSON of a typical Bugzilla bug,
+	// self is added by the fetch as bugs do not carry their URL.
+
+	BugzillaTitle       = "summary"
+	BugzillaState       = "status"
+	BugzillaID          = "self"
+	BugzillaCreator     = "creator"
+	BugzillaAssignee    = "assigned_to"
+	BugzillaLastChanged = "last_change_time"
+
+	ProviderGithub   = "github"
+	ProviderJira     = "jira"
+	ProviderGitlab   = "gitlab"
+	ProviderBugzilla = "bugzilla"
 )
 
 // WorkItemKeyMaps relate remote attribute keys to internal representation
+// for trackers using the default field mappings
 var WorkItemKeyMaps = map[string]WorkItemMap{
-	ProviderGithub: WorkItemMap{
-		AttributeMapper{AttributeExpression(GithubTitle), StringConverter{}}:       workitem.SystemTitle,
-		AttributeMapper{AttributeExpression(GithubDescription), StringConverter{}}: workitem.SystemDescription,
-		AttributeMapper{AttributeExpression(GithubState), GithubStateConverter{}}:  workitem.SystemState,
-		AttributeMapper{AttributeExpression(GithubID), StringConverter{}}:          workitem.SystemRemoteItemID,
-		AttributeMapper{AttributeExpression(GithubCreator), StringConverter{}}:     workitem.SystemCreator,
-		AttributeMapper{AttributeExpression(GithubAssignee), StringConverter{}}:    workitem.SystemAssignee,
-	},
-	ProviderJira: WorkItemMap{
-		AttributeMapper{AttributeExpression(JiraTitle), StringConverter{}}:    workitem.SystemTitle,
-		AttributeMapper{AttributeExpression(JiraBody), StringConverter{}}:     workitem.SystemDescription,
-		AttributeMapper{AttributeExpression(JiraState), JiraStateConverter{}}: workitem.SystemState,
-		AttributeMapper{AttributeExpression(JiraID), StringConverter{}}:       workitem.SystemRemoteItemID,
-		AttributeMapper{AttributeExpression(JiraCreator), StringConverter{}}:  workitem.SystemCreator,
-		AttributeMapper{AttributeExpression(JiraAssignee), StringConverter{}}: workitem.SystemAssignee,
-	},
+	ProviderGithub:   DefaultFieldMappings[ProviderGithub].workItemMap(),
+	ProviderJira:     DefaultFieldMappings[ProviderJira].workItemMap(),
+	ProviderGitlab:   DefaultFieldMappings[ProviderGitlab].workItemMap(),
+	ProviderBugzilla: DefaultFieldMappings[ProviderBugzilla].workItemMap(),
 }
 
 type AttributeConverter interface {
 	Convert(interface{}, AttributeAccessor) (interface{}, error)
 }
 
-type StateConverter interface{}
-
 type StringConverter struct{}
 
-type GithubStateConverter struct{}
-
-type JiraStateConverter struct{}
-
 // Convert method map the external tracker item to ALM WorkItem
 func (sc StringConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
 	return value, nil
 }
 
-func (ghc GithubStateConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
-	if value.(string) == "closed" {
-		value = "closed"
-	} else if value.(string) == "open" {
-		value = "open"
-	}
-	return value, nil
-}
-
-func (jhc JiraStateConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
-	if value.(string) == "closed" {
-		value = "closed"
-	} else if value.(string) == "open" {
-		value = "open"
-	} else if value.(string) == "in progress" {
-		value = "in progress"
-	} else if value.(string) == "resolved" {
-		value = "resolved"
-	}
-	return value, nil
-}
-
 type AttributeMapper struct {
 	expression         AttributeExpression
 	attributeConverter AttributeConverter
@@ -116,8 +101,10 @@ type AttributeAccessor interface {
 
 // RemoteWorkItemImplRegistry contains all possible providers
 var RemoteWorkItemImplRegistry = map[string]func(TrackerItem) (AttributeAccessor, error){
-	ProviderGithub: NewGitHubRemoteWorkItem,
-	ProviderJira:   NewJiraRemoteWorkItem,
+	ProviderGithub:   NewGitHubRemoteWorkItem,
+	ProviderJira:     NewJiraRemoteWorkItem,
+	ProviderGitlab:   NewGitlabRemoteWorkItem,
+	ProviderBugzilla: NewBugzillaRemoteWorkItem,
 }
 
 // GitHubRemoteWorkItem knows how to implement a FieldAccessor on a GitHub Issue JSON struct
@@ -163,6 +150,48 @@ func (jira JiraRemoteWorkItem) Get(field AttributeExpression) interface{} {
 	return jira.issue[string(field)]
 }
 
+// GitlabRemoteWorkItem knows how to implement a FieldAccessor on a GitLab Issue JSON struct
+type GitlabRemoteWorkItem struct {
+	issue map[string]interface{}
+}
+
+// NewGitlabRemoteWorkItem creates a new Decoded AttributeAccessor for a GitLab Issue
+func NewGitlabRemoteWorkItem(item TrackerItem) (AttributeAccessor, error) {
+	var j map[string]interface{}
+	err := json.Unmarshal([]byte(item.Item), &j)
+	if err != nil {
+		return nil, err
+	}
+	j = Flatten(j)
+	return GitlabRemoteWorkItem{issue: j}, nil
+}
+
+// Get attribute from issue map
+func (gl GitlabRemoteWorkItem) Get(field AttributeExpression) interface{} {
+	return gl.issue[string(field)]
+}
+
+// BugzillaRemoteWorkItem knows how to implement a FieldAccessor on a Bugzilla Bug JSON struct
+type BugzillaRemoteWorkItem struct {
+	bug map[string]interface{}
+}
+
+// NewBugzillaRemoteWorkItem creates a new Decoded AttributeAccessor for a Bugzilla Bug
+func NewBugzillaRemoteWorkItem(item TrackerItem) (AttributeAccessor, error) {
+	var j map[string]interface{}
+	err := json.Unmarshal([]byte(item.Item), &j)
+	if err != nil {
+		return nil, err
+	}
+	j = Flatten(j)
+	return BugzillaRemoteWorkItem{bug: j}, nil
+}
+
+// Get attribute from bug map
+func (bz BugzillaRemoteWorkItem) Get(field AttributeExpression) interface{} {
+	return bz.bug[string(field)]
+}
+
 // Map maps the remote WorkItem to a local WorkItem
 func Map(item AttributeAccessor, mapping WorkItemMap) (app.WorkItem, error) {
 	workItem := app.WorkItem{Fields: make(map[string]interface{})}
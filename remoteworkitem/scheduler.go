@@ -2,32 +2,64 @@ package remoteworkitem
 
 import (
 	"log"
+	"strconv"
+	"sync"
+	"time"
 
+	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/models"
 	"github.com/jinzhu/gorm"
 	"github.com/robfig/cron"
 	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
 )
 
 // TrackerSchedule capture all configuration
 type trackerSchedule struct {
-	TrackerID   int
-	URL         string
-	TrackerType string
-	Query       string
-	Schedule    string
+	ID                uint64
+	TrackerID         int
+	URL               string
+	TrackerType       string
+	Query             string
+	Schedule          string
+	ProjectID         uuid.UUID
+	Push              bool
+	FieldMappings     FieldMappings
+	WorkItemType      string
+	WorkItemTypeRules TypeRules
+	LastUpdatedAt     *time.Time
 }
 
 // Scheduler represents scheduler
 type Scheduler struct {
 	db *gorm.DB
+	// ctx is cancelled on Stop, which ends the running fetches
+	ctx    context.Context
+	cancel context.CancelFunc
+	// running maps the running tracker queries to their run, mu guards it
+	mu      sync.Mutex
+	running map[uint64]uint64
+	// fetches holds the trackers fetched on behalf of webhook events and
+	// whether another fetch is pending for them, mu guards it
+	fetches map[uint64]bool
 }
 
 var cr *cron.Cron
 
-// NewScheduler creates a new Scheduler
+// NewScheduler creates a new Scheduler. The runs left running by a previous
+// process never finish, the running ones are only known in memory, so they
+// are marked failed.
 func NewScheduler(db *gorm.DB) *Scheduler {
 	s := Scheduler{db: db}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	err := db.Model(&TrackerQueryRun{}).Where("status = ?", RunRunning).Updates(map[string]interface{}{
+		"status":      RunFailed,
+		"error":       "interrupted by a restart",
+		"finished_at": time.Now(),
+	}).Error
+	if err != nil {
+		log.Printf("failing the interrupted tracker query runs failed: %v", err)
+	}
 	return &s
 }
 
@@ -35,6 +67,17 @@ func NewScheduler(db *gorm.DB) *Scheduler {
 // This should be called only from main
 func (s *Scheduler) Stop() {
 	cr.Stop()
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// context returns the context the fetches of the scheduler run in
+func (s *Scheduler) context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
 }
 
 func batchID() string {
@@ -48,28 +91,264 @@ func (s *Scheduler) ScheduleAllQueries() {
 
 	trackerQueries := fetchTrackerQueries(s.db)
 	for _, tq := range trackerQueries {
+		tq := tq
 		cr.AddFunc(tq.Schedule, func() {
-			tr := lookupProvider(tq)
-			for i := range tr.Fetch() {
-				models.Transactional(s.db, func(tx *gorm.DB) error {
-					// Save the remote items in a 'temporary' table.
-					err := upload(tx, tq.TrackerID, i)
-					if err != nil {
-						return err
-					}
-					// Convert the remote item into a local work item and persist in the DB.
-					_, err = convert(tx, tq.TrackerID, i, tq.TrackerType)
-					return err
-				})
+			if err := s.run(tq); err != nil {
+				log.Printf("running tracker query %d failed: %v", tq.ID, err)
 			}
 		})
 	}
 	cr.Start()
 }
 
+// FetchTracker runs the queries of the tracker with the given id once and
+// returns the error of the first failed one, the others run nonetheless
+func (s *Scheduler) FetchTracker(trackerID uint64) error {
+	var result error
+	for _, tq := range fetchTrackerQueries(s.db) {
+		if uint64(tq.TrackerID) != trackerID {
+			continue
+		}
+		if err := s.run(tq); err != nil {
+			log.Printf("running tracker query %d failed: %v", tq.ID, err)
+			if result == nil {
+				result = err
+			}
+		}
+	}
+	return result
+}
+
+// fetchTrackerInBackground runs the queries of the tracker with the given id
+// in the background. Requests arriving while they run are merged into a single
+// fetch after the running one, which may have passed their items already.
+func (s *Scheduler) fetchTrackerInBackground(trackerID uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.fetches[trackerID]; ok {
+		s.fetches[trackerID] = true
+		return
+	}
+	if s.fetches == nil {
+		s.fetches = map[uint64]bool{}
+	}
+	s.fetches[trackerID] = false
+	go func() {
+		for {
+			s.FetchTracker(trackerID)
+			s.mu.Lock()
+			if !s.fetches[trackerID] {
+				delete(s.fetches, trackerID)
+				s.mu.Unlock()
+				return
+			}
+			s.fetches[trackerID] = false
+			s.mu.Unlock()
+		}
+	}()
+}
+
+// run fetches and imports the remote items of a tracker query and records the
+// run. It returns the error the fetch stopped with; items failing to import are
+// recorded with the run and imported again on the next one. A query that is
+// running already is skipped.
+func (s *Scheduler) run(tq trackerSchedule) error {
+	r, started, err := s.startRun(tq, false)
+	if err != nil {
+		return err
+	}
+	if !started {
+		log.Printf("tracker query %d is running already as run %d", tq.ID, r.ID)
+		return nil
+	}
+	return s.execute(tq, r, 0)
+}
+
+// RunQuery starts a run of the tracker query with the given id in the
+// background and returns it, or the running run if the query is running already
+func (s *Scheduler) RunQuery(queryID string) (*app.TrackerQueryRun, error) {
+	return s.startInBackground(queryID, false, 0)
+}
+
+// PreviewQuery starts a dry run of the tracker query with the given id in the
+// background, which imports at most limit remote items and rolls back every
+// change, and returns it, or the running run if the query is running already.
+// The finished dry run tells what the import would create or change.
+func (s *Scheduler) PreviewQuery(queryID string, limit int) (*app.TrackerQueryRun, error) {
+	return s.startInBackground(queryID, true, limit)
+}
+
+// startInBackground starts a run of the tracker query with the given id in
+// the background and returns it, or the running run if the query is running already
+func (s *Scheduler) startInBackground(queryID string, dryRun bool, limit int) (*app.TrackerQueryRun, error) {
+	tq, err := s.loadSchedule(queryID)
+	if err != nil {
+		return nil, err
+	}
+	r, started, err := s.startRun(tq, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	result := convertRunToApp(*r)
+	if started {
+		go func() {
+			if err := s.execute(tq, r, limit); err != nil {
+				log.Printf("running tracker query %d failed: %v", tq.ID, err)
+			}
+		}()
+	}
+	return result, nil
+}
+
+// loadSchedule returns the schedule of the tracker query with the given id
+func (s *Scheduler) loadSchedule(queryID string) (trackerSchedule, error) {
+	id, err := strconv.ParseUint(queryID, 10, 64)
+	if err != nil || id == 0 {
+		// treating this as a not found error: the fact that we're using number internal is implementation detail
+		return trackerSchedule{}, NotFoundError{"tracker query", queryID}
+	}
+	for _, tq := range fetchTrackerQueries(s.db.Where("tracker_queries.id = ?", id)) {
+		return tq, nil
+	}
+	return trackerSchedule{}, NotFoundError{"tracker query", queryID}
+}
+
+// startRun records the start of a run of the tracker query. If the query is
+// running already no run is started and the running one is returned instead.
+func (s *Scheduler) startRun(tq trackerSchedule, dryRun bool) (*TrackerQueryRun, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if runID, ok := s.running[tq.ID]; ok {
+		r := TrackerQueryRun{}
+		if err := s.db.First(&r, runID).Error; err != nil {
+			return nil, false, InternalError{simpleError{err.Error()}}
+		}
+		return &r, false, nil
+	}
+	r := TrackerQueryRun{TrackerQueryID: tq.ID, DryRun: dryRun, Status: RunRunning, StartedAt: time.Now()}
+	if err := s.db.Create(&r).Error; err != nil {
+		return nil, false, InternalError{simpleError{err.Error()}}
+	}
+	if s.running == nil {
+		s.running = map[uint64]uint64{}
+	}
+	s.running[tq.ID] = r.ID
+	return &r, true, nil
+}
+
+// execute imports the remote items of the tracker query, all of them unless
+// limit is positive, and saves the outcome with the started run
+func (s *Scheduler) execute(tq trackerSchedule, r *TrackerQueryRun, limit int) error {
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, tq.ID)
+		s.mu.Unlock()
+	}()
+	err := s.importQuery(tq, r, limit)
+	r.finish(err, time.Now())
+	if err := s.db.Save(r).Error; err != nil {
+		log.Printf("saving run %d of tracker query %d failed: %v", r.ID, tq.ID, err)
+	}
+	return err
+}
+
+// importQuery fetches the remote items of the tracker query, all of them
+// unless limit is positive, imports them and records the outcome with the run.
+// It returns the error the fetch stopped with. Dry runs neither remember how
+// far the query got nor push local edits.
+func (s *Scheduler) importQuery(tq trackerSchedule, r *TrackerQueryRun, limit int) error {
+	// Only the items updated since the last run are fetched, all of them without a last run.
+	ts := tq
+	ts.LastUpdatedAt = lastUpdatedAt(s.db, tq.ID)
+	latest := ts.LastUpdatedAt
+	failed := false
+	tr := lookupProvider(ts)
+	if tr == nil {
+		return BadParameterError{parameter: "type", value: tq.TrackerType}
+	}
+	ctx, cancel := context.WithCancel(s.context())
+	defer cancel()
+	stopped := false
+	items, fetchErr := tr.Fetch(ctx)
+	for i := range items {
+		if stopped {
+			continue
+		}
+		if limit > 0 && r.Fetched >= limit {
+			// The remaining items are drained, the cancelled fetch stops sending them.
+			stopped = true
+			cancel()
+			continue
+		}
+		result, err := s.importItem(tq, i, ts.LastUpdatedAt != nil, r.DryRun)
+		r.record(i.ID, result, err)
+		if err != nil {
+			log.Printf("importing %s failed: %v", i.ID, err)
+			failed = true
+		}
+		// The items come oldest change first, the next run starts with the first failed one.
+		if !failed {
+			latest = laterOf(latest, remoteUpdatedAt(tq.TrackerType, i))
+		}
+	}
+	err := <-fetchErr
+	if stopped && err == context.Canceled {
+		err = nil
+	}
+	if r.DryRun {
+		return err
+	}
+	// The items fetched before a failure are imported, the next run resumes after them.
+	if err := saveLastUpdatedAt(s.db, tq.ID, ts.LastUpdatedAt, latest); err != nil {
+		log.Printf("saving the last update time of tracker query %d failed: %v", tq.ID, err)
+	}
+	if err != nil {
+		return err
+	}
+	if pusher := lookupPusher(tq); tq.Push && pusher != nil {
+		// Push the local edits of the imported items back to the tracker.
+		return models.Transactional(s.db, func(tx *gorm.DB) error {
+			return push(tx, tq.TrackerID, pusher)
+		})
+	}
+	return nil
+}
+
+// importItem saves a fetched remote item and converts it into a local work
+// item in a transaction, which is rolled back on a dry run. Unchanged items are
+// converted again on a full fetch only, which applies changed mappings and work
+// item types; their result is nil otherwise.
+func (s *Scheduler) importItem(tq trackerSchedule, i TrackerItemContent, incremental bool, dryRun bool) (*importResult, error) {
+	var result *importResult
+	step := func(tx *gorm.DB) error {
+		// Save the remote items in a 'temporary' table.
+		changed, err := uploadChanged(tx, tq.TrackerID, i)
+		if err != nil {
+			return err
+		}
+		if !changed && incremental {
+			return nil
+		}
+		// Convert the remote item into a local work item and persist in the DB.
+		result, err = importAs(tx, tq.ProjectID, tq.TrackerID, i, tq.TrackerType, tq.WorkItemType, tq.WorkItemTypeRules)
+		return err
+	}
+	if !dryRun {
+		err := models.Transactional(s.db, step)
+		return result, err
+	}
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	defer tx.Rollback()
+	err := step(tx)
+	return result, err
+}
+
 func fetchTrackerQueries(db *gorm.DB) []trackerSchedule {
 	tsList := []trackerSchedule{}
-	err := db.Table("tracker_queries").Select("trackers.id as tracker_id, trackers.url, trackers.type as tracker_type, tracker_queries.query, tracker_queries.schedule").Joins("left join trackers on tracker_queries.tracker_id = trackers.id").Where("trackers.deleted_at is NULL AND tracker_queries.deleted_at is NULL").Scan(&tsList).Error
+	err := db.Table("tracker_queries").Select("tracker_queries.id, trackers.id as tracker_id, trackers.url, trackers.type as tracker_type, tracker_queries.query, tracker_queries.schedule, tracker_queries.project_id, trackers.push, trackers.field_mappings, tracker_queries.work_item_type, tracker_queries.work_item_type_rules").Joins("left join trackers on tracker_queries.tracker_id = trackers.id").Where("trackers.deleted_at is NULL AND tracker_queries.deleted_at is NULL").Scan(&tsList).Error
 	if err != nil {
 		log.Printf("Fetch failed %v\n", err)
 	}
@@ -80,9 +359,24 @@ func fetchTrackerQueries(db *gorm.DB) []trackerSchedule {
 func lookupProvider(ts trackerSchedule) TrackerProvider {
 	switch ts.TrackerType {
 	case ProviderGithub:
-		return &GithubTracker{URL: ts.URL, Query: ts.Query}
+		return &GithubTracker{URL: ts.URL, Query: ts.Query, Since: ts.LastUpdatedAt}
+	case ProviderJira:
+		return &JiraTracker{URL: ts.URL, Query: ts.Query, Since: ts.LastUpdatedAt}
+	case ProviderGitlab:
+		return &GitlabTracker{URL: ts.URL, Query: ts.Query, Since: ts.LastUpdatedAt}
+	case ProviderBugzilla:
+		return &BugzillaTracker{URL: ts.URL, Query: ts.Query, Since: ts.LastUpdatedAt}
+	}
+	return nil
+}
+
+// lookupPusher provides the respective tracker to push local edits to based on the type
+func lookupPusher(ts trackerSchedule) TrackerPusher {
+	switch ts.TrackerType {
+	case ProviderGithub:
+		return &GithubTracker{URL: ts.URL, Query: ts.Query, Mappings: ts.FieldMappings.orDefault(ts.TrackerType)}
 	case ProviderJira:
-		return &JiraTracker{URL: ts.URL, Query: ts.Query}
+		return &JiraTracker{URL: ts.URL, Query: ts.Query, Mappings: ts.FieldMappings.orDefault(ts.TrackerType)}
 	}
 	return nil
 }
@@ -95,7 +389,11 @@ type TrackerItemContent struct {
 
 // TrackerProvider represents a remote tracker
 type TrackerProvider interface {
-	Fetch() chan TrackerItemContent // TODO: Change to an interface to enforce the contract
+	// Fetch sends the remote items to the returned item channel until all
+	// were sent, the fetch failed or the context is done. The item channel is
+	// closed then and the error channel yields the error the fetch stopped
+	// with, nil if it completed.
+	Fetch(ctx context.Context) (chan TrackerItemContent, chan error)
 }
 
 func init() {
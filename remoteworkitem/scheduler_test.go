@@ -2,13 +2,21 @@ package remoteworkitem
 
 import (
 	"fmt"
+	"net/http"
 	"os"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/project"
 	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/dnaeon/go-vcr/recorder"
 	"github.com/jinzhu/gorm"
 	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var db *gorm.DB
@@ -58,3 +66,77 @@ func TestLookupProvider(t *testing.T) {
 		t.Error("non-nil provider")
 	}
 }
+
+func TestPreviewQuery(t *testing.T) {
+	resource.Require(t, resource.Database)
+	r, err := recorder.New("../test/data/gitlab_fetch_test")
+	require.Nil(t, err)
+	defer r.Stop()
+	// without a configured token GitLab issues are fetched with the default client
+	transport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = r.Transport
+	defer func() { http.DefaultClient.Transport = transport }()
+
+	tr := Tracker{URL: "https://gitlab.com", Type: ProviderGitlab}
+	require.Nil(t, db.Create(&tr).Error)
+	defer db.Unscoped().Delete(&tr)
+	tq := TrackerQuery{Query: "projects/2146/issues?state=opened", Schedule: "0 0 0 * * *", TrackerID: tr.ID, ProjectID: project.SystemProject, WorkItemType: workitem.SystemBug}
+	require.Nil(t, db.Create(&tq).Error)
+	defer db.Unscoped().Delete(&tq)
+	defer db.Where("tracker_query_id = ?", tq.ID).Delete(&TrackerQueryRun{})
+
+	s := Scheduler{db: db}
+	started, err := s.PreviewQuery(strconv.FormatUint(tq.ID, 10), 50)
+	require.Nil(t, err)
+	assert.True(t, started.DryRun)
+	assert.Equal(t, RunRunning, started.Status)
+
+	// the preview runs in the background
+	runID, err := strconv.ParseUint(started.ID, 10, 64)
+	require.Nil(t, err)
+	var run TrackerQueryRun
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(50 * time.Millisecond) {
+		require.Nil(t, db.First(&run, runID).Error)
+		if run.Status != RunRunning || time.Now().After(deadline) {
+			break
+		}
+	}
+	require.Equal(t, RunSucceeded, run.Status, run.Error)
+	assert.Equal(t, 2, run.Fetched)
+	assert.Equal(t, 2, run.Created)
+	require.Len(t, run.Items, 2)
+	for _, item := range run.Items {
+		assert.Equal(t, RunItemCreate, item.Action)
+	}
+
+	// and changes nothing
+	var count int
+	require.Nil(t, db.Model(&TrackerItem{}).Where("tracker_id = ?", tr.ID).Count(&count).Error)
+	assert.Equal(t, 0, count)
+	remoteIDs := []string{
+		"https://gitlab.com/almighty-test/almighty-test-unit/issues/1",
+		"https://gitlab.com/almighty-test/almighty-test-unit/issues/2",
+	}
+	require.Nil(t, db.Model(&workitem.WorkItem{}).Where("Fields->>? IN (?)", workitem.SystemRemoteItemID, remoteIDs).Count(&count).Error)
+	assert.Equal(t, 0, count)
+	require.Nil(t, db.First(&tq, tq.ID).Error)
+	assert.Nil(t, tq.LastUpdatedAt)
+}
+
+func TestNewSchedulerFailsInterruptedRuns(t *testing.T) {
+	resource.Require(t, resource.Database)
+	tr := Tracker{URL: "https://gitlab.com", Type: ProviderGitlab}
+	require.Nil(t, db.Create(&tr).Error)
+	defer db.Unscoped().Delete(&tr)
+	tq := TrackerQuery{Query: "issues", Schedule: "0 0 0 * * *", TrackerID: tr.ID, ProjectID: project.SystemProject, WorkItemType: workitem.SystemBug}
+	require.Nil(t, db.Create(&tq).Error)
+	defer db.Unscoped().Delete(&tq)
+	run := TrackerQueryRun{TrackerQueryID: tq.ID, Status: RunRunning, StartedAt: time.Now()}
+	require.Nil(t, db.Create(&run).Error)
+	defer db.Delete(&run)
+
+	NewScheduler(db).cancel()
+	require.Nil(t, db.First(&run, run.ID).Error)
+	assert.Equal(t, RunFailed, run.Status)
+	assert.NotNil(t, run.FinishedAt)
+}
@@ -0,0 +1,290 @@
+package remoteworkitem
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// syncedFields are the work item fields synchronized in both directions
+var syncedFields = []string{
+	workitem.SystemTitle,
+	workitem.SystemDescription,
+	workitem.SystemState,
+	workitem.SystemAssignee,
+}
+
+// isSyncedField tells whether local edits of the given field are pushed back
+func isSyncedField(field string) bool {
+	for _, f := range syncedFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteUpdatedKeys name the attribute holding the time of the last change of a remote item
+var remoteUpdatedKeys = map[string]AttributeExpression{
+	ProviderGithub:   GithubUpdatedAt,
+	ProviderJira:     JiraUpdated,
+	ProviderGitlab:   GitlabUpdatedAt,
+	ProviderBugzilla: BugzillaLastChanged,
+}
+
+// remoteTimeLayouts are the formats the supported trackers use for timestamps
+var remoteTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05.000-0700"}
+
+// TrackerPusher writes local edits of imported work items back to a remote tracker
+type TrackerPusher interface {
+	// RemoteValue returns the value the given work item field takes on
+	// the remote tracker once pushed
+	RemoteValue(field string, value interface{}) interface{}
+	// Update sets the given work item fields on the remote item
+	Update(remoteItemID string, fields map[string]interface{}) error
+	// Comment adds a comment to the remote item and returns its remote ID
+	Comment(remoteItemID string, body string) (string, error)
+}
+
+// RemoteComment relates a local comment to its copy on a remote tracker item
+type RemoteComment struct {
+	CreatedAt       time.Time
+	CommentID       uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	TrackerID       uint64
+	RemoteItemID    string
+	RemoteCommentID string
+}
+
+// TableName implements gorm.tabler
+func (c RemoteComment) TableName() string {
+	return "remote_comments"
+}
+
+// Conflict records a field that was edited locally and remotely between two
+// synchronizations, and which side won
+type Conflict struct {
+	CreatedAt   time.Time
+	ID          uint64 `gorm:"primary_key"`
+	TrackerID   uint64
+	WorkItemID  uint64
+	Field       string
+	BaseValue   string
+	LocalValue  string
+	RemoteValue string
+	Policy      string
+	Resolution  string
+}
+
+// TableName implements gorm.tabler
+func (c Conflict) TableName() string {
+	return "sync_conflicts"
+}
+
+// syncValue returns the comparable form of a field value; a missing value
+// equals the empty string.
+func syncValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// parseRemoteTime returns the time of a remote timestamp or the zero time if
+// it can not be parsed.
+func parseRemoteTime(value interface{}) time.Time {
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}
+	}
+	for _, layout := range remoteTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// identityValue is used to compare values when no pusher is available
+func identityValue(field string, value interface{}) interface{} {
+	return value
+}
+
+// remoteUsers returns the remote users of the tracker by the IDs of the
+// identities they are mapped to
+func remoteUsers(tracker Tracker) map[string]string {
+	users := map[string]string{}
+	for name, identityID := range tracker.IdentityMappings {
+		users[identityID] = name
+	}
+	return users
+}
+
+// remoteAssignee returns the assignee as known on the remote tracker:
+// identities become the remote users mapped to them, names of remote users are
+// kept. Identities without a remote user can not be pushed, the second result
+// is false then.
+func remoteAssignee(users map[string]string, value interface{}) (interface{}, bool) {
+	s := syncValue(value)
+	if name, ok := users[s]; ok {
+		return name, true
+	}
+	if _, err := uuid.FromString(s); err == nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// mergeFields applies the remote field values to the local ones. A synced
+// field only counts as changed remotely if it differs from its base value, the
+// value last known on both sides, which keeps the echo of a pushed edit from
+// overwriting newer local edits. Fields edited on both sides are resolved with
+// the ownership policy and returned as conflicts. Without a base every remote
+// value is taken over.
+func mergeFields(ownership FieldOwnership, base, local, remote map[string]interface{}, localUpdated, remoteUpdated time.Time, remoteValue func(string, interface{}) interface{}) (map[string]interface{}, []Conflict) {
+	merged := map[string]interface{}{}
+	for key, value := range local {
+		merged[key] = value
+	}
+	var conflicts []Conflict
+	for key, value := range remote {
+		if base == nil || !isSyncedField(key) {
+			merged[key] = value
+			continue
+		}
+		b := syncValue(base[key])
+		r := syncValue(value)
+		l := syncValue(remoteValue(key, local[key]))
+		if r == b || r == l {
+			continue
+		}
+		if l == b {
+			merged[key] = value
+			continue
+		}
+		policy := ownership.Policy(key)
+		resolution := OwnerRemote
+		switch policy {
+		case OwnerLocal:
+			resolution = OwnerLocal
+		case OwnerLastWriter:
+			if !remoteUpdated.IsZero() && localUpdated.After(remoteUpdated) {
+				resolution = OwnerLocal
+			}
+		}
+		if resolution == OwnerRemote {
+			merged[key] = value
+		}
+		conflicts = append(conflicts, Conflict{
+			Field:       key,
+			BaseValue:   b,
+			LocalValue:  syncValue(local[key]),
+			RemoteValue: r,
+			Policy:      policy,
+			Resolution:  resolution,
+		})
+	}
+	return merged, conflicts
+}
+
+// push sends the local edits of the work items imported from the given
+// tracker, and their comments not yet known remotely, to the remote tracker.
+// Items that fail to push are logged and retried on the next run.
+func push(db *gorm.DB, tID int, pusher TrackerPusher) error {
+	var items []TrackerItem
+	err := db.Select("tracker_items.*").
+		Joins("JOIN work_items ON work_items.id = tracker_items.work_item_id").
+		Where("tracker_items.tracker_id = ? AND tracker_items.synced_fields IS NOT NULL AND work_items.deleted_at IS NULL AND (tracker_items.synced_at IS NULL OR work_items.updated_at > tracker_items.synced_at)", tID).
+		Find(&items).Error
+	if err != nil {
+		return InternalError{simpleError{err.Error()}}
+	}
+	var tracker Tracker
+	if err := db.First(&tracker, tID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return NotFoundError{"tracker", strconv.Itoa(tID)}
+		}
+		return InternalError{simpleError{err.Error()}}
+	}
+	users := remoteUsers(tracker)
+	wir := workitem.NewWorkItemRepository(db)
+	for _, ti := range items {
+		wi, err := wir.LoadFromDB(strconv.FormatUint(*ti.WorkItemID, 10))
+		if err != nil {
+			return err
+		}
+		remoteItemID := syncValue(wi.Fields[workitem.SystemRemoteItemID])
+		changes := map[string]interface{}{}
+		for _, field := range syncedFields {
+			value := wi.Fields[field]
+			if field == workitem.SystemAssignee {
+				var ok bool
+				if value, ok = remoteAssignee(users, value); !ok {
+					log.Printf("not pushing the assignee of work item %d to %s, %v is not mapped to a remote user", wi.ID, remoteItemID, wi.Fields[field])
+					continue
+				}
+			}
+			if syncValue(pusher.RemoteValue(field, value)) != syncValue(ti.SyncedFields[field]) {
+				changes[field] = value
+			}
+		}
+		if len(changes) > 0 {
+			if err := pusher.Update(remoteItemID, changes); err != nil {
+				log.Printf("pushing work item %d to %s failed: %v", wi.ID, remoteItemID, err)
+				continue
+			}
+			for field, value := range changes {
+				ti.SyncedFields[field] = pusher.RemoteValue(field, value)
+			}
+		}
+		now := time.Now()
+		ti.SyncedAt = &now
+		if err := db.Save(&ti).Error; err != nil {
+			return InternalError{simpleError{err.Error()}}
+		}
+	}
+	return pushComments(db, tID, pusher)
+}
+
+// pushComments adds the local comments of imported work items to the remote
+// items and remembers their remote IDs so that they are pushed only once.
+func pushComments(db *gorm.DB, tID int, pusher TrackerPusher) error {
+	var comments []comment.Comment
+	err := db.Select("comments.*").
+		Joins("JOIN tracker_items ON comments.parent_id = tracker_items.work_item_id::text").
+		Where("tracker_items.tracker_id = ? AND tracker_items.synced_fields IS NOT NULL AND comments.parent_type = ? AND comments.deleted_at IS NULL", tID, comment.ParentTypeWorkItem).
+		Where("NOT EXISTS (SELECT 1 FROM remote_comments r WHERE r.comment_id = comments.id)").
+		Order("comments.created_at").
+		Find(&comments).Error
+	if err != nil {
+		return InternalError{simpleError{err.Error()}}
+	}
+	wir := workitem.NewWorkItemRepository(db)
+	for _, c := range comments {
+		wi, err := wir.LoadFromDB(c.ParentID)
+		if err != nil {
+			return err
+		}
+		remoteItemID := syncValue(wi.Fields[workitem.SystemRemoteItemID])
+		remoteCommentID, err := pusher.Comment(remoteItemID, c.Body)
+		if err != nil {
+			log.Printf("pushing comment %s to %s failed: %v", c.ID, remoteItemID, err)
+			continue
+		}
+		rc := RemoteComment{
+			CommentID:       c.ID,
+			TrackerID:       uint64(tID),
+			RemoteItemID:    remoteItemID,
+			RemoteCommentID: remoteCommentID,
+		}
+		if err := db.Create(&rc).Error; err != nil {
+			return InternalError{simpleError{err.Error()}}
+		}
+	}
+	return nil
+}
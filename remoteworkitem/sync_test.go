@@ -0,0 +1,277 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/google/go-github/github"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeFields(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	base := map[string]interface{}{
+		workitem.SystemTitle:       "title",
+		workitem.SystemDescription: "body",
+		workitem.SystemState:       "open",
+		workitem.SystemAssignee:    "pranav",
+	}
+	local := map[string]interface{}{
+		workitem.SystemTitle:       "local title",
+		workitem.SystemDescription: "body",
+		workitem.SystemState:       "closed",
+		workitem.SystemAssignee:    "pranav",
+		workitem.SystemCreator:     "sbose78",
+	}
+	remote := map[string]interface{}{
+		workitem.SystemTitle:       "title",
+		workitem.SystemDescription: "remote body",
+		workitem.SystemState:       "resolved",
+		workitem.SystemAssignee:    "pranav",
+		workitem.SystemCreator:     "someone",
+	}
+	now := time.Now()
+
+	// remote wins by default, changes on one side only are taken over silently
+	merged, conflicts := mergeFields(nil, base, local, remote, now, now.Add(-time.Hour), identityValue)
+	assert.Equal(t, "local title", merged[workitem.SystemTitle])
+	assert.Equal(t, "remote body", merged[workitem.SystemDescription])
+	assert.Equal(t, "resolved", merged[workitem.SystemState])
+	assert.Equal(t, "someone", merged[workitem.SystemCreator])
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, Conflict{Field: workitem.SystemState, BaseValue: "open", LocalValue: "closed", RemoteValue: "resolved", Policy: OwnerRemote, Resolution: OwnerRemote}, conflicts[0])
+
+	merged, conflicts = mergeFields(FieldOwnership{workitem.SystemState: OwnerLocal}, base, local, remote, now, now, identityValue)
+	assert.Equal(t, "closed", merged[workitem.SystemState])
+	assert.Equal(t, OwnerLocal, conflicts[0].Resolution)
+
+	// the most recent edit wins with last-writer
+	ownership := FieldOwnership{workitem.SystemState: OwnerLastWriter}
+	merged, _ = mergeFields(ownership, base, local, remote, now, now.Add(-time.Hour), identityValue)
+	assert.Equal(t, "closed", merged[workitem.SystemState])
+	merged, _ = mergeFields(ownership, base, local, remote, now, now.Add(time.Hour), identityValue)
+	assert.Equal(t, "resolved", merged[workitem.SystemState])
+
+	// the echo of a pushed value does not count as a remote change
+	remote[workitem.SystemState] = "open"
+	merged, conflicts = mergeFields(nil, base, local, remote, now, now, identityValue)
+	assert.Equal(t, "closed", merged[workitem.SystemState])
+	assert.Empty(t, conflicts)
+
+	// without a base, the remote values are taken over
+	merged, conflicts = mergeFields(nil, nil, local, remote, now, now, identityValue)
+	assert.Equal(t, "title", merged[workitem.SystemTitle])
+	assert.Empty(t, conflicts)
+}
+
+func TestFieldOwnershipValidate(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.Nil(t, FieldOwnership{workitem.SystemTitle: OwnerLastWriter, workitem.SystemState: OwnerLocal}.validate())
+	assert.IsType(t, BadParameterError{}, FieldOwnership{workitem.SystemCreator: OwnerLocal}.validate())
+	assert.IsType(t, BadParameterError{}, FieldOwnership{workitem.SystemTitle: "mine"}.validate())
+	assert.Equal(t, OwnerRemote, FieldOwnership{}.Policy(workitem.SystemTitle))
+}
+
+func TestGithubPush(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	var edited map[string]interface{}
+	var commented map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/almighty/test/issues/1", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PATCH", r.Method)
+		json.NewDecoder(r.Body).Decode(&edited)
+		fmt.Fprint(w, `{"number":1}`)
+	})
+	mux.HandleFunc("/repos/almighty/test/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		json.NewDecoder(r.Body).Decode(&commented)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":42}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	g := GithubTracker{URL: server.URL}
+	issueURL := server.URL + "/repos/almighty/test/issues/1"
+	err := g.update(github.NewClient(nil), issueURL, map[string]interface{}{
+		workitem.SystemTitle: "new title",
+		workitem.SystemState: workitem.SystemStateInProgress,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"title": "new title", "state": "open"}, edited)
+
+	id, err := g.comment(github.NewClient(nil), issueURL, "looks good")
+	require.Nil(t, err)
+	assert.Equal(t, "42", id)
+	assert.Equal(t, "looks good", commented["body"])
+
+	_, err = g.comment(github.NewClient(nil), server.URL+"/repos/almighty/test/issues/2", "unknown issue")
+	assert.NotNil(t, err)
+}
+
+func TestJiraPush(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	var edited map[string]interface{}
+	var transitioned map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/2/issue/10001", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method)
+		json.NewDecoder(r.Body).Decode(&edited)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/rest/api/2/issue/10001/transitions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, `{"transitions":[{"id":"11","to":{"name":"In Progress"}},{"id":"21","to":{"name":"Closed"}}]}`)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&transitioned)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/rest/api/2/issue/10001/comment", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":"10100","body":"looks good"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	j := JiraTracker{URL: server.URL}
+	client, err := jira.NewClient(nil, server.URL)
+	require.Nil(t, err)
+	issueURL := server.URL + "/rest/api/2/issue/10001"
+	err = j.update(client, issueURL, map[string]interface{}{
+		workitem.SystemTitle:    "new summary",
+		workitem.SystemAssignee: "aslak",
+		workitem.SystemState:    "closed",
+	})
+	require.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"fields": map[string]interface{}{"summary": "new summary", "assignee": map[string]interface{}{"name": "aslak"}}}, edited)
+	assert.Equal(t, map[string]interface{}{"transition": map[string]interface{}{"id": "21"}}, transitioned)
+
+	err = j.update(client, issueURL, map[string]interface{}{workitem.SystemState: "resolved"})
+	assert.IsType(t, BadParameterError{}, err)
+
+	id, err := j.comment(client, issueURL, "looks good")
+	require.Nil(t, err)
+	assert.Equal(t, "10100", id)
+}
+
+// fakePusher records what is pushed to it
+type fakePusher struct {
+	updates  []map[string]interface{}
+	comments []string
+}
+
+func (p *fakePusher) RemoteValue(field string, value interface{}) interface{} {
+	return value
+}
+
+func (p *fakePusher) Update(remoteItemID string, fields map[string]interface{}) error {
+	p.updates = append(p.updates, fields)
+	return nil
+}
+
+func (p *fakePusher) Comment(remoteItemID string, body string) (string, error) {
+	p.comments = append(p.comments, body)
+	return strconv.Itoa(len(p.comments)), nil
+}
+
+func TestPushLocalChanges(t *testing.T) {
+	resource.Require(t, resource.Database)
+
+	tx := db.Begin()
+	defer tx.Rollback()
+	mapped := uuid.NewV4()
+	tr := Tracker{URL: "https://api.github.com/", Type: ProviderGithub, Push: true, IdentityMappings: NameMappings{"aslak": mapped.String()}}
+	require.Nil(t, tx.Create(&tr).Error)
+	tID := int(tr.ID)
+
+	remoteURL := "https://api.github.com/repos/almighty/test/issues/" + uuid.NewV4().String()
+	content := func(title, state string) TrackerItemContent {
+		return TrackerItemContent{
+			ID:      remoteURL,
+			Content: []byte(`{"title":"` + title + `","url":"` + remoteURL + `","state":"` + state + `","body":"body","user.login":"sbose78","assignee.login":"pranav"}`),
+		}
+	}
+	importItem := func(item TrackerItemContent) string {
+		require.Nil(t, upload(tx, tID, item))
+		wi, err := convert(tx, project.SystemProject, tID, item, ProviderGithub)
+		require.Nil(t, err)
+		return wi.ID
+	}
+	wiID := importItem(content("title", "open"))
+
+	// nothing was edited locally, so nothing is pushed
+	p := &fakePusher{}
+	require.Nil(t, push(tx, tID, p))
+	assert.Empty(t, p.updates)
+
+	wir := workitem.NewWorkItemRepository(tx)
+	wi, err := wir.Load(context.Background(), wiID)
+	require.Nil(t, err)
+	wi.Fields[workitem.SystemTitle] = "local title"
+	_, err = wir.Save(context.Background(), *wi)
+	require.Nil(t, err)
+	c := comment.Comment{ParentType: comment.ParentTypeWorkItem, ParentID: wiID, Body: "local comment", CreatedBy: uuid.NewV4()}
+	require.Nil(t, comment.NewCommentRepository(tx).Create(context.Background(), &c))
+
+	require.Nil(t, push(tx, tID, p))
+	require.Len(t, p.updates, 1)
+	assert.Equal(t, map[string]interface{}{workitem.SystemTitle: "local title"}, p.updates[0])
+	assert.Equal(t, []string{"local comment"}, p.comments)
+	// pushed edits and comments are not pushed again
+	require.Nil(t, push(tx, tID, p))
+	assert.Len(t, p.updates, 1)
+	assert.Len(t, p.comments, 1)
+
+	// the echo of the pushed title is not a remote change, the remote state change is applied
+	importItem(content("local title", "closed"))
+	wi, err = wir.Load(context.Background(), wiID)
+	require.Nil(t, err)
+	assert.Equal(t, "local title", wi.Fields[workitem.SystemTitle])
+	assert.Equal(t, "closed", wi.Fields[workitem.SystemState])
+
+	// edits on both sides are a conflict, won by the remote side by default
+	wi.Fields[workitem.SystemTitle] = "edited again"
+	_, err = wir.Save(context.Background(), *wi)
+	require.Nil(t, err)
+	importItem(content("remote title", "closed"))
+	wi, err = wir.Load(context.Background(), wiID)
+	require.Nil(t, err)
+	assert.Equal(t, "remote title", wi.Fields[workitem.SystemTitle])
+	conflicts, err := NewTrackerRepository(tx).ListConflicts(context.Background(), strconv.FormatUint(tr.ID, 10))
+	require.Nil(t, err)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "edited again", *conflicts[0].LocalValue)
+	assert.Equal(t, OwnerRemote, conflicts[0].Resolution)
+
+	// assignees are pushed as the remote users mapped to them, others not at all
+	for assignee, pushed := range map[string]interface{}{mapped.String(): "aslak", uuid.NewV4().String(): nil} {
+		p = &fakePusher{}
+		wi, err = wir.Load(context.Background(), wiID)
+		require.Nil(t, err)
+		wi.Fields[workitem.SystemAssignee] = assignee
+		_, err = wir.Save(context.Background(), *wi)
+		require.Nil(t, err)
+		require.Nil(t, push(tx, tID, p))
+		if pushed == nil {
+			assert.Empty(t, p.updates)
+		} else {
+			require.Len(t, p.updates, 1)
+			assert.Equal(t, map[string]interface{}{workitem.SystemAssignee: pushed}, p.updates[0])
+		}
+	}
+}
@@ -1,6 +1,12 @@
 package remoteworkitem
 
-import "github.com/almighty/almighty-core/gormsupport"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"github.com/almighty/almighty-core/gormsupport"
+)
 
 // Tracker represents tracker configuration
 type Tracker struct {
@@ -10,4 +16,102 @@ type Tracker struct {
 	URL string
 	// Type of the tracker (jira, github, bugzilla, trello etc.)
 	Type string
+	// Push tells whether local edits of imported work items are pushed back to the tracker
+	Push bool
+	// FieldOwnership decides per work item field who wins conflicting edits
+	FieldOwnership FieldOwnership `sql:"type:jsonb"`
+	// FieldMappings map remote attributes to work item fields, the
+	// DefaultFieldMappings of the type are used if there are none
+	FieldMappings FieldMappings `sql:"type:jsonb"`
+	// WebhookSecret authenticates the webhook events of the tracker, they are
+	// refused if it is empty
+	WebhookSecret string
+	// IdentityMappings map the names of remote users to the IDs of the
+	// identities their comments are imported as
+	IdentityMappings NameMappings `sql:"type:jsonb"`
+	// LinkTypes map the names of remote issue link types to the IDs of the
+	// work item link types they are imported as, other links are dropped
+	LinkTypes NameMappings `sql:"type:jsonb"`
+}
+
+// The ownership policies of a synchronized field
+const (
+	// OwnerRemote lets the remote value win a conflict
+	OwnerRemote = "remote"
+	// OwnerLocal lets the local value win a conflict
+	OwnerLocal = "local"
+	// OwnerLastWriter lets the most recent edit win a conflict
+	OwnerLastWriter = "last-writer"
+)
+
+// FieldOwnership maps a synchronized work item field to its ownership policy
+type FieldOwnership map[string]string
+
+// Value implements the driver.Valuer interface
+func (o FieldOwnership) Value() (driver.Value, error) {
+	if o == nil {
+		return nil, nil
+	}
+	return json.Marshal(o)
+}
+
+// Scan implements the sql.Scanner interface
+func (o *FieldOwnership) Scan(src interface{}) error {
+	if src == nil {
+		*o = nil
+		return nil
+	}
+	s, ok := src.([]byte)
+	if !ok {
+		return errors.New("Scan source was not string")
+	}
+	return json.Unmarshal(s, o)
+}
+
+// Policy returns the ownership policy of the given field, letting the remote
+// tracker win unless configured otherwise.
+func (o FieldOwnership) Policy(field string) string {
+	if policy, ok := o[field]; ok {
+		return policy
+	}
+	return OwnerRemote
+}
+
+// validate checks that only synchronized fields are given known policies
+func (o FieldOwnership) validate() error {
+	for field, policy := range o {
+		if !isSyncedField(field) {
+			return BadParameterError{parameter: "fieldOwnership", value: field}
+		}
+		switch policy {
+		case OwnerRemote, OwnerLocal, OwnerLastWriter:
+		default:
+			return BadParameterError{parameter: "fieldOwnership." + field, value: policy}
+		}
+	}
+	return nil
+}
+
+// NameMappings map remote names to the IDs of local entities
+type NameMappings map[string]string
+
+// Value implements the driver.Valuer interface
+func (m NameMappings) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
+
+// Scan implements the sql.Scanner interface
+func (m *NameMappings) Scan(src interface{}) error {
+	if src == nil {
+		*m = nil
+		return nil
+	}
+	s, ok := src.([]byte)
+	if !ok {
+		return errors.New("Scan source was not string")
+	}
+	return json.Unmarshal(s, m)
 }
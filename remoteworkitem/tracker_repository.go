@@ -6,10 +6,13 @@ import (
 
 	"fmt"
 
+	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
 	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
 	"golang.org/x/net/context"
 	govalidator "gopkg.in/asaskevich/govalidator.v4"
 )
@@ -46,12 +49,7 @@ func (r *GormTrackerRepository) Create(ctx context.Context, url string, typeID s
 		return nil, InternalError{simpleError{err.Error()}}
 	}
 	log.Printf("created tracker %v\n", t)
-	t2 := app.Tracker{
-		ID:   strconv.FormatUint(t.ID, 10),
-		URL:  url,
-		Type: typeID}
-
-	return &t2, nil
+	return convertTrackerToApp(t), nil
 }
 
 // Load returns the tracker configuration for the given id
@@ -73,12 +71,7 @@ func (r *GormTrackerRepository) Load(ctx context.Context, ID string) (*app.Track
 	if tx.Error != nil {
 		return nil, InternalError{simpleError{fmt.Sprintf("error while loading: %s", tx.Error.Error())}}
 	}
-	t := app.Tracker{
-		ID:   strconv.FormatUint(res.ID, 10),
-		URL:  res.URL,
-		Type: res.Type}
-
-	return &t, nil
+	return convertTrackerToApp(res), nil
 }
 
 // List returns tracker selected by the given criteria.Expression, starting with start (zero-based) and returning at most limit items
@@ -104,11 +97,7 @@ func (r *GormTrackerRepository) List(ctx context.Context, criteria criteria.Expr
 	result := make([]*app.Tracker, len(rows))
 
 	for i, tracker := range rows {
-		t := app.Tracker{
-			ID:   strconv.FormatUint(tracker.ID, 10),
-			URL:  tracker.URL,
-			Type: tracker.Type}
-		result[i] = &t
+		result[i] = convertTrackerToApp(tracker)
 	}
 	return result, nil
 }
@@ -135,21 +124,66 @@ func (r *GormTrackerRepository) Save(ctx context.Context, t app.Tracker) (*app.T
 	}
 
 	newT := Tracker{
-		ID:   id,
-		URL:  t.URL,
-		Type: t.Type}
+		ID:               id,
+		URL:              t.URL,
+		Type:             t.Type,
+		Push:             res.Push,
+		FieldOwnership:   res.FieldOwnership,
+		FieldMappings:    res.FieldMappings,
+		WebhookSecret:    res.WebhookSecret,
+		IdentityMappings: res.IdentityMappings,
+		LinkTypes:        res.LinkTypes}
+	// keep the synchronization settings unless new ones are given
+	if t.Push != nil {
+		newT.Push = *t.Push
+	}
+	// only some tracker types take edits
+	if newT.Push && lookupPusher(trackerSchedule{TrackerType: newT.Type}) == nil {
+		return nil, BadParameterError{parameter: "push", value: newT.Push}
+	}
+	if t.WebhookSecret != nil {
+		newT.WebhookSecret = *t.WebhookSecret
+	}
+	if t.IdentityMappings != nil {
+		newT.IdentityMappings = NameMappings(t.IdentityMappings)
+		if err := r.validateIdentityMappings(newT.IdentityMappings); err != nil {
+			return nil, err
+		}
+	}
+	if t.LinkTypes != nil {
+		newT.LinkTypes = NameMappings(t.LinkTypes)
+		if err := r.validateLinkTypes(newT.LinkTypes); err != nil {
+			return nil, err
+		}
+	}
+	if t.FieldOwnership != nil {
+		newT.FieldOwnership = FieldOwnership(t.FieldOwnership)
+		if err := newT.FieldOwnership.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if t.FieldMappings != nil {
+		// an empty list reverts to the default mappings of the tracker type
+		newT.FieldMappings = nil
+		for _, m := range t.FieldMappings {
+			newT.FieldMappings = append(newT.FieldMappings, FieldMapping{
+				Path:      m.Path,
+				Converter: m.Converter,
+				Field:     m.Field,
+				Values:    m.Values,
+			})
+		}
+		if err := r.validateFieldMappings(id, newT.FieldMappings); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := tx.Save(&newT).Error; err != nil {
 		log.Print(err.Error())
 		return nil, InternalError{simpleError{err.Error()}}
 	}
 	log.Printf("updated tracker to %v\n", newT)
-	t2 := app.Tracker{
-		ID:   strconv.FormatUint(id, 10),
-		URL:  t.URL,
-		Type: t.Type}
-
-	return &t2, nil
+	return convertTrackerToApp(newT), nil
 }
 
 // Delete deletes the tracker with the given id
@@ -171,3 +205,112 @@ func (r *GormTrackerRepository) Delete(ctx context.Context, ID string) error {
 	}
 	return nil
 }
+
+// ListConflicts returns the conflicting edits detected while synchronizing
+// with the tracker of the given id, most recent first
+// returns NotFoundError or InternalError
+func (r *GormTrackerRepository) ListConflicts(ctx context.Context, ID string) ([]*app.TrackerConflict, error) {
+	id, err := strconv.ParseUint(ID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, NotFoundError{"tracker", ID}
+	}
+	tx := r.db.First(&Tracker{}, id)
+	if tx.RecordNotFound() {
+		return nil, NotFoundError{"tracker", ID}
+	}
+	if tx.Error != nil {
+		return nil, InternalError{simpleError{tx.Error.Error()}}
+	}
+	var rows []Conflict
+	if err := r.db.Where("tracker_id = ?", id).Order("created_at desc, id desc").Find(&rows).Error; err != nil {
+		return nil, InternalError{simpleError{err.Error()}}
+	}
+	result := make([]*app.TrackerConflict, len(rows))
+	for i, c := range rows {
+		c := c
+		result[i] = &app.TrackerConflict{
+			ID:          strconv.FormatUint(c.ID, 10),
+			WorkItemID:  strconv.FormatUint(c.WorkItemID, 10),
+			Field:       c.Field,
+			BaseValue:   &c.BaseValue,
+			LocalValue:  &c.LocalValue,
+			RemoteValue: &c.RemoteValue,
+			Policy:      c.Policy,
+			Resolution:  c.Resolution,
+			CreatedAt:   c.CreatedAt,
+		}
+	}
+	return result, nil
+}
+
+// validateFieldMappings checks the given mappings against the work item types
+// the queries of the tracker import remote items as
+func (r *GormTrackerRepository) validateFieldMappings(trackerID uint64, mappings FieldMappings) error {
+	if mappings == nil {
+		return nil
+	}
+	var queries []TrackerQuery
+	if err := r.db.Where("tracker_id = ?", trackerID).Find(&queries).Error; err != nil {
+		return InternalError{simpleError{err.Error()}}
+	}
+	if len(queries) == 0 {
+		queries = []TrackerQuery{{WorkItemType: workitem.SystemBug}}
+	}
+	for _, tq := range queries {
+		if err := validateWorkItemTypes(r.db, tq.WorkItemType, tq.WorkItemTypeRules, mappings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateIdentityMappings checks that remote users are mapped to existing identities
+func (r *GormTrackerRepository) validateIdentityMappings(mappings NameMappings) error {
+	for name, id := range mappings {
+		identityID, err := uuid.FromString(id)
+		if err != nil || r.db.First(&account.Identity{}, "id = ?", identityID).RecordNotFound() {
+			return BadParameterError{parameter: "identityMappings." + name, value: id}
+		}
+	}
+	return nil
+}
+
+// validateLinkTypes checks that remote link types are mapped to existing work item link types
+func (r *GormTrackerRepository) validateLinkTypes(linkTypes NameMappings) error {
+	ltr := link.NewWorkItemLinkTypeRepository(r.db)
+	for name, id := range linkTypes {
+		linkTypeID, err := uuid.FromString(id)
+		if err == nil {
+			_, err = ltr.LoadTypeFromDBByID(linkTypeID)
+		}
+		if err != nil {
+			return BadParameterError{parameter: "linkTypes." + name, value: id}
+		}
+	}
+	return nil
+}
+
+// convertTrackerToApp converts a tracker to its REST representation
+func convertTrackerToApp(t Tracker) *app.Tracker {
+	push := t.Push
+	webhook := t.WebhookSecret != ""
+	result := app.Tracker{
+		ID:               strconv.FormatUint(t.ID, 10),
+		URL:              t.URL,
+		Type:             t.Type,
+		Push:             &push,
+		FieldOwnership:   map[string]string(t.FieldOwnership),
+		Webhook:          &webhook,
+		IdentityMappings: map[string]string(t.IdentityMappings),
+		LinkTypes:        map[string]string(t.LinkTypes),
+	}
+	for _, m := range t.FieldMappings {
+		result.FieldMappings = append(result.FieldMappings, &app.TrackerFieldMapping{
+			Path:      m.Path,
+			Converter: m.Converter,
+			Field:     m.Field,
+			Values:    m.Values,
+		})
+	}
+	return &result
+}
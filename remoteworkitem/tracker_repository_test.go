@@ -10,6 +10,7 @@ import (
 	"github.com/almighty/almighty-core/application"
 	"github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
 	"github.com/jinzhu/gorm"
 	"github.com/stretchr/testify/assert"
 )
@@ -126,3 +127,33 @@ func doWithTransaction(t *testing.T, todo func(db *gorm.DB)) {
 	defer tx.Rollback()
 	todo(tx)
 }
+
+func TestTrackerSaveFieldMappings(t *testing.T) {
+	doWithTrackerRepository(t, func(trackerRepo application.TrackerRepository) {
+		tracker, err := trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira)
+		assert.Nil(t, err)
+		assert.Empty(t, tracker.FieldMappings)
+
+		tracker.FieldMappings = []*app.TrackerFieldMapping{
+			{Path: JiraID, Converter: ConverterString, Field: workitem.SystemRemoteItemID},
+			{Path: JiraState, Converter: ConverterTranslate, Field: workitem.SystemState, Values: map[string]string{"Verified": workitem.SystemStateClosed}},
+		}
+		tracker, err = trackerRepo.Save(context.Background(), *tracker)
+		assert.Nil(t, err)
+		tracker, err = trackerRepo.Load(context.Background(), tracker.ID)
+		assert.Nil(t, err)
+		assert.Len(t, tracker.FieldMappings, 2)
+		assert.Equal(t, workitem.SystemStateClosed, tracker.FieldMappings[1].Values["Verified"])
+
+		// the translated values must be valid for the target field
+		tracker.FieldMappings[1].Values["Verified"] = "verified"
+		_, err = trackerRepo.Save(context.Background(), *tracker)
+		assert.IsType(t, BadParameterError{}, err)
+
+		// an empty list reverts to the defaults
+		tracker.FieldMappings = []*app.TrackerFieldMapping{}
+		tracker, err = trackerRepo.Save(context.Background(), *tracker)
+		assert.Nil(t, err)
+		assert.Empty(t, tracker.FieldMappings)
+	})
+}
@@ -1,6 +1,11 @@
 package remoteworkitem
 
-import "github.com/almighty/almighty-core/gormsupport"
+import (
+	"time"
+
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
+)
 
 // TrackerItem represents a remote tracker item
 // Staging area before pushing to work item
@@ -13,4 +18,11 @@ type TrackerItem struct {
 	Item string
 	// FK to tracker
 	TrackerID uint64 `gorm:"ForeignKey:Tracker"`
+	// WorkItemID is the work item the remote item was last imported into
+	WorkItemID *uint64
+	// SyncedFields holds the values of the synchronized fields last known to
+	// be the same locally and remotely
+	SyncedFields workitem.Fields `sql:"type:jsonb"`
+	// SyncedAt is the last time local edits were pushed to the remote item
+	SyncedAt *time.Time
 }
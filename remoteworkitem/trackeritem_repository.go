@@ -1,7 +1,8 @@
 package remoteworkitem
 
 import (
-	"fmt"
+	"sort"
+	"strconv"
 
 	"golang.org/x/net/context"
 
@@ -9,10 +10,18 @@ import (
 	"github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
 )
 
 // upload imports the items into database
 func upload(db *gorm.DB, tID int, item TrackerItemContent) error {
+	_, err := uploadChanged(db, tID, item)
+	return err
+}
+
+// uploadChanged imports the item into the database unless it is stored
+// unchanged already, and tells whether it was written
+func uploadChanged(db *gorm.DB, tID int, item TrackerItemContent) (bool, error) {
 	remoteID := item.ID
 	content := string(item.Content)
 
@@ -22,14 +31,44 @@ func upload(db *gorm.DB, tID int, item TrackerItemContent) error {
 			Item:         content,
 			RemoteItemID: remoteID,
 			TrackerID:    uint64(tID)}
-		return db.Create(&ti).Error
+		return true, db.Create(&ti).Error
+	}
+	if ti.Item == content {
+		return false, nil
 	}
 	ti.Item = content
-	return db.Save(&ti).Error
+	return true, db.Save(&ti).Error
+}
+
+// Map a remote work item into an ALM work item of the given project and persist it into the database.
+// New work items are bugs, use convertAs to import them as another type.
+func convert(db *gorm.DB, projectID uuid.UUID, tID int, item TrackerItemContent, provider string) (*app.WorkItem, error) {
+	return convertAs(db, projectID, tID, item, provider, workitem.SystemBug, nil)
+}
+
+// convertAs maps a remote work item into an ALM work item of the given project and persists it into the database.
+// New work items get the type of the first matching rule or the given type if none matches, reimported items keep
+// their type and are merged with their local edits as decided by the field ownership of the tracker. The comments
+// and issue links of the remote item are imported along.
+func convertAs(db *gorm.DB, projectID uuid.UUID, tID int, item TrackerItemContent, provider string, witName string, rules TypeRules) (*app.WorkItem, error) {
+	result, err := importAs(db, projectID, tID, item, provider, witName, rules)
+	if err != nil {
+		return nil, err
+	}
+	return result.WorkItem, nil
+}
+
+// importResult tells what importing a remote item did to its work item
+type importResult struct {
+	WorkItem *app.WorkItem
+	Created  bool
+	// Fields are the work item fields set by the import
+	Fields []string
 }
 
-// Map a remote work item into an ALM work item and persist it into the database.
-func convert(db *gorm.DB, tID int, item TrackerItemContent, provider string) (*app.WorkItem, error) {
+// importAs works like convertAs and tells whether the work item was created
+// and which of its fields were set
+func importAs(db *gorm.DB, projectID uuid.UUID, tID int, item TrackerItemContent, provider string, witName string, rules TypeRules) (*importResult, error) {
 	remoteID := item.ID
 	content := string(item.Content)
 
@@ -45,42 +84,123 @@ func convert(db *gorm.DB, tID int, item TrackerItemContent, provider string) (*a
 	if err != nil {
 		return nil, InternalError{simpleError{message: " Error parsing the tracker data "}}
 	}
-	workItem, err := Map(remoteTrackerItem, WorkItemKeyMaps[provider])
+	var tracker Tracker
+	if err := db.First(&tracker, tID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, NotFoundError{"tracker", strconv.Itoa(tID)}
+		}
+		return nil, InternalError{simpleError{err.Error()}}
+	}
+	mappings := tracker.FieldMappings.orDefault(provider)
+	workItem, err := Map(remoteTrackerItem, mappings.workItemMap())
 	if err != nil {
 		return nil, ConversionError{simpleError{message: " Error mapping to local work item "}}
 	}
 
+	// The uploaded tracker item remembers the field values last synchronized
+	var stored TrackerItem
+	tracked := !db.Where("remote_item_id = ? AND tracker_id = ?", remoteID, tID).Find(&stored).RecordNotFound()
+	remoteValue := identityValue
+	if pusher := lookupPusher(trackerSchedule{URL: tracker.URL, TrackerType: provider, FieldMappings: mappings}); pusher != nil {
+		remoteValue = pusher.RemoteValue
+	}
+	// local assignees compare as the remote users they are pushed as
+	users := remoteUsers(tracker)
+	compare := func(field string, value interface{}) interface{} {
+		if assignee, ok := remoteAssignee(users, value); ok && field == workitem.SystemAssignee {
+			value = assignee
+		}
+		return remoteValue(field, value)
+	}
+
 	// Get the remote item identifier ( which is currently the url ) to check if the work item exists in the database.
 	workItemRemoteID := workItem.Fields[workitem.SystemRemoteItemID]
 
-	sqlExpression := criteria.Equals(criteria.Field(workitem.SystemRemoteItemID), criteria.Literal(workItemRemoteID))
+	sqlExpression := criteria.And(
+		criteria.Equals(criteria.Field(workitem.SystemRemoteItemID), criteria.Literal(workItemRemoteID)),
+		criteria.Equals(criteria.Field("Project"), criteria.Literal(projectID.String())),
+	)
 
 	var newWorkItem *app.WorkItem
+	result := importResult{}
 
 	// Querying the database
 	existingWorkItems, _, err := wir.List(context.Background(), sqlExpression, nil, nil)
 
 	if len(existingWorkItems) != 0 {
-		fmt.Println("Workitem exists, will be updated")
 		existingWorkItem := existingWorkItems[0]
-		for key, value := range workItem.Fields {
-			existingWorkItem.Fields[key] = value
+		local, err := wir.LoadFromDB(existingWorkItem.ID)
+		if err != nil {
+			return nil, err
 		}
+		var base map[string]interface{}
+		if tracked && stored.SyncedFields != nil {
+			base = stored.SyncedFields
+		}
+		remoteUpdated := parseRemoteTime(remoteTrackerItem.Get(remoteUpdatedKeys[provider]))
+		merged, conflicts := mergeFields(tracker.FieldOwnership, base, existingWorkItem.Fields, workItem.Fields, local.UpdatedAt, remoteUpdated, compare)
+		result.Fields = changedFields(existingWorkItem.Fields, merged)
+		existingWorkItem.Fields = merged
 		newWorkItem, err = wir.Save(context.Background(), *existingWorkItem)
 		if err != nil {
-			fmt.Println("Error updating work item : ", err)
+			return nil, err
+		}
+		for _, c := range conflicts {
+			c.TrackerID = uint64(tID)
+			c.WorkItemID = local.ID
+			if err := db.Create(&c).Error; err != nil {
+				return nil, InternalError{simpleError{err.Error()}}
+			}
 		}
 	} else {
-		fmt.Println("Work item not found , will now create new work item")
 		c := workItem.Fields[workitem.SystemCreator]
 		var creator string
 		if c != nil {
 			creator = c.(string)
 		}
-		newWorkItem, err = wir.Create(context.Background(), workitem.SystemBug, workItem.Fields, creator)
+		newWorkItem, err = wir.Create(context.Background(), projectID, rules.typeOf(remoteTrackerItem, witName), workItem.Fields, creator)
 		if err != nil {
-			fmt.Println("Error creating work item : ", err)
+			return nil, err
+		}
+		result.Created = true
+		result.Fields = changedFields(nil, workItem.Fields)
+	}
+
+	if tracked {
+		// the remote values are now known on both sides
+		id, _ := strconv.ParseUint(newWorkItem.ID, 10, 64)
+		synced := workitem.Fields{}
+		for _, field := range syncedFields {
+			synced[field] = workItem.Fields[field]
+		}
+		stored.WorkItemID = &id
+		stored.SyncedFields = synced
+		if err := db.Save(&stored).Error; err != nil {
+			return nil, InternalError{simpleError{err.Error()}}
+		}
+	}
+	// comments and links are kept per tracker
+	if err := importCommentsAndLinks(db, &tracker, newWorkItem, item, provider); err != nil {
+		return nil, err
+	}
+	result.WorkItem = newWorkItem
+	return &result, nil
+}
+
+// changedFields returns the sorted names of the fields whose values differ
+// between before and after, a missing value equals the empty string
+func changedFields(before, after map[string]interface{}) []string {
+	var fields []string
+	for key, value := range after {
+		if syncValue(value) != syncValue(before[key]) {
+			fields = append(fields, key)
+		}
+	}
+	for key, value := range before {
+		if _, ok := after[key]; !ok && syncValue(value) != "" {
+			fields = append(fields, key)
 		}
 	}
-	return newWorkItem, err
+	sort.Strings(fields)
+	return fields
 }
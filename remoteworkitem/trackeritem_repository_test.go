@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/almighty/almighty-core/models"
+	"github.com/almighty/almighty-core/project"
 	"github.com/almighty/almighty-core/resource"
 	"github.com/almighty/almighty-core/test"
 	"github.com/almighty/almighty-core/workitem"
@@ -35,7 +36,7 @@ func TestConvertNewWorkItem(t *testing.T) {
 			ID:      "http://github.com/sbose/api/testonly/1",
 		}
 
-		workItem, err := convert(db, int(tq.ID), remoteItemData, ProviderGithub)
+		workItem, err := convert(db, project.SystemProject, int(tr.ID), remoteItemData, ProviderGithub)
 
 		assert.Nil(t, err)
 		assert.Equal(t, "linking", workItem.Fields[workitem.SystemTitle])
@@ -72,7 +73,7 @@ func TestConvertExistingWorkItem(t *testing.T) {
 			ID:      "http://github.com/sbose/api/testonly/1",
 		}
 
-		workItem, err := convert(tx, int(tq.ID), remoteItemData, ProviderGithub)
+		workItem, err := convert(tx, project.SystemProject, int(tr.ID), remoteItemData, ProviderGithub)
 
 		assert.Nil(t, err)
 		assert.Equal(t, "linking", workItem.Fields[workitem.SystemTitle])
@@ -89,7 +90,7 @@ func TestConvertExistingWorkItem(t *testing.T) {
 			Content: []byte(`{"title":"linking-updated","url":"http://github.com/api/testonly/1","state":"closed","body":"body of issue","user.login":"sbose78","assignee.login":"pranav"}`),
 			ID:      "http://github.com/sbose/api/testonly/1",
 		}
-		workItemUpdated, err := convert(tx, int(tq.ID), remoteItemDataUpdated, ProviderGithub)
+		workItemUpdated, err := convert(tx, project.SystemProject, int(tr.ID), remoteItemDataUpdated, ProviderGithub)
 
 		assert.Nil(t, err)
 		assert.Equal(t, "linking-updated", workItemUpdated.Fields[workitem.SystemTitle])
@@ -133,7 +134,7 @@ func TestConvertGithubIssue(t *testing.T) {
 			ID:      GitIssueWithAssignee, // GH issue url
 		}
 
-		workItemGithub, err := convert(tx, int(tq.ID), remoteItemDataGithub, ProviderGithub)
+		workItemGithub, err := convert(tx, project.SystemProject, int(tr.ID), remoteItemDataGithub, ProviderGithub)
 
 		assert.Nil(t, err)
 		assert.Equal(t, "map flatten : test case : with assignee", workItemGithub.Fields[workitem.SystemTitle])
@@ -1,6 +1,11 @@
 package remoteworkitem
 
-import "github.com/almighty/almighty-core/gormsupport"
+import (
+	"time"
+
+	"github.com/almighty/almighty-core/gormsupport"
+	uuid "github.com/satori/go.uuid"
+)
 
 // TrackerQuery represents tracker query
 type TrackerQuery struct {
@@ -12,4 +17,13 @@ type TrackerQuery struct {
 	Schedule string
 	// TrackerID is a foreign key for a tracker
 	TrackerID uint64 `gorm:"ForeignKey:Tracker"`
+	// ProjectID is the project into which remote items are imported
+	ProjectID uuid.UUID `sql:"type:uuid default '2e0698d8-753e-4cef-bb7c-f027634824a2'"`
+	// WorkItemType is the type remote items are imported as
+	WorkItemType string `sql:"default 'system.bug'"`
+	// WorkItemTypeRules choose other types based on remote attributes
+	WorkItemTypeRules TypeRules `sql:"type:jsonb"`
+	// LastUpdatedAt is the latest update time of the imported remote items,
+	// the next fetch only asks for items updated since then
+	LastUpdatedAt *time.Time
 }
@@ -6,7 +6,9 @@ import (
 	"strconv"
 
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/workitem"
 	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
 	"golang.org/x/net/context"
 )
 
@@ -22,7 +24,7 @@ func NewTrackerQueryRepository(db *gorm.DB) *GormTrackerQueryRepository {
 
 // Create creates a new tracker query in the repository
 // returns BadParameterError, ConversionError or InternalError
-func (r *GormTrackerQueryRepository) Create(ctx context.Context, query string, schedule string, tracker string) (*app.TrackerQuery, error) {
+func (r *GormTrackerQueryRepository) Create(ctx context.Context, query string, schedule string, tracker string, projectID uuid.UUID) (*app.TrackerQuery, error) {
 	tid, err := strconv.ParseUint(tracker, 10, 64)
 	if err != nil || tid == 0 {
 		// treating this as a not found error: the fact that we're using number internal is implementation detail
@@ -30,21 +32,17 @@ func (r *GormTrackerQueryRepository) Create(ctx context.Context, query string, s
 	}
 	fmt.Printf("tracker id: %v", tid)
 	tq := TrackerQuery{
-		Query:     query,
-		Schedule:  schedule,
-		TrackerID: tid}
+		Query:        query,
+		Schedule:     schedule,
+		TrackerID:    tid,
+		ProjectID:    projectID,
+		WorkItemType: workitem.SystemBug}
 	tx := r.db
 	if err := tx.Create(&tq).Error; err != nil {
 		return nil, InternalError{simpleError{err.Error()}}
 	}
 	log.Printf("created tracker query %v\n", tq)
-	tq2 := app.TrackerQuery{
-		ID:        strconv.FormatUint(tq.ID, 10),
-		Query:     query,
-		Schedule:  schedule,
-		TrackerID: tracker}
-
-	return &tq2, nil
+	return convertTrackerQueryToApp(tq), nil
 }
 
 // Load returns the tracker query for the given id
@@ -62,13 +60,7 @@ func (r *GormTrackerQueryRepository) Load(ctx context.Context, ID string) (*app.
 		log.Printf("not found, res=%v", res)
 		return nil, NotFoundError{"tracker query", ID}
 	}
-	tq := app.TrackerQuery{
-		ID:        strconv.FormatUint(res.ID, 10),
-		Query:     res.Query,
-		Schedule:  res.Schedule,
-		TrackerID: strconv.FormatUint(res.TrackerID, 10)}
-
-	return &tq, nil
+	return convertTrackerQueryToApp(res), nil
 }
 
 // Save updates the given tracker query in storage.
@@ -96,7 +88,8 @@ func (r *GormTrackerQueryRepository) Save(ctx context.Context, tq app.TrackerQue
 		return nil, InternalError{simpleError{fmt.Sprintf("could not load tracker query: %s", tx.Error.Error())}}
 	}
 
-	tx = r.db.First(&Tracker{}, tid)
+	tracker := Tracker{}
+	tx = r.db.First(&tracker, tid)
 	if tx.RecordNotFound() {
 		log.Printf("not found, id=%d", id)
 		return nil, NotFoundError{entity: "tracker", ID: tq.TrackerID}
@@ -105,24 +98,50 @@ func (r *GormTrackerQueryRepository) Save(ctx context.Context, tq app.TrackerQue
 		return nil, InternalError{simpleError{fmt.Sprintf("could not load tracker: %s", tx.Error.Error())}}
 	}
 
+	// keep the project of the existing query unless a new one is given
+	projectID := res.ProjectID
+	if !uuid.Equal(tq.ProjectID, uuid.Nil) {
+		projectID = tq.ProjectID
+	}
+
 	newTq := TrackerQuery{
-		ID:        id,
-		Schedule:  tq.Schedule,
-		Query:     tq.Query,
-		TrackerID: tid}
+		ID:                id,
+		Schedule:          tq.Schedule,
+		Query:             tq.Query,
+		TrackerID:         tid,
+		ProjectID:         projectID,
+		WorkItemType:      res.WorkItemType,
+		WorkItemTypeRules: res.WorkItemTypeRules,
+		LastUpdatedAt:     res.LastUpdatedAt}
+	// another query or tracker has other results, so all of them are fetched again
+	if newTq.Query != res.Query || newTq.TrackerID != res.TrackerID {
+		newTq.LastUpdatedAt = nil
+	}
+	// keep the work item types unless new ones are given
+	if tq.WorkItemType != nil {
+		newTq.WorkItemType = *tq.WorkItemType
+	}
+	if tq.WorkItemTypeRules != nil {
+		// an empty list removes the rules
+		newTq.WorkItemTypeRules = nil
+		for _, rule := range tq.WorkItemTypeRules {
+			newTq.WorkItemTypeRules = append(newTq.WorkItemTypeRules, TypeRule{
+				Path:         rule.Path,
+				Value:        rule.Value,
+				WorkItemType: rule.WorkItemType,
+			})
+		}
+	}
+	if err := validateWorkItemTypes(r.db, newTq.WorkItemType, newTq.WorkItemTypeRules, tracker.FieldMappings.orDefault(tracker.Type)); err != nil {
+		return nil, err
+	}
 
 	if err := tx.Save(&newTq).Error; err != nil {
 		log.Print(err.Error())
 		return nil, InternalError{simpleError{err.Error()}}
 	}
 	log.Printf("updated tracker query to %v\n", newTq)
-	t2 := app.TrackerQuery{
-		ID:        tq.ID,
-		Schedule:  tq.Schedule,
-		Query:     tq.Query,
-		TrackerID: tq.TrackerID}
-
-	return &t2, nil
+	return convertTrackerQueryToApp(newTq), nil
 }
 
 // Delete deletes the tracker query with the given id
@@ -146,20 +165,93 @@ func (r *GormTrackerQueryRepository) Delete(ctx context.Context, ID string) erro
 	return nil
 }
 
-// List returns tracker query selected by the given criteria.Expression, starting with start (zero-based) and returning at most limit items
-func (r *GormTrackerQueryRepository) List(ctx context.Context) ([]*app.TrackerQuery, error) {
+// Resync forgets the latest update time imported by the tracker query with the
+// given id so that its next run fetches and imports all remote items again
+// returns NotFoundError or InternalError
+func (r *GormTrackerQueryRepository) Resync(ctx context.Context, ID string) (*app.TrackerQuery, error) {
+	id, err := strconv.ParseUint(ID, 10, 64)
+	if err != nil || id == 0 {
+		// treating this as a not found error: the fact that we're using number internal is implementation detail
+		return nil, NotFoundError{"tracker query", ID}
+	}
+	res := TrackerQuery{}
+	tx := r.db.First(&res, id)
+	if tx.RecordNotFound() {
+		return nil, NotFoundError{"tracker query", ID}
+	}
+	if tx.Error != nil {
+		return nil, InternalError{simpleError{tx.Error.Error()}}
+	}
+	if err := r.db.Model(&res).UpdateColumn("last_updated_at", gorm.Expr("NULL")).Error; err != nil {
+		return nil, InternalError{simpleError{err.Error()}}
+	}
+	res.LastUpdatedAt = nil
+	return convertTrackerQueryToApp(res), nil
+}
+
+// ListRuns returns at most limit runs of the tracker query with the given id,
+// the latest first
+// returns NotFoundError or InternalError
+func (r *GormTrackerQueryRepository) ListRuns(ctx context.Context, ID string, limit int) ([]*app.TrackerQueryRun, error) {
+	id, err := strconv.ParseUint(ID, 10, 64)
+	if err != nil || id == 0 {
+		// treating this as a not found error: the fact that we're using number internal is implementation detail
+		return nil, NotFoundError{"tracker query", ID}
+	}
+	tx := r.db.First(&TrackerQuery{}, id)
+	if tx.RecordNotFound() {
+		return nil, NotFoundError{"tracker query", ID}
+	}
+	if tx.Error != nil {
+		return nil, InternalError{simpleError{tx.Error.Error()}}
+	}
+	var rows []TrackerQueryRun
+	if err := r.db.Where("tracker_query_id = ?", id).Order("started_at desc, id desc").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, InternalError{simpleError{err.Error()}}
+	}
+	result := make([]*app.TrackerQueryRun, len(rows))
+	for i, run := range rows {
+		result[i] = convertRunToApp(run)
+	}
+	return result, nil
+}
+
+// List returns all tracker queries; if projectID is not nil only the queries
+// of the given project are returned
+func (r *GormTrackerQueryRepository) List(ctx context.Context, projectID *uuid.UUID) ([]*app.TrackerQuery, error) {
 	var rows []TrackerQuery
-	if err := r.db.Find(&rows).Error; err != nil {
+	db := r.db
+	if projectID != nil {
+		db = db.Where("project_id = ?", *projectID)
+	}
+	if err := db.Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	result := make([]*app.TrackerQuery, len(rows))
 	for i, tq := range rows {
-		t := app.TrackerQuery{
-			ID:        strconv.FormatUint(tq.ID, 10),
-			Schedule:  tq.Schedule,
-			Query:     tq.Query,
-			TrackerID: strconv.FormatUint(tq.TrackerID, 10)}
-		result[i] = &t
+		result[i] = convertTrackerQueryToApp(tq)
 	}
 	return result, nil
 }
+
+// convertTrackerQueryToApp converts a tracker query to its REST representation
+func convertTrackerQueryToApp(tq TrackerQuery) *app.TrackerQuery {
+	witName := tq.WorkItemType
+	result := app.TrackerQuery{
+		ID:            strconv.FormatUint(tq.ID, 10),
+		Query:         tq.Query,
+		Schedule:      tq.Schedule,
+		TrackerID:     strconv.FormatUint(tq.TrackerID, 10),
+		ProjectID:     tq.ProjectID,
+		WorkItemType:  &witName,
+		LastUpdatedAt: tq.LastUpdatedAt,
+	}
+	for _, rule := range tq.WorkItemTypeRules {
+		result.WorkItemTypeRules = append(result.WorkItemTypeRules, &app.TrackerQueryTypeRule{
+			Path:         rule.Path,
+			Value:        rule.Value,
+			WorkItemType: rule.WorkItemType,
+		})
+	}
+	return &result
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/almighty/almighty-core/application"
 	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
 	"github.com/almighty/almighty-core/remoteworkitem"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -42,7 +43,8 @@ func (s *trackerQueryRepoBlackBoxTest) TestFailDeleteZeroID() {
 		context.Background(),
 		"project = ARQ AND text ~ 'arquillian'",
 		"15 * * * * *",
-		tr.ID)
+		tr.ID,
+		project.SystemProject)
 	if err != nil {
 		s.T().Error("Could not create tracker query", err)
 	}
@@ -67,7 +69,8 @@ func (s *trackerQueryRepoBlackBoxTest) TestFailSaveZeroID() {
 		context.Background(),
 		"project = ARQ AND text ~ 'arquillian'",
 		"15 * * * * *",
-		tr.ID)
+		tr.ID,
+		project.SystemProject)
 	if err != nil {
 		s.T().Error("Could not create tracker query", err)
 	}
@@ -93,7 +96,8 @@ func (s *trackerQueryRepoBlackBoxTest) TestFaiLoadZeroID() {
 		context.Background(),
 		"project = ARQ AND text ~ 'arquillian'",
 		"15 * * * * *",
-		tr.ID)
+		tr.ID,
+		project.SystemProject)
 	if err != nil {
 		s.T().Error("Could not create tracker query", err)
 	}
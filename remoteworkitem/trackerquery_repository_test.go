@@ -1,23 +1,29 @@
 package remoteworkitem
 
 import (
+	"strconv"
 	"testing"
+	"time"
 
 	"golang.org/x/net/context"
 
+	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/workitem"
 	"github.com/jinzhu/gorm"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTrackerQueryCreate(t *testing.T) {
 	doWithTrackerRepositories(t, func(trackerRepo application.TrackerRepository, queryRepo application.TrackerQueryRepository) {
-		query, err := queryRepo.Create(context.Background(), "abc", "xyz", "lmn")
+		query, err := queryRepo.Create(context.Background(), "abc", "xyz", "lmn", project.SystemProject)
 		assert.IsType(t, NotFoundError{}, err)
 		assert.Nil(t, query)
 
 		tracker, err := trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira)
-		query, err = queryRepo.Create(context.Background(), "abc", "xyz", tracker.ID)
+		query, err = queryRepo.Create(context.Background(), "abc", "xyz", tracker.ID, project.SystemProject)
 		assert.Nil(t, err)
 		assert.Equal(t, "abc", query.Query)
 		assert.Equal(t, "xyz", query.Schedule)
@@ -37,7 +43,7 @@ func TestTrackerQuerySave(t *testing.T) {
 
 		tracker, err := trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira)
 		tracker2, err := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub)
-		query, err = queryRepo.Create(context.Background(), "abc", "xyz", tracker.ID)
+		query, err = queryRepo.Create(context.Background(), "abc", "xyz", tracker.ID, project.SystemProject)
 		query2, err := queryRepo.Load(context.Background(), query.ID)
 		assert.Nil(t, err)
 		assert.Equal(t, query, query2)
@@ -62,13 +68,52 @@ func TestTrackerQuerySave(t *testing.T) {
 	})
 }
 
+func TestTrackerQuerySaveWorkItemType(t *testing.T) {
+	doWithTrackerRepositories(t, func(trackerRepo application.TrackerRepository, queryRepo application.TrackerQueryRepository) {
+		tracker, err := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub)
+		assert.Nil(t, err)
+		query, err := queryRepo.Create(context.Background(), "is:open", "15 * * * * *", tracker.ID, project.SystemProject)
+		assert.Nil(t, err)
+		assert.Equal(t, workitem.SystemBug, *query.WorkItemType)
+
+		unknown := "system.unknown"
+		query.WorkItemType = &unknown
+		_, err = queryRepo.Save(context.Background(), *query)
+		assert.IsType(t, BadParameterError{}, err)
+
+		witName := workitem.SystemFeature
+		query.WorkItemType = &witName
+		query.WorkItemTypeRules = []*app.TrackerQueryTypeRule{
+			{Path: "labels.*.name", Value: "enhancement", WorkItemType: workitem.SystemUserStory},
+		}
+		query, err = queryRepo.Save(context.Background(), *query)
+		assert.Nil(t, err)
+		query, err = queryRepo.Load(context.Background(), query.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, workitem.SystemFeature, *query.WorkItemType)
+		assert.Len(t, query.WorkItemTypeRules, 1)
+
+		// the rules keep their types when not given
+		query.WorkItemTypeRules = nil
+		query, err = queryRepo.Save(context.Background(), *query)
+		assert.Nil(t, err)
+		assert.Len(t, query.WorkItemTypeRules, 1)
+
+		query.WorkItemTypeRules = []*app.TrackerQueryTypeRule{
+			{Path: "labels.*.name", Value: "enhancement", WorkItemType: unknown},
+		}
+		_, err = queryRepo.Save(context.Background(), *query)
+		assert.IsType(t, BadParameterError{}, err)
+	})
+}
+
 func TestTrackerQueryDelete(t *testing.T) {
 	doWithTrackerRepositories(t, func(trackerRepo application.TrackerRepository, queryRepo application.TrackerQueryRepository) {
 		err := queryRepo.Delete(context.Background(), "asdf")
 		assert.IsType(t, NotFoundError{}, err)
 
 		tracker, _ := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub)
-		tq, _ := queryRepo.Create(context.Background(), "is:open is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker.ID)
+		tq, _ := queryRepo.Create(context.Background(), "is:open is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker.ID, project.SystemProject)
 		err = queryRepo.Delete(context.Background(), tq.ID)
 		assert.Nil(t, err)
 
@@ -84,23 +129,53 @@ func TestTrackerQueryDelete(t *testing.T) {
 
 func TestTrackerQueryList(t *testing.T) {
 	doWithTrackerRepositories(t, func(trackerRepo application.TrackerRepository, queryRepo application.TrackerQueryRepository) {
-		trackerqueries1, _ := queryRepo.List(context.Background())
+		trackerqueries1, _ := queryRepo.List(context.Background(), nil)
 
 		tracker1, _ := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub)
-		queryRepo.Create(context.Background(), "is:open is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker1.ID)
-		queryRepo.Create(context.Background(), "is:close is:issue user:arquillian author:aslakknutsen", "", tracker1.ID)
+		queryRepo.Create(context.Background(), "is:open is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker1.ID, project.SystemProject)
+		queryRepo.Create(context.Background(), "is:close is:issue user:arquillian author:aslakknutsen", "", tracker1.ID, project.SystemProject)
 
 		tracker2, _ := trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira)
-		queryRepo.Create(context.Background(), "project = ARQ AND text ~ 'arquillian'", "15 * * * * *", tracker2.ID)
-		queryRepo.Create(context.Background(), "project = ARQ AND text ~ 'javadoc'", "15 * * * * *", tracker2.ID)
+		queryRepo.Create(context.Background(), "project = ARQ AND text ~ 'arquillian'", "15 * * * * *", tracker2.ID, project.SystemProject)
+		queryRepo.Create(context.Background(), "project = ARQ AND text ~ 'javadoc'", "15 * * * * *", tracker2.ID, project.SystemProject)
 
-		trackerqueries2, _ := queryRepo.List(context.Background())
+		trackerqueries2, _ := queryRepo.List(context.Background(), nil)
 		assert.Equal(t, len(trackerqueries1)+4, len(trackerqueries2))
-		trackerqueries3, _ := queryRepo.List(context.Background())
+		trackerqueries3, _ := queryRepo.List(context.Background(), nil)
 		assert.Equal(t, trackerqueries2[1], trackerqueries3[1])
 	})
 }
 
+func TestTrackerQueryListRuns(t *testing.T) {
+	doWithTransaction(t, func(db *gorm.DB) {
+		trackerRepo := NewTrackerRepository(db)
+		queryRepo := NewTrackerQueryRepository(db)
+		_, err := queryRepo.ListRuns(context.Background(), "100000", 10)
+		assert.IsType(t, NotFoundError{}, err)
+
+		tracker, _ := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub)
+		tq, _ := queryRepo.Create(context.Background(), "is:open is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker.ID, project.SystemProject)
+		id, _ := strconv.ParseUint(tq.ID, 10, 64)
+		started := time.Now().Add(-time.Hour)
+		for i := 0; i < 3; i++ {
+			run := TrackerQueryRun{TrackerQueryID: id, Status: RunSucceeded, StartedAt: started.Add(time.Duration(i) * time.Minute)}
+			require.Nil(t, db.Create(&run).Error)
+		}
+		failed := TrackerQueryRun{TrackerQueryID: id, Status: RunFailed, StartedAt: started.Add(time.Hour), Error: "failure", Failed: 1, Items: RunItems{{RemoteItemID: "1", Action: RunItemFailed, Error: "failure"}}}
+		require.Nil(t, db.Create(&failed).Error)
+
+		runs, err := queryRepo.ListRuns(context.Background(), tq.ID, 2)
+		require.Nil(t, err)
+		require.Len(t, runs, 2)
+		// the latest run comes first
+		assert.Equal(t, strconv.FormatUint(failed.ID, 10), runs[0].ID)
+		assert.Equal(t, RunFailed, runs[0].Status)
+		require.Len(t, runs[0].Items, 1)
+		assert.Equal(t, "failure", *runs[0].Items[0].Error)
+		assert.Equal(t, RunSucceeded, runs[1].Status)
+	})
+}
+
 func doWithTrackerRepositories(t *testing.T, todo func(trackerRepo application.TrackerRepository, queryRepo application.TrackerQueryRepository)) {
 	doWithTransaction(t, func(db *gorm.DB) {
 		trackerRepo := NewTrackerRepository(db)
@@ -0,0 +1,165 @@
+package remoteworkitem
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+)
+
+// The states of a tracker query run
+const (
+	RunRunning   = "running"
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+)
+
+// The outcomes of importing a remote item
+const (
+	RunItemCreate    = "create"
+	RunItemUpdate    = "update"
+	RunItemUnchanged = "unchanged"
+	RunItemFailed    = "failed"
+)
+
+// maxRunItems limits the items kept with a run
+const maxRunItems = 100
+
+// TrackerQueryRun records a run of a tracker query
+type TrackerQueryRun struct {
+	ID             uint64 `gorm:"primary_key"`
+	TrackerQueryID uint64
+	// DryRun runs preview the import, they change nothing
+	DryRun     bool
+	Status     string
+	StartedAt  time.Time
+	FinishedAt *time.Time
+	// The number of fetched remote items and what importing them did
+	Fetched   int
+	Created   int
+	Updated   int
+	Unchanged int
+	Failed    int
+	// Error is why the fetch stopped before all remote items were fetched
+	Error string
+	// Items are the failed remote items, on dry runs all remote items
+	Items RunItems `sql:"type:jsonb"`
+}
+
+// TableName implements gorm.tabler
+func (r TrackerQueryRun) TableName() string {
+	return "tracker_query_runs"
+}
+
+// RunItem is the outcome of importing a remote item
+type RunItem struct {
+	RemoteItemID string   `json:"remoteItemID"`
+	Action       string   `json:"action"`
+	WorkItemID   string   `json:"workItemID,omitempty"`
+	Fields       []string `json:"fields,omitempty"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// RunItems are the remote items of a run
+type RunItems []RunItem
+
+// Value implements the driver.Valuer interface
+func (i RunItems) Value() (driver.Value, error) {
+	if i == nil {
+		return nil, nil
+	}
+	return json.Marshal(i)
+}
+
+// Scan implements the sql.Scanner interface
+func (i *RunItems) Scan(src interface{}) error {
+	if src == nil {
+		*i = nil
+		return nil
+	}
+	s, ok := src.([]byte)
+	if !ok {
+		return errors.New("Scan source was not string")
+	}
+	return json.Unmarshal(s, i)
+}
+
+// record counts the outcome of importing the remote item with the given ID,
+// the result is nil for unchanged items that were not converted
+func (r *TrackerQueryRun) record(remoteItemID string, result *importResult, err error) {
+	r.Fetched++
+	item := RunItem{RemoteItemID: remoteItemID}
+	switch {
+	case err != nil:
+		r.Failed++
+		item.Action = RunItemFailed
+		item.Error = err.Error()
+	case result == nil || (!result.Created && len(result.Fields) == 0):
+		r.Unchanged++
+		item.Action = RunItemUnchanged
+	case result.Created:
+		// the work item of a dry run is gone once rolled back
+		r.Created++
+		item.Action = RunItemCreate
+		item.Fields = result.Fields
+	default:
+		r.Updated++
+		item.Action = RunItemUpdate
+		item.Fields = result.Fields
+	}
+	if result != nil && !result.Created && result.WorkItem != nil {
+		item.WorkItemID = result.WorkItem.ID
+	}
+	if (r.DryRun || err != nil) && len(r.Items) < maxRunItems {
+		r.Items = append(r.Items, item)
+	}
+}
+
+// finish ends the run with the error the fetch stopped with
+func (r *TrackerQueryRun) finish(err error, now time.Time) {
+	r.FinishedAt = &now
+	r.Status = RunSucceeded
+	if err != nil {
+		r.Status = RunFailed
+		r.Error = err.Error()
+	}
+}
+
+// convertRunToApp converts a tracker query run to its REST representation
+func convertRunToApp(r TrackerQueryRun) *app.TrackerQueryRun {
+	result := app.TrackerQueryRun{
+		ID:             strconv.FormatUint(r.ID, 10),
+		TrackerQueryID: strconv.FormatUint(r.TrackerQueryID, 10),
+		DryRun:         r.DryRun,
+		Status:         r.Status,
+		StartedAt:      r.StartedAt,
+		FinishedAt:     r.FinishedAt,
+		Fetched:        r.Fetched,
+		Created:        r.Created,
+		Updated:        r.Updated,
+		Unchanged:      r.Unchanged,
+		Failed:         r.Failed,
+	}
+	if r.Error != "" {
+		result.Error = &r.Error
+	}
+	for _, i := range r.Items {
+		i := i
+		item := app.TrackerQueryRunItem{
+			RemoteItemID: i.RemoteItemID,
+			Action:       i.Action,
+			Fields:       i.Fields,
+		}
+		if i.WorkItemID != "" {
+			item.WorkItemID = &i.WorkItemID
+		}
+		if i.Error != "" {
+			item.Error = &i.Error
+		}
+		result.Items = append(result.Items, &item)
+	}
+	return &result
+}
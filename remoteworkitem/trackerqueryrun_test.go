@@ -0,0 +1,71 @@
+package remoteworkitem
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordRun(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	r := TrackerQueryRun{Status: RunRunning}
+	r.record("1", &importResult{WorkItem: &app.WorkItem{ID: "10"}, Created: true, Fields: []string{"system.title"}}, nil)
+	r.record("2", &importResult{WorkItem: &app.WorkItem{ID: "11"}, Fields: []string{"system.state"}}, nil)
+	r.record("3", &importResult{WorkItem: &app.WorkItem{ID: "12"}}, nil)
+	r.record("4", nil, nil)
+	r.record("5", nil, errors.New("failure"))
+	assert.Equal(t, 5, r.Fetched)
+	assert.Equal(t, 1, r.Created)
+	assert.Equal(t, 1, r.Updated)
+	assert.Equal(t, 2, r.Unchanged)
+	assert.Equal(t, 1, r.Failed)
+	// only failed items are kept
+	assert.Equal(t, RunItems{{RemoteItemID: "5", Action: RunItemFailed, Error: "failure"}}, r.Items)
+
+	now := time.Now()
+	r.finish(nil, now)
+	assert.Equal(t, RunSucceeded, r.Status)
+	assert.Equal(t, &now, r.FinishedAt)
+	r.finish(errors.New("rate limit exceeded"), now)
+	assert.Equal(t, RunFailed, r.Status)
+	assert.Equal(t, "rate limit exceeded", r.Error)
+}
+
+func TestRecordDryRun(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	r := TrackerQueryRun{DryRun: true, Status: RunRunning}
+	r.record("1", &importResult{WorkItem: &app.WorkItem{ID: "10"}, Created: true, Fields: []string{"system.title"}}, nil)
+	r.record("2", &importResult{WorkItem: &app.WorkItem{ID: "11"}, Fields: []string{"system.state"}}, nil)
+	// dry runs keep every item, the work items they created are rolled back
+	assert.Equal(t, RunItems{
+		{RemoteItemID: "1", Action: RunItemCreate, Fields: []string{"system.title"}},
+		{RemoteItemID: "2", Action: RunItemUpdate, WorkItemID: "11", Fields: []string{"system.state"}},
+	}, r.Items)
+
+	for i := 0; i < maxRunItems; i++ {
+		r.record("3", nil, nil)
+	}
+	assert.Len(t, r.Items, maxRunItems)
+	assert.Equal(t, maxRunItems+2, r.Fetched)
+
+	result := convertRunToApp(r)
+	assert.True(t, result.DryRun)
+	assert.Nil(t, result.Error)
+	require.Len(t, result.Items, maxRunItems)
+	assert.Nil(t, result.Items[0].WorkItemID)
+	assert.Equal(t, "11", *result.Items[1].WorkItemID)
+}
+
+func TestChangedFields(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	before := map[string]interface{}{"system.title": "title", "system.state": "open", "system.description": "text"}
+	after := map[string]interface{}{"system.title": "title", "system.state": "closed", "system.assignee": "aslak"}
+	assert.Equal(t, []string{"system.assignee", "system.description", "system.state"}, changedFields(before, after))
+	assert.Equal(t, []string{"system.title"}, changedFields(nil, map[string]interface{}{"system.title": "title", "system.state": nil}))
+	assert.Empty(t, changedFields(before, before))
+}
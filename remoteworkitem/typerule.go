@@ -0,0 +1,113 @@
+package remoteworkitem
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/jinzhu/gorm"
+)
+
+// TypeRule chooses the work item type of the imported items whose remote
+// attribute has the given value
+type TypeRule struct {
+	// Path is the attribute expression in the flattened remote item; a * stands
+	// for any index of a list, e.g. labels.*.name for the labels of a Github issue
+	Path string `json:"path"`
+	// Value is compared with the remote value ignoring case
+	Value string `json:"value"`
+	// WorkItemType is the type of the items matching the rule
+	WorkItemType string `json:"workItemType"`
+}
+
+// TypeRules are the rules of a tracker query, the first matching one wins
+type TypeRules []TypeRule
+
+// Value implements the driver.Valuer interface
+func (r TypeRules) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return json.Marshal(r)
+}
+
+// Scan implements the sql.Scanner interface
+func (r *TypeRules) Scan(src interface{}) error {
+	if src == nil {
+		*r = nil
+		return nil
+	}
+	s, ok := src.([]byte)
+	if !ok {
+		return errors.New("Scan source was not string")
+	}
+	return json.Unmarshal(s, r)
+}
+
+// matches tells whether the remote item has the value of the rule
+func (rule TypeRule) matches(item AttributeAccessor) bool {
+	matched, _ := matchPath(item, rule.Path, rule.Value)
+	return matched
+}
+
+// matchPath compares the remote value at the path with the given value. For a
+// * every index of the list is tried up to the first missing element. found
+// tells whether there is a value at the path at all.
+func matchPath(item AttributeAccessor, path string, value string) (matched bool, found bool) {
+	star := strings.Index(path, "*")
+	if star < 0 {
+		v := item.Get(AttributeExpression(path))
+		return v != nil && strings.EqualFold(syncValue(v), value), v != nil
+	}
+	for i := 0; ; i++ {
+		m, f := matchPath(item, path[:star]+strconv.Itoa(i)+path[star+1:], value)
+		if m {
+			return true, true
+		}
+		if !f {
+			return false, i > 0
+		}
+	}
+}
+
+// typeOf returns the work item type of the first rule matching the remote
+// item or the given type if none matches
+func (r TypeRules) typeOf(item AttributeAccessor, fallback string) string {
+	for _, rule := range r {
+		if rule.matches(item) {
+			return rule.WorkItemType
+		}
+	}
+	return fallback
+}
+
+// validateWorkItemTypes checks that the work item type and those of the rules
+// exist and that the field mappings of the tracker fit all of them
+func validateWorkItemTypes(db *gorm.DB, witName string, rules TypeRules, mappings FieldMappings) error {
+	names := []string{witName}
+	for i, rule := range rules {
+		if rule.Path == "" {
+			return BadParameterError{parameter: fmt.Sprintf("workItemTypeRules[%d].path", i), value: rule.Path}
+		}
+		names = append(names, rule.WorkItemType)
+	}
+	witr := workitem.NewWorkItemTypeRepository(db)
+	for i, name := range names {
+		wit, err := witr.LoadTypeFromDB(name)
+		if err != nil {
+			parameter := "workItemType"
+			if i > 0 {
+				parameter = fmt.Sprintf("workItemTypeRules[%d].workItemType", i-1)
+			}
+			return BadParameterError{parameter: parameter, value: name}
+		}
+		if err := mappings.validate(wit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package remoteworkitem
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeRules(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	rules := TypeRules{
+		{Path: "labels.*.name", Value: "enhancement", WorkItemType: workitem.SystemUserStory},
+		{Path: "fields.issuetype.name", Value: "feature", WorkItemType: workitem.SystemFeature},
+	}
+
+	issue, err := NewGitHubRemoteWorkItem(TrackerItem{Item: `{"title":"t","labels":[{"name":"bug"},{"name":"Enhancement"}]}`})
+	require.Nil(t, err)
+	assert.Equal(t, workitem.SystemUserStory, rules.typeOf(issue, workitem.SystemBug))
+
+	issue, err = NewGitHubRemoteWorkItem(TrackerItem{Item: `{"title":"t","labels":[{"name":"bug"}]}`})
+	require.Nil(t, err)
+	assert.Equal(t, workitem.SystemBug, rules.typeOf(issue, workitem.SystemBug))
+
+	issue, err = NewGitHubRemoteWorkItem(TrackerItem{Item: `{"title":"t","labels":[]}`})
+	require.Nil(t, err)
+	assert.Equal(t, workitem.SystemBug, rules.typeOf(issue, workitem.SystemBug))
+
+	issue, err = NewJiraRemoteWorkItem(TrackerItem{Item: `{"key":"ARQ-1","fields":{"issuetype":{"name":"Feature"}}}`})
+	require.Nil(t, err)
+	assert.Equal(t, workitem.SystemFeature, rules.typeOf(issue, workitem.SystemBug))
+	assert.Equal(t, workitem.SystemBug, TypeRules(nil).typeOf(issue, workitem.SystemBug))
+}
@@ -0,0 +1,182 @@
+package remoteworkitem
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/almighty/almighty-core/models"
+	"github.com/almighty/almighty-core/workitem"
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/google/go-github/github"
+	"github.com/jinzhu/gorm"
+)
+
+// The Github events carrying an issue
+const (
+	GithubEventIssues       = "issues"
+	GithubEventIssueComment = "issue_comment"
+)
+
+// JiraEventIssueDeleted is the Jira event of a deleted issue, which is not imported
+const JiraEventIssueDeleted = "jira:issue_deleted"
+
+// ReceiveGithubEvent imports the issue of a Github webhook event sent to the
+// tracker with the given id. The body must be signed with the webhook secret
+// of the tracker, events without an issue are ignored.
+func (s *Scheduler) ReceiveGithubEvent(trackerID string, event string, signature string, body []byte) error {
+	tracker, err := loadWebhookTracker(s.db, trackerID, ProviderGithub)
+	if err != nil {
+		return err
+	}
+	if !validGithubSignature(tracker.WebhookSecret, body, signature) {
+		return UnauthorizedError{simpleError{"invalid webhook signature"}}
+	}
+	item, err := githubEventItem(event, body)
+	if err != nil || item == nil {
+		return err
+	}
+	return s.receive(tracker, *item)
+}
+
+// ReceiveJiraEvent imports the issue of a Jira webhook event sent to the
+// tracker with the given id. Jira does not sign its events, so the webhook
+// secret of the tracker is given with the request instead.
+func (s *Scheduler) ReceiveJiraEvent(trackerID string, secret string, body []byte) error {
+	tracker, err := loadWebhookTracker(s.db, trackerID, ProviderJira)
+	if err != nil {
+		return err
+	}
+	if tracker.WebhookSecret == "" || subtle.ConstantTimeCompare([]byte(tracker.WebhookSecret), []byte(secret)) != 1 {
+		return UnauthorizedError{simpleError{"invalid webhook secret"}}
+	}
+	item, err := jiraEventItem(body)
+	if err != nil || item == nil {
+		return err
+	}
+	return s.receive(tracker, *item)
+}
+
+// loadWebhookTracker returns the tracker with the given id if it is of the given type
+func loadWebhookTracker(db *gorm.DB, trackerID string, provider string) (Tracker, error) {
+	var tracker Tracker
+	id, err := strconv.ParseUint(trackerID, 10, 64)
+	if err != nil || id == 0 || db.First(&tracker, id).RecordNotFound() || tracker.Type != provider {
+		return tracker, NotFoundError{entity: "tracker", ID: trackerID}
+	}
+	return tracker, nil
+}
+
+// receive imports the item of a webhook event. Items imported before are
+// converted right away, for unknown ones the tracker queries are run as
+// only they tell whether and where an item is imported.
+func (s *Scheduler) receive(tracker Tracker, item TrackerItemContent) error {
+	known := false
+	err := models.Transactional(s.db, func(tx *gorm.DB) error {
+		var err error
+		known, err = importKnownItem(tx, tracker, item)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !known {
+		s.fetchTrackerInBackground(tracker.ID)
+	}
+	return nil
+}
+
+// importKnownItem runs the item through the upload and convert steps of the
+// scheduler if it was imported into a work item before, and tells whether it was
+func importKnownItem(db *gorm.DB, tracker Tracker, item TrackerItemContent) (bool, error) {
+	var stored TrackerItem
+	if db.Where("remote_item_id = ? AND tracker_id = ?", item.ID, tracker.ID).Find(&stored).RecordNotFound() || stored.WorkItemID == nil {
+		return false, nil
+	}
+	wi, err := workitem.NewWorkItemRepository(db).LoadFromDB(strconv.FormatUint(*stored.WorkItemID, 10))
+	if err != nil {
+		// the work item is gone, the tracker queries decide whether to import it again
+		log.Printf("loading work item %d of %s failed: %v", *stored.WorkItemID, item.ID, err)
+		return false, nil
+	}
+	tID := int(tracker.ID)
+	if _, err := uploadChanged(db, tID, item); err != nil {
+		return false, InternalError{simpleError{err.Error()}}
+	}
+	if _, err := convert(db, wi.ProjectID, tID, item, tracker.Type); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// validGithubSignature checks the X-Hub-Signature or X-Hub-Signature-256
+// header of a Github event, the HMAC of the body keyed with the secret
+func validGithubSignature(secret string, body []byte, signature string) bool {
+	if secret == "" {
+		return false
+	}
+	var newHash func() hash.Hash
+	switch {
+	case strings.HasPrefix(signature, "sha1="):
+		newHash = sha1.New
+	case strings.HasPrefix(signature, "sha256="):
+		newHash = sha256.New
+	default:
+		return false
+	}
+	expected, err := hex.DecodeString(signature[strings.Index(signature, "=")+1:])
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// githubEventItem returns the issue of a Github issues or issue_comment
+// event in the form Fetch returns it, or nil for other events
+func githubEventItem(event string, body []byte) (*TrackerItemContent, error) {
+	if event != GithubEventIssues && event != GithubEventIssueComment {
+		return nil, nil
+	}
+	var payload struct {
+		Issue *github.Issue `json:"issue"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, BadParameterError{parameter: "body", value: err.Error()}
+	}
+	if payload.Issue == nil || payload.Issue.URL == nil {
+		return nil, BadParameterError{parameter: "issue.url", value: nil}
+	}
+	id, _ := json.Marshal(payload.Issue.URL)
+	content, _ := json.Marshal(payload.Issue)
+	return &TrackerItemContent{ID: string(id), Content: content}, nil
+}
+
+// jiraEventItem returns the issue of a Jira webhook event in the form Fetch
+// returns it, or nil for events without an issue and for deleted issues
+func jiraEventItem(body []byte) (*TrackerItemContent, error) {
+	var payload struct {
+		WebhookEvent string      `json:"webhookEvent"`
+		Issue        *jira.Issue `json:"issue"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, BadParameterError{parameter: "body", value: err.Error()}
+	}
+	if payload.Issue == nil || payload.WebhookEvent == JiraEventIssueDeleted {
+		return nil, nil
+	}
+	if payload.Issue.Key == "" {
+		return nil, BadParameterError{parameter: "issue.key", value: payload.Issue.Key}
+	}
+	id, _ := json.Marshal(payload.Issue.Key)
+	content, _ := json.Marshal(payload.Issue)
+	return &TrackerItemContent{ID: string(id), Content: content}, nil
+}
@@ -0,0 +1,143 @@
+package remoteworkitem
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidGithubSignature(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	body := []byte(`{"action":"opened"}`)
+	mac := hmac.New(sha1.New, []byte("secret"))
+	mac.Write(body)
+	signature := "sha1=" + hex.EncodeToString(mac.Sum(nil))
+
+	assert.True(t, validGithubSignature("secret", body, signature))
+	assert.False(t, validGithubSignature("other", body, signature))
+	assert.False(t, validGithubSignature("secret", []byte(`{"action":"closed"}`), signature))
+	assert.False(t, validGithubSignature("", body, signature))
+	assert.False(t, validGithubSignature("secret", body, ""))
+	assert.False(t, validGithubSignature("secret", body, "md5=abc"))
+
+	mac = hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	assert.True(t, validGithubSignature("secret", body, "sha256="+hex.EncodeToString(mac.Sum(nil))))
+}
+
+func TestGithubEventItem(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	body := []byte(`{"action":"edited","issue":{"url":"https://api.github.com/repos/almighty/test/issues/1","title":"title","body":null,"state":"open"}}`)
+	item, err := githubEventItem(GithubEventIssues, body)
+	require.Nil(t, err)
+	require.NotNil(t, item)
+	assert.Equal(t, `"https://api.github.com/repos/almighty/test/issues/1"`, item.ID)
+	assert.JSONEq(t, `{"state":"open","title":"title","url":"https://api.github.com/repos/almighty/test/issues/1"}`, string(item.Content))
+
+	item, err = githubEventItem(GithubEventIssueComment, body)
+	require.Nil(t, err)
+	assert.NotNil(t, item)
+
+	item, err = githubEventItem("ping", []byte(`{"zen":"Keep it logically awesome."}`))
+	assert.Nil(t, err)
+	assert.Nil(t, item)
+
+	_, err = githubEventItem(GithubEventIssues, []byte(`{"action":"edited"}`))
+	assert.IsType(t, BadParameterError{}, err)
+	_, err = githubEventItem(GithubEventIssues, []byte(`not json`))
+	assert.IsType(t, BadParameterError{}, err)
+}
+
+func TestJiraEventItem(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	item, err := jiraEventItem([]byte(`{"webhookEvent":"jira:issue_updated","issue":{"id":"10001","key":"ARQ-1","fields":{"summary":"summary"}}}`))
+	require.Nil(t, err)
+	require.NotNil(t, item)
+	assert.Equal(t, `"ARQ-1"`, item.ID)
+	assert.Contains(t, string(item.Content), `"summary":"summary"`)
+
+	item, err = jiraEventItem([]byte(`{"webhookEvent":"jira:issue_deleted","issue":{"id":"10001","key":"ARQ-1"}}`))
+	assert.Nil(t, err)
+	assert.Nil(t, item)
+
+	_, err = jiraEventItem([]byte(`{"webhookEvent":"jira:issue_updated","issue":{"id":"10001"}}`))
+	assert.IsType(t, BadParameterError{}, err)
+}
+
+func TestReceiveEventRefused(t *testing.T) {
+	doWithTransaction(t, func(tx *gorm.DB) {
+		s := Scheduler{db: tx}
+		tr := Tracker{URL: "https://api.github.com/", Type: ProviderGithub}
+		require.Nil(t, tx.Create(&tr).Error)
+		trackerID := strconv.FormatUint(tr.ID, 10)
+
+		// without a secret webhooks are disabled
+		err := s.ReceiveGithubEvent(trackerID, GithubEventIssues, "sha1=00", []byte(`{}`))
+		assert.IsType(t, UnauthorizedError{}, err)
+		tr.WebhookSecret = "secret"
+		require.Nil(t, tx.Save(&tr).Error)
+		err = s.ReceiveGithubEvent(trackerID, GithubEventIssues, "sha1=00", []byte(`{}`))
+		assert.IsType(t, UnauthorizedError{}, err)
+
+		// the events of a tracker are sent to the endpoint of its type
+		err = s.ReceiveJiraEvent(trackerID, "secret", []byte(`{}`))
+		assert.IsType(t, NotFoundError{}, err)
+		err = s.ReceiveGithubEvent("100000", GithubEventIssues, "", []byte(`{}`))
+		assert.IsType(t, NotFoundError{}, err)
+	})
+}
+
+func TestFetchTrackerInBackgroundMerged(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	s := Scheduler{}
+	// a fetch of the tracker is running
+	s.fetches = map[uint64]bool{1: false}
+
+	// the requests arriving meanwhile make a single pending fetch
+	s.fetchTrackerInBackground(1)
+	s.fetchTrackerInBackground(1)
+	assert.Equal(t, map[uint64]bool{1: true}, s.fetches)
+}
+
+func TestImportKnownItem(t *testing.T) {
+	doWithTransaction(t, func(tx *gorm.DB) {
+		tr := Tracker{URL: "https://api.github.com/", Type: ProviderGithub}
+		require.Nil(t, tx.Create(&tr).Error)
+		tID := int(tr.ID)
+		issueURL := `"https://api.github.com/repos/almighty/test/issues/` + strconv.FormatUint(tr.ID, 10) + `"`
+		item := TrackerItemContent{
+			ID:      issueURL,
+			Content: []byte(`{"title":"title","url":` + issueURL + `,"state":"open","body":"body","user.login":"sbose78"}`),
+		}
+
+		// unknown items are left to the tracker queries
+		known, err := importKnownItem(tx, tr, item)
+		require.Nil(t, err)
+		assert.False(t, known)
+
+		require.Nil(t, upload(tx, tID, item))
+		wi, err := convert(tx, project.SystemProject, tID, item, ProviderGithub)
+		require.Nil(t, err)
+
+		item.Content = []byte(`{"title":"new title","url":` + issueURL + `,"state":"closed","body":"body","user.login":"sbose78"}`)
+		known, err = importKnownItem(tx, tr, item)
+		require.Nil(t, err)
+		assert.True(t, known)
+		wi, err = workitem.NewWorkItemRepository(tx).Load(context.Background(), wi.ID)
+		require.Nil(t, err)
+		assert.Equal(t, "new title", wi.Fields[workitem.SystemTitle])
+		assert.Equal(t, workitem.SystemStateClosed, wi.Fields[workitem.SystemState])
+	})
+}
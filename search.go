@@ -55,7 +55,12 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 
 	return application.Transactional(c.db, func(appl application.Application) error {
 		//return transaction.Do(c.ts, func() error {
-		result, c, err := appl.SearchItems().SearchFullText(ctx.Context, ctx.Q, &offset, &limit)
+		projects, err := readableProjects(ctx.Context, appl)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		result, c, err := appl.SearchItems().SearchFullTextInProjects(ctx.Context, ctx.Q, projects, &offset, &limit)
 		count := int(c)
 		if err != nil {
 			switch err := err.(type) {
@@ -19,6 +19,7 @@ import (
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/asaskevich/govalidator"
 	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
 )
 
 const (
@@ -52,6 +53,7 @@ func convertFromModel(wiType workitem.WorkItemType, workItem workitem.WorkItem)
 		ID:      strconv.FormatUint(workItem.ID, 10),
 		Type:    workItem.Type,
 		Version: workItem.Version,
+		Project: &workItem.ProjectID,
 		Fields:  map[string]interface{}{}}
 
 	for name, field := range wiType.Fields {
@@ -68,6 +70,7 @@ func convertFromModel(wiType workitem.WorkItemType, workItem workitem.WorkItem)
 //searchKeyword defines how a decomposed raw search query will look like
 type searchKeyword struct {
 	workItemTypes []string
+	projects      []string
 	id            []string
 	words         []string
 }
@@ -233,6 +236,12 @@ func parseSearchString(rawSearchString string) (searchKeyword, error) {
 				return res, errors.NewBadParameterError("Type name must not be empty", part)
 			}
 			res.workItemTypes = append(res.workItemTypes, typeName)
+		} else if strings.HasPrefix(part, "project:") {
+			projectID := strings.TrimPrefix(part, "project:")
+			if _, err := uuid.FromString(projectID); err != nil {
+				return res, errors.NewBadParameterError("Project must be a valid ID", part)
+			}
+			res.projects = append(res.projects, projectID)
 		} else if govalidator.IsURL(part) {
 			part := strings.ToLower(part)
 			part = trimProtocolFromURLString(part)
@@ -261,7 +270,7 @@ func generateSQLSearchInfo(keywords searchKeyword) (sqlParameter string) {
 
 // extracted this function from List() in order to close the rows object with "defer" for more readability
 // workaround for https://github.com/lib/pq/issues/81
-func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParameter string, workItemTypes []string, start *int, limit *int) ([]workitem.WorkItem, uint64, error) {
+func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParameter string, workItemTypes []string, projects []string, start *int, limit *int) ([]workitem.WorkItem, uint64, error) {
 	db := r.db.Model(workitem.WorkItem{}).Where("tsv @@ query")
 	if start != nil {
 		if *start < 0 {
@@ -283,6 +292,9 @@ func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParamet
 			"where supertype.name in (?))", workitem.WorkItem{}.TableName(), workitem.WorkItemType{}.TableName())
 		db = db.Where(query, workItemTypes)
 	}
+	if len(projects) > 0 {
+		db = db.Where(fmt.Sprintf("%s.project_id in (?)", workitem.WorkItem{}.TableName()), projects)
+	}
 
 	db = db.Select("count(*) over () as cnt2 , *")
 	db = db.Joins(", to_tsquery('english', ?) as query, ts_rank(tsv, query) as rank", sqlSearchQueryParameter)
@@ -341,9 +353,45 @@ func (r *GormSearchRepository) SearchFullText(ctx context.Context, rawSearchStri
 		return nil, 0, err
 	}
 
+	return r.searchKeywords(ctx, parsedSearchDict, start, limit)
+}
+
+// SearchFullTextInProjects returns work items for the given query that belong
+// to one of the given projects. Projects named in the query are restricted to
+// the given ones.
+func (r *GormSearchRepository) SearchFullTextInProjects(ctx context.Context, rawSearchString string, projectIDs []uuid.UUID, start *int, limit *int) ([]*app.WorkItem, uint64, error) {
+	parsedSearchDict, err := parseSearchString(rawSearchString)
+	if err != nil {
+		return nil, 0, err
+	}
+	allowed := make(map[string]bool, len(projectIDs))
+	for _, id := range projectIDs {
+		allowed[id.String()] = true
+	}
+	var projects []string
+	if len(parsedSearchDict.projects) == 0 {
+		for id := range allowed {
+			projects = append(projects, id)
+		}
+	} else {
+		for _, id := range parsedSearchDict.projects {
+			if parsed, err := uuid.FromString(id); err == nil && allowed[parsed.String()] {
+				projects = append(projects, id)
+			}
+		}
+	}
+	if len(projects) == 0 {
+		return []*app.WorkItem{}, 0, nil
+	}
+	parsedSearchDict.projects = projects
+	return r.searchKeywords(ctx, parsedSearchDict, start, limit)
+}
+
+// searchKeywords returns work items for the parsed query
+func (r *GormSearchRepository) searchKeywords(ctx context.Context, parsedSearchDict searchKeyword, start *int, limit *int) ([]*app.WorkItem, uint64, error) {
 	sqlSearchQueryParameter := generateSQLSearchInfo(parsedSearchDict)
 	var rows []workitem.WorkItem
-	rows, count, err := r.search(ctx, sqlSearchQueryParameter, parsedSearchDict.workItemTypes, start, limit)
+	rows, count, err := r.search(ctx, sqlSearchQueryParameter, parsedSearchDict.workItemTypes, parsedSearchDict.projects, start, limit)
 	if err != nil {
 		return nil, 0, err
 	}
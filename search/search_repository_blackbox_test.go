@@ -6,9 +6,11 @@ import (
 	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
 	"github.com/almighty/almighty-core/resource"
 	"github.com/almighty/almighty-core/search"
 	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -44,27 +46,27 @@ func (s *searchRepositoryBlackboxTest) TestRestrictByType() {
 	s.DB.Unscoped().Delete(&workitem.WorkItemType{Name: "sub two"})
 
 	extended := workitem.SystemBug
-	base, err := typeRepo.Create(ctx, &extended, "base", map[string]app.FieldDefinition{})
+	base, err := typeRepo.Create(ctx, project.SystemProject, &extended, "base", map[string]app.FieldDefinition{})
 	require.NotNil(s.T(), base)
 	require.Nil(s.T(), err)
 
 	extended = "base"
-	sub1, err := typeRepo.Create(ctx, &extended, "sub1", map[string]app.FieldDefinition{})
+	sub1, err := typeRepo.Create(ctx, project.SystemProject, &extended, "sub1", map[string]app.FieldDefinition{})
 	require.NotNil(s.T(), sub1)
 	require.Nil(s.T(), err)
 
-	sub2, err := typeRepo.Create(ctx, &extended, "sub two", map[string]app.FieldDefinition{})
+	sub2, err := typeRepo.Create(ctx, project.SystemProject, &extended, "sub two", map[string]app.FieldDefinition{})
 	require.NotNil(s.T(), sub2)
 	require.Nil(s.T(), err)
 
-	wi1, err := wiRepo.Create(ctx, "sub1", map[string]interface{}{
+	wi1, err := wiRepo.Create(ctx, project.SystemProject, "sub1", map[string]interface{}{
 		workitem.SystemTitle: "Test TestRestrictByType",
 		workitem.SystemState: "closed",
 	}, account.TestIdentity.ID.String())
 	require.NotNil(s.T(), wi1)
 	require.Nil(s.T(), err)
 
-	wi2, err := wiRepo.Create(ctx, "sub two", map[string]interface{}{
+	wi2, err := wiRepo.Create(ctx, project.SystemProject, "sub two", map[string]interface{}{
 		workitem.SystemTitle: "Test TestRestrictByType 2",
 		workitem.SystemState: "closed",
 	}, account.TestIdentity.ID.String())
@@ -105,3 +107,38 @@ func (s *searchRepositoryBlackboxTest) TestRestrictByType() {
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(0), count)
 }
+
+func (s *searchRepositoryBlackboxTest) TestRestrictToProjects() {
+	resource.Require(s.T(), resource.Database)
+	defer gormsupport.DeleteCreatedEntities(s.DB)()
+	ctx := context.Background()
+	wiRepo := workitem.NewWorkItemRepository(s.DB)
+	searchRepo := search.NewGormSearchRepository(s.DB)
+
+	var projectIDs []uuid.UUID
+	for i := 0; i < 2; i++ {
+		p, err := project.NewRepository(s.DB).Create(ctx, "TestRestrictToProjects-"+uuid.NewV4().String())
+		require.Nil(s.T(), err)
+		_, err = wiRepo.Create(ctx, p.ID, workitem.SystemBug, map[string]interface{}{
+			workitem.SystemTitle: "Test TestRestrictToProjects",
+			workitem.SystemState: workitem.SystemStateNew,
+		}, account.TestIdentity.ID.String())
+		require.Nil(s.T(), err)
+		projectIDs = append(projectIDs, p.ID)
+	}
+
+	res, count, err := searchRepo.SearchFullTextInProjects(ctx, "TestRestrictToProjects", projectIDs[:1], nil, nil)
+	require.Nil(s.T(), err)
+	require.Equal(s.T(), uint64(1), count)
+	assert.Equal(s.T(), projectIDs[0], *res[0].Project)
+
+	// projects named in the query are limited to the given ones
+	res, count, err = searchRepo.SearchFullTextInProjects(ctx, "TestRestrictToProjects project:"+projectIDs[1].String(), projectIDs[:1], nil, nil)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), uint64(0), count)
+	assert.Empty(s.T(), res)
+
+	_, count, err = searchRepo.SearchFullTextInProjects(ctx, "TestRestrictToProjects", projectIDs, nil, nil)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), uint64(2), count)
+}
@@ -10,11 +10,13 @@ import (
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/models"
+	"github.com/almighty/almighty-core/project"
 	"github.com/almighty/almighty-core/resource"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/jinzhu/gorm"
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"golang.org/x/net/context"
 )
@@ -113,7 +115,7 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByText() {
 			minimumResults := testData.minimumResults
 			workItemURLInSearchString := "http://demo.almighty.io/work-item-list/detail/"
 
-			createdWorkItem, err := wir.Create(context.Background(), workitem.SystemBug, workItem.Fields, account.TestIdentity.ID.String())
+			createdWorkItem, err := wir.Create(context.Background(), project.SystemProject, workitem.SystemBug, workItem.Fields, account.TestIdentity.ID.String())
 			if err != nil {
 				s.T().Fatal("Couldnt create test data")
 			}
@@ -213,7 +215,7 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByID() {
 			workitem.SystemState:       "closed",
 		}
 
-		createdWorkItem, err := wir.Create(context.Background(), workitem.SystemBug, workItem.Fields, account.TestIdentity.ID.String())
+		createdWorkItem, err := wir.Create(context.Background(), project.SystemProject, workitem.SystemBug, workItem.Fields, account.TestIdentity.ID.String())
 		if err != nil {
 			s.T().Fatal("Couldnt create test data")
 		}
@@ -223,7 +225,7 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByID() {
 		// up in search results
 
 		workItem.Fields[workitem.SystemTitle] = "Search test sbose " + createdWorkItem.ID
-		_, err = wir.Create(context.Background(), workitem.SystemBug, workItem.Fields, account.TestIdentity.ID.String())
+		_, err = wir.Create(context.Background(), project.SystemProject, workitem.SystemBug, workItem.Fields, account.TestIdentity.ID.String())
 		if err != nil {
 			s.T().Fatal("Couldnt create test data")
 		}
@@ -339,6 +341,22 @@ func TestParseSearchStringCombination(t *testing.T) {
 	assert.True(t, assert.ObjectsAreEqualValues(expectedSearchRes, op))
 }
 
+func TestParseSearchStringProject(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	input := "project:2e0698d8-753e-4cef-bb7c-f027634824a2 golang"
+	op, err := parseSearchString(input)
+	require.Nil(t, err)
+	expectedSearchRes := searchKeyword{
+		projects: []string{"2e0698d8-753e-4cef-bb7c-f027634824a2"},
+		words:    []string{"golang:*"},
+	}
+	assert.True(t, assert.ObjectsAreEqualValues(expectedSearchRes, op))
+
+	_, err = parseSearchString("project:not-an-id golang")
+	assert.NotNil(t, err)
+}
+
 func TestRegisterAsKnownURL(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
 	// build 2 fake urls and cross check against RegisterAsKnownURL
@@ -37,11 +37,11 @@ func TestSearch(t *testing.T) {
 			workitem.SystemState:       "closed"},
 	}
 
-	_, wiResult := test.CreateWorkitemCreated(t, service.Context, service, wiController, &wiPayload)
+	_, wiResult := test.CreateWorkitemCreated(t, service.Context, service, wiController, nil, &wiPayload)
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := "specialwordforsearch"
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, service.Context, service, controller, nil, nil, q)
 	r := sr.Data[0]
 	assert.Equal(t, "specialwordforsearch", r.Fields[workitem.SystemTitle])
 	test.DeleteWorkitemOK(t, nil, nil, wiController, wiResult.ID)
@@ -61,11 +61,11 @@ func TestSearchPagination(t *testing.T) {
 			workitem.SystemState:       "closed"},
 	}
 
-	_, wiResult := test.CreateWorkitemCreated(t, service.Context, service, wiController, &wiPayload)
+	_, wiResult := test.CreateWorkitemCreated(t, service.Context, service, wiController, nil, &wiPayload)
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := "specialwordforsearch2"
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, service.Context, service, controller, nil, nil, q)
 	assert.Equal(t, "http:///api/search?q=specialwordforsearch2&page[offset]=0&page[limit]=100", *sr.Links.First)
 	assert.Equal(t, "http:///api/search?q=specialwordforsearch2&page[offset]=0&page[limit]=100", *sr.Links.Last)
 	r := sr.Data[0]
@@ -87,11 +87,11 @@ func TestSearchWithEmptyValue(t *testing.T) {
 			workitem.SystemState:       "closed"},
 	}
 
-	_, wiResult := test.CreateWorkitemCreated(t, service.Context, service, wiController, &wiPayload)
+	_, wiResult := test.CreateWorkitemCreated(t, service.Context, service, wiController, nil, &wiPayload)
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := ""
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, service.Context, service, controller, nil, nil, q)
 	assert.Equal(t, 0, len(sr.Data))
 	test.DeleteWorkitemOK(t, nil, nil, wiController, wiResult.ID)
 }
@@ -111,11 +111,11 @@ func TestSearchWithDomainPortCombination(t *testing.T) {
 			workitem.SystemState:       "closed"},
 	}
 
-	_, wiResult := test.CreateWorkitemCreated(t, service.Context, service, wiController, &wiPayload)
+	_, wiResult := test.CreateWorkitemCreated(t, service.Context, service, wiController, nil, &wiPayload)
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := `"http://localhost:8080/detail/154687364529310"`
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, service.Context, service, controller, nil, nil, q)
 	assert.NotEqual(t, 0, len(sr.Data))
 	r := sr.Data[0]
 	assert.Equal(t, expectedDescription, r.Fields[workitem.SystemDescription])
@@ -137,11 +137,11 @@ func TestSearchURLWithoutPort(t *testing.T) {
 			workitem.SystemState:       "closed"},
 	}
 
-	_, wiResult := test.CreateWorkitemCreated(t, service.Context, service, wiController, &wiPayload)
+	_, wiResult := test.CreateWorkitemCreated(t, service.Context, service, wiController, nil, &wiPayload)
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := `"http://localhost/detail/876394"`
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, service.Context, service, controller, nil, nil, q)
 	assert.NotEqual(t, 0, len(sr.Data))
 	r := sr.Data[0]
 	assert.Equal(t, expectedDescription, r.Fields[workitem.SystemDescription])
@@ -162,11 +162,11 @@ func TestUnregisteredURLWithPort(t *testing.T) {
 			workitem.SystemState:       "closed"},
 	}
 
-	_, wiResult := test.CreateWorkitemCreated(t, service.Context, service, wiController, &wiPayload)
+	_, wiResult := test.CreateWorkitemCreated(t, service.Context, service, wiController, nil, &wiPayload)
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := `http://some-other-domain:8080/different-path/`
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, service.Context, service, controller, nil, nil, q)
 	assert.NotEqual(t, 0, len(sr.Data))
 	r := sr.Data[0]
 	assert.Equal(t, expectedDescription, r.Fields[workitem.SystemDescription])
@@ -187,12 +187,12 @@ func TestUnwantedCharactersRelatedToSearchLogic(t *testing.T) {
 			workitem.SystemState:       "closed"},
 	}
 
-	_, wiResult := test.CreateWorkitemCreated(t, service.Context, service, wiController, &wiPayload)
+	_, wiResult := test.CreateWorkitemCreated(t, service.Context, service, wiController, nil, &wiPayload)
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	// add url: in the query, that is not expected by the code hence need to make sure it gives expected result.
 	q := `http://url:some-random-other-domain:8080/different-path/`
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, service.Context, service, controller, nil, nil, q)
 	assert.Equal(t, 0, len(sr.Data))
 	test.DeleteWorkitemOK(t, nil, nil, wiController, wiResult.ID)
 }
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/token"
+	"github.com/goadesign/goa"
+)
+
+// ServiceAccountTokensController implements the service-account-tokens resource.
+type ServiceAccountTokensController struct {
+	*goa.Controller
+	db           application.DB
+	tokenManager token.Manager
+}
+
+// NewServiceAccountTokensController creates a service-account-tokens controller.
+func NewServiceAccountTokensController(service *goa.Service, db application.DB, tokenManager token.Manager) *ServiceAccountTokensController {
+	if db == nil {
+		panic("db must not be nil")
+	}
+	return &ServiceAccountTokensController{Controller: service.NewController("ServiceAccountTokensController"), db: db, tokenManager: tokenManager}
+}
+
+// List runs the list action.
+func (c *ServiceAccountTokensController) List(ctx *app.ListServiceAccountTokensContext) error {
+	identityID, err := c.tokenManager.Locate(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		sa, err := loadServiceAccount(ctx.Context, appl, identityID, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		tokens, err := appl.AccessTokens().List(ctx.Context, sa.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(convertAccessTokensFromModel(ctx.RequestData, tokens))
+	})
+}
+
+// Create runs the create action.
+func (c *ServiceAccountTokensController) Create(ctx *app.CreateServiceAccountTokensContext) error {
+	identityID, err := c.tokenManager.Locate(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+	attributes := ctx.Payload.Data.Attributes
+	if attributes == nil || attributes.Name == nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("data.attributes.name", nil).Expected("not nil"))
+		return ctx.BadRequest(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		sa, err := loadServiceAccount(ctx.Context, appl, identityID, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		data, err := createAccessToken(ctx.Context, appl, c.tokenManager, ctx.RequestData, sa, attributes)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		ctx.ResponseData.Header().Set("Location", app.AccessTokenHref(*data.ID))
+		return ctx.Created(&app.AccessToken{Data: data})
+	})
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/token"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ServiceAccountController implements the service-account resource.
+type ServiceAccountController struct {
+	*goa.Controller
+	db           application.DB
+	tokenManager token.Manager
+}
+
+// NewServiceAccountController creates a service-account controller.
+func NewServiceAccountController(service *goa.Service, db application.DB, tokenManager token.Manager) *ServiceAccountController {
+	if db == nil {
+		panic("db must not be nil")
+	}
+	return &ServiceAccountController{Controller: service.NewController("ServiceAccountController"), db: db, tokenManager: tokenManager}
+}
+
+// List runs the list action.
+func (c *ServiceAccountController) List(ctx *app.ListServiceAccountContext) error {
+	identityID, err := c.tokenManager.Locate(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		accounts, err := appl.Identities().Query(account.IdentityServiceAccountsOf(identityID))
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(errors.NewInternalError(err.Error()))
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		res := &app.ServiceAccountArray{
+			Data: make([]*app.ServiceAccountData, len(accounts)),
+		}
+		for index, sa := range accounts {
+			res.Data[index] = convertServiceAccountFromModel(ctx.RequestData, sa)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Show runs the show action.
+func (c *ServiceAccountController) Show(ctx *app.ShowServiceAccountContext) error {
+	identityID, err := c.tokenManager.Locate(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		sa, err := loadServiceAccount(ctx.Context, appl, identityID, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(&app.ServiceAccount{
+			Data: convertServiceAccountFromModel(ctx.RequestData, sa),
+		})
+	})
+}
+
+// Create runs the create action. Service accounts can not own service accounts.
+func (c *ServiceAccountController) Create(ctx *app.CreateServiceAccountContext) error {
+	identityID, err := c.tokenManager.Locate(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+	attributes := ctx.Payload.Data.Attributes
+	if attributes == nil || attributes.Name == nil || strings.TrimSpace(*attributes.Name) == "" {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("data.attributes.name", nil).Expected("not empty"))
+		return ctx.BadRequest(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		owner, err := loadIdentity(ctx.Context, appl, identityID)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+			return ctx.Unauthorized(jerrors)
+		}
+		if owner.ServiceAccount {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewForbiddenError("service accounts can not own service accounts"))
+			return ctx.Forbidden(jerrors)
+		}
+		sa := account.Identity{
+			FullName:       *attributes.Name,
+			ServiceAccount: true,
+			OwnerID:        &owner.ID,
+		}
+		if err := appl.Identities().Create(ctx.Context, &sa); err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(errors.NewInternalError(err.Error()))
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		ctx.ResponseData.Header().Set("Location", app.ServiceAccountHref(sa.ID))
+		return ctx.Created(&app.ServiceAccount{
+			Data: convertServiceAccountFromModel(ctx.RequestData, &sa),
+		})
+	})
+}
+
+// Delete runs the delete action.
+func (c *ServiceAccountController) Delete(ctx *app.DeleteServiceAccountContext) error {
+	identityID, err := c.tokenManager.Locate(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		sa, err := loadServiceAccount(ctx.Context, appl, identityID, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		if err := appl.AccessTokens().RevokeAll(ctx.Context, sa.ID); err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		if err := appl.Identities().Delete(ctx.Context, sa.ID); err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(errors.NewInternalError(err.Error()))
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// loadServiceAccount loads the service account with the given ID. Identities
+// that are no service accounts of the owner are treated like unknown ones,
+// just like an ID that is not a valid UUID.
+func loadServiceAccount(ctx context.Context, appl application.Application, ownerID uuid.UUID, id string) (*account.Identity, error) {
+	saID, err := uuid.FromString(id)
+	if err != nil {
+		return nil, errors.NewNotFoundError("service account", id)
+	}
+	sa, err := loadIdentity(ctx, appl, saID)
+	if err != nil {
+		return nil, err
+	}
+	if !sa.ServiceAccount || sa.OwnerID == nil || !uuid.Equal(*sa.OwnerID, ownerID) {
+		return nil, errors.NewNotFoundError("service account", id)
+	}
+	return sa, nil
+}
+
+// convertServiceAccountFromModel converts between internal and external REST representation
+func convertServiceAccountFromModel(request *goa.RequestData, sa *account.Identity) *app.ServiceAccountData {
+	selfURL := absoluteURL(request, app.ServiceAccountHref(sa.ID))
+	return &app.ServiceAccountData{
+		ID:   &sa.ID,
+		Type: "service-accounts",
+		Attributes: &app.ServiceAccountAttributes{
+			Name:      &sa.FullName,
+			Owner:     sa.OwnerID,
+			CreatedAt: &sa.CreatedAt,
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/team"
+	"github.com/goadesign/goa"
+)
+
+// TeamMembersController implements the team-members resource.
+type TeamMembersController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewTeamMembersController creates a team-members controller.
+func NewTeamMembersController(service *goa.Service, db application.DB) *TeamMembersController {
+	if db == nil {
+		panic("db must not be nil")
+	}
+	return &TeamMembersController{Controller: service.NewController("TeamMembersController"), db: db}
+}
+
+// List runs the list action.
+func (c *TeamMembersController) List(ctx *app.ListTeamMembersContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		t, err := loadTeam(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		members, err := appl.Teams().ListMembers(ctx.Context, t.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		res := &app.TeamMemberArray{
+			Data: make([]*app.TeamMemberData, len(members)),
+		}
+		for index, m := range members {
+			res.Data[index] = convertTeamMemberFromModel(m)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Create runs the create action.
+func (c *TeamMembersController) Create(ctx *app.CreateTeamMembersContext) error {
+	attributes := ctx.Payload.Data.Attributes
+	return application.Transactional(c.db, func(appl application.Application) error {
+		t, err := loadTeam(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		m, err := appl.Teams().AddMember(ctx.Context, t.ID, attributes.Identity)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.Created(&app.TeamMember{
+			Data: convertTeamMemberFromModel(m),
+		})
+	})
+}
+
+// Delete runs the delete action.
+func (c *TeamMembersController) Delete(ctx *app.DeleteTeamMembersContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		t, err := loadTeam(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		err = appl.Teams().RemoveMember(ctx.Context, t.ID, ctx.IdentityID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// convertTeamMemberFromModel converts between internal and external REST representation
+func convertTeamMemberFromModel(m *team.Member) *app.TeamMemberData {
+	return &app.TeamMemberData{
+		ID:   &m.ID,
+		Type: "team-members",
+		Attributes: &app.TeamMemberAttributes{
+			Identity:  m.IdentityID,
+			CreatedAt: &m.CreatedAt,
+		},
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/team"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// TeamController implements the team resource.
+type TeamController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewTeamController creates a team controller.
+func NewTeamController(service *goa.Service, db application.DB) *TeamController {
+	if db == nil {
+		panic("db must not be nil")
+	}
+	return &TeamController{Controller: service.NewController("TeamController"), db: db}
+}
+
+// List runs the list action.
+func (c *TeamController) List(ctx *app.ListTeamContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		teams, err := appl.Teams().List(ctx.Context)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		res := &app.TeamArray{
+			Data: make([]*app.TeamData, len(teams)),
+		}
+		for index, t := range teams {
+			res.Data[index] = convertTeamFromModel(ctx.RequestData, t)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Show runs the show action.
+func (c *TeamController) Show(ctx *app.ShowTeamContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		t, err := loadTeam(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(&app.Team{
+			Data: convertTeamFromModel(ctx.RequestData, t),
+		})
+	})
+}
+
+// Create runs the create action.
+func (c *TeamController) Create(ctx *app.CreateTeamContext) error {
+	attributes := ctx.Payload.Data.Attributes
+	if attributes == nil || attributes.Name == nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("data.attributes.name", nil).Expected("not nil"))
+		return ctx.BadRequest(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		t := team.Team{}
+		applyTeamAttributes(&t, attributes)
+		err := appl.Teams().Create(ctx.Context, &t)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		ctx.ResponseData.Header().Set("Location", app.TeamHref(t.ID))
+		return ctx.Created(&app.Team{
+			Data: convertTeamFromModel(ctx.RequestData, &t),
+		})
+	})
+}
+
+// Update runs the update action.
+func (c *TeamController) Update(ctx *app.UpdateTeamContext) error {
+	attributes := ctx.Payload.Data.Attributes
+	if attributes == nil || attributes.Version == nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("data.attributes.version", nil).Expected("not nil"))
+		return ctx.BadRequest(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		t, err := loadTeam(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		t.Version = *attributes.Version
+		applyTeamAttributes(t, attributes)
+		t, err = appl.Teams().Save(ctx.Context, *t)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(&app.Team{
+			Data: convertTeamFromModel(ctx.RequestData, t),
+		})
+	})
+}
+
+// Delete runs the delete action.
+func (c *TeamController) Delete(ctx *app.DeleteTeamContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		t, err := loadTeam(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		err = appl.Teams().Delete(ctx.Context, t.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// Mentions runs the mentions action.
+func (c *TeamController) Mentions(ctx *app.MentionsTeamContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		t, err := loadTeam(ctx.Context, appl, ctx.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		comments, err := appl.Teams().ListMentions(ctx.Context, t.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		res := &app.CommentArray{}
+		res.Data = []*app.Comment{}
+		for _, cmt := range comments {
+			res.Data = append(res.Data, toAPI(cmt))
+		}
+		return ctx.OK(res)
+	})
+}
+
+// applyTeamAttributes copies the attributes given in a payload to the team.
+// Attributes that are not given are left untouched.
+func applyTeamAttributes(t *team.Team, attributes *app.TeamAttributes) {
+	if attributes.Name != nil {
+		t.Name = *attributes.Name
+	}
+	if attributes.Description != nil {
+		t.Description = *attributes.Description
+	}
+	if attributes.Parent != nil {
+		t.ParentID = attributes.Parent
+	}
+}
+
+// convertTeamFromModel converts between internal and external REST representation
+func convertTeamFromModel(request *goa.RequestData, t *team.Team) *app.TeamData {
+	selfURL := absoluteURL(request, app.TeamHref(t.ID))
+	return &app.TeamData{
+		ID:   &t.ID,
+		Type: "teams",
+		Attributes: &app.TeamAttributes{
+			Name:        &t.Name,
+			Description: &t.Description,
+			Parent:      t.ParentID,
+			Version:     &t.Version,
+			CreatedAt:   &t.CreatedAt,
+			UpdatedAt:   &t.UpdatedAt,
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
+
+// loadTeam loads the team with the given ID, treating an ID that is not a
+// valid UUID like an unknown one.
+func loadTeam(ctx context.Context, appl application.Application, id string) (*team.Team, error) {
+	teamID, err := uuid.FromString(id)
+	if err != nil {
+		return nil, errors.NewNotFoundError("team", id)
+	}
+	return appl.Teams().Load(ctx, teamID)
+}
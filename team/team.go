@@ -0,0 +1,440 @@
+package team
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Team is a named group of identities. Teams can be nested in a parent team;
+// the members of a team are also counted as members of all its ancestors.
+// Work items are assigned to a team by referencing it in their system.team
+// field.
+type Team struct {
+	gormsupport.Lifecycle
+	ID          uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	Version     int
+	ParentID    *uuid.UUID `sql:"type:uuid"`
+	Name        string
+	Description string
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (t Team) TableName() string {
+	return "teams"
+}
+
+// Member makes an identity a member of a team
+type Member struct {
+	gormsupport.Lifecycle
+	ID         uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	TeamID     uuid.UUID `sql:"type:uuid"`
+	IdentityID uuid.UUID `sql:"type:uuid"`
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Member) TableName() string {
+	return "team_members"
+}
+
+// ProjectRole gives all members of a team and of its sub-teams a role in a
+// project
+type ProjectRole struct {
+	gormsupport.Lifecycle
+	ID        uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	ProjectID uuid.UUID `sql:"type:uuid"`
+	TeamID    uuid.UUID `sql:"type:uuid"`
+	Role      string
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (r ProjectRole) TableName() string {
+	return "project_team_roles"
+}
+
+// mentionPattern matches mentions of teams like @frontend in comment bodies.
+// Trailing dots are not part of the name, so that mentions can end a sentence.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@([A-Za-z0-9][A-Za-z0-9_.-]*)`)
+
+// Mentions returns the names mentioned with @name in the text, each once
+func Mentions(text string) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		name := strings.TrimRight(match[1], ".")
+		if !seen[strings.ToLower(name)] {
+			seen[strings.ToLower(name)] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Repository encapsulate storage & retrieval of teams, their members and the
+// roles granted to them
+type Repository interface {
+	Create(ctx context.Context, t *Team) error
+	Load(ctx context.Context, id uuid.UUID) (*Team, error)
+	Save(ctx context.Context, t Team) (*Team, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context) ([]*Team, error)
+	AddMember(ctx context.Context, teamID, identityID uuid.UUID) (*Member, error)
+	RemoveMember(ctx context.Context, teamID, identityID uuid.UUID) error
+	ListMembers(ctx context.Context, teamID uuid.UUID) ([]*Member, error)
+	GrantRole(ctx context.Context, projectID, teamID uuid.UUID, role string) (*ProjectRole, error)
+	RevokeRole(ctx context.Context, projectID, teamID uuid.UUID) error
+	ListRoles(ctx context.Context, projectID uuid.UUID) ([]*ProjectRole, error)
+	RolesOf(ctx context.Context, projectID, identityID uuid.UUID) ([]string, error)
+	RecordMentions(ctx context.Context, c *comment.Comment) ([]*Team, error)
+	ListMentions(ctx context.Context, teamID uuid.UUID) ([]*comment.Comment, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for teams.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Create creates a new team
+// returns BadParameterError or InternalError
+func (r *GormRepository) Create(ctx context.Context, t *Team) error {
+	defer goa.MeasureSince([]string{"goa", "db", "team", "create"}, time.Now())
+
+	t.ID = uuid.NewV4()
+	if err := r.validate(ctx, *t); err != nil {
+		return err
+	}
+	tx := r.db.Create(t)
+	if err := tx.Error; err != nil {
+		return convertError(tx.Error, *t)
+	}
+	return nil
+}
+
+// Load returns the team for the given id
+// returns NotFoundError or InternalError
+func (r *GormRepository) Load(ctx context.Context, id uuid.UUID) (*Team, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "team", "load"}, time.Now())
+
+	res := Team{}
+	tx := r.db.Where("id = ?", id).First(&res)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("team", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &res, nil
+}
+
+// Save updates the given team in the db. Version must be the same as the one
+// in the stored version.
+// returns NotFoundError, BadParameterError, VersionConflictError or InternalError
+func (r *GormRepository) Save(ctx context.Context, t Team) (*Team, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "team", "save"}, time.Now())
+
+	if _, err := r.Load(ctx, t.ID); err != nil {
+		return nil, err
+	}
+	if err := r.validate(ctx, t); err != nil {
+		return nil, err
+	}
+	oldVersion := t.Version
+	t.Version++
+	tx := r.db.Where("Version = ?", oldVersion).Save(&t)
+	if err := tx.Error; err != nil {
+		return nil, convertError(tx.Error, t)
+	}
+	if tx.RowsAffected == 0 {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+	return &t, nil
+}
+
+// Delete deletes the team with the given id. Teams that have sub-teams or are
+// assigned to work items can not be deleted.
+// returns NotFoundError, BadParameterError or InternalError
+func (r *GormRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "team", "delete"}, time.Now())
+
+	var children int
+	if err := r.db.Model(&Team{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if children > 0 {
+		return errors.NewBadParameterError("team", id.String()).Expected("no sub-teams")
+	}
+	var assigned int
+	if err := r.db.Model(&workitem.WorkItem{}).Where("Fields->>? = ?", workitem.SystemTeam, id.String()).Count(&assigned).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if assigned > 0 {
+		return errors.NewBadParameterError("team", id.String()).Expected("no work items assigned to the team")
+	}
+	tx := r.db.Delete(Team{ID: id})
+	if err := tx.Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("team", id.String())
+	}
+	return nil
+}
+
+// List returns all teams ordered by their name
+// returns InternalError
+func (r *GormRepository) List(ctx context.Context) ([]*Team, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "team", "list"}, time.Now())
+
+	var rows []*Team
+	if err := r.db.Order("lower(name)").Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return rows, nil
+}
+
+// AddMember makes the identity a member of the team. An identity can be
+// member of a team only once.
+// returns NotFoundError, BadParameterError or InternalError
+func (r *GormRepository) AddMember(ctx context.Context, teamID, identityID uuid.UUID) (*Member, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "team", "addmember"}, time.Now())
+
+	if _, err := r.Load(ctx, teamID); err != nil {
+		return nil, err
+	}
+	if _, err := account.NewIdentityRepository(r.db).Load(ctx, identityID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("identity", identityID.String())
+		}
+		return nil, errors.NewInternalError(err.Error())
+	}
+	m := Member{
+		TeamID:     teamID,
+		IdentityID: identityID,
+	}
+	tx := r.db.Create(&m)
+	if err := tx.Error; err != nil {
+		if gormsupport.IsUniqueViolation(tx.Error, "team_members_team_identity_idx") {
+			return nil, errors.NewBadParameterError("identity", identityID.String()).Expected("not yet a member of the team")
+		}
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &m, nil
+}
+
+// RemoveMember removes the identity from the team
+// returns NotFoundError or InternalError
+func (r *GormRepository) RemoveMember(ctx context.Context, teamID, identityID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "team", "removemember"}, time.Now())
+
+	tx := r.db.Where("team_id = ? AND identity_id = ?", teamID, identityID).Delete(&Member{})
+	if err := tx.Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("team member", identityID.String())
+	}
+	return nil
+}
+
+// ListMembers returns the direct members of the team, without the members of
+// its sub-teams
+// returns InternalError
+func (r *GormRepository) ListMembers(ctx context.Context, teamID uuid.UUID) ([]*Member, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "team", "listmembers"}, time.Now())
+
+	var rows []*Member
+	if err := r.db.Where("team_id = ?", teamID).Order("created_at").Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return rows, nil
+}
+
+// GrantRole gives the members of the team and of its sub-teams the role in
+// the project. A team can have only one role per project.
+// returns NotFoundError, BadParameterError or InternalError
+func (r *GormRepository) GrantRole(ctx context.Context, projectID, teamID uuid.UUID, role string) (*ProjectRole, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "team", "grantrole"}, time.Now())
+
+	if !project.IsValidRole(role) {
+		return nil, errors.NewBadParameterError("role", role).Expected(project.Roles)
+	}
+	if _, err := r.Load(ctx, teamID); err != nil {
+		if _, ok := err.(errors.NotFoundError); ok {
+			return nil, errors.NewBadParameterError("team", teamID.String()).Expected("existing team")
+		}
+		return nil, err
+	}
+	pr := ProjectRole{
+		ProjectID: projectID,
+		TeamID:    teamID,
+		Role:      role,
+	}
+	tx := r.db.Create(&pr)
+	if err := tx.Error; err != nil {
+		if gormsupport.IsUniqueViolation(tx.Error, "project_team_roles_project_team_idx") {
+			return nil, errors.NewBadParameterError("team", teamID.String()).Expected("no role in the project yet")
+		}
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return &pr, nil
+}
+
+// RevokeRole removes the role of the team in the project
+// returns NotFoundError or InternalError
+func (r *GormRepository) RevokeRole(ctx context.Context, projectID, teamID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "team", "revokerole"}, time.Now())
+
+	tx := r.db.Where("project_id = ? AND team_id = ?", projectID, teamID).Delete(&ProjectRole{})
+	if err := tx.Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("project team role", teamID.String())
+	}
+	return nil
+}
+
+// ListRoles returns the roles granted to teams in the project
+// returns InternalError
+func (r *GormRepository) ListRoles(ctx context.Context, projectID uuid.UUID) ([]*ProjectRole, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "team", "listroles"}, time.Now())
+
+	var rows []*ProjectRole
+	if err := r.db.Where("project_id = ?", projectID).Order("created_at").Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return rows, nil
+}
+
+// RolesOf returns the roles the identity has in the project through the teams
+// it is a member of, including the roles granted to their ancestors
+// returns InternalError
+func (r *GormRepository) RolesOf(ctx context.Context, projectID, identityID uuid.UUID) ([]string, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "team", "rolesof"}, time.Now())
+
+	rows, err := r.db.Raw(`WITH RECURSIVE teams_of(id) AS (
+			SELECT team_id FROM team_members WHERE identity_id = ? AND deleted_at IS NULL
+			UNION SELECT t.parent_id FROM teams t JOIN teams_of o ON t.id = o.id WHERE t.parent_id IS NOT NULL AND t.deleted_at IS NULL
+		)
+		SELECT DISTINCT role FROM project_team_roles WHERE project_id = ? AND deleted_at IS NULL AND team_id IN (SELECT id FROM teams_of)`,
+		identityID, projectID).Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+	roles := []string{}
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		roles = append(roles, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return roles, nil
+}
+
+// RecordMentions records the teams mentioned with @name in the body of the
+// comment and returns them. Names that do not belong to a team are ignored.
+// Mentions recorded before for teams no longer named in the body are removed,
+// so it is called again whenever the body of a comment changes.
+// returns InternalError
+func (r *GormRepository) RecordMentions(ctx context.Context, c *comment.Comment) ([]*Team, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "team", "recordmentions"}, time.Now())
+
+	mentioned := []*Team{}
+	names := Mentions(c.Body)
+	if len(names) == 0 {
+		if err := r.db.Exec("DELETE FROM team_mentions WHERE comment_id = ?", c.ID).Error; err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		return mentioned, nil
+	}
+	lowered := make([]string, len(names))
+	for i, name := range names {
+		lowered[i] = strings.ToLower(name)
+	}
+	if err := r.db.Where("lower(name) IN (?)", lowered).Order("lower(name)").Find(&mentioned).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	teamIDs := []uuid.UUID{uuid.Nil}
+	for _, t := range mentioned {
+		teamIDs = append(teamIDs, t.ID)
+	}
+	if err := r.db.Exec("DELETE FROM team_mentions WHERE comment_id = ? AND team_id NOT IN (?)", c.ID, teamIDs).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	for _, t := range mentioned {
+		err := r.db.Exec("INSERT INTO team_mentions (created_at, comment_id, team_id) VALUES (?, ?, ?) ON CONFLICT DO NOTHING", time.Now(), c.ID, t.ID).Error
+		if err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+	}
+	return mentioned, nil
+}
+
+// ListMentions returns the comments mentioning the team, newest first
+// returns InternalError
+func (r *GormRepository) ListMentions(ctx context.Context, teamID uuid.UUID) ([]*comment.Comment, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "team", "listmentions"}, time.Now())
+
+	var rows []*comment.Comment
+	tx := r.db.Joins("JOIN team_mentions ON team_mentions.comment_id = comments.id").Where("team_mentions.team_id = ?", teamID)
+	if err := tx.Order("comments.created_at DESC").Find(&rows).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return rows, nil
+}
+
+// validate checks that the parent of the team exists and is not the team
+// itself or one of its sub-teams
+func (r *GormRepository) validate(ctx context.Context, t Team) error {
+	parentID := t.ParentID
+	for parentID != nil {
+		if uuid.Equal(*parentID, t.ID) {
+			return errors.NewBadParameterError("parent", t.ParentID.String()).Expected("not the team itself or one of its sub-teams")
+		}
+		parent, err := r.Load(ctx, *parentID)
+		if err != nil {
+			if _, ok := err.(errors.NotFoundError); ok {
+				return errors.NewBadParameterError("parent", t.ParentID.String()).Expected("existing team")
+			}
+			return err
+		}
+		parentID = parent.ParentID
+	}
+	return nil
+}
+
+// convertError turns constraint violations into BadParameterErrors
+func convertError(err error, t Team) error {
+	if gormsupport.IsCheckViolation(err, "teams_name_check") {
+		return errors.NewBadParameterError("name", t.Name).Expected("letters, digits, '_', '.' and '-', starting with a letter or digit")
+	}
+	if gormsupport.IsUniqueViolation(err, "teams_name_idx") {
+		return errors.NewBadParameterError("name", t.Name).Expected("unique")
+	}
+	return errors.NewInternalError(err.Error())
+}
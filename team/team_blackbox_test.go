@@ -0,0 +1,219 @@
+package team_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/team"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+func TestMentions(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, []string{"frontend", "ops-team"}, team.Mentions("@frontend please ask @ops-team. Thanks @Frontend!"))
+	assert.Equal(t, []string{"qa.eu"}, team.Mentions("(@qa.eu) mail me@example.com or @@nobody"))
+	assert.Empty(t, team.Mentions("no mentions here"))
+}
+
+func TestRunTeamRepoBBTest(t *testing.T) {
+	suite.Run(t, &teamRepoBBTest{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+type teamRepoBBTest struct {
+	gormsupport.DBTestSuite
+	clean func()
+	repo  team.Repository
+}
+
+func (test *teamRepoBBTest) SetupTest() {
+	test.clean = gormsupport.DeleteCreatedEntities(test.DB)
+	test.repo = team.NewRepository(test.DB)
+}
+
+func (test *teamRepoBBTest) TearDownTest() {
+	test.clean()
+}
+
+// create creates a team with a unique name starting with the given prefix
+func (test *teamRepoBBTest) create(prefix string, parent *team.Team) *team.Team {
+	t := team.Team{Name: prefix + "-" + uuid.NewV4().String()[:8]}
+	if parent != nil {
+		t.ParentID = &parent.ID
+	}
+	require.Nil(test.T(), test.repo.Create(context.Background(), &t))
+	return &t
+}
+
+func (test *teamRepoBBTest) createIdentity() uuid.UUID {
+	identity := account.Identity{FullName: "Team Member"}
+	require.Nil(test.T(), account.NewIdentityRepository(test.DB).Create(context.Background(), &identity))
+	return identity.ID
+}
+
+func (test *teamRepoBBTest) TestCreateAndSave() {
+	ctx := context.Background()
+	parent := test.create("parent", nil)
+	child := test.create("child", parent)
+
+	loaded, err := test.repo.Load(ctx, child.ID)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), parent.ID, *loaded.ParentID)
+	_, err = test.repo.Load(ctx, uuid.NewV4())
+	assert.IsType(test.T(), errors.NotFoundError{}, err)
+
+	// names are unique regardless of case and usable in mentions
+	err = test.repo.Create(ctx, &team.Team{Name: "PARENT" + parent.Name[len("parent"):]})
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+	err = test.repo.Create(ctx, &team.Team{Name: "two words"})
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+	unknown := uuid.NewV4()
+	err = test.repo.Create(ctx, &team.Team{Name: "orphan", ParentID: &unknown})
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+
+	// a team can not be nested in one of its sub-teams
+	parent.ParentID = &child.ID
+	_, err = test.repo.Save(ctx, *parent)
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+
+	loaded.Description = "renamed"
+	saved, err := test.repo.Save(ctx, *loaded)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), 1, saved.Version)
+	_, err = test.repo.Save(ctx, *loaded)
+	assert.IsType(test.T(), errors.VersionConflictError{}, err)
+}
+
+func (test *teamRepoBBTest) TestMembersAndRoles() {
+	ctx := context.Background()
+	parent := test.create("parent", nil)
+	child := test.create("child", parent)
+	member := test.createIdentity()
+	p, err := project.NewRepository(test.DB).Create(ctx, "team-test-"+uuid.NewV4().String())
+	require.Nil(test.T(), err)
+
+	_, err = test.repo.AddMember(ctx, child.ID, member)
+	require.Nil(test.T(), err)
+	_, err = test.repo.AddMember(ctx, child.ID, member)
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+	_, err = test.repo.AddMember(ctx, uuid.NewV4(), member)
+	assert.IsType(test.T(), errors.NotFoundError{}, err)
+	_, err = test.repo.AddMember(ctx, child.ID, uuid.NewV4())
+	assert.IsType(test.T(), errors.NotFoundError{}, err)
+	members, err := test.repo.ListMembers(ctx, parent.ID)
+	require.Nil(test.T(), err)
+	assert.Len(test.T(), members, 0)
+
+	_, err = test.repo.GrantRole(ctx, p.ID, parent.ID, "admin")
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+	_, err = test.repo.GrantRole(ctx, p.ID, parent.ID, project.RoleOwner)
+	require.Nil(test.T(), err)
+	_, err = test.repo.GrantRole(ctx, p.ID, parent.ID, project.RoleViewer)
+	assert.IsType(test.T(), errors.BadParameterError{}, err)
+
+	// roles of the parent team apply to the members of its sub-teams
+	roles, err := test.repo.RolesOf(ctx, p.ID, member)
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), []string{project.RoleOwner}, roles)
+	roles, err = test.repo.RolesOf(ctx, p.ID, test.createIdentity())
+	require.Nil(test.T(), err)
+	assert.Empty(test.T(), roles)
+
+	require.Nil(test.T(), test.repo.RemoveMember(ctx, child.ID, member))
+	assert.IsType(test.T(), errors.NotFoundError{}, test.repo.RemoveMember(ctx, child.ID, member))
+	roles, err = test.repo.RolesOf(ctx, p.ID, member)
+	require.Nil(test.T(), err)
+	assert.Empty(test.T(), roles)
+
+	require.Nil(test.T(), test.repo.RevokeRole(ctx, p.ID, parent.ID))
+	assert.IsType(test.T(), errors.NotFoundError{}, test.repo.RevokeRole(ctx, p.ID, parent.ID))
+}
+
+func (test *teamRepoBBTest) TestAssignedToMemberFilter() {
+	ctx := context.Background()
+	parent := test.create("parent", nil)
+	child := test.create("child", parent)
+	inChild := test.createIdentity()
+	inParent := test.createIdentity()
+	outsider := test.createIdentity()
+	for teamID, identityID := range map[uuid.UUID]uuid.UUID{child.ID: inChild, parent.ID: inParent} {
+		_, err := test.repo.AddMember(ctx, teamID, identityID)
+		require.Nil(test.T(), err)
+	}
+	p, err := project.NewRepository(test.DB).Create(ctx, "team-test-"+uuid.NewV4().String())
+	require.Nil(test.T(), err)
+	wiRepo := workitem.NewWorkItemRepository(test.DB)
+	for _, assignee := range []uuid.UUID{inChild, inParent, outsider} {
+		_, err := wiRepo.Create(ctx, p.ID, workitem.SystemBug, map[string]interface{}{
+			workitem.SystemTitle:    "assigned",
+			workitem.SystemState:    workitem.SystemStateNew,
+			workitem.SystemAssignee: assignee.String(),
+			workitem.SystemTeam:     child.ID.String(),
+		}, "xx")
+		require.Nil(test.T(), err)
+	}
+
+	inProject := criteria.Equals(criteria.Field("Project"), criteria.Literal(p.ID.String()))
+	assignedTo := func(t *team.Team) []string {
+		items, _, err := wiRepo.List(ctx, criteria.And(inProject, criteria.MemberOf(criteria.Field(workitem.SystemAssignee), criteria.Literal(t.ID.String()))), nil, nil)
+		require.Nil(test.T(), err)
+		assignees := []string{}
+		for _, wi := range items {
+			assignees = append(assignees, wi.Fields[workitem.SystemAssignee].(string))
+		}
+		return assignees
+	}
+	assert.Equal(test.T(), []string{inChild.String()}, assignedTo(child))
+	assert.Len(test.T(), assignedTo(parent), 2)
+	assert.NotContains(test.T(), assignedTo(parent), outsider.String())
+
+	// teams with sub-teams or assigned work items can not be deleted
+	assert.IsType(test.T(), errors.BadParameterError{}, test.repo.Delete(ctx, parent.ID))
+	assert.IsType(test.T(), errors.BadParameterError{}, test.repo.Delete(ctx, child.ID))
+	empty := test.create("empty", nil)
+	require.Nil(test.T(), test.repo.Delete(ctx, empty.ID))
+	assert.IsType(test.T(), errors.NotFoundError{}, test.repo.Delete(ctx, empty.ID))
+}
+
+func (test *teamRepoBBTest) TestRecordMentions() {
+	ctx := context.Background()
+	frontend := test.create("frontend", nil)
+	c := comment.Comment{
+		ParentType: comment.ParentTypeWorkItem,
+		ParentID:   "1",
+		Body:       "@" + frontend.Name + " and @unknown-team, please have a look",
+		CreatedBy:  test.createIdentity(),
+	}
+	require.Nil(test.T(), comment.NewCommentRepository(test.DB).Create(ctx, &c))
+
+	mentioned, err := test.repo.RecordMentions(ctx, &c)
+	require.Nil(test.T(), err)
+	require.Len(test.T(), mentioned, 1)
+	assert.Equal(test.T(), frontend.ID, mentioned[0].ID)
+	// recording twice does not duplicate the mention
+	_, err = test.repo.RecordMentions(ctx, &c)
+	require.Nil(test.T(), err)
+
+	comments, err := test.repo.ListMentions(ctx, frontend.ID)
+	require.Nil(test.T(), err)
+	require.Len(test.T(), comments, 1)
+	assert.Equal(test.T(), c.ID, comments[0].ID)
+
+	// editing the body away from the team drops the mention
+	c.Body = "never mind"
+	_, err = test.repo.RecordMentions(ctx, &c)
+	require.Nil(test.T(), err)
+	comments, err = test.repo.ListMentions(ctx, frontend.ID)
+	require.Nil(test.T(), err)
+	assert.Len(test.T(), comments, 0)
+}
@@ -0,0 +1,132 @@
+package main_test
+
+import (
+	"testing"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestTeamREST struct {
+	gormsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	clean func()
+}
+
+func TestRunTeamREST(t *testing.T) {
+	suite.Run(t, &TestTeamREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestTeamREST) SetupTest() {
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = gormsupport.DeleteCreatedEntities(rest.DB)
+}
+
+func (rest *TestTeamREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestTeamREST) service() *goa.Service {
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+	return testsupport.ServiceAsUser("Team-Service", almtoken.NewManager(pub, priv), account.TestIdentity)
+}
+
+func (rest *TestTeamREST) createTeam(svc *goa.Service, name string, parent *uuid.UUID) *app.Team {
+	_, t := test.CreateTeamCreated(rest.T(), svc.Context, svc, NewTeamController(svc, rest.db), &app.CreateTeamPayload{
+		Data: &app.TeamData{
+			Type:       "teams",
+			Attributes: &app.TeamAttributes{Name: &name, Parent: parent},
+		},
+	})
+	return t
+}
+
+func (rest *TestTeamREST) TestTeamsMembersAndRoles() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc := rest.service()
+	ctrl := NewTeamController(svc, rest.db)
+	name := "platform-" + uuid.NewV4().String()[:8]
+	parent := rest.createTeam(svc, name, nil)
+	child := rest.createTeam(svc, "backend-"+uuid.NewV4().String()[:8], parent.Data.ID)
+	assert.Equal(t, *parent.Data.ID, *child.Data.Attributes.Parent)
+
+	test.CreateTeamBadRequest(t, svc.Context, svc, ctrl, &app.CreateTeamPayload{
+		Data: &app.TeamData{Type: "teams", Attributes: &app.TeamAttributes{Name: &name}},
+	})
+	_, shown := test.ShowTeamOK(t, svc.Context, svc, ctrl, child.Data.ID.String())
+	assert.Equal(t, *child.Data.Attributes.Name, *shown.Data.Attributes.Name)
+	test.ShowTeamNotFound(t, svc.Context, svc, ctrl, uuid.NewV4().String())
+
+	// a team can not become a sub-team of its own sub-team
+	test.UpdateTeamBadRequest(t, svc.Context, svc, ctrl, parent.Data.ID.String(), &app.UpdateTeamPayload{
+		Data: &app.TeamData{
+			Type:       "teams",
+			Attributes: &app.TeamAttributes{Parent: child.Data.ID, Version: parent.Data.Attributes.Version},
+		},
+	})
+
+	membersCtrl := NewTeamMembersController(svc, rest.db)
+	test.CreateTeamMembersCreated(t, svc.Context, svc, membersCtrl, child.Data.ID.String(), &app.CreateTeamMemberPayload{
+		Data: &app.TeamMemberData{
+			Type:       "team-members",
+			Attributes: &app.TeamMemberAttributes{Identity: account.TestIdentity.ID},
+		},
+	})
+	_, members := test.ListTeamMembersOK(t, svc.Context, svc, membersCtrl, child.Data.ID.String())
+	require.Len(t, members.Data, 1)
+	assert.Equal(t, account.TestIdentity.ID, members.Data[0].Attributes.Identity)
+
+	projectName := "TestTeamREST-" + uuid.NewV4().String()
+	_, p := test.CreateProjectCreated(t, svc.Context, svc, NewProjectController(svc, rest.db), createProjectPayload(&projectName, nil))
+	projectTeamsCtrl := NewProjectTeamsController(svc, rest.db)
+	test.CreateProjectTeamsCreated(t, svc.Context, svc, projectTeamsCtrl, p.Data.ID.String(), &app.CreateProjectTeamPayload{
+		Data: &app.ProjectTeamData{
+			Type:       "project-teams",
+			Attributes: &app.ProjectTeamAttributes{Team: *parent.Data.ID, Role: project.RoleViewer},
+		},
+	})
+	_, roles := test.ListProjectTeamsOK(t, svc.Context, svc, projectTeamsCtrl, p.Data.ID.String())
+	require.Len(t, roles.Data, 1)
+	assert.Equal(t, project.RoleViewer, roles.Data[0].Attributes.Role)
+
+	// teams with sub-teams can not be deleted
+	test.DeleteTeamBadRequest(t, svc.Context, svc, ctrl, parent.Data.ID.String())
+	test.DeleteProjectTeamsOK(t, svc.Context, svc, projectTeamsCtrl, p.Data.ID.String(), *parent.Data.ID)
+	test.DeleteTeamMembersOK(t, svc.Context, svc, membersCtrl, child.Data.ID.String(), account.TestIdentity.ID)
+	test.DeleteTeamOK(t, svc.Context, svc, ctrl, child.Data.ID.String())
+	test.DeleteTeamOK(t, svc.Context, svc, ctrl, parent.Data.ID.String())
+}
+
+func (rest *TestTeamREST) TestMentions() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc := rest.service()
+	frontend := rest.createTeam(svc, "frontend-"+uuid.NewV4().String()[:8], nil)
+	wiid, err := createWorkItem(rest.db)
+	require.Nil(t, err)
+	_, c := test.CreateWorkItemCommentsOK(t, svc.Context, svc, NewWorkItemCommentsController(svc, rest.db), wiid,
+		createComment("@"+*frontend.Data.Attributes.Name+", can you have a look?"))
+	test.CreateWorkItemCommentsOK(t, svc.Context, svc, NewWorkItemCommentsController(svc, rest.db), wiid, createComment("no mention"))
+
+	_, mentions := test.MentionsTeamOK(t, svc.Context, svc, NewTeamController(svc, rest.db), frontend.Data.ID.String())
+	require.Len(t, mentions.Data, 1)
+	assert.Equal(t, *c.Data.ID, *mentions.Data[0].ID)
+}
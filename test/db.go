@@ -1,11 +1,19 @@
 package test
 
 import (
+	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/area"
 	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/milestone"
 	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/team"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
 )
 
 func NewMockDB() *MockDB {
@@ -27,9 +35,29 @@ func (db *MockDB) WorkItemTypes() workitem.WorkItemTypeRepository {
 }
 
 func (db *MockDB) Projects() project.Repository {
+	return systemProjectRepository{}
+}
+
+func (db *MockDB) ProjectMemberships() project.MembershipRepository {
+	return noMembershipRepository{}
+}
+
+func (db *MockDB) Iterations() iteration.Repository {
+	return nil
+}
+
+func (db *MockDB) Areas() area.Repository {
 	return nil
 }
 
+func (db *MockDB) Milestones() milestone.Repository {
+	return nil
+}
+
+func (db *MockDB) Teams() team.Repository {
+	return noTeamRepository{}
+}
+
 func (db *MockDB) Trackers() application.TrackerRepository {
 	return nil
 }
@@ -42,6 +70,12 @@ func (db *MockDB) SearchItems() application.SearchRepository {
 func (db *MockDB) Identities() application.IdentityRepository {
 	return nil
 }
+func (db *MockDB) Users() account.UserRepository {
+	return nil
+}
+func (db *MockDB) AccessTokens() account.AccessTokenRepository {
+	return nil
+}
 func (db *MockDB) WorkItemLinkCategories() link.WorkItemLinkCategoryRepository {
 	return nil
 }
@@ -65,3 +99,31 @@ func (db *MockDB) Rollback() error {
 func (db *MockDB) BeginTransaction() (application.Transaction, error) {
 	return db, nil
 }
+
+// systemProjectRepository lists the system project only, so that the work
+// items of the mock repository are readable by every identity
+type systemProjectRepository struct {
+	project.Repository
+}
+
+func (r systemProjectRepository) List(ctx context.Context, start *int, length *int) ([]project.Project, uint64, error) {
+	return []project.Project{{ID: project.SystemProject, Name: "system"}}, 1, nil
+}
+
+// noMembershipRepository knows no memberships
+type noMembershipRepository struct {
+	project.MembershipRepository
+}
+
+func (r noMembershipRepository) Load(ctx context.Context, projectID, identityID uuid.UUID) (*project.Membership, error) {
+	return nil, errors.NewNotFoundError("project membership", identityID.String())
+}
+
+// noTeamRepository knows no teams
+type noTeamRepository struct {
+	team.Repository
+}
+
+func (r noTeamRepository) RolesOf(ctx context.Context, projectID, identityID uuid.UUID) ([]string, error) {
+	return []string{}, nil
+}
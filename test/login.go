@@ -1,6 +1,8 @@
 package test
 
 import (
+	"time"
+
 	"golang.org/x/net/context"
 
 	"github.com/almighty/almighty-core/account"
@@ -9,16 +11,22 @@ import (
 	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/goadesign/goa"
 	goajwt "github.com/goadesign/goa/middleware/security/jwt"
+	uuid "github.com/satori/go.uuid"
 )
 
 // WithIdentity fills the context with token
 // Token is filled using input Identity object
 func WithIdentity(ctx context.Context, ident account.Identity) context.Context {
-	token := jwt.New(jwt.SigningMethodRS256)
-	token.Claims.(jwt.MapClaims)["uuid"] = ident.ID.String()
-	token.Claims.(jwt.MapClaims)["fullName"] = ident.FullName
-	token.Claims.(jwt.MapClaims)["imageURL"] = ident.ImageURL
-	return goajwt.WithJWT(ctx, token)
+	tk := jwt.New(jwt.SigningMethodRS256)
+	tk.Claims.(jwt.MapClaims)["uuid"] = ident.ID.String()
+	tk.Claims.(jwt.MapClaims)["fullName"] = ident.FullName
+	tk.Claims.(jwt.MapClaims)["imageURL"] = ident.ImageURL
+	tk.Claims.(jwt.MapClaims)["jti"] = uuid.NewV4().String()
+	tk.Claims.(jwt.MapClaims)["token_type"] = token.TypeLoginToken
+	tk.Claims.(jwt.MapClaims)["aud"] = token.DefaultAudience
+	tk.Claims.(jwt.MapClaims)["iat"] = time.Now().Unix()
+	tk.Claims.(jwt.MapClaims)["exp"] = time.Now().Add(time.Hour).Unix()
+	return goajwt.WithJWT(ctx, tk)
 }
 
 // ServiceAsUser creates a new service and fill the context with input Identity
@@ -0,0 +1,63 @@
+package token
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// PublicKey is a key that tokens can be verified with. The KeyID is the
+// "kid" header of the tokens signed with the matching private key.
+type PublicKey struct {
+	KeyID string
+	Key   *rsa.PublicKey
+}
+
+// Modulus returns the base64url encoded modulus of the key as used in a JSON
+// Web Key (RFC 7518, section 6.3.1)
+func (k PublicKey) Modulus() string {
+	return base64.RawURLEncoding.EncodeToString(k.Key.N.Bytes())
+}
+
+// Exponent returns the base64url encoded exponent of the key as used in a
+// JSON Web Key (RFC 7518, section 6.3.1)
+func (k PublicKey) Exponent() string {
+	return base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.Key.E)).Bytes())
+}
+
+// KeyID returns the JSON Web Key thumbprint (RFC 7638) of the key. Being
+// derived from the key itself, it does not need to be configured and stays
+// the same across all instances of the service.
+func KeyID(key *rsa.PublicKey) string {
+	k := PublicKey{Key: key}
+	// the members must be in lexicographic order
+	thumbprint := fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, k.Exponent(), k.Modulus())
+	sum := sha256.Sum256([]byte(thumbprint))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// ParsePublicKeys parses any number of concatenated PEM encoded public keys
+func ParsePublicKeys(keys []byte) ([]*rsa.PublicKey, error) {
+	var res []*rsa.PublicKey
+	for {
+		var block *pem.Block
+		block, keys = pem.Decode(keys)
+		if block == nil {
+			return res, nil
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("Key is not a RSA public key")
+		}
+		res = append(res, rsaKey)
+	}
+}
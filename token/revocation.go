@@ -0,0 +1,123 @@
+package token
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// RevocationList keeps the IDs of tokens and login sessions that must no
+// longer be accepted even though their signature is valid.
+type RevocationList interface {
+	// Revoke marks the ID as revoked until the given time, after which all
+	// tokens carrying it have expired anyway.
+	Revoke(ctx context.Context, id uuid.UUID, until time.Time) error
+	// IsRevoked returns true if any of the IDs is revoked.
+	IsRevoked(ctx context.Context, ids ...uuid.UUID) (bool, error)
+	// Consume revokes the ID until the given time unless it is revoked
+	// already and returns whether it was revoked by this call. Of concurrent
+	// calls with the same ID only one consumes it, which makes tokens
+	// carrying it usable once.
+	Consume(ctx context.Context, id uuid.UUID, until time.Time) (bool, error)
+}
+
+// NewMemoryRevocationList creates a RevocationList that lives in memory of
+// the current process only.
+func NewMemoryRevocationList() RevocationList {
+	return &memoryRevocationList{revoked: map[uuid.UUID]time.Time{}}
+}
+
+type memoryRevocationList struct {
+	lock    sync.RWMutex
+	revoked map[uuid.UUID]time.Time
+}
+
+func (l *memoryRevocationList) Revoke(ctx context.Context, id uuid.UUID, until time.Time) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := time.Now()
+	for revokedID, expiresAt := range l.revoked {
+		if expiresAt.Before(now) {
+			delete(l.revoked, revokedID)
+		}
+	}
+	if until.After(l.revoked[id]) {
+		l.revoked[id] = until
+	}
+	return nil
+}
+
+func (l *memoryRevocationList) Consume(ctx context.Context, id uuid.UUID, until time.Time) (bool, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if expiresAt, ok := l.revoked[id]; ok && expiresAt.After(time.Now()) {
+		return false, nil
+	}
+	l.revoked[id] = until
+	return true, nil
+}
+
+func (l *memoryRevocationList) IsRevoked(ctx context.Context, ids ...uuid.UUID) (bool, error) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	now := time.Now()
+	for _, id := range ids {
+		if expiresAt, ok := l.revoked[id]; ok && expiresAt.After(now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// NewGormRevocationList creates a RevocationList that is stored in the
+// database and thus shared by all instances of the service.
+func NewGormRevocationList(db *gorm.DB) RevocationList {
+	return &GormRevocationList{db: db}
+}
+
+// GormRevocationList is the implementation of RevocationList on top of the
+// revoked_tokens table.
+type GormRevocationList struct {
+	db *gorm.DB
+}
+
+// Revoke adds the ID to the list or extends its expiry. Entries that have
+// expired get purged along the way.
+func (l *GormRevocationList) Revoke(ctx context.Context, id uuid.UUID, until time.Time) error {
+	err := l.db.Exec(`INSERT INTO revoked_tokens (created_at, id, expires_at) VALUES (now(), ?, ?)
+		ON CONFLICT (id) DO UPDATE SET expires_at = GREATEST(revoked_tokens.expires_at, EXCLUDED.expires_at)`, id, until).Error
+	if err != nil {
+		return err
+	}
+	return l.db.Exec("DELETE FROM revoked_tokens WHERE expires_at < now()").Error
+}
+
+// Consume adds the ID to the list unless it is on it already. Expired entries
+// get purged first so that they do not count.
+func (l *GormRevocationList) Consume(ctx context.Context, id uuid.UUID, until time.Time) (bool, error) {
+	if err := l.db.Exec("DELETE FROM revoked_tokens WHERE expires_at < now()").Error; err != nil {
+		return false, err
+	}
+	tx := l.db.Exec(`INSERT INTO revoked_tokens (created_at, id, expires_at) VALUES (now(), ?, ?)
+		ON CONFLICT (id) DO NOTHING`, id, until)
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+	return tx.RowsAffected == 1, nil
+}
+
+// IsRevoked returns true if any of the IDs is on the list and not expired yet.
+func (l *GormRevocationList) IsRevoked(ctx context.Context, ids ...uuid.UUID) (bool, error) {
+	if len(ids) == 0 {
+		return false, nil
+	}
+	var count int
+	err := l.db.Table("revoked_tokens").Where("id IN (?) AND expires_at > now()", ids).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
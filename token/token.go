@@ -3,6 +3,8 @@ package token
 import (
 	"crypto/rsa"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/almighty/almighty-core/account"
 	jwt "github.com/dgrijalva/jwt-go"
@@ -11,31 +13,152 @@ import (
 	"golang.org/x/net/context"
 )
 
+const (
+	// TypeLoginToken is the "token_type" claim of the short-lived tokens
+	// generated on login
+	TypeLoginToken = "login_token"
+	// TypeRefreshToken is the "token_type" claim of tokens that can only be
+	// exchanged once for a new pair of login and refresh token
+	TypeRefreshToken = "refresh_token"
+	// TypeAccessToken is the "token_type" claim of tokens generated for an
+	// account.AccessToken
+	TypeAccessToken = "access_token"
+)
+
+// DefaultAudience is the "aud" claim of the tokens of a Manager created with
+// NewManager
+const DefaultAudience = "almighty-core"
+
 // Manager generate and find auth token information
 type Manager interface {
 	Generate(account.Identity) (string, error)
+	GeneratePair(account.Identity) (string, string, error)
+	GenerateAccessToken(account.Identity, account.AccessToken) (string, error)
 	Extract(string) (*account.Identity, error)
 	Locate(ctx context.Context) (uuid.UUID, error)
+	Validate(ctx context.Context) error
+	Refresh(ctx context.Context, refreshToken string) (uuid.UUID, error)
+	Revoke(ctx context.Context, token *jwt.Token) error
+	PublicKeys() []PublicKey
+}
+
+// Config holds the settings of a token Manager
+type Config struct {
+	// Audience is the "aud" claim of generated tokens. Tokens for any other
+	// audience are rejected.
+	Audience string
+	// LoginTokenLifetime is how long a login token is valid
+	LoginTokenLifetime time.Duration
+	// RefreshTokenLifetime is how long a refresh token is valid
+	RefreshTokenLifetime time.Duration
+	// Revocations keeps the revoked tokens and login sessions
+	Revocations RevocationList
+	// RetiredPublicKeys are the public keys of former signing keys. Tokens
+	// signed with them are accepted until they expire, so that the signing
+	// key can be replaced without logging everybody out.
+	RetiredPublicKeys []*rsa.PublicKey
 }
 
 type tokenManager struct {
-	publicKey  *rsa.PublicKey
+	// publicKeys holds the key matching the privateKey first
+	publicKeys []PublicKey
 	privateKey *rsa.PrivateKey
+	config     Config
 }
 
-// NewManager returns a new token Manager for handling creation of tokens
+// NewManager returns a new token Manager for handling creation of tokens. The
+// login tokens are valid for an hour, the refresh tokens for 30 days and
+// revocations are kept in memory.
 func NewManager(publicKey *rsa.PublicKey, privateKey *rsa.PrivateKey) Manager {
+	return NewManagerWithConfig(publicKey, privateKey, Config{
+		Audience:             DefaultAudience,
+		LoginTokenLifetime:   time.Hour,
+		RefreshTokenLifetime: 30 * 24 * time.Hour,
+		Revocations:          NewMemoryRevocationList(),
+	})
+}
+
+// NewManagerWithConfig returns a new token Manager using the given settings
+func NewManagerWithConfig(publicKey *rsa.PublicKey, privateKey *rsa.PrivateKey, config Config) Manager {
+	publicKeys := []PublicKey{{KeyID: KeyID(publicKey), Key: publicKey}}
+	for _, key := range config.RetiredPublicKeys {
+		publicKeys = append(publicKeys, PublicKey{KeyID: KeyID(key), Key: key})
+	}
 	return &tokenManager{
-		publicKey:  publicKey,
+		publicKeys: publicKeys,
 		privateKey: privateKey,
+		config:     config,
 	}
 }
 
-func (mgm tokenManager) Generate(ident account.Identity) (string, error) {
+// PublicKeys returns the keys that tokens are verified with, starting with
+// the one of the current signing key
+func (mgm tokenManager) PublicKeys() []PublicKey {
+	return mgm.publicKeys
+}
+
+// newToken creates an unsigned token whose "kid" header identifies the
+// current signing key
+func (mgm tokenManager) newToken() *jwt.Token {
 	token := jwt.New(jwt.SigningMethodRS256)
-	token.Claims.(jwt.MapClaims)["uuid"] = ident.ID.String()
-	token.Claims.(jwt.MapClaims)["fullName"] = ident.FullName
-	token.Claims.(jwt.MapClaims)["imageURL"] = ident.ImageURL
+	token.Header["kid"] = mgm.publicKeys[0].KeyID
+	return token
+}
+
+// keyFunc returns the public key identified by the "kid" header of the token.
+// Tokens without "kid" were signed before key rotation was introduced and
+// thus with the current key.
+func (mgm tokenManager) keyFunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+		return nil, fmt.Errorf("Unexpected signing method %v", token.Header["alg"])
+	}
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return mgm.publicKeys[0].Key, nil
+	}
+	for _, key := range mgm.publicKeys {
+		if key.KeyID == kid {
+			return key.Key, nil
+		}
+	}
+	return nil, fmt.Errorf("Unknown signing key %s", kid)
+}
+
+// Generate signs a login token of a new login session
+func (mgm tokenManager) Generate(ident account.Identity) (string, error) {
+	return mgm.generate(ident, TypeLoginToken, uuid.NewV4(), mgm.config.LoginTokenLifetime)
+}
+
+// GeneratePair signs a login token along with the refresh token of the same,
+// new login session
+func (mgm tokenManager) GeneratePair(ident account.Identity) (string, string, error) {
+	sessionID := uuid.NewV4()
+	loginToken, err := mgm.generate(ident, TypeLoginToken, sessionID, mgm.config.LoginTokenLifetime)
+	if err != nil {
+		return "", "", err
+	}
+	refreshToken, err := mgm.generate(ident, TypeRefreshToken, sessionID, mgm.config.RefreshTokenLifetime)
+	if err != nil {
+		return "", "", err
+	}
+	return loginToken, refreshToken, nil
+}
+
+// generate signs a token of the given type for the login session. Every token
+// has its own "jti" so that it can be revoked on its own.
+func (mgm tokenManager) generate(ident account.Identity, tokenType string, sessionID uuid.UUID, lifetime time.Duration) (string, error) {
+	now := time.Now()
+	token := mgm.newToken()
+	claims := token.Claims.(jwt.MapClaims)
+	claims["uuid"] = ident.ID.String()
+	claims["fullName"] = ident.FullName
+	claims["imageURL"] = ident.ImageURL
+	claims["jti"] = uuid.NewV4().String()
+	claims["sid"] = sessionID.String()
+	claims["token_type"] = tokenType
+	claims["aud"] = mgm.config.Audience
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(lifetime).Unix()
 
 	tokenStr, err := token.SignedString(mgm.privateKey)
 	if err != nil {
@@ -44,16 +167,33 @@ func (mgm tokenManager) Generate(ident account.Identity) (string, error) {
 	return tokenStr, nil
 }
 
+// GenerateAccessToken signs a token for the given access token of the
+// identity. The token carries the ID of the access token as "jti" so that it
+// can be checked for revocation and scopes.
+func (mgm tokenManager) GenerateAccessToken(ident account.Identity, accessToken account.AccessToken) (string, error) {
+	token := mgm.newToken()
+	claims := token.Claims.(jwt.MapClaims)
+	claims["uuid"] = ident.ID.String()
+	claims["fullName"] = ident.FullName
+	claims["imageURL"] = ident.ImageURL
+	claims["jti"] = accessToken.ID.String()
+	claims["token_type"] = TypeAccessToken
+	claims["scope"] = accessToken.Scopes
+	claims["aud"] = mgm.config.Audience
+	claims["iat"] = time.Now().Unix()
+	if accessToken.ExpiresAt != nil {
+		claims["exp"] = accessToken.ExpiresAt.Unix()
+	}
+	return token.SignedString(mgm.privateKey)
+}
+
 func (mgm tokenManager) Extract(tokenString string) (*account.Identity, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return mgm.publicKey, nil
-	})
+	token, err := mgm.parse(context.Background(), tokenString)
 	if err != nil {
 		return nil, err
 	}
-
-	if !token.Valid {
-		return nil, errors.New("Token not valid")
+	if token.Claims.(jwt.MapClaims)["token_type"] == TypeRefreshToken {
+		return nil, errors.New("Refresh token can only be used to refresh")
 	}
 
 	claimedUUID := token.Claims.(jwt.MapClaims)["uuid"]
@@ -75,11 +215,19 @@ func (mgm tokenManager) Extract(tokenString string) (*account.Identity, error) {
 	return &ident, nil
 }
 
+// Locate returns the ID of the identity of the token in the context. The
+// token must not be a refresh token and must not have been revoked.
 func (mgm tokenManager) Locate(ctx context.Context) (uuid.UUID, error) {
 	token := goajwt.ContextJWT(ctx)
 	if token == nil {
 		return uuid.UUID{}, errors.New("Missing token") // TODO, make specific tokenErrors
 	}
+	if token.Claims.(jwt.MapClaims)["token_type"] == TypeRefreshToken {
+		return uuid.UUID{}, errors.New("Refresh token can only be used to refresh")
+	}
+	if err := mgm.checkRevoked(ctx, token.Claims.(jwt.MapClaims)); err != nil {
+		return uuid.UUID{}, err
+	}
 	id := token.Claims.(jwt.MapClaims)["uuid"]
 	if id == nil {
 		return uuid.UUID{}, errors.New("Missing uuid")
@@ -91,6 +239,159 @@ func (mgm tokenManager) Locate(ctx context.Context) (uuid.UUID, error) {
 	return idTyped, nil
 }
 
+// Validate checks that the token in the context, whose signature the JWT
+// middleware verified already, was issued for the audience of the Manager
+// and has an expiry. Only access tokens may be valid until revoked.
+func (mgm tokenManager) Validate(ctx context.Context) error {
+	token := goajwt.ContextJWT(ctx)
+	if token == nil {
+		return errors.New("Missing token")
+	}
+	return mgm.checkClaims(token.Claims.(jwt.MapClaims))
+}
+
+// Refresh checks the given refresh token and returns the ID of the identity
+// it was issued for. A refresh token can only be used once.
+func (mgm tokenManager) Refresh(ctx context.Context, refreshToken string) (uuid.UUID, error) {
+	token, err := mgm.parse(ctx, refreshToken)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	if claims["token_type"] != TypeRefreshToken {
+		return uuid.Nil, errors.New("Not a refresh token")
+	}
+	claimedUUID, _ := claims["uuid"].(string)
+	id, err := uuid.FromString(claimedUUID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	tokenID, err := claimedID(claims, "jti")
+	if err != nil {
+		return uuid.Nil, err
+	}
+	// the check in parse alone would let concurrent refreshes with the same token pass
+	consumed, err := mgm.config.Revocations.Consume(ctx, tokenID, expiresAt(claims, time.Now().Add(mgm.config.RefreshTokenLifetime)))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !consumed {
+		return uuid.Nil, errors.New("Refresh token has been used already")
+	}
+	return id, nil
+}
+
+// Revoke makes the token and all other tokens of its login session, in
+// particular the refresh token, unusable.
+func (mgm tokenManager) Revoke(ctx context.Context, token *jwt.Token) error {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return errors.New("Unexpected token claims")
+	}
+	tokenID, err := claimedID(claims, "jti")
+	if err != nil {
+		return err
+	}
+	sessionEnd := time.Now().Add(mgm.config.RefreshTokenLifetime)
+	if err := mgm.config.Revocations.Revoke(ctx, tokenID, expiresAt(claims, sessionEnd)); err != nil {
+		return err
+	}
+	if sessionID, err := claimedID(claims, "sid"); err == nil {
+		return mgm.config.Revocations.Revoke(ctx, sessionID, sessionEnd)
+	}
+	return nil
+}
+
+// parse verifies the signature and the claims of the given token
+func (mgm tokenManager) parse(ctx context.Context, tokenString string) (*jwt.Token, error) {
+	token, err := jwt.Parse(tokenString, mgm.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, errors.New("Token not valid")
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	if err := mgm.checkClaims(claims); err != nil {
+		return nil, err
+	}
+	if err := mgm.checkRevoked(ctx, claims); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// checkClaims returns an error unless the token was issued for the audience
+// of the Manager and expires, unless it is an access token
+func (mgm tokenManager) checkClaims(claims jwt.MapClaims) error {
+	if !claims.VerifyAudience(mgm.config.Audience, true) {
+		return errors.New("Token not issued for this service")
+	}
+	if _, ok := claims["exp"]; !ok && claims["token_type"] != TypeAccessToken {
+		return errors.New("Token does not expire")
+	}
+	return nil
+}
+
+// checkRevoked returns an error if the token or its login session got revoked
+func (mgm tokenManager) checkRevoked(ctx context.Context, claims jwt.MapClaims) error {
+	var ids []uuid.UUID
+	for _, claim := range []string{"jti", "sid"} {
+		if id, err := claimedID(claims, claim); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	revoked, err := mgm.config.Revocations.IsRevoked(ctx, ids...)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return errors.New("Token has been revoked")
+	}
+	return nil
+}
+
+// claimedID returns the UUID held by the given claim
+func claimedID(claims jwt.MapClaims, claim string) (uuid.UUID, error) {
+	s, ok := claims[claim].(string)
+	if !ok {
+		return uuid.Nil, errors.New("Missing " + claim)
+	}
+	return uuid.FromString(s)
+}
+
+// expiresAt returns the time of the "exp" claim or the given fallback if
+// there is none
+func expiresAt(claims jwt.MapClaims, fallback time.Time) time.Time {
+	switch exp := claims["exp"].(type) {
+	case float64:
+		return time.Unix(int64(exp), 0)
+	case int64:
+		return time.Unix(exp, 0)
+	}
+	return fallback
+}
+
+// AccessTokenID returns the ID of the account.AccessToken the token in the
+// context was generated for. It returns false for other tokens.
+func AccessTokenID(ctx context.Context) (uuid.UUID, bool) {
+	token := goajwt.ContextJWT(ctx)
+	if token == nil {
+		return uuid.Nil, false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["token_type"] != TypeAccessToken {
+		return uuid.Nil, false
+	}
+	jti, _ := claims["jti"].(string)
+	id, err := uuid.FromString(jti)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
 // ParsePublicKey parses a []byte representation of a public key into a rsa.PublicKey instance
 func ParsePublicKey(key []byte) (*rsa.PublicKey, error) {
 	return jwt.ParseRSAPublicKeyFromPEM(key)
@@ -1,6 +1,8 @@
 package token_test
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
 	"testing"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	goajwt "github.com/goadesign/goa/middleware/security/jwt"
 	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGenerateToken(t *testing.T) {
@@ -134,6 +137,260 @@ func TestLocateInvalidUUIDInTokenInContext(t *testing.T) {
 	}
 }
 
+func TestGenerateAccessToken(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	manager := createManager(t)
+	ident := account.Identity{ID: uuid.NewV4(), FullName: "CI Bot"}
+	accessToken := account.AccessToken{ID: uuid.NewV4(), IdentityID: ident.ID, Scopes: "read.workitem"}
+
+	tokenString, err := manager.GenerateAccessToken(ident, accessToken)
+	assert.Nil(t, err)
+	extracted, err := manager.Extract(tokenString)
+	assert.Nil(t, err)
+	assert.Equal(t, ident.ID, extracted.ID)
+
+	publicKey, _ := token.ParsePublicKey([]byte(token.RSAPublicKey))
+	tk, err := jwt.Parse(tokenString, func(*jwt.Token) (interface{}, error) { return publicKey, nil })
+	assert.Nil(t, err)
+	id, ok := token.AccessTokenID(goajwt.WithJWT(context.Background(), tk))
+	assert.True(t, ok)
+	assert.Equal(t, accessToken.ID, id)
+
+	// login tokens are no access tokens
+	tokenString, err = manager.Generate(ident)
+	assert.Nil(t, err)
+	tk, err = jwt.Parse(tokenString, func(*jwt.Token) (interface{}, error) { return publicKey, nil })
+	assert.Nil(t, err)
+	_, ok = token.AccessTokenID(goajwt.WithJWT(context.Background(), tk))
+	assert.False(t, ok)
+
+	expired := time.Now().Add(-time.Minute)
+	accessToken.ExpiresAt = &expired
+	tokenString, err = manager.GenerateAccessToken(ident, accessToken)
+	assert.Nil(t, err)
+	_, err = manager.Extract(tokenString)
+	assert.NotNil(t, err)
+}
+
+func TestGenerateTokenClaims(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	manager := createManager(t)
+	tokenString, err := manager.Generate(account.Identity{ID: uuid.NewV4(), FullName: "Mr Test Case"})
+	require.Nil(t, err)
+
+	claims := parseClaims(t, tokenString)
+	assert.Equal(t, token.DefaultAudience, claims["aud"])
+	assert.Equal(t, token.TypeLoginToken, claims["token_type"])
+	assert.NotEmpty(t, claims["jti"])
+	assert.NotEmpty(t, claims["sid"])
+	assert.InDelta(t, time.Now().Unix(), claims["iat"], 5)
+	assert.InDelta(t, time.Now().Add(time.Hour).Unix(), claims["exp"], 5)
+}
+
+func TestExtractRejectsUnexpectedClaims(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	manager := createManager(t)
+	privateKey, _ := token.ParsePrivateKey([]byte(token.RSAPrivateKey))
+	sign := func(claims jwt.MapClaims) string {
+		tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		tokenStr, err := tok.SignedString(privateKey)
+		require.Nil(t, err)
+		return tokenStr
+	}
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"uuid":     uuid.NewV4().String(),
+			"fullName": "Mr Test Case",
+			"imageURL": "",
+			"aud":      token.DefaultAudience,
+			"exp":      time.Now().Add(time.Minute).Unix(),
+		}
+	}
+
+	_, err := manager.Extract(sign(valid()))
+	assert.Nil(t, err)
+
+	claims := valid()
+	delete(claims, "exp")
+	_, err = manager.Extract(sign(claims))
+	assert.NotNil(t, err, "tokens without expiry must be rejected")
+
+	claims = valid()
+	claims["aud"] = "someone-else"
+	_, err = manager.Extract(sign(claims))
+	assert.NotNil(t, err, "tokens for other audiences must be rejected")
+}
+
+func TestRefreshToken(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	manager := createManager(t)
+	ident := account.Identity{ID: uuid.NewV4(), FullName: "Mr Test Case"}
+	loginToken, refreshToken, err := manager.GeneratePair(ident)
+	require.Nil(t, err)
+	assert.Equal(t, parseClaims(t, loginToken)["sid"], parseClaims(t, refreshToken)["sid"])
+
+	// refresh tokens are no login tokens and vice versa
+	_, err = manager.Extract(refreshToken)
+	assert.NotNil(t, err)
+	_, err = manager.Locate(goajwt.WithJWT(context.Background(), parseToken(t, refreshToken)))
+	assert.NotNil(t, err)
+	_, err = manager.Refresh(context.Background(), loginToken)
+	assert.NotNil(t, err)
+
+	id, err := manager.Refresh(context.Background(), refreshToken)
+	require.Nil(t, err)
+	assert.Equal(t, ident.ID, id)
+
+	// a refresh token can only be used once
+	_, err = manager.Refresh(context.Background(), refreshToken)
+	assert.NotNil(t, err)
+
+	// even by concurrent refreshes
+	_, refreshToken, err = manager.GeneratePair(ident)
+	require.Nil(t, err)
+	results := make(chan error, 10)
+	for i := 0; i < cap(results); i++ {
+		go func() {
+			_, err := manager.Refresh(context.Background(), refreshToken)
+			results <- err
+		}()
+	}
+	succeeded := 0
+	for i := 0; i < cap(results); i++ {
+		if <-results == nil {
+			succeeded++
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+}
+
+func TestRevokeToken(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	manager := createManager(t)
+	ident := account.Identity{ID: uuid.NewV4(), FullName: "Mr Test Case"}
+	loginToken, refreshToken, err := manager.GeneratePair(ident)
+	require.Nil(t, err)
+	otherToken, err := manager.Generate(ident)
+	require.Nil(t, err)
+
+	ctx := goajwt.WithJWT(context.Background(), parseToken(t, loginToken))
+	require.Nil(t, manager.Validate(ctx))
+	_, err = manager.Locate(ctx)
+	require.Nil(t, err)
+
+	require.Nil(t, manager.Revoke(ctx, goajwt.ContextJWT(ctx)))
+	_, err = manager.Locate(ctx)
+	assert.NotNil(t, err)
+	_, err = manager.Extract(loginToken)
+	assert.NotNil(t, err)
+	// the refresh token of the same login goes with it
+	_, err = manager.Refresh(context.Background(), refreshToken)
+	assert.NotNil(t, err)
+	// other logins of the identity are not affected
+	_, err = manager.Extract(otherToken)
+	assert.Nil(t, err)
+}
+
+func TestMemoryRevocationList(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	list := token.NewMemoryRevocationList()
+	revoked, expired := uuid.NewV4(), uuid.NewV4()
+	require.Nil(t, list.Revoke(context.Background(), revoked, time.Now().Add(time.Minute)))
+	require.Nil(t, list.Revoke(context.Background(), expired, time.Now().Add(-time.Minute)))
+
+	isRevoked, err := list.IsRevoked(context.Background(), uuid.NewV4(), revoked)
+	require.Nil(t, err)
+	assert.True(t, isRevoked)
+	isRevoked, err = list.IsRevoked(context.Background(), expired)
+	require.Nil(t, err)
+	assert.False(t, isRevoked)
+	isRevoked, err = list.IsRevoked(context.Background())
+	require.Nil(t, err)
+	assert.False(t, isRevoked)
+
+	// an ID is consumed once, expired entries do not count
+	for _, id := range []uuid.UUID{uuid.NewV4(), expired} {
+		consumed, err := list.Consume(context.Background(), id, time.Now().Add(time.Minute))
+		require.Nil(t, err)
+		assert.True(t, consumed)
+		consumed, err = list.Consume(context.Background(), id, time.Now().Add(time.Minute))
+		require.Nil(t, err)
+		assert.False(t, consumed)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	oldPublicKey, _ := token.ParsePublicKey([]byte(token.RSAPublicKey))
+	oldPrivateKey, _ := token.ParsePrivateKey([]byte(token.RSAPrivateKey))
+	newPrivateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+	ident := account.Identity{ID: uuid.NewV4(), FullName: "Mr Test Case"}
+
+	oldManager := token.NewManager(oldPublicKey, oldPrivateKey)
+	oldToken, err := oldManager.Generate(ident)
+	require.Nil(t, err)
+	assert.Equal(t, token.KeyID(oldPublicKey), parseToken(t, oldToken).Header["kid"])
+
+	config := token.Config{
+		Audience:             token.DefaultAudience,
+		LoginTokenLifetime:   time.Hour,
+		RefreshTokenLifetime: time.Hour,
+		Revocations:          token.NewMemoryRevocationList(),
+	}
+	rotated := config
+	rotated.RetiredPublicKeys = []*rsa.PublicKey{oldPublicKey}
+	newManager := token.NewManagerWithConfig(&newPrivateKey.PublicKey, newPrivateKey, rotated)
+	require.Len(t, newManager.PublicKeys(), 2)
+	assert.Equal(t, token.KeyID(&newPrivateKey.PublicKey), newManager.PublicKeys()[0].KeyID)
+
+	// tokens signed with the retired key are still accepted
+	ident2, err := newManager.Extract(oldToken)
+	require.Nil(t, err)
+	assert.Equal(t, ident.ID, ident2.ID)
+	newToken, err := newManager.Generate(ident)
+	require.Nil(t, err)
+	_, err = newManager.Extract(newToken)
+	assert.Nil(t, err)
+
+	// until the retired key is dropped
+	_, err = token.NewManagerWithConfig(&newPrivateKey.PublicKey, newPrivateKey, config).Extract(oldToken)
+	assert.NotNil(t, err)
+	_, err = oldManager.Extract(newToken)
+	assert.NotNil(t, err)
+}
+
+func TestParsePublicKeys(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	keys, err := token.ParsePublicKeys([]byte(""))
+	require.Nil(t, err)
+	assert.Empty(t, keys)
+
+	keys, err = token.ParsePublicKeys([]byte(token.RSAPublicKey + "\n" + token.RSAPublicKey))
+	require.Nil(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, token.KeyID(keys[0]), token.KeyID(keys[1]))
+}
+
+func parseToken(t *testing.T, tokenString string) *jwt.Token {
+	publicKey, _ := token.ParsePublicKey([]byte(token.RSAPublicKey))
+	tk, err := jwt.Parse(tokenString, func(*jwt.Token) (interface{}, error) { return publicKey, nil })
+	require.Nil(t, err)
+	return tk
+}
+
+func parseClaims(t *testing.T, tokenString string) jwt.MapClaims {
+	return parseToken(t, tokenString).Claims.(jwt.MapClaims)
+}
+
 func createManager(t *testing.T) token.Manager {
 	publicKey, err := token.ParsePublicKey([]byte(token.RSAPublicKey))
 	if err != nil {
@@ -28,6 +28,15 @@ func NewTrackerController(service *goa.Service, db application.DB, scheduler *re
 func (c *TrackerController) Create(ctx *app.CreateTrackerContext) error {
 	result := application.Transactional(c.db, func(appl application.Application) error {
 		t, err := appl.Trackers().Create(ctx.Context, ctx.Payload.URL, ctx.Payload.Type)
+		if err == nil && (ctx.Payload.Push != nil || ctx.Payload.FieldOwnership != nil || ctx.Payload.FieldMappings != nil || ctx.Payload.WebhookSecret != nil || ctx.Payload.IdentityMappings != nil || ctx.Payload.LinkTypes != nil) {
+			t.Push = ctx.Payload.Push
+			t.FieldOwnership = ctx.Payload.FieldOwnership
+			t.FieldMappings = ctx.Payload.FieldMappings
+			t.WebhookSecret = ctx.Payload.WebhookSecret
+			t.IdentityMappings = ctx.Payload.IdentityMappings
+			t.LinkTypes = ctx.Payload.LinkTypes
+			t, err = appl.Trackers().Save(ctx.Context, *t)
+		}
 		if err != nil {
 			switch err := err.(type) {
 			case remoteworkitem.BadParameterError, remoteworkitem.ConversionError:
@@ -107,14 +116,38 @@ func (c *TrackerController) List(ctx *app.ListTrackerContext) error {
 
 }
 
+// Conflicts runs the conflicts action.
+func (c *TrackerController) Conflicts(ctx *app.ConflictsTrackerContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		result, err := appl.Trackers().ListConflicts(ctx.Context, ctx.ID)
+		if err != nil {
+			switch err.(type) {
+			case remoteworkitem.NotFoundError:
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrNotFound(err.Error()))
+				return ctx.NotFound(jerrors)
+			default:
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(err.Error()))
+				return ctx.InternalServerError(jerrors)
+			}
+		}
+		return ctx.OK(result)
+	})
+}
+
 // Update runs the update action.
 func (c *TrackerController) Update(ctx *app.UpdateTrackerContext) error {
 	result := application.Transactional(c.db, func(appl application.Application) error {
 
 		toSave := app.Tracker{
-			ID:   ctx.ID,
-			URL:  ctx.Payload.URL,
-			Type: ctx.Payload.Type,
+			ID:               ctx.ID,
+			URL:              ctx.Payload.URL,
+			Type:             ctx.Payload.Type,
+			Push:             ctx.Payload.Push,
+			FieldOwnership:   ctx.Payload.FieldOwnership,
+			FieldMappings:    ctx.Payload.FieldMappings,
+			WebhookSecret:    ctx.Payload.WebhookSecret,
+			IdentityMappings: ctx.Payload.IdentityMappings,
+			LinkTypes:        ctx.Payload.LinkTypes,
 		}
 		t, err := appl.Trackers().Save(ctx.Context, toSave)
 
@@ -7,6 +7,7 @@ import (
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
 	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/project"
 	"github.com/almighty/almighty-core/remoteworkitem"
 	"github.com/goadesign/goa"
 )
@@ -25,23 +26,39 @@ func NewTrackerqueryController(service *goa.Service, db application.DB, schedule
 
 // Create runs the create action.
 func (c *TrackerqueryController) Create(ctx *app.CreateTrackerqueryContext) error {
-	result := application.Transactional(c.db, func(appl application.Application) error {
-		tq, err := appl.TrackerQueries().Create(ctx.Context, ctx.Payload.Query, ctx.Payload.Schedule, ctx.Payload.TrackerID)
-		if err != nil {
-			switch err := err.(type) {
-			case remoteworkitem.BadParameterError, remoteworkitem.ConversionError:
-				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
-				return ctx.BadRequest(jerrors)
-			default:
-				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(err.Error()))
-				return ctx.InternalServerError(jerrors)
-			}
+	projectID := project.SystemProject
+	if ctx.Payload.ProjectID != nil {
+		projectID = *ctx.Payload.ProjectID
+	}
+	// the error is returned from the transaction, which rolls back the query
+	// when its work item types are rejected
+	var tq *app.TrackerQuery
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		if _, err := appl.Projects().Load(ctx.Context, projectID); err != nil {
+			return err
 		}
-		ctx.ResponseData.Header().Set("Location", app.TrackerqueryHref(tq.ID))
-		return ctx.Created(tq)
+		var err error
+		tq, err = appl.TrackerQueries().Create(ctx.Context, ctx.Payload.Query, ctx.Payload.Schedule, ctx.Payload.TrackerID, projectID)
+		if err == nil && (ctx.Payload.WorkItemType != nil || ctx.Payload.WorkItemTypeRules != nil) {
+			tq.WorkItemType = ctx.Payload.WorkItemType
+			tq.WorkItemTypeRules = ctx.Payload.WorkItemTypeRules
+			tq, err = appl.TrackerQueries().Save(ctx.Context, *tq)
+		}
+		return err
 	})
+	if err != nil {
+		switch err := err.(type) {
+		case remoteworkitem.BadParameterError, remoteworkitem.ConversionError:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
+			return ctx.BadRequest(jerrors)
+		default:
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+	}
 	c.scheduler.ScheduleAllQueries()
-	return result
+	ctx.ResponseData.Header().Set("Location", app.TrackerqueryHref(tq.ID))
+	return ctx.Created(tq)
 }
 
 // Show runs the show action.
@@ -67,10 +84,24 @@ func (c *TrackerqueryController) Update(ctx *app.UpdateTrackerqueryContext) erro
 	result := application.Transactional(c.db, func(appl application.Application) error {
 
 		toSave := app.TrackerQuery{
-			ID:        ctx.ID,
-			Query:     ctx.Payload.Query,
-			Schedule:  ctx.Payload.Schedule,
-			TrackerID: ctx.Payload.TrackerID,
+			ID:                ctx.ID,
+			Query:             ctx.Payload.Query,
+			Schedule:          ctx.Payload.Schedule,
+			TrackerID:         ctx.Payload.TrackerID,
+			WorkItemType:      ctx.Payload.WorkItemType,
+			WorkItemTypeRules: ctx.Payload.WorkItemTypeRules,
+		}
+		if ctx.Payload.ProjectID != nil {
+			if _, err := appl.Projects().Load(ctx.Context, *ctx.Payload.ProjectID); err != nil {
+				jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+				return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+			}
+			// the authorizer checked the current project of the query, moving it needs the target project too
+			if err := checkCurrentPermission(ctx.Context, appl, *ctx.Payload.ProjectID, Permissions.ManageProject); err != nil {
+				jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+				return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+			}
+			toSave.ProjectID = *ctx.Payload.ProjectID
 		}
 		tq, err := appl.TrackerQueries().Save(ctx.Context, toSave)
 
@@ -110,10 +141,71 @@ func (c *TrackerqueryController) Delete(ctx *app.DeleteTrackerqueryContext) erro
 	return result
 }
 
+// Resync runs the resync action.
+func (c *TrackerqueryController) Resync(ctx *app.ResyncTrackerqueryContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		tq, err := appl.TrackerQueries().Resync(ctx.Context, ctx.ID)
+		if err != nil {
+			switch err.(type) {
+			case remoteworkitem.NotFoundError:
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrNotFound(err.Error()))
+				return ctx.NotFound(jerrors)
+			default:
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(err.Error()))
+				return ctx.InternalServerError(jerrors)
+			}
+		}
+		return ctx.OK(tq)
+	})
+}
+
+// Runs runs the runs action.
+func (c *TrackerqueryController) Runs(ctx *app.RunsTrackerqueryContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		result, err := appl.TrackerQueries().ListRuns(ctx.Context, ctx.ID, ctx.Limit)
+		if err != nil {
+			switch err.(type) {
+			case remoteworkitem.NotFoundError:
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrNotFound(err.Error()))
+				return ctx.NotFound(jerrors)
+			default:
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(err.Error()))
+				return ctx.InternalServerError(jerrors)
+			}
+		}
+		return ctx.OK(result)
+	})
+}
+
+// Run runs the run action.
+func (c *TrackerqueryController) Run(ctx *app.RunTrackerqueryContext) error {
+	var run *app.TrackerQueryRun
+	var err error
+	if ctx.DryRun {
+		run, err = c.scheduler.PreviewQuery(ctx.ID, ctx.Limit)
+	} else {
+		run, err = c.scheduler.RunQuery(ctx.ID)
+	}
+	if err != nil {
+		switch err.(type) {
+		case remoteworkitem.NotFoundError:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrNotFound(err.Error()))
+			return ctx.NotFound(jerrors)
+		case remoteworkitem.BadParameterError:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
+			return ctx.BadRequest(jerrors)
+		default:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(err.Error()))
+			return ctx.InternalServerError(jerrors)
+		}
+	}
+	return ctx.Accepted(run)
+}
+
 // List runs the list action.
 func (c *TrackerqueryController) List(ctx *app.ListTrackerqueryContext) error {
 	return application.Transactional(c.db, func(appl application.Application) error {
-		result, err := appl.TrackerQueries().List(ctx.Context)
+		result, err := appl.TrackerQueries().List(ctx.Context, nil)
 		if err != nil {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(fmt.Sprintf("Error listing tracker queries: %s", err.Error())))
 			return ctx.InternalServerError(jerrors)
@@ -120,6 +120,15 @@ func getTrackerQueryTestData(t *testing.T) []testSecureAPI {
 			payload:            createTrackerQueryPayload,
 			jwtToken:           "",
 		},
+		// Run tracker query API without a token
+		{
+			method:             http.MethodPost,
+			url:                "/api/trackerqueries/12345/runs",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedErrorCode:  jsonapi.ErrorCodeJWTSecurityError,
+			payload:            nil,
+			jwtToken:           "",
+		},
 		// Try fetching a random tracker query
 		// We do not have security on GET hence this should return 404 not found
 		{
@@ -164,3 +164,30 @@ func TestCreateTrackerQueryValidId(t *testing.T) {
 	}
 	test.DeleteTrackerqueryOK(t, nil, nil, &tqController, trackerquery.ID)
 }
+
+// A query rejected for its work item type is not created.
+func TestCreateTrackerQueryUnknownWorkItemType(t *testing.T) {
+	resource.Require(t, resource.Database)
+	controller := TrackerController{Controller: nil, db: gormapplication.NewGormDB(DB), scheduler: RwiScheduler}
+	payload := app.CreateTrackerAlternatePayload{
+		URL:  "http://api.github.com",
+		Type: "github",
+	}
+	_, result := test.CreateTrackerCreated(t, nil, nil, &controller, &payload)
+	tqController := TrackerqueryController{Controller: nil, db: gormapplication.NewGormDB(DB), scheduler: RwiScheduler}
+	_, before := test.ListTrackerqueryOK(t, nil, nil, &tqController)
+
+	witName := "unknown-type"
+	tqpayload := app.CreateTrackerQueryAlternatePayload{
+		Query:        "is:open is:issue user:arquillian author:aslakknutsen",
+		Schedule:     "15 * * * * *",
+		TrackerID:    result.ID,
+		WorkItemType: &witName,
+	}
+	test.CreateTrackerqueryBadRequest(t, nil, nil, &tqController, &tqpayload)
+
+	_, after := test.ListTrackerqueryOK(t, nil, nil, &tqController)
+	if len(after) != len(before) {
+		t.Errorf("expected %d tracker queries, got %d", len(before), len(after))
+	}
+}
@@ -2,24 +2,41 @@ package main
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/token"
 	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
 )
 
 // UserController implements the user resource.
 type UserController struct {
 	*goa.Controller
+	db                 application.DB
 	identityRepository account.IdentityRepository
 	tokenManager       token.Manager
+	lockout            account.LockoutPolicy
 }
 
 // NewUserController creates a user controller.
-func NewUserController(service *goa.Service, identityRepository account.IdentityRepository, tokenManager token.Manager) *UserController {
-	return &UserController{Controller: service.NewController("UserController"), identityRepository: identityRepository, tokenManager: tokenManager}
+func NewUserController(service *goa.Service, db application.DB, identityRepository account.IdentityRepository, tokenManager token.Manager) *UserController {
+	return &UserController{Controller: service.NewController("UserController"), db: db, identityRepository: identityRepository, tokenManager: tokenManager}
+}
+
+// NewUserControllerWithLockout creates a user controller that locks the local
+// login according to the policy when the current password given to set a new
+// one is wrong too often.
+func NewUserControllerWithLockout(service *goa.Service, db application.DB, identityRepository account.IdentityRepository, tokenManager token.Manager, lockout account.LockoutPolicy) *UserController {
+	ctrl := NewUserController(service, db, identityRepository, tokenManager)
+	ctrl.lockout = lockout
+	return ctrl
 }
 
 // Show returns the authorized user based on the provided Token
@@ -36,9 +53,232 @@ func (c *UserController) Show(ctx *app.ShowUserContext) error {
 		return ctx.Unauthorized(jerrors)
 	}
 
-	res := app.User{
+	return ctx.OK(convertUser(ident))
+}
+
+// Update changes the profile of the authorized user
+func (c *UserController) Update(ctx *app.UpdateUserContext) error {
+	identID, err := c.tokenManager.Locate(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
+		return ctx.BadRequest(jerrors)
+	}
+	if err := validateUserPayload(ctx.Payload); err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(err)
+		return ctx.BadRequest(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		ident, err := appl.Identities().Load(ctx, identID)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(fmt.Sprintf("Auth token contains id %s of unknown Identity\n", identID)))
+			return ctx.Unauthorized(jerrors)
+		}
+		if ctx.Payload.FullName != nil {
+			ident.FullName = *ctx.Payload.FullName
+		}
+		if ctx.Payload.ImageURL != nil {
+			ident.ImageURL = *ctx.Payload.ImageURL
+		}
+		if ctx.Payload.Bio != nil {
+			ident.Bio = *ctx.Payload.Bio
+		}
+		if ctx.Payload.Timezone != nil {
+			ident.Timezone = *ctx.Payload.Timezone
+		}
+		if err := appl.Identities().Save(ctx, ident); err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(fmt.Sprintf("Error updating identity: %s", err.Error())))
+			return ctx.InternalServerError(jerrors)
+		}
+		return ctx.OK(convertUser(ident))
+	})
+}
+
+// ListEmails returns the emails of the authorized user
+func (c *UserController) ListEmails(ctx *app.ListEmailsUserContext) error {
+	identID, err := c.tokenManager.Locate(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
+		return ctx.BadRequest(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		emails, err := appl.Users().ListByIdentity(ctx, identID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		res := make(app.UserEmailCollection, len(emails))
+		for index, u := range emails {
+			res[index] = convertUserEmail(u)
+		}
+		return ctx.OK(res)
+	})
+}
+
+// AddEmail registers a secondary email for the authorized user
+func (c *UserController) AddEmail(ctx *app.AddEmailUserContext) error {
+	identID, err := c.tokenManager.Locate(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
+		return ctx.BadRequest(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		u, err := appl.Users().AddEmail(ctx, identID, ctx.Payload.Email)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		// TODO: send the code to the new address once we are able to send mails.
+		// The code itself must never be logged, whoever reads it could verify the address.
+		goa.LogInfo(ctx, "email verification code sent", "email", u.Email)
+		return ctx.Created(convertUserEmail(u))
+	})
+}
+
+// VerifyEmail verifies a secondary email of the authorized user
+func (c *UserController) VerifyEmail(ctx *app.VerifyEmailUserContext) error {
+	identID, err := c.tokenManager.Locate(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
+		return ctx.BadRequest(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		id, err := uuid.FromString(ctx.ID)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewNotFoundError("email", ctx.ID))
+			return ctx.NotFound(jerrors)
+		}
+		u, err := appl.Users().VerifyEmail(ctx, identID, id, ctx.Payload.Code)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(convertUserEmail(u))
+	})
+}
+
+// RemoveEmail removes a secondary email of the authorized user
+func (c *UserController) RemoveEmail(ctx *app.RemoveEmailUserContext) error {
+	identID, err := c.tokenManager.Locate(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
+		return ctx.BadRequest(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		id, err := uuid.FromString(ctx.ID)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewNotFoundError("email", ctx.ID))
+			return ctx.NotFound(jerrors)
+		}
+		err = appl.Users().RemoveEmail(ctx, identID, id)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// Merge merges the identity of the given token into the authorized user
+func (c *UserController) Merge(ctx *app.MergeUserContext) error {
+	identID, err := c.tokenManager.Locate(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
+		return ctx.BadRequest(jerrors)
+	}
+	source, err := c.tokenManager.Extract(ctx.Payload.Token)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(fmt.Sprintf("Invalid token of the identity to merge: %s", err.Error())))
+		return ctx.Unauthorized(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		err := appl.Identities().Merge(ctx, identID, source.ID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		ident, err := appl.Identities().Load(ctx, identID)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(fmt.Sprintf("Error loading identity: %s", err.Error())))
+			return ctx.InternalServerError(jerrors)
+		}
+		return ctx.OK(convertUser(ident))
+	})
+}
+
+// SetPassword sets the password for the local login with the primary email
+// of the authorized user. The current password has to be given once one is set.
+func (c *UserController) SetPassword(ctx *app.SetPasswordUserContext) error {
+	identID, err := c.tokenManager.Locate(ctx)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
+		return ctx.BadRequest(jerrors)
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		emails, err := appl.Users().ListByIdentity(ctx, identID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		if len(emails) == 0 || !emails[0].Primary || !emails[0].Verified {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("identity", identID.String()).Expected("an identity with a verified primary email"))
+			return ctx.BadRequest(jerrors)
+		}
+		primary := emails[0]
+		if primary.PasswordHash != "" {
+			current := ""
+			if ctx.Payload.CurrentPassword != nil {
+				current = *ctx.Payload.CurrentPassword
+			}
+			// failures count towards the lockout of the local login
+			if _, err := appl.Users().Authenticate(ctx, primary.Email, current, c.lockout); err != nil {
+				if _, ok := err.(errors.UnauthorizedError); ok {
+					err = errors.NewBadParameterError("current_password", "***").Expected("the password set so far")
+				}
+				jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+				return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+			}
+		}
+		if err := appl.Users().SetPassword(ctx, primary.ID, ctx.Payload.NewPassword); err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// validateUserPayload checks the profile attributes that are given
+func validateUserPayload(payload *app.UpdateUserPayload) error {
+	if payload.FullName != nil && strings.TrimSpace(*payload.FullName) == "" {
+		return errors.NewBadParameterError("fullName", *payload.FullName).Expected("not empty")
+	}
+	if payload.ImageURL != nil && *payload.ImageURL != "" {
+		u, err := url.Parse(*payload.ImageURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.NewBadParameterError("imageURL", *payload.ImageURL).Expected("an absolute http(s) URL")
+		}
+	}
+	if payload.Timezone != nil && *payload.Timezone != "" {
+		if _, err := time.LoadLocation(*payload.Timezone); err != nil {
+			return errors.NewBadParameterError("timezone", *payload.Timezone).Expected("an IANA time zone name")
+		}
+	}
+	return nil
+}
+
+func convertUser(ident *account.Identity) *app.User {
+	return &app.User{
 		FullName: &ident.FullName,
 		ImageURL: &ident.ImageURL,
+		Bio:      &ident.Bio,
+		Timezone: &ident.Timezone,
+	}
+}
+
+func convertUserEmail(u *account.User) *app.UserEmail {
+	return &app.UserEmail{
+		ID:       u.ID,
+		Email:    u.Email,
+		Primary:  u.Primary,
+		Verified: u.Verified,
 	}
-	return ctx.OK(&res)
 }
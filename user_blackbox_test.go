@@ -0,0 +1,176 @@
+package main_test
+
+import (
+	"testing"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/team"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+type TestUserREST struct {
+	gormsupport.DBTestSuite
+
+	db           *gormapplication.GormDB
+	clean        func()
+	tokenManager almtoken.Manager
+	identity     account.Identity
+}
+
+func TestRunUserREST(t *testing.T) {
+	suite.Run(t, &TestUserREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestUserREST) SetupTest() {
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = gormsupport.DeleteCreatedEntities(rest.DB)
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+	rest.tokenManager = almtoken.NewManager(pub, priv)
+	rest.identity = rest.createIdentity("TestUserREST")
+}
+
+func (rest *TestUserREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestUserREST) createIdentity(name string) account.Identity {
+	identity := account.Identity{FullName: name}
+	require.Nil(rest.T(), account.NewIdentityRepository(rest.DB).Create(context.Background(), &identity))
+	return identity
+}
+
+func (rest *TestUserREST) controller() (*goa.Service, *UserController) {
+	svc := testsupport.ServiceAsUser("User-Service", rest.tokenManager, rest.identity)
+	return svc, NewUserController(svc, rest.db, account.NewIdentityRepository(rest.DB), rest.tokenManager)
+}
+
+func (rest *TestUserREST) TestUpdateProfile() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	svc, ctrl := rest.controller()
+
+	bio := "Writes tests"
+	timezone := "Europe/Berlin"
+	_, user := test.UpdateUserOK(t, svc.Context, svc, ctrl, &app.UpdateUserPayload{Bio: &bio, Timezone: &timezone})
+	assert.Equal(t, bio, *user.Bio)
+	assert.Equal(t, timezone, *user.Timezone)
+	assert.Equal(t, rest.identity.FullName, *user.FullName)
+
+	_, user = test.ShowUserOK(t, svc.Context, svc, ctrl)
+	assert.Equal(t, timezone, *user.Timezone)
+
+	invalid := "Mars/Olympus_Mons"
+	test.UpdateUserBadRequest(t, svc.Context, svc, ctrl, &app.UpdateUserPayload{Timezone: &invalid})
+	relative := "avatar.png"
+	test.UpdateUserBadRequest(t, svc.Context, svc, ctrl, &app.UpdateUserPayload{ImageURL: &relative})
+}
+
+func (rest *TestUserREST) TestSecondaryEmails() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	svc, ctrl := rest.controller()
+
+	_, email := test.AddEmailUserCreated(t, svc.Context, svc, ctrl, &app.AddUserEmailPayload{Email: uuid.NewV4().String() + "@example.com"})
+	assert.False(t, email.Verified)
+	test.VerifyEmailUserBadRequest(t, svc.Context, svc, ctrl, email.ID.String(), &app.VerifyUserEmailPayload{Code: "wrong"})
+	test.VerifyEmailUserNotFound(t, svc.Context, svc, ctrl, "not-a-uuid", &app.VerifyUserEmailPayload{Code: "wrong"})
+
+	users, err := account.NewUserRepository(rest.DB).ListByIdentity(context.Background(), rest.identity.ID)
+	require.Nil(t, err)
+	require.Len(t, users, 1)
+	_, email = test.VerifyEmailUserOK(t, svc.Context, svc, ctrl, email.ID.String(), &app.VerifyUserEmailPayload{Code: users[0].VerificationCode})
+	assert.True(t, email.Verified)
+
+	_, emails := test.ListEmailsUserOK(t, svc.Context, svc, ctrl)
+	assert.Len(t, emails, 1)
+	test.RemoveEmailUserOK(t, svc.Context, svc, ctrl, email.ID.String())
+	test.RemoveEmailUserNotFound(t, svc.Context, svc, ctrl, email.ID.String())
+}
+
+func (rest *TestUserREST) TestMerge() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	svc, ctrl := rest.controller()
+
+	source := rest.createIdentity("TestUserREST duplicate")
+	p, err := project.NewRepository(rest.DB).Create(context.Background(), "TestUserREST-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	wi, err := workitem.NewWorkItemRepository(rest.DB).Create(context.Background(), p.ID, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle:    "created by the duplicate",
+		workitem.SystemState:    workitem.SystemStateNew,
+		workitem.SystemAssignee: source.ID.String(),
+	}, source.ID.String())
+	require.Nil(t, err)
+	// the source is a member of both teams, the target of the second one only
+	teams := team.NewRepository(rest.DB)
+	var teamIDs []uuid.UUID
+	for i := 0; i < 2; i++ {
+		tm := team.Team{Name: "TestUserREST-" + uuid.NewV4().String()[:8]}
+		require.Nil(t, teams.Create(context.Background(), &tm))
+		_, err = teams.AddMember(context.Background(), tm.ID, source.ID)
+		require.Nil(t, err)
+		teamIDs = append(teamIDs, tm.ID)
+	}
+	_, err = teams.AddMember(context.Background(), teamIDs[1], rest.identity.ID)
+	require.Nil(t, err)
+
+	test.MergeUserUnauthorized(t, svc.Context, svc, ctrl, &app.MergeUserPayload{Token: "garbage"})
+	token, err := rest.tokenManager.Generate(source)
+	require.Nil(t, err)
+	test.MergeUserOK(t, svc.Context, svc, ctrl, &app.MergeUserPayload{Token: token})
+
+	merged, err := workitem.NewWorkItemRepository(rest.DB).Load(context.Background(), wi.ID)
+	require.Nil(t, err)
+	assert.Equal(t, rest.identity.ID.String(), merged.Fields[workitem.SystemCreator])
+	assert.Equal(t, rest.identity.ID.String(), merged.Fields[workitem.SystemAssignee])
+	for _, teamID := range teamIDs {
+		members, err := teams.ListMembers(context.Background(), teamID)
+		require.Nil(t, err)
+		require.Len(t, members, 1)
+		assert.Equal(t, rest.identity.ID, members[0].IdentityID)
+	}
+
+	// the identity is gone, so its token does not work anymore
+	test.MergeUserNotFound(t, svc.Context, svc, ctrl, &app.MergeUserPayload{Token: token})
+}
+
+func (rest *TestUserREST) TestSetPassword() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	svc, ctrl := rest.controller()
+
+	// identities without a verified primary email can not log in locally
+	test.SetPasswordUserBadRequest(t, svc.Context, svc, ctrl, &app.SetPasswordPayload{NewPassword: "correct horse"})
+
+	users := account.NewUserRepository(rest.DB)
+	primary := account.User{Email: uuid.NewV4().String() + "@example.com", IdentityID: rest.identity.ID, Primary: true, Verified: true}
+	require.Nil(t, users.Create(context.Background(), &primary))
+	test.SetPasswordUserOK(t, svc.Context, svc, ctrl, &app.SetPasswordPayload{NewPassword: "correct horse"})
+	_, err := users.Authenticate(context.Background(), primary.Email, "correct horse", account.LockoutPolicy{})
+	require.Nil(t, err)
+
+	// once a password is set, it has to be given to set a new one
+	test.SetPasswordUserBadRequest(t, svc.Context, svc, ctrl, &app.SetPasswordPayload{NewPassword: "battery staple"})
+	wrong := "wrong horse"
+	test.SetPasswordUserBadRequest(t, svc.Context, svc, ctrl, &app.SetPasswordPayload{CurrentPassword: &wrong, NewPassword: "battery staple"})
+	current := "correct horse"
+	test.SetPasswordUserOK(t, svc.Context, svc, ctrl, &app.SetPasswordPayload{CurrentPassword: &current, NewPassword: "battery staple"})
+	_, err = users.Authenticate(context.Background(), primary.Email, "battery staple", account.LockoutPolicy{})
+	require.Nil(t, err)
+}
@@ -24,7 +24,7 @@ func newUserController() *UserController {
 func newUserControllerWithRepo(repo *TestIdentityRepository) *UserController {
 	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
 	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
-	return NewUserController(goa.New("alm-test"), repo, almtoken.NewManager(pub, priv))
+	return NewUserController(goa.New("alm-test"), nil, repo, almtoken.NewManager(pub, priv))
 }
 
 func TestCurrentAuthorizedMissingUUID(t *testing.T) {
@@ -112,3 +112,8 @@ func (m *TestIdentityRepository) Delete(ctx context.Context, id uuid.UUID) error
 func (m *TestIdentityRepository) Query(funcs ...func(*gorm.DB) *gorm.DB) ([]*account.Identity, error) {
 	return []*account.Identity{m.Identity}, nil
 }
+
+// Merge is not supported by the test repository
+func (m *TestIdentityRepository) Merge(ctx context.Context, targetID, sourceID uuid.UUID) error {
+	return errors.New("not supported")
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"io/ioutil"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/remoteworkitem"
+	"github.com/goadesign/goa"
+)
+
+// WebhookController implements the webhook resource.
+type WebhookController struct {
+	*goa.Controller
+	scheduler *remoteworkitem.Scheduler
+}
+
+// NewWebhookController creates a webhook controller.
+func NewWebhookController(service *goa.Service, scheduler *remoteworkitem.Scheduler) *WebhookController {
+	return &WebhookController{Controller: service.NewController("WebhookController"), scheduler: scheduler}
+}
+
+// Github runs the github action.
+func (c *WebhookController) Github(ctx *app.GithubWebhookContext) error {
+	body, err := ioutil.ReadAll(ctx.Body)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
+		return ctx.BadRequest(jerrors)
+	}
+	var event, signature string
+	if ctx.XGitHubEvent != nil {
+		event = *ctx.XGitHubEvent
+	}
+	if ctx.XHubSignature256 != nil {
+		signature = *ctx.XHubSignature256
+	} else if ctx.XHubSignature != nil {
+		signature = *ctx.XHubSignature
+	}
+	err = c.scheduler.ReceiveGithubEvent(ctx.ID, event, signature, body)
+	if err != nil {
+		return webhookError(ctx, err)
+	}
+	return ctx.OK([]byte{})
+}
+
+// Jira runs the jira action.
+func (c *WebhookController) Jira(ctx *app.JiraWebhookContext) error {
+	body, err := ioutil.ReadAll(ctx.Body)
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
+		return ctx.BadRequest(jerrors)
+	}
+	var secret string
+	if ctx.Secret != nil {
+		secret = *ctx.Secret
+	}
+	err = c.scheduler.ReceiveJiraEvent(ctx.ID, secret, body)
+	if err != nil {
+		return webhookError(ctx, err)
+	}
+	return ctx.OK([]byte{})
+}
+
+// webhookErrorResponder is implemented by the contexts of the webhook actions
+type webhookErrorResponder interface {
+	BadRequest(*app.JSONAPIErrors) error
+	NotFound(*app.JSONAPIErrors) error
+	Unauthorized(*app.JSONAPIErrors) error
+	InternalServerError(*app.JSONAPIErrors) error
+}
+
+// webhookError responds with the status matching the error of a webhook event
+func webhookError(ctx webhookErrorResponder, err error) error {
+	switch err.(type) {
+	case remoteworkitem.BadParameterError, remoteworkitem.ConversionError:
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
+		return ctx.BadRequest(jerrors)
+	case remoteworkitem.NotFoundError:
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrNotFound(err.Error()))
+		return ctx.NotFound(jerrors)
+	case remoteworkitem.UnauthorizedError:
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+		return ctx.Unauthorized(jerrors)
+	default:
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(err.Error()))
+		return ctx.InternalServerError(jerrors)
+	}
+}
@@ -45,9 +45,10 @@ func (c *WorkItemCommentsController) Create(ctx *app.CreateWorkItemCommentsConte
 		reqComment := ctx.Payload.Data
 
 		newComment := comment.Comment{
-			ParentID:  ctx.ID,
-			Body:      reqComment.Attributes.Body,
-			CreatedBy: currentUserID,
+			ParentType: comment.ParentTypeWorkItem,
+			ParentID:   ctx.ID,
+			Body:       reqComment.Attributes.Body,
+			CreatedBy:  currentUserID,
 		}
 
 		err = appl.WorkItemComments().Create(ctx, &newComment)
@@ -55,6 +56,11 @@ func (c *WorkItemCommentsController) Create(ctx *app.CreateWorkItemCommentsConte
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
 			return ctx.InternalServerError(jerrors)
 		}
+		_, err = appl.Teams().RecordMentions(ctx, &newComment)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.InternalServerError(jerrors)
+		}
 
 		res := &app.CommentSingle{
 			Data: toAPI(&newComment),
@@ -75,7 +81,7 @@ func (c *WorkItemCommentsController) List(ctx *app.ListWorkItemCommentsContext)
 		res := &app.CommentArray{}
 		res.Data = []*app.Comment{}
 
-		comments, err := appl.WorkItemComments().List(ctx, ctx.ID)
+		comments, err := appl.WorkItemComments().List(ctx, comment.ParentTypeWorkItem, ctx.ID)
 		if err != nil {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
 			return ctx.InternalServerError(jerrors)
@@ -103,6 +109,12 @@ func toAPI(comment *comment.Comment) *app.Comment {
 					ID:   &comment.CreatedBy,
 				},
 			},
+			Parent: &app.CommentParent{
+				Data: &app.CommentParentData{
+					Type: comment.ParentType,
+					ID:   comment.ParentID,
+				},
+			},
 		},
 	}
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/almighty/almighty-core/comment"
 	"github.com/almighty/almighty-core/gormapplication"
 	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
 	"github.com/almighty/almighty-core/resource"
 	testsupport "github.com/almighty/almighty-core/test"
 	almtoken "github.com/almighty/almighty-core/token"
@@ -83,10 +84,10 @@ func (rest *TestCommentREST) TestListCommentsByParentWorkItem() {
 	}
 	application.Transactional(rest.db, func(app application.Application) error {
 		repo := app.WorkItemComments()
-		repo.Create(context.Background(), &comment.Comment{ParentID: wiid, Body: "Test 1", CreatedBy: uuid.NewV4()})
-		repo.Create(context.Background(), &comment.Comment{ParentID: wiid, Body: "Test 2", CreatedBy: uuid.NewV4()})
-		repo.Create(context.Background(), &comment.Comment{ParentID: wiid, Body: "Test 3", CreatedBy: uuid.NewV4()})
-		repo.Create(context.Background(), &comment.Comment{ParentID: wiid + "_other", Body: "Test 1", CreatedBy: uuid.NewV4()})
+		repo.Create(context.Background(), &comment.Comment{ParentType: comment.ParentTypeWorkItem, ParentID: wiid, Body: "Test 1", CreatedBy: uuid.NewV4()})
+		repo.Create(context.Background(), &comment.Comment{ParentType: comment.ParentTypeWorkItem, ParentID: wiid, Body: "Test 2", CreatedBy: uuid.NewV4()})
+		repo.Create(context.Background(), &comment.Comment{ParentType: comment.ParentTypeWorkItem, ParentID: wiid, Body: "Test 3", CreatedBy: uuid.NewV4()})
+		repo.Create(context.Background(), &comment.Comment{ParentType: comment.ParentTypeWorkItem, ParentID: wiid + "_other", Body: "Test 1", CreatedBy: uuid.NewV4()})
 		return nil
 	})
 
@@ -171,6 +172,8 @@ func assertComment(t *testing.T, c *app.Comment) {
 	assert.NotNil(t, c.Relationships.CreatedBy)
 	assert.Equal(t, "identities", c.Relationships.CreatedBy.Data.Type)
 	assert.NotNil(t, c.Relationships.CreatedBy.Data.ID)
+	assert.NotNil(t, c.Relationships.Parent)
+	assert.Equal(t, "workitems", c.Relationships.Parent.Data.Type)
 }
 
 func createComment(body string) *app.CreateWorkItemCommentsPayload {
@@ -190,6 +193,7 @@ func createWorkItem(db *gormapplication.GormDB) (string, error) {
 		repo := appl.WorkItems()
 		wi, err := repo.Create(
 			context.Background(),
+			project.SystemProject,
 			workitem.SystemBug,
 			map[string]interface{}{
 				workitem.SystemTitle: "A",
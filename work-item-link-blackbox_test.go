@@ -169,7 +169,7 @@ func (s *workItemLinkSuite) SetupTest() {
 
 	// Create 3 work items (bug1, bug2, and feature1)
 	bug1Payload := CreateWorkItem(workitem.SystemBug, "bug1")
-	_, bug1 := test.CreateWorkitemCreated(s.T(), s.workItemSvc.Context, s.workItemSvc, s.workItemCtrl, bug1Payload)
+	_, bug1 := test.CreateWorkitemCreated(s.T(), s.workItemSvc.Context, s.workItemSvc, s.workItemCtrl, nil, bug1Payload)
 	require.NotNil(s.T(), bug1)
 	s.deleteWorkItems = append(s.deleteWorkItems, bug1.ID)
 	s.bug1ID, err = strconv.ParseUint(bug1.ID, 10, 64)
@@ -177,7 +177,7 @@ func (s *workItemLinkSuite) SetupTest() {
 	fmt.Printf("Created bug1 with ID: %s\n", bug1.ID)
 
 	bug2Payload := CreateWorkItem(workitem.SystemBug, "bug2")
-	_, bug2 := test.CreateWorkitemCreated(s.T(), s.workItemSvc.Context, s.workItemSvc, s.workItemCtrl, bug2Payload)
+	_, bug2 := test.CreateWorkitemCreated(s.T(), s.workItemSvc.Context, s.workItemSvc, s.workItemCtrl, nil, bug2Payload)
 	require.NotNil(s.T(), bug2)
 	s.deleteWorkItems = append(s.deleteWorkItems, bug2.ID)
 	s.bug2ID, err = strconv.ParseUint(bug2.ID, 10, 64)
@@ -185,7 +185,7 @@ func (s *workItemLinkSuite) SetupTest() {
 	fmt.Printf("Created bug2 with ID: %s\n", bug2.ID)
 
 	bug3Payload := CreateWorkItem(workitem.SystemBug, "bug3")
-	_, bug3 := test.CreateWorkitemCreated(s.T(), s.workItemSvc.Context, s.workItemSvc, s.workItemCtrl, bug3Payload)
+	_, bug3 := test.CreateWorkitemCreated(s.T(), s.workItemSvc.Context, s.workItemSvc, s.workItemCtrl, nil, bug3Payload)
 	require.NotNil(s.T(), bug3)
 	s.deleteWorkItems = append(s.deleteWorkItems, bug3.ID)
 	s.bug3ID, err = strconv.ParseUint(bug3.ID, 10, 64)
@@ -193,7 +193,7 @@ func (s *workItemLinkSuite) SetupTest() {
 	fmt.Printf("Created bug3 with ID: %s\n", bug3.ID)
 
 	feature1Payload := CreateWorkItem(workitem.SystemFeature, "feature1")
-	_, feature1 := test.CreateWorkitemCreated(s.T(), s.workItemSvc.Context, s.workItemSvc, s.workItemCtrl, feature1Payload)
+	_, feature1 := test.CreateWorkitemCreated(s.T(), s.workItemSvc.Context, s.workItemSvc, s.workItemCtrl, nil, feature1Payload)
 	require.NotNil(s.T(), feature1)
 	s.deleteWorkItems = append(s.deleteWorkItems, feature1.ID)
 	s.feature1ID, err = strconv.ParseUint(feature1.ID, 10, 64)
@@ -591,7 +591,7 @@ func (s *workItemLinkSuite) validateSomeLinks(linkCollection *app.WorkItemLinkAr
 // "test-bug-blocker" and "related" in the list of work item links
 func (s *workItemLinkSuite) TestListWorkItemLinkOK() {
 	link1, link2 := s.createSomeLinks()
-	_, linkCollection := test.ListWorkItemLinkOK(s.T(), nil, nil, s.workItemLinkCtrl)
+	_, linkCollection := test.ListWorkItemLinkOK(s.T(), s.workItemSvc.Context, nil, s.workItemLinkCtrl)
 	s.validateSomeLinks(linkCollection, link1, link2)
 }
 
@@ -599,7 +599,7 @@ func (s *workItemLinkSuite) TestListWorkItemLinkOK() {
 func (s *workItemLinkSuite) TestListWorkItemRelationshipsLinksOK() {
 	link1, link2 := s.createSomeLinks()
 	filterByWorkItemID := strconv.FormatUint(s.bug2ID, 10)
-	_, linkCollection := test.ListWorkItemRelationshipsLinksOK(s.T(), nil, nil, s.workItemRelsLinksCtrl, filterByWorkItemID)
+	_, linkCollection := test.ListWorkItemRelationshipsLinksOK(s.T(), s.workItemSvc.Context, nil, s.workItemRelsLinksCtrl, filterByWorkItemID)
 	s.validateSomeLinks(linkCollection, link1, link2)
 }
 
@@ -748,13 +748,12 @@ func getWorkItemLinkTestData(t *testing.T, wiID *string) func(t *testing.T) []te
 				payload:            nil,
 				jwtToken:           "",
 			},
-			// Try fetching a random work item link
-			// We do not have security on GET hence this should return 404 not found
+			// Try fetching a random work item link, reading requires a token as well
 			{
 				method:             http.MethodGet,
 				url:                "%s" + "/fc591f38-a805-4abd-bfce-2460e49d8cc4",
-				expectedStatusCode: http.StatusNotFound,
-				expectedErrorCode:  jsonapi.ErrorCodeNotFound,
+				expectedStatusCode: http.StatusUnauthorized,
+				expectedErrorCode:  jsonapi.ErrorCodeJWTSecurityError,
 				payload:            nil,
 				jwtToken:           "",
 			},
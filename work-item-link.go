@@ -9,6 +9,7 @@ import (
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/workitem/link"
 	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
 )
 
 // WorkItemLinkController implements the work-item-link resource.
@@ -139,6 +140,10 @@ func listWorkItemLink(appl application.Application, ctx context.Context, db appl
 		jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
 		return responseData.Service.Send(ctx, httpStatusCode, jerrors)
 	}
+	if err := filterReadableLinks(appl, ctx, linkArr); err != nil {
+		jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+		return responseData.Service.Send(ctx, httpStatusCode, jerrors)
+	}
 	if err := enrichLinkArrayWithTypes(appl, ctx, linkArr); err != nil {
 		jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
 		return responseData.Service.Send(ctx, httpStatusCode, jerrors)
@@ -146,6 +151,42 @@ func listWorkItemLink(appl application.Application, ctx context.Context, db appl
 	return funcs.OK(linkArr)
 }
 
+// filterReadableLinks removes the links from the array whose source work item
+// belongs to a project the identity making the request may not read
+func filterReadableLinks(appl application.Application, ctx context.Context, linkArr *app.WorkItemLinkArray) error {
+	projects, err := readableProjects(ctx, appl)
+	if err != nil {
+		return err
+	}
+	readable := make(map[uuid.UUID]bool, len(projects))
+	for _, id := range projects {
+		readable[id] = true
+	}
+	// many links usually share their source
+	projectOfSource := map[string]uuid.UUID{}
+	data := []*app.WorkItemLinkData{}
+	for _, l := range linkArr.Data {
+		src, _ := getSrcTgt(l)
+		if src == nil {
+			continue
+		}
+		projectID, ok := projectOfSource[*src]
+		if !ok {
+			projectID, err = projectOfWorkItem(ctx, appl, *src)
+			if err != nil {
+				return err
+			}
+			projectOfSource[*src] = projectID
+		}
+		if readable[projectID] {
+			data = append(data, l)
+		}
+	}
+	linkArr.Data = data
+	linkArr.Meta = &app.WorkItemLinkArrayMeta{TotalCount: len(data)}
+	return nil
+}
+
 // List runs the list action.
 func (c *WorkItemLinkController) List(ctx *app.ListWorkItemLinkContext) error {
 	return application.Transactional(c.db, func(appl application.Application) error {
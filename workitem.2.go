@@ -7,6 +7,7 @@ import (
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/jsonapi"
 	query "github.com/almighty/almighty-core/query/simple"
@@ -104,36 +105,15 @@ func (c *Workitem2Controller) List(ctx *app.ListWorkitem2Context) error {
 		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("could not parse filter: %s", err.Error())))
 		return ctx.BadRequest(jerrors)
 	}
-	var offset int
-	var limit int
+	offset, limit := computePagingLimits(ctx.PageOffset, ctx.PageLimit)
 
-	if ctx.PageOffset == nil {
-		offset = 0
-	} else {
-		offsetValue, err := strconv.Atoi(*ctx.PageOffset)
+	return application.Transactional(c.db, func(tx application.Application) error {
+		projects, err := readableProjects(ctx.Context, tx)
 		if err != nil {
-			offset = 0
-		} else {
-			offset = offsetValue
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
 		}
-	}
-	if offset < 0 {
-		offset = 0
-	}
-
-	if ctx.PageLimit == nil {
-		limit = pageSizeDefault
-	} else {
-		limit = *ctx.PageLimit
-	}
-
-	if limit <= 0 {
-		limit = pageSizeDefault
-	} else if limit > pageSizeMax {
-		limit = pageSizeMax
-	}
-
-	return application.Transactional(c.db, func(tx application.Application) error {
+		exp = criteria.And(exp, inProjects(projects))
 		result, c, err := tx.WorkItems().List(ctx.Context, exp, &offset, &limit)
 		count := int(c)
 		if err != nil {
@@ -162,12 +142,49 @@ func (c *Workitem2Controller) List(ctx *app.ListWorkitem2Context) error {
 	// Workitem2Controller_List: end_implement
 }
 
+// computePagingLimits returns the offset and limit to use for the given
+// "page[offset]" and "page[limit]" query parameters, falling back to the
+// defaults for missing or invalid values.
+func computePagingLimits(offsetParam *string, limitParam *int) (offset int, limit int) {
+	if offsetParam == nil {
+		offset = 0
+	} else {
+		offsetValue, err := strconv.Atoi(*offsetParam)
+		if err != nil {
+			offset = 0
+		} else {
+			offset = offsetValue
+		}
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	if limitParam == nil {
+		limit = pageSizeDefault
+	} else {
+		limit = *limitParam
+	}
+
+	if limit <= 0 {
+		limit = pageSizeDefault
+	} else if limit > pageSizeMax {
+		limit = pageSizeMax
+	}
+	return offset, limit
+}
+
 func buildAbsoluteURL(req *goa.RequestData) string {
+	return absoluteURL(req, req.URL.Path)
+}
+
+// absoluteURL prefixes the given path with the scheme and host of the request
+func absoluteURL(req *goa.RequestData, path string) string {
 	scheme := "http"
 	if req.TLS != nil { // isHTTPS
 		scheme = "https"
 	}
-	return fmt.Sprintf("%s://%s%s", scheme, req.Host, req.URL.Path)
+	return fmt.Sprintf("%s://%s%s", scheme, req.Host, path)
 }
 
 // ConvertWorkItemToJSONAPI is responsible for converting given WorkItem model object into a
@@ -9,6 +9,7 @@ import (
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/login"
@@ -85,6 +86,12 @@ func (c *WorkitemController) List(ctx *app.ListWorkitemContext) error {
 		return ctx.BadRequest(jerrors)
 	}
 	return application.Transactional(c.db, func(appl application.Application) error {
+		projects, err := readableProjects(ctx.Context, appl)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		exp = criteria.And(exp, inProjects(projects))
 		result, _, err := appl.WorkItems().List(ctx.Context, exp, start, &limit)
 		if err != nil {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(fmt.Sprintf("Error listing work items: %s", err.Error())))
@@ -102,7 +109,12 @@ func (c *WorkitemController) Create(ctx *app.CreateWorkitemContext) error {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
 			return ctx.Unauthorized(jerrors)
 		}
-		wi, err := appl.WorkItems().Create(ctx.Context, ctx.Payload.Type, ctx.Payload.Fields, currentUser)
+		projectID, err := projectOrSystem(ctx.Context, appl, ctx.Project)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		wi, err := appl.WorkItems().Create(ctx.Context, projectID, ctx.Payload.Type, ctx.Payload.Fields, currentUser)
 
 		if err != nil {
 			switch err := err.(type) {
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/almighty/almighty-core/criteria"
+	uuid "github.com/satori/go.uuid"
 )
 
 const (
@@ -38,13 +39,19 @@ func bubbleUpJSONContext(exp criteria.Expression) bool {
 	return true
 }
 
+// columns maps the field names that reference a column instead of a json field
+// to the name of the column.
+var columns = map[string]string{
+	"ID":      "ID",
+	"Type":    "Type",
+	"Version": "Version",
+	"Project": "project_id",
+}
+
 // does the field name reference a json field or a column?
 func isJSONField(fieldName string) bool {
-	switch fieldName {
-	case "ID", "Type", "Version":
-		return false
-	}
-	return true
+	_, isColumn := columns[fieldName]
+	return !isColumn
 }
 
 func newExpressionCompiler() expressionCompiler {
@@ -63,7 +70,7 @@ type expressionCompiler struct {
 
 func (c *expressionCompiler) Field(f *criteria.FieldExpression) interface{} {
 	if !isJSONField(f.FieldName) {
-		return f.FieldName
+		return columns[f.FieldName]
 	}
 	if strings.Contains(f.FieldName, "'") {
 		// beware of injection, it's a reasonable restriction for field names, make sure it's not allowed when creating wi types
@@ -95,6 +102,41 @@ func (c *expressionCompiler) Equals(e *criteria.EqualsExpression) interface{} {
 	return c.binary(e, "=")
 }
 
+// teamMembersQuery selects the identities that are members of the team given
+// as parameter or of one of its sub-teams. The selected column is filled in
+// with fmt.Sprintf.
+const teamMembersQuery = `SELECT %s FROM team_members m WHERE m.deleted_at IS NULL AND m.team_id IN (
+	WITH RECURSIVE sub_teams(id) AS (
+		SELECT ?::uuid
+		UNION SELECT t.id FROM teams t JOIN sub_teams s ON t.parent_id = s.id WHERE t.deleted_at IS NULL
+	) SELECT id FROM sub_teams)`
+
+// MemberOf compiles to a sub-select of the members of the team. The team must
+// be given as a literal ID.
+func (c *expressionCompiler) MemberOf(m *criteria.MemberOfExpression) interface{} {
+	left := m.Left().Accept(c)
+	team, ok := m.Right().(*criteria.LiteralExpression)
+	if !ok {
+		c.err = append(c.err, fmt.Errorf("the team of a member of expression must be a literal"))
+		return nil
+	}
+	teamID, ok := team.Value.(string)
+	if _, err := uuid.FromString(teamID); !ok || err != nil {
+		c.err = append(c.err, fmt.Errorf("team %v should be an ID", team.Value))
+		return nil
+	}
+	if left == nil {
+		return nil
+	}
+	members := "m.identity_id"
+	if m.Left().Annotation(jsonAnnotation) == true {
+		// identities are stored as JSON strings in the fields
+		members = "to_jsonb(m.identity_id::text)"
+	}
+	c.parameters = append(c.parameters, teamID)
+	return "(" + left.(string) + " IN (" + fmt.Sprintf(teamMembersQuery, members) + "))"
+}
+
 func (c *expressionCompiler) Parameter(v *criteria.ParameterExpression) interface{} {
 	c.err = append(c.err, fmt.Errorf("Parameter expression not supported"))
 	return nil
@@ -3,6 +3,7 @@ package workitem_test
 import (
 	"reflect"
 	"runtime/debug"
+	"strings"
 	"testing"
 
 	. "github.com/almighty/almighty-core/criteria"
@@ -15,6 +16,7 @@ func TestField(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
 	expect(t, Equals(Field("foo"), Literal(23)), "(Fields->'foo' = ?::jsonb)", []interface{}{"23"})
 	expect(t, Equals(Field("Type"), Literal("abcd")), "(Type = ?)", []interface{}{"abcd"})
+	expect(t, Equals(Field("Project"), Literal("2e0698d8-753e-4cef-bb7c-f027634824a2")), "(project_id = ?)", []interface{}{"2e0698d8-753e-4cef-bb7c-f027634824a2"})
 }
 
 func TestAndOr(t *testing.T) {
@@ -26,6 +28,31 @@ func TestAndOr(t *testing.T) {
 	expect(t, Or(Equals(Field("foo"), Literal("abcd")), Equals(Literal(true), Literal(false))), "((Fields->'foo' = ?::jsonb) or (? = ?))", []interface{}{"\"abcd\"", true, false})
 }
 
+func TestMemberOf(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	team := "2e0698d8-753e-4cef-bb7c-f027634824a2"
+	clause, parameters, err := Compile(MemberOf(Field("system.assignee"), Literal(team)))
+	if len(err) > 0 {
+		t.Fatal(err[0].Error())
+	}
+	if !strings.HasPrefix(clause, "(Fields->'system.assignee' IN (SELECT to_jsonb(m.identity_id::text) FROM team_members m") {
+		t.Fatalf("clause should select the team members as JSON but is %s", clause)
+	}
+	if !reflect.DeepEqual([]interface{}{team}, parameters) {
+		t.Fatalf("parameters should be %v but is %v", []interface{}{team}, parameters)
+	}
+
+	_, _, err = Compile(MemberOf(Field("system.assignee"), Literal("ops")))
+	if len(err) == 0 {
+		t.Fatal("team names should not compile")
+	}
+	_, _, err = Compile(MemberOf(Field("system.assignee"), Field("system.team")))
+	if len(err) == 0 {
+		t.Fatal("fields as team should not compile")
+	}
+}
+
 func expect(t *testing.T, expr Expression, expectedClause string, expectedParameters []interface{}) {
 	clause, parameters, err := Compile(expr)
 	if len(err) > 0 {
@@ -20,6 +20,10 @@ const (
 	KindUser              Kind = "user"
 	KindEnum              Kind = "enum"
 	KindList              Kind = "list"
+	KindIteration         Kind = "iteration"
+	KindArea              Kind = "area"
+	KindMilestone         Kind = "milestone"
+	KindTeam              Kind = "team"
 )
 
 // Kind is the kind of field type
@@ -14,6 +14,10 @@ var (
 	stDuration = SimpleType{Kind: KindDuration}
 	stURL      = SimpleType{Kind: KindURL}
 	stList     = SimpleType{Kind: KindList}
+	stIter     = SimpleType{Kind: KindIteration}
+	stArea     = SimpleType{Kind: KindArea}
+	stMile     = SimpleType{Kind: KindMilestone}
+	stTeam     = SimpleType{Kind: KindTeam}
 )
 
 type input struct {
@@ -54,6 +58,19 @@ func TestSimpleTypeConversion(t *testing.T) {
 		{stList, [4]int{1, 2, 3, 4}, [4]int{1, 2, 3, 4}, false},
 		{stList, [2]string{"1", "2"}, [2]string{"1", "2"}, false},
 		{stList, "", nil, true},
+
+		{stIter, "3e2a0ed8-6b58-4d6d-a1b5-4fd9b6ad5b3d", "3e2a0ed8-6b58-4d6d-a1b5-4fd9b6ad5b3d", false},
+		{stIter, "sprint 1", nil, true},
+		{stIter, 1, nil, true},
+
+		{stArea, "0e5a6c2a-7d3b-4c56-9a3f-2b5f0c8e1d44", "0e5a6c2a-7d3b-4c56-9a3f-2b5f0c8e1d44", false},
+		{stArea, "frontend", nil, true},
+
+		{stMile, "6a1f4e0b-3c2d-4b8e-9f7a-5d6c7b8a9e0f", "6a1f4e0b-3c2d-4b8e-9f7a-5d6c7b8a9e0f", false},
+		{stMile, 1.0, nil, true},
+
+		{stTeam, "5b7c2e4a-1f3d-4a6e-8c9b-0d2e4f6a8b1c", "5b7c2e4a-1f3d-4a6e-8c9b-0d2e4f6a8b1c", false},
+		{stTeam, "ops", nil, true},
 		// {stList, []int{}, []int{}, false}, need to find out the way for empty array.
 		// because slices do not have equality operator.
 	}
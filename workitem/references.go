@@ -0,0 +1,54 @@
+package workitem
+
+import (
+	"fmt"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// referenceTable is the table holding the entities that fields of a reference
+// kind point to. Entities of project scoped tables have to belong to the
+// project of the work item.
+type referenceTable struct {
+	name          string
+	projectScoped bool
+}
+
+// referenceTables maps the reference kinds to their tables. The packages of
+// those entities depend on this one, hence the lookup by table name.
+var referenceTables = map[Kind]referenceTable{
+	KindIteration: {"iterations", true},
+	KindArea:      {"areas", true},
+	KindMilestone: {"milestones", true},
+	KindTeam:      {"teams", false},
+}
+
+// checkReferences returns a BadParameterError for the first field of the work
+// item that references an iteration, area, milestone or team which does not
+// exist or belongs to another project than the work item.
+func checkReferences(db *gorm.DB, wiType *WorkItemType, projectID uuid.UUID, fields Fields) error {
+	for fieldName, fieldDef := range wiType.Fields {
+		table, ok := referenceTables[fieldDef.Type.GetKind()]
+		if !ok || fields[fieldName] == nil {
+			continue
+		}
+		query := db.Table(table.name).Where("id = ? AND deleted_at IS NULL", fields[fieldName])
+		if table.projectScoped {
+			query = query.Where("project_id = ?", projectID)
+		}
+		var count int
+		if err := query.Count(&count).Error; err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+		if count == 0 {
+			expected := fmt.Sprintf("existing %s", fieldDef.Type.GetKind())
+			if table.projectScoped {
+				expected = fmt.Sprintf("%s of project %s", fieldDef.Type.GetKind(), projectID)
+			}
+			return errors.NewBadParameterError(fieldName, fields[fieldName]).Expected(expected)
+		}
+	}
+	return nil
+}
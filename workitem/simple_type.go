@@ -8,6 +8,7 @@ import (
 
 	"github.com/almighty/almighty-core/convert"
 	"github.com/asaskevich/govalidator"
+	uuid "github.com/satori/go.uuid"
 )
 
 // SimpleType is an unstructured FieldType
@@ -74,6 +75,16 @@ func (fieldType SimpleType) ConvertToModel(value interface{}) (interface{}, erro
 		}
 		idValue, err := strconv.Atoi(value.(string))
 		return idValue, err
+	case KindIteration, KindArea, KindMilestone, KindTeam:
+		// the ID of an iteration, area, milestone or team, the work item repositories
+		// check that it exists in the project of the work item
+		if valueType.Kind() != reflect.String {
+			return nil, fmt.Errorf("value %v should be %s, but is %s", value, "string", valueType.Name())
+		}
+		if _, err := uuid.FromString(value.(string)); err != nil {
+			return nil, fmt.Errorf("value %v should be %s, but is not: %s", value, "an ID", err.Error())
+		}
+		return value, nil
 	case KindList:
 		if (valueType.Kind() != reflect.Array) && (valueType.Kind() != reflect.Slice) {
 			return nil, fmt.Errorf("value %v should be %s, but is %s,", value, "array/slice", valueType.Kind())
@@ -91,7 +102,7 @@ func (fieldType SimpleType) ConvertToModel(value interface{}) (interface{}, erro
 func (fieldType SimpleType) ConvertFromModel(value interface{}) (interface{}, error) {
 	valueType := reflect.TypeOf(value)
 	switch fieldType.GetKind() {
-	case KindString, KindURL, KindUser, KindInteger, KindFloat, KindDuration:
+	case KindString, KindURL, KindUser, KindInteger, KindFloat, KindDuration, KindIteration, KindArea, KindMilestone, KindTeam:
 		return value, nil
 	case KindInstant:
 		return time.Unix(0, value.(int64)), nil
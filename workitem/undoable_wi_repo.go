@@ -13,6 +13,7 @@ import (
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
 )
 
 var _ WorkItemRepository = &UndoableWorkItemRepository{}
@@ -86,8 +87,8 @@ func (r *UndoableWorkItemRepository) Delete(ctx context.Context, ID string) erro
 }
 
 // Create implements application.WorkItemRepository
-func (r *UndoableWorkItemRepository) Create(ctx context.Context, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error) {
-	result, err := r.wrapped.Create(ctx, typeID, fields, creator)
+func (r *UndoableWorkItemRepository) Create(ctx context.Context, projectID uuid.UUID, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error) {
+	result, err := r.wrapped.Create(ctx, projectID, typeID, fields, creator)
 	if err != nil {
 		return result, err
 	}
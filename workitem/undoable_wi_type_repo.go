@@ -6,6 +6,7 @@ import (
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
 )
 
 var _ WorkItemTypeRepository = &UndoableWorkItemTypeRepository{}
@@ -28,13 +29,13 @@ func (r *UndoableWorkItemTypeRepository) Load(ctx context.Context, name string)
 }
 
 // List implements application.WorkItemTypeRepository
-func (r *UndoableWorkItemTypeRepository) List(ctx context.Context, start *int, length *int) ([]*app.WorkItemType, error) {
-	return r.wrapped.List(ctx, start, length)
+func (r *UndoableWorkItemTypeRepository) List(ctx context.Context, projectID uuid.UUID, start *int, length *int) ([]*app.WorkItemType, error) {
+	return r.wrapped.List(ctx, projectID, start, length)
 }
 
 // Create implements application.WorkItemTypeRepository
-func (r *UndoableWorkItemTypeRepository) Create(ctx context.Context, extendedTypeID *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error) {
-	res, err := r.wrapped.Create(ctx, extendedTypeID, name, fields)
+func (r *UndoableWorkItemTypeRepository) Create(ctx context.Context, projectID uuid.UUID, extendedTypeID *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error) {
+	res, err := r.wrapped.Create(ctx, projectID, extendedTypeID, name, fields)
 	if err == nil {
 		r.undo.Append(func(db *gorm.DB) error {
 			db = db.Unscoped().Delete(&WorkItemType{Name: name})
@@ -6,6 +6,7 @@ import (
 	"github.com/almighty/almighty-core/convert"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
+	uuid "github.com/satori/go.uuid"
 )
 
 // WorkItem represents a work item as it is stored in the database
@@ -18,6 +19,8 @@ type WorkItem struct {
 	Version int
 	// the field values
 	Fields Fields `sql:"type:jsonb"`
+	// the project the work item belongs to
+	ProjectID uuid.UUID `sql:"type:uuid default '2e0698d8-753e-4cef-bb7c-f027634824a2'"`
 }
 
 // TableName implements gorm.tabler
@@ -48,6 +51,9 @@ func (wi WorkItem) Equal(u convert.Equaler) bool {
 	if wi.Version != other.Version {
 		return false
 	}
+	if !uuid.Equal(wi.ProjectID, other.ProjectID) {
+		return false
+	}
 	return wi.Fields.Equal(other.Fields)
 }
 
@@ -65,10 +65,11 @@ func (r *GormWorkItem2Repository) Save(ctx context.Context, wi app.WorkItemDataF
 	}
 
 	newWi := WorkItem{
-		ID:      id,
-		Type:    res.Type, // read WIT from DB object and not from payload relationship
-		Version: version + 1,
-		Fields:  res.Fields,
+		ID:        id,
+		Type:      res.Type, // read WIT from DB object and not from payload relationship
+		Version:   version + 1,
+		Fields:    res.Fields,
+		ProjectID: res.ProjectID,
 	}
 
 	wiType, err := r.wir.LoadTypeFromDB(newWi.Type)
@@ -113,6 +114,9 @@ func (r *GormWorkItem2Repository) Save(ctx context.Context, wi app.WorkItemDataF
 			return nil, errors.NewBadParameterError(fieldName, fieldValue)
 		}
 	}
+	if err := checkReferences(r.db, wiType, newWi.ProjectID, newWi.Fields); err != nil {
+		return nil, err
+	}
 
 	if err := tx.Save(&newWi).Error; err != nil {
 		log.Print(err.Error())
@@ -10,6 +10,7 @@ import (
 	"github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
 )
 
 // WorkItemRepository encapsulates storage & retrieval of work items
@@ -17,7 +18,7 @@ type WorkItemRepository interface {
 	Load(ctx context.Context, ID string) (*app.WorkItem, error)
 	Save(ctx context.Context, wi app.WorkItem) (*app.WorkItem, error)
 	Delete(ctx context.Context, ID string) error
-	Create(ctx context.Context, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error)
+	Create(ctx context.Context, projectID uuid.UUID, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error)
 	List(ctx context.Context, criteria criteria.Expression, start *int, length *int) ([]*app.WorkItem, uint64, error)
 }
 
@@ -113,12 +114,16 @@ func (r *GormWorkItemRepository) Save(ctx context.Context, wi app.WorkItem) (*ap
 	if err != nil {
 		return nil, errors.NewBadParameterError("Type", wi.Type)
 	}
+	if !wiType.IsUsableInProject(res.ProjectID) {
+		return nil, errors.NewBadParameterError("type", wi.Type).Expected("type of project " + res.ProjectID.String())
+	}
 
 	newWi := WorkItem{
-		ID:      id,
-		Type:    wi.Type,
-		Version: wi.Version + 1,
-		Fields:  Fields{},
+		ID:        id,
+		Type:      wi.Type,
+		Version:   wi.Version + 1,
+		Fields:    Fields{},
+		ProjectID: res.ProjectID,
 	}
 
 	for fieldName, fieldDef := range wiType.Fields {
@@ -129,6 +134,9 @@ func (r *GormWorkItemRepository) Save(ctx context.Context, wi app.WorkItem) (*ap
 			return nil, errors.NewBadParameterError(fieldName, fieldValue)
 		}
 	}
+	if err := checkReferences(r.db, wiType, newWi.ProjectID, newWi.Fields); err != nil {
+		return nil, err
+	}
 
 	tx = tx.Where("Version = ?", wi.Version).Save(&newWi)
 	if err := tx.Error; err != nil {
@@ -146,16 +154,20 @@ func (r *GormWorkItemRepository) Save(ctx context.Context, wi app.WorkItem) (*ap
 	return result, nil
 }
 
-// Create creates a new work item in the repository
+// Create creates a new work item in the given project
 // returns BadParameterError, ConversionError or InternalError
-func (r *GormWorkItemRepository) Create(ctx context.Context, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error) {
+func (r *GormWorkItemRepository) Create(ctx context.Context, projectID uuid.UUID, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error) {
 	wiType, err := r.wir.LoadTypeFromDB(typeID)
 	if err != nil {
 		return nil, errors.NewBadParameterError("type", typeID)
 	}
+	if !wiType.IsUsableInProject(projectID) {
+		return nil, errors.NewBadParameterError("type", typeID).Expected("type of project " + projectID.String())
+	}
 	wi := WorkItem{
-		Type:   typeID,
-		Fields: Fields{},
+		Type:      typeID,
+		Fields:    Fields{},
+		ProjectID: projectID,
 	}
 	fields[SystemCreator] = creator
 	for fieldName, fieldDef := range wiType.Fields {
@@ -166,6 +178,9 @@ func (r *GormWorkItemRepository) Create(ctx context.Context, typeID string, fiel
 			return nil, errors.NewBadParameterError(fieldName, fieldValue)
 		}
 	}
+	if err := checkReferences(r.db, wiType, projectID, wi.Fields); err != nil {
+		return nil, err
+	}
 	tx := r.db
 
 	if err = tx.Create(&wi).Error; err != nil {
@@ -3,9 +3,12 @@ package workitem_test
 import (
 	"testing"
 
+	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
 	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"golang.org/x/net/context"
@@ -29,7 +32,7 @@ func (s *workItemRepoBlackBoxTest) TestFailDeleteZeroID() {
 
 	// Create at least 1 item to avoid RowsEffectedCheck
 	_, err := s.repo.Create(
-		context.Background(), "system.bug",
+		context.Background(), project.SystemProject, "system.bug",
 		map[string]interface{}{
 			workitem.SystemTitle: "Title",
 			workitem.SystemState: workitem.SystemStateNew,
@@ -48,7 +51,7 @@ func (s *workItemRepoBlackBoxTest) TestFailSaveZeroID() {
 
 	// Create at least 1 item to avoid RowsEffectedCheck
 	wi, err := s.repo.Create(
-		context.Background(), "system.bug",
+		context.Background(), project.SystemProject, "system.bug",
 		map[string]interface{}{
 			workitem.SystemTitle: "Title",
 			workitem.SystemState: workitem.SystemStateNew,
@@ -68,7 +71,7 @@ func (s *workItemRepoBlackBoxTest) TestFaiLoadZeroID() {
 
 	// Create at least 1 item to avoid RowsEffectedCheck
 	_, err := s.repo.Create(
-		context.Background(), "system.bug",
+		context.Background(), project.SystemProject, "system.bug",
 		map[string]interface{}{
 			workitem.SystemTitle: "Title",
 			workitem.SystemState: workitem.SystemStateNew,
@@ -81,3 +84,25 @@ func (s *workItemRepoBlackBoxTest) TestFaiLoadZeroID() {
 	_, err = s.repo.Load(context.Background(), "0")
 	require.IsType(s.T(), errors.NotFoundError{}, err)
 }
+
+func (s *workItemRepoBlackBoxTest) TestFailSaveTypeOfOtherProject() {
+	defer gormsupport.DeleteCreatedEntities(s.DB)()
+
+	p, err := project.NewRepository(s.DB).Create(context.Background(), "save-type-test-"+uuid.NewV4().String())
+	require.Nil(s.T(), err)
+	wit, err := workitem.NewWorkItemTypeRepository(s.DB).Create(context.Background(), p.ID, nil, "save-type-test-"+uuid.NewV4().String(), map[string]app.FieldDefinition{})
+	require.Nil(s.T(), err)
+
+	wi, err := s.repo.Create(
+		context.Background(), project.SystemProject, "system.bug",
+		map[string]interface{}{
+			workitem.SystemTitle: "Title",
+			workitem.SystemState: workitem.SystemStateNew,
+		}, "xx")
+	require.Nil(s.T(), err)
+
+	// the type belongs to another project than the work item
+	wi.Type = wit.Name
+	_, err = s.repo.Save(context.Background(), *wi)
+	require.IsType(s.T(), errors.BadParameterError{}, err)
+}
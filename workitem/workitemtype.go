@@ -7,6 +7,8 @@ import (
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/convert"
 	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
+	uuid "github.com/satori/go.uuid"
 )
 
 // String constants for the local work item types.
@@ -20,6 +22,10 @@ const (
 	SystemState        = "system.state"
 	SystemAssignee     = "system.assignee"
 	SystemCreator      = "system.creator"
+	SystemIteration    = "system.iteration"
+	SystemArea         = "system.area"
+	SystemMilestone    = "system.milestone"
+	SystemTeam         = "system.team"
 
 	// base item type with common fields for planner item types like userstory, experience, bug, feature, etc.
 	SystemPlannerItem = "system.planneritem"
@@ -49,6 +55,8 @@ type WorkItemType struct {
 	Path string
 	// definitions of the fields this work item type supports
 	Fields FieldDefinitions `sql:"type:jsonb"`
+	// the project the work item type belongs to
+	ProjectID uuid.UUID `sql:"type:uuid default '2e0698d8-753e-4cef-bb7c-f027634824a2'"`
 }
 
 // TableName implements gorm.tabler
@@ -78,6 +86,9 @@ func (wit WorkItemType) Equal(u convert.Equaler) bool {
 	if wit.Path != other.Path {
 		return false
 	}
+	if !uuid.Equal(wit.ProjectID, other.ProjectID) {
+		return false
+	}
 	if len(wit.Fields) != len(other.Fields) {
 		return false
 	}
@@ -99,6 +110,7 @@ func (wit WorkItemType) ConvertFromModel(workItem WorkItem) (*app.WorkItem, erro
 		ID:      strconv.FormatUint(workItem.ID, 10),
 		Type:    workItem.Type,
 		Version: workItem.Version,
+		Project: &workItem.ProjectID,
 		Fields:  map[string]interface{}{}}
 
 	for name, field := range wit.Fields {
@@ -112,6 +124,13 @@ func (wit WorkItemType) ConvertFromModel(workItem WorkItem) (*app.WorkItem, erro
 	return &result, nil
 }
 
+// IsUsableInProject returns true if work items of this type can be created in
+// the given project. The types owned by the system project are usable in every
+// project.
+func (wit WorkItemType) IsUsableInProject(projectID uuid.UUID) bool {
+	return uuid.Equal(wit.ProjectID, projectID) || uuid.Equal(wit.ProjectID, project.SystemProject)
+}
+
 // IsTypeOrSubtypeOf returns true if the work item type is of the given type name,
 // or a subtype; otherwise false is returned.
 func (wit WorkItemType) IsTypeOrSubtypeOf(typeName string) bool {
@@ -9,14 +9,16 @@ import (
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/project"
 	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
 )
 
 // WorkItemTypeRepository encapsulates storage & retrieval of work item types
 type WorkItemTypeRepository interface {
 	Load(ctx context.Context, name string) (*app.WorkItemType, error)
-	Create(ctx context.Context, extendedTypeID *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error)
-	List(ctx context.Context, start *int, length *int) ([]*app.WorkItemType, error)
+	Create(ctx context.Context, projectID uuid.UUID, extendedTypeID *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error)
+	List(ctx context.Context, projectID uuid.UUID, start *int, length *int) ([]*app.WorkItemType, error)
 }
 
 // NewWorkItemRepository creates a wi repository based on gorm
@@ -68,9 +70,9 @@ func (r *GormWorkItemTypeRepository) LoadTypeFromDB(name string) (*WorkItemType,
 	return &res, nil
 }
 
-// Create creates a new work item in the repository
+// Create creates a new work item type in the given project
 // returns BadParameterError, ConversionError or InternalError
-func (r *GormWorkItemTypeRepository) Create(ctx context.Context, extendedTypeName *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error) {
+func (r *GormWorkItemTypeRepository) Create(ctx context.Context, projectID uuid.UUID, extendedTypeName *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error) {
 	existing, _ := r.LoadTypeFromDB(name)
 	if existing != nil {
 		log.Printf("creating type %s again", name)
@@ -88,6 +90,9 @@ func (r *GormWorkItemTypeRepository) Create(ctx context.Context, extendedTypeNam
 		if err := db.Error; err != nil {
 			return nil, errors.NewInternalError(err.Error())
 		}
+		if !extendedType.IsUsableInProject(projectID) {
+			return nil, errors.NewBadParameterError("extendedTypeName", *extendedTypeName).Expected("type of project " + projectID.String())
+		}
 		// copy fields from extended type
 		for key, value := range extendedType.Fields {
 			allFields[key] = value
@@ -113,10 +118,11 @@ func (r *GormWorkItemTypeRepository) Create(ctx context.Context, extendedTypeNam
 	}
 
 	created := WorkItemType{
-		Version: 0,
-		Name:    name,
-		Path:    path,
-		Fields:  allFields,
+		Version:   0,
+		Name:      name,
+		Path:      path,
+		Fields:    allFields,
+		ProjectID: projectID,
 	}
 
 	if err := r.db.Save(&created).Error; err != nil {
@@ -127,12 +133,12 @@ func (r *GormWorkItemTypeRepository) Create(ctx context.Context, extendedTypeNam
 	return &result, nil
 }
 
-// List returns work item types selected by the given criteria.Expression, starting with start (zero-based) and returning at most "limit" item types
-func (r *GormWorkItemTypeRepository) List(ctx context.Context, start *int, limit *int) ([]*app.WorkItemType, error) {
-	// Currently we don't implement filtering here, so leave this empty
+// List returns the work item types usable in the given project, starting with start (zero-based) and returning at most "limit" item types.
+// Those are the types of the project itself as well as the system types.
+func (r *GormWorkItemTypeRepository) List(ctx context.Context, projectID uuid.UUID, start *int, limit *int) ([]*app.WorkItemType, error) {
 	// TODO: (kwk) implement criteria parsing just like for work items
-	var where string
-	var parameters []interface{}
+	where := "project_id in (?)"
+	parameters := []interface{}{[]uuid.UUID{projectID, project.SystemProject}}
 
 	var rows []WorkItemType
 	db := r.db.Where(where, parameters...)
@@ -161,9 +167,11 @@ func compatibleFields(existing FieldDefinition, new FieldDefinition) bool {
 
 // converts from models to app representation
 func convertTypeFromModels(t *WorkItemType) app.WorkItemType {
+	projectID := t.ProjectID
 	var converted = app.WorkItemType{
 		Name:    t.Name,
 		Version: t.Version,
+		Project: &projectID,
 		Fields:  map[string]*app.FieldDefinition{},
 	}
 	for name, def := range t.Fields {
@@ -205,7 +213,7 @@ func convertAnyToKind(any interface{}) (*Kind, error) {
 func convertStringToKind(k string) (*Kind, error) {
 	kind := Kind(k)
 	switch kind {
-	case KindString, KindInteger, KindFloat, KindInstant, KindDuration, KindURL, KindWorkitemReference, KindUser, KindEnum, KindList:
+	case KindString, KindInteger, KindFloat, KindInstant, KindDuration, KindURL, KindWorkitemReference, KindUser, KindEnum, KindList, KindIteration, KindArea, KindMilestone, KindTeam:
 		return &kind, nil
 	}
 	return nil, fmt.Errorf("Not a simple type")
@@ -8,6 +8,7 @@ import (
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/project"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -41,7 +42,7 @@ func (s *workItemTypeRepoBlackBoxTest) TearDownTest() {
 
 func (s *workItemTypeRepoBlackBoxTest) TestCreateLoadWIT() {
 
-	wit, err := s.repo.Create(context.Background(), nil, "foo.bar", map[string]app.FieldDefinition{
+	wit, err := s.repo.Create(context.Background(), project.SystemProject, nil, "foo.bar", map[string]app.FieldDefinition{
 		"foo": app.FieldDefinition{
 			Required: true,
 			Type:     &app.FieldType{Kind: string(workitem.KindFloat)},
@@ -50,7 +51,7 @@ func (s *workItemTypeRepoBlackBoxTest) TestCreateLoadWIT() {
 	assert.Nil(s.T(), err)
 	assert.NotNil(s.T(), wit)
 
-	wit3, err := s.repo.Create(context.Background(), nil, "foo.bar", map[string]app.FieldDefinition{})
+	wit3, err := s.repo.Create(context.Background(), project.SystemProject, nil, "foo.bar", map[string]app.FieldDefinition{})
 	assert.IsType(s.T(), errors.BadParameterError{}, err)
 	assert.Nil(s.T(), wit3)
 
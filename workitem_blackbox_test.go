@@ -18,9 +18,11 @@ import (
 	"github.com/almighty/almighty-core/app/test"
 	"github.com/almighty/almighty-core/configuration"
 	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/migration"
 	"github.com/almighty/almighty-core/models"
+	"github.com/almighty/almighty-core/project"
 	"github.com/almighty/almighty-core/resource"
 	testsupport "github.com/almighty/almighty-core/test"
 	almtoken "github.com/almighty/almighty-core/token"
@@ -50,7 +52,7 @@ func TestGetWorkItem(t *testing.T) {
 			workitem.SystemState:   "closed"},
 	}
 
-	_, result := test.CreateWorkitemCreated(t, svc.Context, svc, controller, &payload)
+	_, result := test.CreateWorkitemCreated(t, svc.Context, svc, controller, nil, &payload)
 
 	_, wi := test.ShowWorkitemOK(t, nil, nil, controller, result.ID)
 
@@ -102,7 +104,7 @@ func TestCreateWI(t *testing.T) {
 		},
 	}
 
-	_, created := test.CreateWorkitemCreated(t, svc.Context, svc, controller, &payload)
+	_, created := test.CreateWorkitemCreated(t, svc.Context, svc, controller, nil, &payload)
 	if created.ID == "" {
 		t.Error("no id")
 	}
@@ -124,7 +126,7 @@ func TestCreateWorkItemWithoutContext(t *testing.T) {
 			workitem.SystemState:   workitem.SystemStateNew,
 		},
 	}
-	test.CreateWorkitemUnauthorized(t, svc.Context, svc, controller, &payload)
+	test.CreateWorkitemUnauthorized(t, svc.Context, svc, controller, nil, &payload)
 }
 
 func TestListByFields(t *testing.T) {
@@ -144,11 +146,11 @@ func TestListByFields(t *testing.T) {
 		},
 	}
 
-	_, wi := test.CreateWorkitemCreated(t, svc.Context, svc, controller, &payload)
+	_, wi := test.CreateWorkitemCreated(t, svc.Context, svc, controller, nil, &payload)
 
 	filter := "{\"system.title\":\"run integration test\"}"
 	page := "0,1"
-	_, result := test.ListWorkitemOK(t, nil, nil, controller, &filter, &page)
+	_, result := test.ListWorkitemOK(t, svc.Context, svc, controller, &filter, &page)
 
 	if result == nil {
 		t.Errorf("nil result")
@@ -159,7 +161,7 @@ func TestListByFields(t *testing.T) {
 	}
 
 	filter = fmt.Sprintf("{\"system.creator\":\"%s\"}", account.TestIdentity.ID.String())
-	_, result = test.ListWorkitemOK(t, nil, nil, controller, &filter, &page)
+	_, result = test.ListWorkitemOK(t, svc.Context, svc, controller, &filter, &page)
 
 	if result == nil {
 		t.Errorf("nil result")
@@ -172,6 +174,35 @@ func TestListByFields(t *testing.T) {
 	test.DeleteWorkitemOK(t, nil, nil, controller, wi.ID)
 }
 
+func TestListOnlyReadableProjects(t *testing.T) {
+	resource.Require(t, resource.Database)
+	defer gormsupport.DeleteCreatedEntities(DB)()
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+	svc := testsupport.ServiceAsUser("TestListOnlyReadableProjects-Service", almtoken.NewManager(pub, priv), account.TestIdentity)
+	controller := NewWorkitem2Controller(svc, gormapplication.NewGormDB(DB))
+
+	ctx := context.Background()
+	p, err := project.NewRepository(DB).Create(ctx, "TestListOnlyReadableProjects-"+uuid.NewV4().String())
+	require.Nil(t, err)
+	title := "TestListOnlyReadableProjects-" + uuid.NewV4().String()
+	_, err = workitem.NewWorkItemRepository(DB).Create(ctx, p.ID, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle: title,
+		workitem.SystemState: workitem.SystemStateNew,
+	}, account.TestIdentity.ID.String())
+	require.Nil(t, err)
+	filter := fmt.Sprintf("{\"system.title\":\"%s\"}", title)
+
+	// the work items of projects the user is not a member of are left out
+	_, result := test.ListWorkitem2OK(t, svc.Context, svc, controller, &filter, nil, nil)
+	assert.Empty(t, result.Data)
+
+	_, err = project.NewMembershipRepository(DB).Create(ctx, p.ID, account.TestIdentity.ID, project.RoleViewer)
+	require.Nil(t, err)
+	_, result = test.ListWorkitem2OK(t, svc.Context, svc, controller, &filter, nil, nil)
+	assert.Len(t, result.Data, 1)
+}
+
 func getWorkItemTestData(t *testing.T) []testSecureAPI {
 	privatekey, err := jwt.ParseRSAPrivateKeyFromPEM((configuration.GetTokenPrivateKey()))
 	if err != nil {
@@ -325,6 +356,29 @@ func getWorkItemTestData(t *testing.T) []testSecureAPI {
 			payload:            createWIPayloadString,
 			jwtToken:           "",
 		},
+		// Reading work items requires a token as well
+		{
+			method:             http.MethodGet,
+			url:                endpointWorkItems + "/088481764871",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedErrorCode:  jsonapi.ErrorCodeJWTSecurityError,
+			payload:            nil,
+			jwtToken:           "",
+		}, {
+			method:             http.MethodGet,
+			url:                endpointWorkItems,
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedErrorCode:  jsonapi.ErrorCodeJWTSecurityError,
+			payload:            nil,
+			jwtToken:           "",
+		}, {
+			method:             http.MethodGet,
+			url:                "/api/workitems.2",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedErrorCode:  jsonapi.ErrorCodeJWTSecurityError,
+			payload:            nil,
+			jwtToken:           "",
+		},
 	}
 }
 
@@ -341,12 +395,12 @@ func TestUnauthorizeWorkItemCUD(t *testing.T) {
 	})
 }
 
-func createPagingTest(t *testing.T, controller *Workitem2Controller, repo *testsupport.WorkItemRepository, totalCount int) func(start int, limit int, first string, last string, prev string, next string) {
+func createPagingTest(t *testing.T, ctx context.Context, controller *Workitem2Controller, repo *testsupport.WorkItemRepository, totalCount int) func(start int, limit int, first string, last string, prev string, next string) {
 	return func(start int, limit int, first string, last string, prev string, next string) {
 		count := computeCount(totalCount, int(start), int(limit))
 		repo.ListReturns(makeWorkItems(count), uint64(totalCount), nil)
 		offset := strconv.Itoa(start)
-		_, response := test.ListWorkitem2OK(t, context.Background(), nil, controller, nil, &limit, &offset)
+		_, response := test.ListWorkitem2OK(t, ctx, nil, controller, nil, &limit, &offset)
 		assertLink(t, "first", first, response.Links.First)
 		assertLink(t, "last", last, response.Links.Last)
 		assertLink(t, "prev", prev, response.Links.Prev)
@@ -393,13 +447,15 @@ func makeWorkItems(count int) []*app.WorkItem {
 
 func TestPagingLinks(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
-	svc := goa.New("TestPaginLinks-Service")
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+	svc := testsupport.ServiceAsUser("TestPaginLinks-Service", almtoken.NewManager(pub, priv), account.TestIdentity)
 	assert.NotNil(t, svc)
 	db := testsupport.NewMockDB()
 	controller := NewWorkitem2Controller(svc, db)
 
 	repo := db.WorkItems().(*testsupport.WorkItemRepository)
-	pagingTest := createPagingTest(t, controller, repo, 13)
+	pagingTest := createPagingTest(t, svc.Context, controller, repo, 13)
 	pagingTest(2, 5, "page[offset]=0&page[limit]=2", "page[offset]=12&page[limit]=5", "page[offset]=0&page[limit]=2", "page[offset]=7&page[limit]=5")
 	pagingTest(10, 3, "page[offset]=0&page[limit]=1", "page[offset]=10&page[limit]=3", "page[offset]=7&page[limit]=3", "")
 	pagingTest(0, 4, "page[offset]=0&page[limit]=4", "page[offset]=12&page[limit]=4", "", "page[offset]=4&page[limit]=4")
@@ -411,13 +467,15 @@ func TestPagingLinks(t *testing.T) {
 	pagingTest(3, 50, "page[offset]=0&page[limit]=3", "page[offset]=3&page[limit]=50", "page[offset]=0&page[limit]=3", "")
 	pagingTest(0, 50, "page[offset]=0&page[limit]=50", "page[offset]=0&page[limit]=50", "", "")
 
-	pagingTest = createPagingTest(t, controller, repo, 0)
+	pagingTest = createPagingTest(t, svc.Context, controller, repo, 0)
 	pagingTest(2, 5, "page[offset]=0&page[limit]=2", "page[offset]=0&page[limit]=2", "", "")
 }
 
 func TestPagingErrors(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
-	svc := goa.New("TestPaginErrors-Service")
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+	svc := testsupport.ServiceAsUser("TestPaginErrors-Service", almtoken.NewManager(pub, priv), account.TestIdentity)
 	db := testsupport.NewMockDB()
 	controller := NewWorkitem2Controller(svc, db)
 	repo := db.WorkItems().(*testsupport.WorkItemRepository)
@@ -425,28 +483,28 @@ func TestPagingErrors(t *testing.T) {
 
 	var offset string = "-1"
 	var limit int = 2
-	_, result := test.ListWorkitem2OK(t, context.Background(), nil, controller, nil, &limit, &offset)
+	_, result := test.ListWorkitem2OK(t, svc.Context, nil, controller, nil, &limit, &offset)
 	if !strings.Contains(*result.Links.First, "page[offset]=0") {
 		assert.Fail(t, "Offset is negative", "Expected offset to be %d, but was %s", 0, *result.Links.First)
 	}
 
 	offset = "0"
 	limit = 0
-	_, result = test.ListWorkitem2OK(t, context.Background(), nil, controller, nil, &limit, &offset)
+	_, result = test.ListWorkitem2OK(t, svc.Context, nil, controller, nil, &limit, &offset)
 	if !strings.Contains(*result.Links.First, "page[limit]=20") {
 		assert.Fail(t, "Limit is 0", "Expected limit to be default size %d, but was %s", 20, *result.Links.First)
 	}
 
 	offset = "0"
 	limit = -1
-	_, result = test.ListWorkitem2OK(t, context.Background(), nil, controller, nil, &limit, &offset)
+	_, result = test.ListWorkitem2OK(t, svc.Context, nil, controller, nil, &limit, &offset)
 	if !strings.Contains(*result.Links.First, "page[limit]=20") {
 		assert.Fail(t, "Limit is negative", "Expected limit to be default size %d, but was %s", 20, *result.Links.First)
 	}
 
 	offset = "-3"
 	limit = -1
-	_, result = test.ListWorkitem2OK(t, context.Background(), nil, controller, nil, &limit, &offset)
+	_, result = test.ListWorkitem2OK(t, svc.Context, nil, controller, nil, &limit, &offset)
 	if !strings.Contains(*result.Links.First, "page[limit]=20") {
 		assert.Fail(t, "Limit is negative", "Expected limit to be default size %d, but was %s", 20, *result.Links.First)
 	}
@@ -456,7 +514,7 @@ func TestPagingErrors(t *testing.T) {
 
 	offset = "ALPHA"
 	limit = 40
-	_, result = test.ListWorkitem2OK(t, context.Background(), nil, controller, nil, &limit, &offset)
+	_, result = test.ListWorkitem2OK(t, svc.Context, nil, controller, nil, &limit, &offset)
 	if !strings.Contains(*result.Links.First, "page[limit]=40") {
 		assert.Fail(t, "Limit is within range", "Expected limit to be size %d, but was %s", 40, *result.Links.First)
 	}
@@ -467,7 +525,9 @@ func TestPagingErrors(t *testing.T) {
 
 func TestPagingLinksHasAbsoluteURL(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
-	svc := goa.New("TestPaginAbsoluteURL-Service")
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+	svc := testsupport.ServiceAsUser("TestPaginAbsoluteURL-Service", almtoken.NewManager(pub, priv), account.TestIdentity)
 	db := testsupport.NewMockDB()
 	controller := NewWorkitem2Controller(svc, db)
 
@@ -477,7 +537,7 @@ func TestPagingLinksHasAbsoluteURL(t *testing.T) {
 	repo := db.WorkItems().(*testsupport.WorkItemRepository)
 	repo.ListReturns(makeWorkItems(10), uint64(100), nil)
 
-	_, result := test.ListWorkitem2OK(t, context.Background(), nil, controller, nil, &limit, &offset)
+	_, result := test.ListWorkitem2OK(t, svc.Context, nil, controller, nil, &limit, &offset)
 	if !strings.HasPrefix(*result.Links.First, "http://") {
 		assert.Fail(t, "Not Absolute URL", "Expected link %s to contain absolute URL but was %s", "First", *result.Links.First)
 	}
@@ -494,7 +554,9 @@ func TestPagingLinksHasAbsoluteURL(t *testing.T) {
 
 func TestPagingDefaultAndMaxSize(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
-	svc := goa.New("TestPaginSize-Service")
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+	svc := testsupport.ServiceAsUser("TestPaginSize-Service", almtoken.NewManager(pub, priv), account.TestIdentity)
 	db := testsupport.NewMockDB()
 	controller := NewWorkitem2Controller(svc, db)
 
@@ -503,23 +565,33 @@ func TestPagingDefaultAndMaxSize(t *testing.T) {
 	repo := db.WorkItems().(*testsupport.WorkItemRepository)
 	repo.ListReturns(makeWorkItems(10), uint64(100), nil)
 
-	_, result := test.ListWorkitem2OK(t, context.Background(), nil, controller, nil, nil, &offset)
+	_, result := test.ListWorkitem2OK(t, svc.Context, nil, controller, nil, nil, &offset)
 	if !strings.Contains(*result.Links.First, "page[limit]=20") {
 		assert.Fail(t, "Limit is nil", "Expected limit to be default size %d, got %v", 20, *result.Links.First)
 	}
 	limit = 1000
-	_, result = test.ListWorkitem2OK(t, context.Background(), nil, controller, nil, &limit, &offset)
+	_, result = test.ListWorkitem2OK(t, svc.Context, nil, controller, nil, &limit, &offset)
 	if !strings.Contains(*result.Links.First, "page[limit]=100") {
 		assert.Fail(t, "Limit is more than max", "Expected limit to be %d, got %v", 100, *result.Links.First)
 	}
 
 	limit = 50
-	_, result = test.ListWorkitem2OK(t, context.Background(), nil, controller, nil, &limit, &offset)
+	_, result = test.ListWorkitem2OK(t, svc.Context, nil, controller, nil, &limit, &offset)
 	if !strings.Contains(*result.Links.First, "page[limit]=50") {
 		assert.Fail(t, "Limit is within range", "Expected limit to be %d, got %v", 50, *result.Links.First)
 	}
 }
 
+func TestListWorkItemsAnonymously(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	svc := goa.New("TestListAnonymously-Service")
+	db := testsupport.NewMockDB()
+	controller := NewWorkitem2Controller(svc, db)
+	db.WorkItems().(*testsupport.WorkItemRepository).ListReturns(makeWorkItems(10), uint64(10), nil)
+
+	test.ListWorkitem2Unauthorized(t, context.Background(), nil, controller, nil, nil, nil)
+}
+
 // ========== helper functions for tests inside WorkItem2Suite ==========
 func getMinimumRequiredUpdatePayload(wi *app.WorkItem) *app.UpdateWorkItemJSONAPIPayload {
 	return &app.UpdateWorkItemJSONAPIPayload{
@@ -608,7 +680,7 @@ func (s *WorkItem2Suite) SetupTest() {
 			workitem.SystemTitle: "Test WI",
 			workitem.SystemState: "new"},
 	}
-	_, s.wi = test.CreateWorkitemCreated(s.T(), s.svc.Context, s.svc, s.wiCtrl, &payload)
+	_, s.wi = test.CreateWorkitemCreated(s.T(), s.svc.Context, s.svc, s.wiCtrl, nil, &payload)
 	s.minimumPayload = getMinimumRequiredUpdatePayload(s.wi)
 
 }
@@ -38,18 +38,27 @@ func (c *WorkitemtypeController) Show(ctx *app.ShowWorkitemtypeContext) error {
 // Create runs the create action.
 func (c *WorkitemtypeController) Create(ctx *app.CreateWorkitemtypeContext) error {
 	return application.Transactional(c.db, func(appl application.Application) error {
+		projectID, err := projectOrSystem(ctx.Context, appl, ctx.Project)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
 		var fields = map[string]app.FieldDefinition{}
 
 		for key, fd := range ctx.Payload.Fields {
 			fields[key] = *fd
 		}
-		wit, err := appl.WorkItemTypes().Create(ctx.Context, ctx.Payload.ExtendedTypeName, ctx.Payload.Name, fields)
+		wit, err := appl.WorkItemTypes().Create(ctx.Context, projectID, ctx.Payload.ExtendedTypeName, ctx.Payload.Name, fields)
 
 		if err != nil {
 			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
 			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
 		}
-		ctx.ResponseData.Header().Set("Location", app.WorkitemtypeHref(wit.Name))
+		if ctx.Project != nil {
+			ctx.ResponseData.Header().Set("Location", app.ProjectWorkItemTypesHref(projectID, wit.Name))
+		} else {
+			ctx.ResponseData.Header().Set("Location", app.WorkitemtypeHref(wit.Name))
+		}
 		return ctx.Created(wit)
 	})
 }
@@ -62,7 +71,12 @@ func (c *WorkitemtypeController) List(ctx *app.ListWorkitemtypeContext) error {
 		return ctx.BadRequest(jerrors)
 	}
 	return application.Transactional(c.db, func(appl application.Application) error {
-		result, err := appl.WorkItemTypes().List(ctx.Context, start, &limit)
+		projectID, err := projectOrSystem(ctx.Context, appl, ctx.Project)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		result, err := appl.WorkItemTypes().List(ctx.Context, projectID, start, &limit)
 		if err != nil {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("Error listing work item types: %s", err.Error())))
 			return ctx.BadRequest(jerrors)
@@ -138,7 +138,7 @@ func (s *WorkItemTypeSuite) createWorkItemTypeAnimal() (http.ResponseWriter, *ap
 		Name: "animal",
 	}
 
-	return test.CreateWorkitemtypeCreated(s.T(), nil, nil, s.typeCtrl, &payload)
+	return test.CreateWorkitemtypeCreated(s.T(), nil, nil, s.typeCtrl, nil, &payload)
 }
 
 // createWorkItemTypePerson defines a work item type "person" that consists of
@@ -161,7 +161,7 @@ func (s *WorkItemTypeSuite) createWorkItemTypePerson() (http.ResponseWriter, *ap
 		Name: "person",
 	}
 
-	return test.CreateWorkitemtypeCreated(s.T(), nil, nil, s.typeCtrl, &payload)
+	return test.CreateWorkitemtypeCreated(s.T(), nil, nil, s.typeCtrl, nil, &payload)
 }
 
 //-----------------------------------------------------------------------------
@@ -203,7 +203,7 @@ func (s *WorkItemTypeSuite) TestListWorkItemType() {
 	// Fetch a single work item type
 	// Paging in the format <start>,<limit>"
 	page := "0,-1"
-	_, witCollection := test.ListWorkitemtypeOK(s.T(), nil, nil, s.typeCtrl, &page)
+	_, witCollection := test.ListWorkitemtypeOK(s.T(), nil, nil, s.typeCtrl, &page, nil)
 
 	assert.NotNil(s.T(), witCollection)
 	assert.Nil(s.T(), witCollection.Validate())